@@ -0,0 +1,310 @@
+// Package archive exports workflow history from a store to line-delimited JSON
+// and imports it into another store, for archival and store migration.
+//
+//	f, _ := os.Create("runs.jsonl")
+//	stats, err := archive.Export(ctx, libsqlStore, gorkflow.RunFilter{}, f)
+//
+//	f, _ = os.Open("runs.jsonl")
+//	stats, err = archive.Import(ctx, postgresStore, f)
+//
+// An archive starts with a header record followed by, for each run, a run record
+// and the run's step execution, step output and state records. Records are written
+// and read one at a time, so step data never has to fit in memory at once.
+package archive
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/sicko7947/gorkflow"
+)
+
+// Format identifies gorkflow archives in the header record
+const Format = "gorkflow-archive"
+
+// Version is the archive format version written by Export
+const Version = 1
+
+// Record types
+const (
+	RecordHeader = "header"
+	RecordRun    = "run"
+	RecordStep   = "step"
+	RecordOutput = "output"
+	RecordState  = "state"
+)
+
+// ErrUnsupportedArchive indicates the input is not an archive this package can read
+var ErrUnsupportedArchive = errors.New("unsupported archive")
+
+// Record is a single line of an archive
+type Record struct {
+	Type string `json:"type"`
+
+	// Header fields
+	Format     string     `json:"format,omitempty"`
+	Version    int        `json:"version,omitempty"`
+	ExportedAt *time.Time `json:"exportedAt,omitempty"`
+
+	// Run and step execution records
+	Run  *gorkflow.WorkflowRun   `json:"run,omitempty"`
+	Step *gorkflow.StepExecution `json:"step,omitempty"`
+
+	// Output and state records
+	RunID  string `json:"runId,omitempty"`
+	StepID string `json:"stepId,omitempty"`
+	Key    string `json:"key,omitempty"`
+	Data   []byte `json:"data,omitempty"`
+}
+
+// Stats counts the records exported or imported
+type Stats struct {
+	Runs           int `json:"runs"`
+	StepExecutions int `json:"stepExecutions"`
+	Outputs        int `json:"outputs"`
+	StateEntries   int `json:"stateEntries"`
+
+	// SkippedRuns counts imported runs that already existed in the target store
+	SkippedRuns int `json:"skippedRuns"`
+}
+
+// exportPageSize is the number of runs Export lists at a time
+const exportPageSize = 100
+
+// Export writes the runs matching filter, with their step executions, step
+// outputs and state, to w. Runs are listed a page at a time, each page starting
+// after the last run of the previous one; filter.Limit and filter.Offset apply
+// to the whole export.
+func Export(ctx context.Context, store gorkflow.WorkflowStore, filter gorkflow.RunFilter, w io.Writer) (Stats, error) {
+	var stats Stats
+
+	bw := bufio.NewWriter(w)
+	enc := json.NewEncoder(bw)
+
+	now := time.Now().UTC()
+	if err := enc.Encode(Record{Type: RecordHeader, Format: Format, Version: Version, ExportedAt: &now}); err != nil {
+		return stats, fmt.Errorf("failed to write header: %w", err)
+	}
+
+	remaining := filter.Limit // 0 or less exports every matching run
+	page := filter
+	for {
+		page.Limit = exportPageSize
+		if remaining > 0 {
+			page.Limit = min(remaining, exportPageSize)
+		}
+		runs, err := store.ListRuns(ctx, page)
+		if err != nil {
+			return stats, fmt.Errorf("failed to list runs: %w", err)
+		}
+
+		for _, run := range runs {
+			if err := ctx.Err(); err != nil {
+				return stats, err
+			}
+			if err := exportRun(ctx, store, enc, run, &stats); err != nil {
+				return stats, fmt.Errorf("failed to export run %s: %w", run.RunID, err)
+			}
+		}
+
+		if len(runs) < page.Limit {
+			break
+		}
+		// Runs created or purged meanwhile would shift an offset
+		last := runs[len(runs)-1]
+		page.After = &gorkflow.RunCursor{CreatedAt: last.CreatedAt, RunID: last.RunID}
+		page.Offset = 0
+		if remaining > 0 {
+			remaining -= len(runs)
+			if remaining == 0 {
+				break
+			}
+		}
+	}
+
+	if err := bw.Flush(); err != nil {
+		return stats, fmt.Errorf("failed to flush archive: %w", err)
+	}
+	return stats, nil
+}
+
+// exportRun writes one run and its related records
+func exportRun(ctx context.Context, store gorkflow.WorkflowStore, enc *json.Encoder, run *gorkflow.WorkflowRun, stats *Stats) error {
+	if err := enc.Encode(Record{Type: RecordRun, Run: run}); err != nil {
+		return err
+	}
+	stats.Runs++
+
+	execs, err := store.ListStepExecutions(ctx, run.RunID)
+	if err != nil {
+		return fmt.Errorf("failed to list step executions: %w", err)
+	}
+	for _, exec := range execs {
+		if err := enc.Encode(Record{Type: RecordStep, Step: exec}); err != nil {
+			return err
+		}
+		stats.StepExecutions++
+
+		output, err := store.LoadStepOutput(ctx, run.RunID, exec.StepID)
+		if errors.Is(err, gorkflow.ErrStepOutputNotFound) {
+			continue
+		}
+		if err != nil {
+			return fmt.Errorf("failed to load output of step %s: %w", exec.StepID, err)
+		}
+		if err := enc.Encode(Record{Type: RecordOutput, RunID: run.RunID, StepID: exec.StepID, Data: output}); err != nil {
+			return err
+		}
+		stats.Outputs++
+	}
+
+	state, err := store.GetAllState(ctx, run.RunID)
+	if err != nil {
+		return fmt.Errorf("failed to load state: %w", err)
+	}
+	for key, value := range state {
+		if err := enc.Encode(Record{Type: RecordState, RunID: run.RunID, Key: key, Data: value}); err != nil {
+			return err
+		}
+		stats.StateEntries++
+	}
+	return nil
+}
+
+// Import reads an archive from r and writes its records to store. Runs that
+// already exist in store are not overwritten; only their step executions,
+// outputs and state entries missing from store are written. An interrupted
+// import can therefore be repeated and completes the runs it left partial.
+func Import(ctx context.Context, store gorkflow.WorkflowStore, r io.Reader) (Stats, error) {
+	var stats Stats
+
+	dec := json.NewDecoder(bufio.NewReader(r))
+
+	var header Record
+	if err := dec.Decode(&header); err != nil {
+		return stats, fmt.Errorf("failed to read header: %w", err)
+	}
+	if header.Type != RecordHeader || header.Format != Format {
+		return stats, fmt.Errorf("%w: missing %s header", ErrUnsupportedArchive, Format)
+	}
+	if header.Version < 1 || header.Version > Version {
+		return stats, fmt.Errorf("%w: version %d", ErrUnsupportedArchive, header.Version)
+	}
+
+	// Runs whose records are being imported, true if the run already existed
+	existing := make(map[string]bool)
+
+	for line := 2; ; line++ {
+		if err := ctx.Err(); err != nil {
+			return stats, err
+		}
+
+		var rec Record
+		err := dec.Decode(&rec)
+		if err == io.EOF {
+			return stats, nil
+		}
+		if err != nil {
+			return stats, fmt.Errorf("failed to read record %d: %w", line, err)
+		}
+
+		if err := importRecord(ctx, store, &rec, existing, &stats); err != nil {
+			return stats, fmt.Errorf("record %d: %w", line, err)
+		}
+	}
+}
+
+// importRecord writes a single archive record to store. Records of runs that
+// already existed are written only if store does not have them.
+func importRecord(ctx context.Context, store gorkflow.WorkflowStore, rec *Record, existing map[string]bool, stats *Stats) error {
+	switch rec.Type {
+	case RecordRun:
+		if rec.Run == nil {
+			return fmt.Errorf("run record without run")
+		}
+		if _, err := store.GetRun(ctx, rec.Run.RunID); err == nil {
+			existing[rec.Run.RunID] = true
+			stats.SkippedRuns++
+			return nil
+		} else if !errors.Is(err, gorkflow.ErrRunNotFound) {
+			return fmt.Errorf("failed to check run %s: %w", rec.Run.RunID, err)
+		}
+		if err := store.CreateRun(ctx, rec.Run); err != nil {
+			return fmt.Errorf("failed to create run %s: %w", rec.Run.RunID, err)
+		}
+		existing[rec.Run.RunID] = false
+		stats.Runs++
+
+	case RecordStep:
+		if rec.Step == nil {
+			return fmt.Errorf("step record without step execution")
+		}
+		write, err := shouldWrite(existing, rec.Step.RunID, gorkflow.ErrStepExecutionNotFound, func() error {
+			_, err := store.GetStepExecution(ctx, rec.Step.RunID, rec.Step.StepID)
+			return err
+		})
+		if !write {
+			return err
+		}
+		if err := store.CreateStepExecution(ctx, rec.Step); err != nil {
+			return fmt.Errorf("failed to create step execution %s/%s: %w", rec.Step.RunID, rec.Step.StepID, err)
+		}
+		stats.StepExecutions++
+
+	case RecordOutput:
+		write, err := shouldWrite(existing, rec.RunID, gorkflow.ErrStepOutputNotFound, func() error {
+			_, err := store.LoadStepOutput(ctx, rec.RunID, rec.StepID)
+			return err
+		})
+		if !write {
+			return err
+		}
+		if err := store.SaveStepOutput(ctx, rec.RunID, rec.StepID, rec.Data); err != nil {
+			return fmt.Errorf("failed to save output %s/%s: %w", rec.RunID, rec.StepID, err)
+		}
+		stats.Outputs++
+
+	case RecordState:
+		write, err := shouldWrite(existing, rec.RunID, gorkflow.ErrStateNotFound, func() error {
+			_, err := store.LoadState(ctx, rec.RunID, rec.Key)
+			return err
+		})
+		if !write {
+			return err
+		}
+		if err := store.SaveState(ctx, rec.RunID, rec.Key, rec.Data); err != nil {
+			return fmt.Errorf("failed to save state %s/%s: %w", rec.RunID, rec.Key, err)
+		}
+		stats.StateEntries++
+
+	default:
+		return fmt.Errorf("%w: unknown record type %q", ErrUnsupportedArchive, rec.Type)
+	}
+	return nil
+}
+
+// shouldWrite reports whether a record of runID should be written. Records
+// must follow their run record. Records of runs that already existed are
+// written only if load fails with notFound.
+func shouldWrite(existing map[string]bool, runID string, notFound error, load func() error) (bool, error) {
+	exists, seen := existing[runID]
+	if !seen {
+		return false, fmt.Errorf("record for run %s precedes its run record", runID)
+	}
+	if !exists {
+		return true, nil
+	}
+	err := load()
+	if errors.Is(err, notFound) {
+		return true, nil
+	}
+	if err != nil {
+		return false, fmt.Errorf("failed to check record of run %s: %w", runID, err)
+	}
+	return false, nil
+}
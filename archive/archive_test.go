@@ -0,0 +1,270 @@
+package archive_test
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/sicko7947/gorkflow"
+	"github.com/sicko7947/gorkflow/archive"
+	"github.com/sicko7947/gorkflow/engine"
+	"github.com/sicko7947/gorkflow/store"
+)
+
+type greetInput struct {
+	Name string `json:"name"`
+}
+
+type greetOutput struct {
+	Greeting string `json:"greeting"`
+}
+
+// seedStore runs a small workflow n times and returns the run IDs
+func seedStore(t *testing.T, s gorkflow.WorkflowStore, n int) []string {
+	t.Helper()
+
+	greet := gorkflow.NewStep("greet", "Greet", func(ctx *gorkflow.StepContext, in greetInput) (greetOutput, error) {
+		if err := ctx.State.Set("greeted", in.Name); err != nil {
+			return greetOutput{}, err
+		}
+		return greetOutput{Greeting: "hello " + in.Name}, nil
+	})
+	shout := gorkflow.NewStep("shout", "Shout", func(ctx *gorkflow.StepContext, in greetOutput) (greetOutput, error) {
+		return greetOutput{Greeting: strings.ToUpper(in.Greeting)}, nil
+	})
+	wf, err := gorkflow.NewWorkflow("greeter", "Greeter").ThenStep(greet).ThenStep(shout).Build()
+	require.NoError(t, err)
+
+	eng := engine.NewEngine(s, engine.WithLogger(zerolog.Nop()))
+	runIDs := make([]string, 0, n)
+	for i := 0; i < n; i++ {
+		runID, err := eng.StartWorkflow(context.Background(), wf, greetInput{Name: "ada"}, gorkflow.WithSynchronousExecution())
+		require.NoError(t, err)
+		runIDs = append(runIDs, runID)
+	}
+	return runIDs
+}
+
+func TestExportImport_RoundTrip(t *testing.T) {
+	ctx := context.Background()
+	source := store.NewMemoryStore()
+	runIDs := seedStore(t, source, 3)
+
+	var buf bytes.Buffer
+	exported, err := archive.Export(ctx, source, gorkflow.RunFilter{}, &buf)
+	require.NoError(t, err)
+	assert.Equal(t, archive.Stats{Runs: 3, StepExecutions: 6, Outputs: 6, StateEntries: 3}, exported)
+
+	// One JSON object per line, starting with the header
+	scanner := bufio.NewScanner(bytes.NewReader(buf.Bytes()))
+	require.True(t, scanner.Scan())
+	var header archive.Record
+	require.NoError(t, json.Unmarshal(scanner.Bytes(), &header))
+	assert.Equal(t, archive.RecordHeader, header.Type)
+	assert.Equal(t, archive.Version, header.Version)
+	lines := 1
+	for scanner.Scan() {
+		lines++
+	}
+	assert.Equal(t, 1+3+6+6+3, lines)
+
+	target, err := store.NewLibSQLStore("file:" + filepath.Join(t.TempDir(), "target.db"))
+	require.NoError(t, err)
+	defer target.Close()
+
+	imported, err := archive.Import(ctx, target, bytes.NewReader(buf.Bytes()))
+	require.NoError(t, err)
+	assert.Equal(t, exported, imported)
+
+	for _, runID := range runIDs {
+		want, err := source.GetRun(ctx, runID)
+		require.NoError(t, err)
+		got, err := target.GetRun(ctx, runID)
+		require.NoError(t, err)
+		assert.Equal(t, want.Status, got.Status)
+		assert.JSONEq(t, string(want.Output), string(got.Output))
+
+		execs, err := target.ListStepExecutions(ctx, runID)
+		require.NoError(t, err)
+		assert.Len(t, execs, 2)
+
+		output, err := target.LoadStepOutput(ctx, runID, "shout")
+		require.NoError(t, err)
+		assert.JSONEq(t, `{"greeting":"HELLO ADA"}`, string(output))
+
+		state, err := target.LoadState(ctx, runID, "greeted")
+		require.NoError(t, err)
+		assert.JSONEq(t, `"ada"`, string(state))
+	}
+
+	// Importing again skips existing runs
+	again, err := archive.Import(ctx, target, bytes.NewReader(buf.Bytes()))
+	require.NoError(t, err)
+	assert.Equal(t, archive.Stats{SkippedRuns: 3}, again)
+}
+
+func TestExport_Filter(t *testing.T) {
+	ctx := context.Background()
+	source := store.NewMemoryStore()
+	seedStore(t, source, 3)
+
+	var buf bytes.Buffer
+	stats, err := archive.Export(ctx, source, gorkflow.RunFilter{Limit: 1}, &buf)
+	require.NoError(t, err)
+	assert.Equal(t, 1, stats.Runs)
+}
+
+func TestExport_Pages(t *testing.T) {
+	ctx := context.Background()
+	source := store.NewMemoryStore()
+	seedStore(t, source, 205)
+
+	var buf bytes.Buffer
+	stats, err := archive.Export(ctx, source, gorkflow.RunFilter{}, &buf)
+	require.NoError(t, err)
+	assert.Equal(t, 205, stats.Runs)
+
+	buf.Reset()
+	stats, err = archive.Export(ctx, source, gorkflow.RunFilter{Limit: 150, Offset: 30}, &buf)
+	require.NoError(t, err)
+	assert.Equal(t, 150, stats.Runs)
+
+	// Every run is exported once
+	runIDs := make(map[string]bool)
+	dec := json.NewDecoder(&buf)
+	for dec.More() {
+		var rec archive.Record
+		require.NoError(t, dec.Decode(&rec))
+		if rec.Type == archive.RecordRun {
+			assert.False(t, runIDs[rec.Run.RunID], "run %s exported twice", rec.Run.RunID)
+			runIDs[rec.Run.RunID] = true
+		}
+	}
+	assert.Len(t, runIDs, 150)
+}
+
+// churningStore creates a run and deletes two already listed ones after the
+// first page of runs is listed, as workers and retention do during an export
+type churningStore struct {
+	gorkflow.WorkflowStore
+	pages int
+}
+
+func (s *churningStore) ListRuns(ctx context.Context, filter gorkflow.RunFilter) ([]*gorkflow.WorkflowRun, error) {
+	runs, err := s.WorkflowStore.ListRuns(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+	s.pages++
+	if s.pages == 1 && len(runs) >= 2 {
+		now := time.Now()
+		if err := s.CreateRun(ctx, &gorkflow.WorkflowRun{
+			RunID: "created-during-export", WorkflowID: "greeter", Status: gorkflow.RunStatusPending,
+			CreatedAt: now, UpdatedAt: now,
+		}); err != nil {
+			return nil, err
+		}
+		for _, run := range runs[:2] {
+			if err := s.DeleteRun(ctx, run.RunID); err != nil {
+				return nil, err
+			}
+		}
+	}
+	return runs, nil
+}
+
+func TestExport_PagesStableUnderChurn(t *testing.T) {
+	ctx := context.Background()
+	source := store.NewMemoryStore()
+	seeded := seedStore(t, source, 205)
+
+	var buf bytes.Buffer
+	stats, err := archive.Export(ctx, &churningStore{WorkflowStore: source}, gorkflow.RunFilter{}, &buf)
+	require.NoError(t, err)
+	assert.Equal(t, 205, stats.Runs)
+
+	runIDs := make(map[string]bool)
+	dec := json.NewDecoder(&buf)
+	for dec.More() {
+		var rec archive.Record
+		require.NoError(t, dec.Decode(&rec))
+		if rec.Type == archive.RecordRun {
+			assert.False(t, runIDs[rec.Run.RunID], "run %s exported twice", rec.Run.RunID)
+			runIDs[rec.Run.RunID] = true
+		}
+	}
+	for _, runID := range seeded {
+		assert.True(t, runIDs[runID], "run %s missing from the export", runID)
+	}
+}
+
+func TestImport_ResumesInterruptedImport(t *testing.T) {
+	ctx := context.Background()
+	source := store.NewMemoryStore()
+	runIDs := seedStore(t, source, 2)
+
+	var buf bytes.Buffer
+	exported, err := archive.Export(ctx, source, gorkflow.RunFilter{}, &buf)
+	require.NoError(t, err)
+
+	// Stop after the header, the first run and its first step execution
+	lines := strings.SplitAfter(buf.String(), "\n")
+	target := store.NewMemoryStore()
+	partial, err := archive.Import(ctx, target, strings.NewReader(strings.Join(lines[:3], "")))
+	require.NoError(t, err)
+	assert.Equal(t, archive.Stats{Runs: 1, StepExecutions: 1}, partial)
+
+	// Importing again completes the partial run
+	resumed, err := archive.Import(ctx, target, bytes.NewReader(buf.Bytes()))
+	require.NoError(t, err)
+	assert.Equal(t, archive.Stats{
+		Runs:           1,
+		SkippedRuns:    1,
+		StepExecutions: exported.StepExecutions - 1,
+		Outputs:        exported.Outputs,
+		StateEntries:   exported.StateEntries,
+	}, resumed)
+
+	for _, runID := range runIDs {
+		execs, err := target.ListStepExecutions(ctx, runID)
+		require.NoError(t, err)
+		assert.Len(t, execs, 2)
+		_, err = target.LoadStepOutput(ctx, runID, "shout")
+		require.NoError(t, err)
+		_, err = target.LoadState(ctx, runID, "greeted")
+		require.NoError(t, err)
+	}
+}
+
+func TestImport_Rejects(t *testing.T) {
+	ctx := context.Background()
+
+	tests := []struct {
+		name  string
+		input string
+	}{
+		{"not an archive", `{"type":"run"}`},
+		{"newer version", `{"type":"header","format":"gorkflow-archive","version":99}`},
+		{"unknown record", `{"type":"header","format":"gorkflow-archive","version":1}
+{"type":"mystery"}`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := archive.Import(ctx, store.NewMemoryStore(), strings.NewReader(tt.input))
+			assert.ErrorIs(t, err, archive.ErrUnsupportedArchive)
+		})
+	}
+
+	_, err := archive.Import(ctx, store.NewMemoryStore(), strings.NewReader(`{"type":"header","format":"gorkflow-archive","version":1}
+{"type":"state","runId":"r1","key":"k","data":"InYi"}`))
+	assert.ErrorContains(t, err, "precedes its run record")
+}
@@ -0,0 +1,19 @@
+package gorkflow
+
+import "context"
+
+// BlobStore holds large payloads outside the workflow store. Keys are
+// slash-separated paths such as runs/<runID>/steps/<stepID>/output.
+type BlobStore interface {
+	// PutBlob stores data under key, replacing any existing blob
+	PutBlob(ctx context.Context, key string, data []byte) error
+
+	// GetBlob returns the blob stored under key, or ErrBlobNotFound
+	GetBlob(ctx context.Context, key string) ([]byte, error)
+
+	// DeleteBlob removes the blob stored under key; missing blobs are not an error
+	DeleteBlob(ctx context.Context, key string) error
+
+	// DeleteBlobs removes every blob whose key starts with prefix
+	DeleteBlobs(ctx context.Context, prefix string) error
+}
@@ -0,0 +1,354 @@
+package gorkflow
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// CircuitState is the state of a circuit breaker
+type CircuitState string
+
+const (
+	// CircuitClosed lets every attempt through while failures are recorded
+	CircuitClosed CircuitState = "CLOSED"
+	// CircuitOpen rejects attempts until the open duration has passed
+	CircuitOpen CircuitState = "OPEN"
+	// CircuitHalfOpen lets a limited number of probe attempts through
+	CircuitHalfOpen CircuitState = "HALF_OPEN"
+)
+
+// String returns the string representation
+func (s CircuitState) String() string {
+	return string(s)
+}
+
+// BreakerConfig configures a circuit breaker
+type BreakerConfig struct {
+	// WindowSize is the number of most recent attempts used to compute the failure rate
+	WindowSize int
+
+	// MinRequests is the minimum number of attempts in the window before the circuit can open
+	MinRequests int
+
+	// FailureRate in (0, 1] opens the circuit when reached
+	FailureRate float64
+
+	// OpenDuration is how long the circuit stays open before probing
+	OpenDuration time.Duration
+
+	// HalfOpenProbes is the number of successful probes required to close the circuit.
+	// At most this many probes run concurrently while half-open.
+	HalfOpenProbes int
+
+	// WaitWhenOpen parks attempts until the circuit is half-open instead of failing
+	// them with ErrCircuitOpen
+	WaitWhenOpen bool
+}
+
+// DefaultBreakerConfig provides sensible defaults
+var DefaultBreakerConfig = BreakerConfig{
+	WindowSize:     20,
+	MinRequests:    5,
+	FailureRate:    0.5,
+	OpenDuration:   30 * time.Second,
+	HalfOpenProbes: 1,
+}
+
+// CircuitSnapshot is a point-in-time view of a circuit breaker
+type CircuitSnapshot struct {
+	Name        string       `json:"name"`
+	State       CircuitState `json:"state"`
+	Requests    int          `json:"requests"`
+	Failures    int          `json:"failures"`
+	FailureRate float64      `json:"failureRate"`
+	OpenedAt    *time.Time   `json:"openedAt,omitempty"`
+	OpenUntil   *time.Time   `json:"openUntil,omitempty"`
+}
+
+// CircuitBreaker tracks the outcome of step attempts against a dependency and
+// rejects attempts while the failure rate is too high. It is safe for concurrent use.
+type CircuitBreaker struct {
+	name   string
+	config BreakerConfig
+
+	// Called after every state transition, outside the breaker lock
+	onStateChange func(name string, from, to CircuitState)
+
+	// Source of time for the open period
+	clock Clock
+
+	mu       sync.Mutex
+	state    CircuitState
+	outcomes []bool // ring buffer, true = failure
+	next     int
+	count    int
+	failures int
+	openedAt time.Time
+	probes   int // probes in flight while half-open
+	passed   int // successful probes while half-open
+}
+
+// NewCircuitBreaker creates a circuit breaker; zero config fields take their defaults
+func NewCircuitBreaker(name string, config BreakerConfig) *CircuitBreaker {
+	if config.WindowSize <= 0 {
+		config.WindowSize = DefaultBreakerConfig.WindowSize
+	}
+	if config.MinRequests <= 0 {
+		config.MinRequests = DefaultBreakerConfig.MinRequests
+	}
+	if config.FailureRate <= 0 || config.FailureRate > 1 {
+		config.FailureRate = DefaultBreakerConfig.FailureRate
+	}
+	if config.OpenDuration <= 0 {
+		config.OpenDuration = DefaultBreakerConfig.OpenDuration
+	}
+	if config.HalfOpenProbes <= 0 {
+		config.HalfOpenProbes = DefaultBreakerConfig.HalfOpenProbes
+	}
+	return &CircuitBreaker{
+		name:     name,
+		config:   config,
+		state:    CircuitClosed,
+		outcomes: make([]bool, config.WindowSize),
+		clock:    SystemClock,
+	}
+}
+
+// SetClock sets the clock measuring the open period. The engine passes its own
+// clock to the breakers registered on it.
+func (b *CircuitBreaker) SetClock(clock Clock) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if clock != nil {
+		b.clock = clock
+	}
+}
+
+// Name returns the breaker name
+func (b *CircuitBreaker) Name() string {
+	return b.name
+}
+
+// Config returns the breaker configuration
+func (b *CircuitBreaker) Config() BreakerConfig {
+	return b.config
+}
+
+// OnStateChange registers a callback invoked after every state transition
+func (b *CircuitBreaker) OnStateChange(fn func(name string, from, to CircuitState)) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	prev := b.onStateChange
+	if prev == nil {
+		b.onStateChange = fn
+		return
+	}
+	b.onStateChange = func(name string, from, to CircuitState) {
+		prev(name, from, to)
+		fn(name, from, to)
+	}
+}
+
+// Allow reports whether an attempt may proceed. It returns ErrCircuitOpen while
+// the circuit is open, or while half-open and all probe slots are taken.
+// Every allowed attempt must be followed by a call to Record.
+func (b *CircuitBreaker) Allow() error {
+	b.mu.Lock()
+	from := b.state
+	err := b.allowLocked(b.clock.Now())
+	to, notify := b.state, b.onStateChange
+	b.mu.Unlock()
+
+	if from != to && notify != nil {
+		notify(b.name, from, to)
+	}
+	return err
+}
+
+// Wait blocks until an attempt may proceed or ctx is done
+func (b *CircuitBreaker) Wait(ctx context.Context) error {
+	for {
+		err := b.Allow()
+		if err == nil {
+			return nil
+		}
+
+		b.mu.Lock()
+		delay := b.openedAt.Add(b.config.OpenDuration).Sub(b.clock.Now())
+		clock := b.clock
+		b.mu.Unlock()
+		if delay <= 0 {
+			// Half-open with all probes in flight; check again shortly
+			delay = b.config.OpenDuration / 10
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-clock.After(delay):
+		}
+	}
+}
+
+func (b *CircuitBreaker) allowLocked(now time.Time) error {
+	switch b.state {
+	case CircuitOpen:
+		if now.Before(b.openedAt.Add(b.config.OpenDuration)) {
+			return ErrCircuitOpen
+		}
+		b.state = CircuitHalfOpen
+		b.probes, b.passed = 0, 0
+		fallthrough
+	case CircuitHalfOpen:
+		if b.probes >= b.config.HalfOpenProbes {
+			return ErrCircuitOpen
+		}
+		b.probes++
+	}
+	return nil
+}
+
+// Record records the outcome of an attempt previously admitted by Allow
+func (b *CircuitBreaker) Record(success bool) {
+	b.mu.Lock()
+	from := b.state
+	b.recordLocked(success, b.clock.Now())
+	to, notify := b.state, b.onStateChange
+	b.mu.Unlock()
+
+	if from != to && notify != nil {
+		notify(b.name, from, to)
+	}
+}
+
+func (b *CircuitBreaker) recordLocked(success bool, now time.Time) {
+	switch b.state {
+	case CircuitHalfOpen:
+		if b.probes > 0 {
+			b.probes--
+		}
+		if !success {
+			b.open(now)
+			return
+		}
+		b.passed++
+		if b.passed >= b.config.HalfOpenProbes {
+			b.state = CircuitClosed
+			b.resetWindow()
+		}
+	case CircuitClosed:
+		b.push(!success)
+		if b.count >= b.config.MinRequests &&
+			float64(b.failures)/float64(b.count) >= b.config.FailureRate {
+			b.open(now)
+		}
+	}
+	// Outcomes recorded while open (attempts admitted before it opened) are ignored
+}
+
+// push adds an outcome to the sliding window
+func (b *CircuitBreaker) push(failed bool) {
+	if b.count == len(b.outcomes) {
+		if b.outcomes[b.next] {
+			b.failures--
+		}
+	} else {
+		b.count++
+	}
+	b.outcomes[b.next] = failed
+	if failed {
+		b.failures++
+	}
+	b.next = (b.next + 1) % len(b.outcomes)
+}
+
+func (b *CircuitBreaker) open(now time.Time) {
+	b.state = CircuitOpen
+	b.openedAt = now
+	b.probes, b.passed = 0, 0
+	b.resetWindow()
+}
+
+func (b *CircuitBreaker) resetWindow() {
+	for i := range b.outcomes {
+		b.outcomes[i] = false
+	}
+	b.next, b.count, b.failures = 0, 0, 0
+}
+
+// Release frees a probe slot taken by Allow without recording an outcome,
+// for attempts that were cancelled before they could succeed or fail
+func (b *CircuitBreaker) Release() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.state == CircuitHalfOpen && b.probes > 0 {
+		b.probes--
+	}
+}
+
+// Reset forces the circuit closed and clears its history
+func (b *CircuitBreaker) Reset() {
+	b.mu.Lock()
+	from := b.state
+	b.state = CircuitClosed
+	b.probes, b.passed = 0, 0
+	b.resetWindow()
+	notify := b.onStateChange
+	b.mu.Unlock()
+
+	if from != CircuitClosed && notify != nil {
+		notify(b.name, from, CircuitClosed)
+	}
+}
+
+// State returns the current state. An open circuit whose open duration has
+// passed is reported as half-open.
+func (b *CircuitBreaker) State() CircuitState {
+	return b.Snapshot().State
+}
+
+// Snapshot returns a point-in-time view of the breaker
+func (b *CircuitBreaker) Snapshot() CircuitSnapshot {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	snap := CircuitSnapshot{
+		Name:     b.name,
+		State:    b.state,
+		Requests: b.count,
+		Failures: b.failures,
+	}
+	if b.count > 0 {
+		snap.FailureRate = float64(b.failures) / float64(b.count)
+	}
+	if b.state == CircuitOpen {
+		openedAt := b.openedAt
+		openUntil := b.openedAt.Add(b.config.OpenDuration)
+		snap.OpenedAt = &openedAt
+		snap.OpenUntil = &openUntil
+		if !b.clock.Now().Before(openUntil) {
+			snap.State = CircuitHalfOpen
+		}
+	}
+	return snap
+}
+
+// WithCircuitBreaker guards the step with the named engine circuit breaker.
+// Steps sharing a name share the breaker; use a unique name for a per-step breaker.
+func WithCircuitBreaker(name string) StepOption {
+	return stepOptionFunc(func(s interface{}) {
+		if step, ok := s.(interface{ SetCircuitBreaker(string) }); ok {
+			step.SetCircuitBreaker(name)
+		}
+	})
+}
+
+// StepCircuitBreaker returns the circuit breaker name attached to a step, looking
+// through conditional wrappers. Steps without a breaker return "".
+func StepCircuitBreaker(step StepExecutor) string {
+	if s, ok := step.(interface{ GetCircuitBreaker() string }); ok {
+		return s.GetCircuitBreaker()
+	}
+	return ""
+}
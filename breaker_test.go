@@ -0,0 +1,114 @@
+package gorkflow
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCircuitBreaker_OpensOnFailureRate(t *testing.T) {
+	b := NewCircuitBreaker("vendor", BreakerConfig{WindowSize: 4, MinRequests: 4, FailureRate: 0.5, OpenDuration: time.Minute})
+
+	var transitions []CircuitState
+	b.OnStateChange(func(name string, from, to CircuitState) {
+		assert.Equal(t, "vendor", name)
+		transitions = append(transitions, to)
+	})
+
+	for _, success := range []bool{true, true, false} {
+		require.NoError(t, b.Allow())
+		b.Record(success)
+	}
+	assert.Equal(t, CircuitClosed, b.State(), "below MinRequests")
+
+	require.NoError(t, b.Allow())
+	b.Record(false)
+
+	assert.Equal(t, CircuitOpen, b.State())
+	assert.ErrorIs(t, b.Allow(), ErrCircuitOpen)
+	assert.Equal(t, []CircuitState{CircuitOpen}, transitions)
+
+	snap := b.Snapshot()
+	require.NotNil(t, snap.OpenUntil)
+	assert.WithinDuration(t, time.Now().Add(time.Minute), *snap.OpenUntil, time.Second)
+}
+
+func TestCircuitBreaker_SlidingWindow(t *testing.T) {
+	b := NewCircuitBreaker("vendor", BreakerConfig{WindowSize: 4, MinRequests: 4, FailureRate: 0.75})
+
+	// Old failures fall out of the window
+	for _, success := range []bool{false, false, true, true, true, true, false, false} {
+		require.NoError(t, b.Allow())
+		b.Record(success)
+	}
+	snap := b.Snapshot()
+	assert.Equal(t, CircuitClosed, snap.State)
+	assert.Equal(t, 4, snap.Requests)
+	assert.Equal(t, 2, snap.Failures)
+	assert.InDelta(t, 0.5, snap.FailureRate, 0.001)
+}
+
+func TestCircuitBreaker_HalfOpenProbes(t *testing.T) {
+	b := NewCircuitBreaker("vendor", BreakerConfig{WindowSize: 2, MinRequests: 1, FailureRate: 1, OpenDuration: 20 * time.Millisecond})
+
+	var transitions []CircuitState
+	b.OnStateChange(func(name string, from, to CircuitState) { transitions = append(transitions, to) })
+
+	require.NoError(t, b.Allow())
+	b.Record(false)
+	require.ErrorIs(t, b.Allow(), ErrCircuitOpen)
+
+	time.Sleep(30 * time.Millisecond)
+	assert.Equal(t, CircuitHalfOpen, b.State())
+
+	// A single probe at a time
+	require.NoError(t, b.Allow())
+	assert.ErrorIs(t, b.Allow(), ErrCircuitOpen)
+
+	// Failed probe reopens
+	b.Record(false)
+	assert.Equal(t, CircuitOpen, b.State())
+
+	time.Sleep(30 * time.Millisecond)
+	require.NoError(t, b.Allow())
+	b.Record(true)
+	assert.Equal(t, CircuitClosed, b.State())
+
+	assert.Equal(t, []CircuitState{CircuitOpen, CircuitHalfOpen, CircuitOpen, CircuitHalfOpen, CircuitClosed}, transitions)
+}
+
+func TestCircuitBreaker_ReleaseAndReset(t *testing.T) {
+	b := NewCircuitBreaker("vendor", BreakerConfig{MinRequests: 1, FailureRate: 1, OpenDuration: 10 * time.Millisecond})
+	require.NoError(t, b.Allow())
+	b.Record(false)
+
+	time.Sleep(20 * time.Millisecond)
+	require.NoError(t, b.Allow())
+	b.Release()
+	require.NoError(t, b.Allow(), "released probe slot is reusable")
+
+	b.Reset()
+	assert.Equal(t, CircuitClosed, b.State())
+	assert.Equal(t, 0, b.Snapshot().Requests)
+}
+
+func TestCircuitBreaker_Wait(t *testing.T) {
+	b := NewCircuitBreaker("vendor", BreakerConfig{MinRequests: 1, FailureRate: 1, OpenDuration: 30 * time.Millisecond})
+	require.NoError(t, b.Allow())
+	b.Record(false)
+
+	start := time.Now()
+	require.NoError(t, b.Wait(context.Background()))
+	assert.GreaterOrEqual(t, time.Since(start), 20*time.Millisecond)
+	b.Record(true)
+
+	// Cancelled while open
+	require.NoError(t, b.Allow())
+	b.Record(false)
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Millisecond)
+	defer cancel()
+	assert.ErrorIs(t, b.Wait(ctx), context.DeadlineExceeded)
+}
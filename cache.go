@@ -0,0 +1,70 @@
+package gorkflow
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"time"
+)
+
+// CacheKeyFunc derives a cache key from a step's serialized input
+type CacheKeyFunc func(input []byte) (string, error)
+
+// CachePolicy configures cross-run output caching for a step
+type CachePolicy struct {
+	// TTL is how long a cached output stays valid; zero means no expiry
+	TTL time.Duration
+
+	// Key derives the cache key from the input, HashInput if nil
+	Key CacheKeyFunc
+}
+
+// StepCache stores step outputs across runs. Stores implementing it are used
+// by the engine automatically; see engine.WithStepCache.
+type StepCache interface {
+	// GetCachedOutput returns the cached output for key, or ErrCacheMiss
+	GetCachedOutput(ctx context.Context, key string) ([]byte, error)
+
+	// PutCachedOutput stores output under key; a zero ttl never expires
+	PutCachedOutput(ctx context.Context, key string, output []byte, ttl time.Duration) error
+}
+
+// HashInput is the default cache key: the hex SHA-256 of the input bytes
+func HashInput(input []byte) (string, error) {
+	sum := sha256.Sum256(input)
+	return hex.EncodeToString(sum[:]), nil
+}
+
+// CacheKey returns the full cache key for a step input. Keys are namespaced
+// by workflow ID, workflow version and step ID, so a step only shares entries
+// with the same step of the same workflow version.
+func (p *CachePolicy) CacheKey(workflowID, version, stepID string, input []byte) (string, error) {
+	keyFunc := p.Key
+	if keyFunc == nil {
+		keyFunc = HashInput
+	}
+	key, err := keyFunc(input)
+	if err != nil {
+		return "", err
+	}
+	return workflowID + "@" + version + ":" + stepID + ":" + key, nil
+}
+
+// WithCache reuses the output of a previous successful execution of the step
+// when the cache key of its input matches, instead of calling the handler.
+// key may be nil to hash the input bytes. Only enable caching for deterministic steps.
+func WithCache(ttl time.Duration, key CacheKeyFunc) StepOption {
+	return stepOptionFunc(func(s interface{}) {
+		if step, ok := s.(interface{ SetCachePolicy(*CachePolicy) }); ok {
+			step.SetCachePolicy(&CachePolicy{TTL: ttl, Key: key})
+		}
+	})
+}
+
+// StepCachePolicy returns the cache policy attached to a step, or nil
+func StepCachePolicy(step StepExecutor) *CachePolicy {
+	if s, ok := step.(interface{ GetCachePolicy() *CachePolicy }); ok {
+		return s.GetCachePolicy()
+	}
+	return nil
+}
@@ -0,0 +1,67 @@
+package gorkflow
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestCachePolicy_CacheKey(t *testing.T) {
+	policy := &CachePolicy{}
+	a, err := policy.CacheKey("orders", "1", "step", []byte(`{"q":1}`))
+	if err != nil {
+		t.Fatalf("CacheKey() failed: %v", err)
+	}
+	b, _ := policy.CacheKey("orders", "1", "step", []byte(`{"q":1}`))
+	c, _ := policy.CacheKey("orders", "1", "other", []byte(`{"q":1}`))
+	d, _ := policy.CacheKey("orders", "1", "step", []byte(`{"q":2}`))
+	e, _ := policy.CacheKey("refunds", "1", "step", []byte(`{"q":1}`))
+	f, _ := policy.CacheKey("orders", "2", "step", []byte(`{"q":1}`))
+
+	if a != b {
+		t.Errorf("equal inputs produced different keys: %s, %s", a, b)
+	}
+	if a == c {
+		t.Error("keys should be namespaced by step ID")
+	}
+	if a == d {
+		t.Error("different inputs produced the same key")
+	}
+	if a == e {
+		t.Error("keys should be namespaced by workflow ID")
+	}
+	if a == f {
+		t.Error("keys should be namespaced by workflow version")
+	}
+
+	custom := &CachePolicy{Key: func(input []byte) (string, error) { return "fixed", nil }}
+	if key, _ := custom.CacheKey("orders", "1", "step", []byte("anything")); key != "orders@1:step:fixed" {
+		t.Errorf("CacheKey() = %q, want orders@1:step:fixed", key)
+	}
+
+	failing := &CachePolicy{Key: func(input []byte) (string, error) { return "", errors.New("boom") }}
+	if _, err := failing.CacheKey("orders", "1", "step", nil); err == nil {
+		t.Error("CacheKey() should return the key function's error")
+	}
+}
+
+func TestWithCache(t *testing.T) {
+	step := NewStep("cached", "Cached", func(ctx *StepContext, input string) (string, error) {
+		return input, nil
+	}, WithCache(time.Minute, nil))
+
+	policy := StepCachePolicy(step)
+	if policy == nil {
+		t.Fatal("StepCachePolicy() = nil, want policy")
+	}
+	if policy.TTL != time.Minute {
+		t.Errorf("TTL = %v, want 1m", policy.TTL)
+	}
+
+	plain := NewStep("plain", "Plain", func(ctx *StepContext, input string) (string, error) {
+		return input, nil
+	})
+	if StepCachePolicy(plain) != nil {
+		t.Error("steps should not be cached by default")
+	}
+}
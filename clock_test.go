@@ -0,0 +1,26 @@
+package gorkflow
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestSystemClock(t *testing.T) {
+	start := SystemClock.Now()
+	<-SystemClock.After(time.Millisecond)
+	if SystemClock.Since(start) < time.Millisecond {
+		t.Error("After returned before the duration elapsed")
+	}
+
+	ctx, cancel := SystemClock.WithTimeout(context.Background(), time.Millisecond)
+	defer cancel()
+	<-ctx.Done()
+	if ctx.Err() != context.DeadlineExceeded {
+		t.Errorf("ctx.Err() = %v, want DeadlineExceeded", ctx.Err())
+	}
+
+	ticker := SystemClock.NewTicker(time.Millisecond)
+	<-ticker.C()
+	ticker.Stop()
+}
@@ -0,0 +1,176 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/sicko7947/gorkflow"
+	"github.com/sicko7947/gorkflow/archive"
+)
+
+// purge deletes finished runs, or lists them with --dry-run
+func (c *cli) purge(ctx context.Context, args []string) error {
+	fs := c.flags("purge")
+	var filter gorkflow.PurgeFilter
+	var olderThan time.Duration
+	var statuses, exclude string
+	var dryRun bool
+	fs.DurationVar(&olderThan, "older-than", 0, "minimum age since the last update")
+	fs.StringVar(&statuses, "status", "", "comma separated run statuses")
+	fs.StringVar(&filter.WorkflowID, "workflow", "", "workflow ID")
+	fs.StringVar(&exclude, "exclude", "", "comma separated workflow IDs to keep")
+	fs.BoolVar(&dryRun, "dry-run", false, "list the runs without deleting them")
+	if _, err := c.parse(fs, args, 0, 0); err != nil {
+		return err
+	}
+	if olderThan <= 0 {
+		return fmt.Errorf("%w: purge requires a positive --older-than", errUsage)
+	}
+	filter.OlderThan = time.Now().Add(-olderThan)
+	for _, s := range splitList(statuses) {
+		status, err := parseStatus(s)
+		if err != nil {
+			return err
+		}
+		if !status.IsTerminal() {
+			return fmt.Errorf("%w: cannot purge %s runs", errUsage, status)
+		}
+		filter.Statuses = append(filter.Statuses, status)
+	}
+	filter.ExcludeWorkflowIDs = splitList(exclude)
+	if err := c.open(); err != nil {
+		return err
+	}
+
+	if !dryRun {
+		deleted, err := c.store.PurgeRuns(ctx, filter)
+		if err != nil {
+			return err
+		}
+		if c.output == "json" {
+			return c.printJSON(map[string]int{"deleted": deleted})
+		}
+		_, err = fmt.Fprintf(c.stdout, "deleted %d run(s)\n", deleted)
+		return err
+	}
+
+	runs, err := c.store.ListRuns(ctx, gorkflow.RunFilter{WorkflowID: filter.WorkflowID})
+	if err != nil {
+		return err
+	}
+	var matched []*gorkflow.WorkflowRun
+	for _, run := range runs {
+		if filter.Matches(run) {
+			matched = append(matched, run)
+		}
+	}
+	if c.output == "json" {
+		ids := make([]string, len(matched))
+		for i, run := range matched {
+			ids[i] = run.RunID
+		}
+		return c.printJSON(map[string]any{"wouldDelete": len(matched), "runIds": ids})
+	}
+	rows := make([][]string, len(matched))
+	for i, run := range matched {
+		rows[i] = []string{run.RunID, run.WorkflowID, string(run.Status), formatTime(&run.UpdatedAt)}
+	}
+	if err := c.printTable([]string{"RUN ID", "WORKFLOW", "STATUS", "UPDATED"}, rows); err != nil {
+		return err
+	}
+	_, err = fmt.Fprintf(c.stdout, "would delete %d run(s)\n", len(matched))
+	return err
+}
+
+// export writes an archive of runs. Payloads are exported as stored, so
+// offloaded and encrypted payloads stay references and ciphertext.
+func (c *cli) export(ctx context.Context, args []string) error {
+	fs := c.flags("export")
+	var filter gorkflow.RunFilter
+	var status, file string
+	runFilter(fs, &filter, &status, 0)
+	fs.StringVar(&file, "file", "", "archive to write (default stdout)")
+	if _, err := c.parse(fs, args, 0, 0); err != nil {
+		return err
+	}
+	if err := applyStatus(&filter, status); err != nil {
+		return err
+	}
+	if err := c.open(); err != nil {
+		return err
+	}
+
+	w := c.stdout
+	if file != "" {
+		f, err := os.Create(file)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		w = f
+	}
+	stats, err := archive.Export(ctx, c.raw, filter, w)
+	if err != nil {
+		return err
+	}
+	if file == "" {
+		// The archive is on stdout
+		return c.printStats(c.stderr, "exported", stats)
+	}
+	return c.printStats(c.stdout, "exported", stats)
+}
+
+// importArchive reads an archive written by export
+func (c *cli) importArchive(ctx context.Context, args []string) error {
+	fs := c.flags("import")
+	var file string
+	fs.StringVar(&file, "file", "", "archive to read (default stdin)")
+	if _, err := c.parse(fs, args, 0, 0); err != nil {
+		return err
+	}
+	if err := c.open(); err != nil {
+		return err
+	}
+
+	r := c.stdin
+	if file != "" {
+		f, err := os.Open(file)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		r = f
+	}
+	stats, err := archive.Import(ctx, c.raw, r)
+	if err != nil {
+		return err
+	}
+	return c.printStats(c.stdout, "imported", stats)
+}
+
+func (c *cli) printStats(w io.Writer, verb string, stats archive.Stats) error {
+	if c.output == "json" {
+		return writeJSON(w, stats)
+	}
+	fmt.Fprintf(w, "%s %d run(s), %d step execution(s), %d output(s), %d state entries\n",
+		verb, stats.Runs, stats.StepExecutions, stats.Outputs, stats.StateEntries)
+	if stats.SkippedRuns > 0 {
+		fmt.Fprintf(w, "skipped %d run(s) already in the store\n", stats.SkippedRuns)
+	}
+	return nil
+}
+
+// splitList splits a comma separated flag value
+func splitList(s string) []string {
+	var items []string
+	for _, item := range strings.Split(s, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
@@ -0,0 +1,237 @@
+// Command gorkflow inspects and operates on the runs in a gorkflow store.
+//
+//	gorkflow --dsn postgres://localhost/app runs list --status FAILED
+//	gorkflow --dsn file:./workflows.db runs get 3f1c... --output json
+//	gorkflow --dsn file:./workflows.db purge --older-than 720h
+//
+// Postgres DSNs start with postgres:// or postgresql://; anything else is opened
+// as a LibSQL URL (file:, libsql://, http://). The DSN can also be set with the
+// GORKFLOW_DSN environment variable.
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"strconv"
+	"strings"
+	"syscall"
+
+	"github.com/sicko7947/gorkflow"
+	"github.com/sicko7947/gorkflow/payload"
+	"github.com/sicko7947/gorkflow/store"
+)
+
+const usage = `Usage: gorkflow [flags] <command> [arguments]
+
+Commands:
+  runs list [--workflow ID] [--status STATUS] [--resource ID] [--limit N]
+  runs get <run-id>
+  runs cancel <run-id>
+  runs retry <run-id>
+  steps output <run-id> <step-id>
+  state get <run-id> [key]
+  state set <run-id> <key> <json-value>
+  purge --older-than DURATION [--status S,...] [--workflow ID] [--exclude ID,...] [--dry-run]
+  export [--workflow ID] [--status STATUS] [--file PATH]
+  import [--file PATH]
+
+Flags (before or after the command):
+  --dsn DSN          store to connect to (default $GORKFLOW_DSN)
+  --output FORMAT    table or json (default table)
+  --blob-dir DIR     directory of offloaded payloads (FileBlobStore)
+`
+
+// errUsage reports invalid command line arguments
+var errUsage = errors.New("invalid usage")
+
+func main() {
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	os.Exit(run(ctx, os.Args[1:], os.Stdin, os.Stdout, os.Stderr))
+}
+
+// run executes a command line and returns the exit code
+func run(ctx context.Context, args []string, stdin io.Reader, stdout, stderr io.Writer) int {
+	cli := &cli{stdin: stdin, stdout: stdout, stderr: stderr}
+	err := cli.run(ctx, args)
+	switch {
+	case err == nil:
+		return 0
+	case errors.Is(err, flag.ErrHelp):
+		fmt.Fprint(stdout, usage)
+		return 0
+	case errors.Is(err, errUsage):
+		fmt.Fprintf(stderr, "gorkflow: %v\n\n%s", err, usage)
+		return 2
+	default:
+		fmt.Fprintf(stderr, "gorkflow: %v\n", err)
+		return 1
+	}
+}
+
+// cli holds the global flags and the store of an invocation
+type cli struct {
+	stdin  io.Reader
+	stdout io.Writer
+	stderr io.Writer
+
+	dsn     string
+	output  string
+	blobDir string
+
+	raw   gorkflow.WorkflowStore // the store as persisted
+	store gorkflow.WorkflowStore // raw with payloads decoded
+}
+
+// flags returns a flag set with the global flags registered
+func (c *cli) flags(name string) *flag.FlagSet {
+	fs := flag.NewFlagSet(name, flag.ContinueOnError)
+	fs.SetOutput(io.Discard)
+	fs.StringVar(&c.dsn, "dsn", c.dsn, "store DSN")
+	fs.StringVar(&c.output, "output", c.output, "output format")
+	fs.StringVar(&c.blobDir, "blob-dir", c.blobDir, "blob directory")
+	return fs
+}
+
+// parse parses the flags of a command, which may be mixed with its arguments,
+// and checks the number of arguments. Numbers and arguments after -- are never
+// flags.
+func (c *cli) parse(fs *flag.FlagSet, args []string, minArgs, maxArgs int) ([]string, error) {
+	var positional []string
+	for len(args) > 0 {
+		if args[0] == "--" {
+			positional = append(positional, args[1:]...)
+			break
+		}
+		if !strings.HasPrefix(args[0], "-") || args[0] == "-" || isNumber(args[0]) {
+			positional = append(positional, args[0])
+			args = args[1:]
+			continue
+		}
+		if err := fs.Parse(args); err != nil {
+			if errors.Is(err, flag.ErrHelp) {
+				return nil, err
+			}
+			return nil, fmt.Errorf("%w: %v", errUsage, err)
+		}
+		consumed := args[:len(args)-fs.NArg()]
+		args = fs.Args()
+		if consumed[len(consumed)-1] == "--" {
+			positional = append(positional, args...)
+			break
+		}
+	}
+	if len(positional) < minArgs || len(positional) > maxArgs {
+		return nil, fmt.Errorf("%w: %s takes %s", errUsage, fs.Name(), argCount(minArgs, maxArgs))
+	}
+	if c.output != "table" && c.output != "json" {
+		return nil, fmt.Errorf("%w: unknown output format %q", errUsage, c.output)
+	}
+	return positional, nil
+}
+
+func isNumber(arg string) bool {
+	_, err := strconv.ParseFloat(arg, 64)
+	return err == nil
+}
+
+func argCount(minArgs, maxArgs int) string {
+	switch {
+	case minArgs == maxArgs && minArgs == 0:
+		return "no arguments"
+	case minArgs == maxArgs:
+		return fmt.Sprintf("%d argument(s)", minArgs)
+	default:
+		return fmt.Sprintf("%d to %d arguments", minArgs, maxArgs)
+	}
+}
+
+func (c *cli) run(ctx context.Context, args []string) error {
+	c.dsn = os.Getenv("GORKFLOW_DSN")
+	c.output = "table"
+
+	fs := c.flags("gorkflow")
+	if err := fs.Parse(args); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return err
+		}
+		return fmt.Errorf("%w: %v", errUsage, err)
+	}
+	args = fs.Args()
+	if len(args) == 0 {
+		return fmt.Errorf("%w: missing command", errUsage)
+	}
+
+	command, args := args[0], args[1:]
+	if command == "help" {
+		return flag.ErrHelp
+	}
+	if command == "runs" || command == "steps" || command == "state" {
+		if len(args) == 0 {
+			return fmt.Errorf("%w: missing %s subcommand", errUsage, command)
+		}
+		command, args = command+" "+args[0], args[1:]
+	}
+
+	handlers := map[string]func(context.Context, []string) error{
+		"runs list":    c.runsList,
+		"runs get":     c.runsGet,
+		"runs cancel":  c.runsCancel,
+		"runs retry":   c.runsRetry,
+		"steps output": c.stepsOutput,
+		"state get":    c.stateGet,
+		"state set":    c.stateSet,
+		"purge":        c.purge,
+		"export":       c.export,
+		"import":       c.importArchive,
+	}
+	handler, ok := handlers[command]
+	if !ok {
+		return fmt.Errorf("%w: unknown command %q", errUsage, command)
+	}
+	defer c.close()
+	return handler(ctx, args)
+}
+
+// open connects to the store named by the DSN
+func (c *cli) open() error {
+	if c.dsn == "" {
+		return fmt.Errorf("%w: no store; set --dsn or GORKFLOW_DSN", errUsage)
+	}
+
+	if strings.HasPrefix(c.dsn, "postgres://") || strings.HasPrefix(c.dsn, "postgresql://") {
+		pg, err := store.NewPostgresStore(c.dsn)
+		if err != nil {
+			return err
+		}
+		c.raw = pg
+	} else {
+		db, err := store.NewLibSQLStore(c.dsn)
+		if err != nil {
+			return err
+		}
+		c.raw = db
+	}
+
+	opts := payload.Options{}
+	if c.blobDir != "" {
+		blobs, err := store.NewFileBlobStore(c.blobDir)
+		if err != nil {
+			return err
+		}
+		opts.Blobs = blobs
+	}
+	c.store = payload.NewStore(c.raw, opts)
+	return nil
+}
+
+func (c *cli) close() {
+	if closer, ok := c.raw.(io.Closer); ok {
+		closer.Close()
+	}
+}
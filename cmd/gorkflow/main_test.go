@@ -0,0 +1,238 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/sicko7947/gorkflow"
+	"github.com/sicko7947/gorkflow/engine"
+	"github.com/sicko7947/gorkflow/payload"
+	"github.com/sicko7947/gorkflow/store"
+)
+
+type greeting struct {
+	Message string `json:"message"`
+}
+
+// seedStore creates a store with a completed and a failed run
+func seedStore(t *testing.T) (dsn, completed, failed string) {
+	t.Helper()
+	dsn = "file:" + filepath.Join(t.TempDir(), "cli.db")
+	s, err := store.NewLibSQLStore(dsn)
+	require.NoError(t, err)
+	defer s.Close()
+
+	greet := gorkflow.NewStep("greet", "Greet", func(ctx *gorkflow.StepContext, name string) (greeting, error) {
+		if err := ctx.State.Set("greeted", name); err != nil {
+			return greeting{}, err
+		}
+		return greeting{Message: "hello " + name}, nil
+	})
+	fail := gorkflow.NewStep("deliver", "Deliver", func(ctx *gorkflow.StepContext, in greeting) (greeting, error) {
+		return greeting{}, errors.New("mailbox full")
+	}, gorkflow.WithRetries(0))
+
+	ok, err := gorkflow.NewWorkflow("greeter", "Greeter").ThenStep(greet).Build()
+	require.NoError(t, err)
+	broken, err := gorkflow.NewWorkflow("mailer", "Mailer").ThenStep(greet).ThenStep(fail).Build()
+	require.NoError(t, err)
+
+	eng := engine.NewEngine(s, engine.WithLogger(zerolog.Nop()))
+	defer eng.Close()
+	ctx := context.Background()
+	completed, err = eng.StartWorkflow(ctx, ok, "ada", gorkflow.WithSynchronousExecution())
+	require.NoError(t, err)
+	failed, err = eng.StartWorkflow(ctx, broken, "bob", gorkflow.WithSynchronousExecution())
+	require.Error(t, err)
+	return dsn, completed, failed
+}
+
+// runCLI executes a command line and returns its exit code, stdout and stderr
+func runCLI(t *testing.T, stdin string, args ...string) (int, string, string) {
+	t.Helper()
+	var stdout, stderr bytes.Buffer
+	code := run(context.Background(), args, strings.NewReader(stdin), &stdout, &stderr)
+	return code, stdout.String(), stderr.String()
+}
+
+func TestCLI_RunsList(t *testing.T) {
+	dsn, completed, failed := seedStore(t)
+
+	code, out, _ := runCLI(t, "", "--dsn", dsn, "runs", "list")
+	require.Equal(t, 0, code)
+	assert.Contains(t, out, "RUN ID")
+	assert.Contains(t, out, completed)
+	assert.Contains(t, out, failed)
+
+	code, out, _ = runCLI(t, "", "runs", "list", "--status", "failed", "--dsn", dsn, "--output", "json")
+	require.Equal(t, 0, code)
+	var runs []gorkflow.WorkflowRun
+	require.NoError(t, json.Unmarshal([]byte(out), &runs))
+	require.Len(t, runs, 1)
+	assert.Equal(t, failed, runs[0].RunID)
+	assert.Equal(t, "mailer", runs[0].WorkflowID)
+}
+
+func TestCLI_RunsGet(t *testing.T) {
+	dsn, _, failed := seedStore(t)
+
+	code, out, _ := runCLI(t, "", "--dsn", dsn, "runs", "get", failed)
+	require.Equal(t, 0, code)
+	assert.Contains(t, out, "FAILED")
+	assert.Contains(t, out, "mailbox full")
+	assert.Contains(t, out, "greet")
+	assert.Contains(t, out, "deliver")
+
+	code, out, _ = runCLI(t, "", "--dsn", dsn, "--output", "json", "runs", "get", failed)
+	require.Equal(t, 0, code)
+	var view struct {
+		Run   gorkflow.WorkflowRun     `json:"run"`
+		Steps []gorkflow.StepExecution `json:"steps"`
+	}
+	require.NoError(t, json.Unmarshal([]byte(out), &view))
+	assert.Equal(t, gorkflow.RunStatusFailed, view.Run.Status)
+	assert.Len(t, view.Steps, 2)
+	assert.JSONEq(t, `"bob"`, string(view.Run.Input))
+}
+
+func TestCLI_StepsOutputAndState(t *testing.T) {
+	dsn, completed, _ := seedStore(t)
+
+	code, out, _ := runCLI(t, "", "--dsn", dsn, "steps", "output", completed, "greet")
+	require.Equal(t, 0, code)
+	assert.JSONEq(t, `{"message":"hello ada"}`, out)
+
+	code, _, _ = runCLI(t, "", "--dsn", dsn, "state", "set", completed, "attempts", "-1")
+	require.Equal(t, 0, code)
+	code, out, _ = runCLI(t, "", "--dsn", dsn, "--output", "json", "state", "get", completed)
+	require.Equal(t, 0, code)
+	assert.JSONEq(t, `{"greeted":"ada","attempts":-1}`, out)
+
+	code, out, _ = runCLI(t, "", "--dsn", dsn, "state", "get", completed, "greeted")
+	require.Equal(t, 0, code)
+	assert.Equal(t, "\"ada\"\n", out)
+
+	code, _, errOut := runCLI(t, "", "--dsn", dsn, "state", "set", completed, "name", "ada")
+	assert.Equal(t, 2, code)
+	assert.Contains(t, errOut, "must be JSON")
+}
+
+func TestCLI_CancelAndRetry(t *testing.T) {
+	dsn, completed, failed := seedStore(t)
+
+	code, _, errOut := runCLI(t, "", "--dsn", dsn, "runs", "cancel", completed)
+	assert.Equal(t, 1, code)
+	assert.Contains(t, errOut, "COMPLETED")
+
+	code, _, errOut = runCLI(t, "", "--dsn", dsn, "runs", "retry", completed)
+	assert.Equal(t, 1, code)
+	assert.Contains(t, errOut, gorkflow.ErrRunNotRetryable.Error())
+
+	code, out, _ := runCLI(t, "", "--dsn", dsn, "runs", "retry", failed)
+	require.Equal(t, 0, code)
+	assert.Contains(t, out, "PENDING")
+
+	code, out, _ = runCLI(t, "", "--dsn", dsn, "--output", "json", "runs", "cancel", failed)
+	require.Equal(t, 0, code)
+	assert.JSONEq(t, `{"runId":"`+failed+`","status":"CANCELLED"}`, out)
+}
+
+func TestCLI_CancelOffloadedRun(t *testing.T) {
+	dir := t.TempDir()
+	dsn := "file:" + filepath.Join(dir, "cli.db")
+	blobDir := filepath.Join(dir, "blobs")
+
+	raw, err := store.NewLibSQLStore(dsn)
+	require.NoError(t, err)
+	blobs, err := store.NewFileBlobStore(blobDir)
+	require.NoError(t, err)
+	s := payload.NewStore(raw, payload.Options{Blobs: blobs, OffloadThreshold: 16})
+
+	input := json.RawMessage(`"` + strings.Repeat("x", 64) + `"`)
+	now := time.Now()
+	require.NoError(t, s.CreateRun(context.Background(), &gorkflow.WorkflowRun{
+		RunID: "offloaded", WorkflowID: "greeter", Status: gorkflow.RunStatusRunning,
+		Input: input, CreatedAt: now, UpdatedAt: now,
+	}))
+	require.NoError(t, raw.Close())
+
+	code, out, errOut := runCLI(t, "", "--dsn", dsn, "--blob-dir", blobDir, "runs", "cancel", "offloaded")
+	require.Equal(t, 0, code, errOut)
+	assert.Contains(t, out, "CANCELLED")
+
+	code, out, errOut = runCLI(t, "", "--dsn", dsn, "--blob-dir", blobDir, "--output", "json", "runs", "get", "offloaded")
+	require.Equal(t, 0, code, errOut)
+	var view struct {
+		Run gorkflow.WorkflowRun `json:"run"`
+	}
+	require.NoError(t, json.Unmarshal([]byte(out), &view))
+	assert.Equal(t, gorkflow.RunStatusCancelled, view.Run.Status)
+	assert.JSONEq(t, string(input), string(view.Run.Input))
+}
+
+func TestCLI_PurgeAndArchive(t *testing.T) {
+	dsn, completed, failed := seedStore(t)
+	archivePath := filepath.Join(t.TempDir(), "runs.jsonl")
+
+	code, _, _ := runCLI(t, "", "--dsn", dsn, "export", "--file", archivePath)
+	require.Equal(t, 0, code)
+
+	code, out, _ := runCLI(t, "", "--dsn", dsn, "purge", "--older-than", "1ns", "--status", "COMPLETED", "--dry-run")
+	require.Equal(t, 0, code)
+	assert.Contains(t, out, completed)
+	assert.NotContains(t, out, failed)
+	assert.Contains(t, out, "would delete 1 run(s)")
+
+	code, out, _ = runCLI(t, "", "--dsn", dsn, "purge", "--older-than", "1ns")
+	require.Equal(t, 0, code)
+	assert.Contains(t, out, "deleted 2 run(s)")
+
+	code, out, _ = runCLI(t, "", "--dsn", dsn, "--output", "json", "import", "--file", archivePath)
+	require.Equal(t, 0, code)
+	var stats struct{ Runs, StepExecutions int }
+	require.NoError(t, json.Unmarshal([]byte(out), &stats))
+	assert.Equal(t, 2, stats.Runs)
+	assert.Equal(t, 3, stats.StepExecutions)
+
+	code, out, _ = runCLI(t, "", "--dsn", dsn, "steps", "output", completed, "greet")
+	require.Equal(t, 0, code)
+	assert.JSONEq(t, `{"message":"hello ada"}`, out)
+}
+
+func TestCLI_Usage(t *testing.T) {
+	code, out, _ := runCLI(t, "", "--help")
+	assert.Equal(t, 0, code)
+	assert.Contains(t, out, "Usage: gorkflow")
+
+	tests := []struct {
+		name string
+		args []string
+		want string
+	}{
+		{"no command", nil, "missing command"},
+		{"unknown command", []string{"runs", "delete"}, `unknown command "runs delete"`},
+		{"no dsn", []string{"runs", "list"}, "no store"},
+		{"missing argument", []string{"--dsn", "file:x.db", "runs", "get"}, "runs get takes 1 argument(s)"},
+		{"bad output", []string{"--dsn", "file:x.db", "--output", "xml", "runs", "list"}, `unknown output format "xml"`},
+		{"bad status", []string{"--dsn", "file:x.db", "runs", "list", "--status", "done"}, `unknown run status "done"`},
+		{"purge without age", []string{"--dsn", "file:x.db", "purge"}, "--older-than"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv("GORKFLOW_DSN", "")
+			code, _, errOut := runCLI(t, "", tt.args...)
+			assert.Equal(t, 2, code)
+			assert.Contains(t, errOut, tt.want)
+		})
+	}
+}
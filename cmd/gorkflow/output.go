@@ -0,0 +1,73 @@
+package main
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/sicko7947/gorkflow"
+)
+
+// printJSON writes v to stdout as indented JSON
+func (c *cli) printJSON(v any) error {
+	return writeJSON(c.stdout, v)
+}
+
+func writeJSON(w io.Writer, v any) error {
+	data, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return err
+	}
+	_, err = fmt.Fprintf(w, "%s\n", data)
+	return err
+}
+
+// printTable writes rows aligned in columns under header
+func (c *cli) printTable(header []string, rows [][]string) error {
+	w := tabwriter.NewWriter(c.stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, strings.Join(header, "\t"))
+	for _, row := range rows {
+		fmt.Fprintln(w, strings.Join(row, "\t"))
+	}
+	return w.Flush()
+}
+
+// jsonPayload returns a payload as JSON. Payloads written by other serializers
+// are decoded; payloads that cannot be decoded become a base64 string.
+func jsonPayload(data []byte) json.RawMessage {
+	if out, err := gorkflow.PayloadJSON(data); err == nil {
+		return out
+	}
+	out, _ := json.Marshal(base64.StdEncoding.EncodeToString(data))
+	return out
+}
+
+// textPayload returns a payload for table output
+func textPayload(data []byte) string {
+	return string(jsonPayload(data))
+}
+
+func formatTime(t *time.Time) string {
+	if t == nil || t.IsZero() {
+		return "-"
+	}
+	return t.Local().Format(time.DateTime)
+}
+
+func formatDuration(ms int64) string {
+	if ms <= 0 {
+		return "-"
+	}
+	return (time.Duration(ms) * time.Millisecond).String()
+}
+
+func orDash(s string) string {
+	if s == "" {
+		return "-"
+	}
+	return s
+}
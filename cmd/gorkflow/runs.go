@@ -0,0 +1,231 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/sicko7947/gorkflow"
+)
+
+// parseStatus validates a run status given on the command line
+func parseStatus(s string) (gorkflow.RunStatus, error) {
+	status := gorkflow.RunStatus(strings.ToUpper(s))
+	switch status {
+	case gorkflow.RunStatusPending, gorkflow.RunStatusRunning, gorkflow.RunStatusCompleted,
+		gorkflow.RunStatusFailed, gorkflow.RunStatusCancelled:
+		return status, nil
+	}
+	return "", fmt.Errorf("%w: unknown run status %q", errUsage, s)
+}
+
+// runFilter registers the run filter flags on fs
+func runFilter(fs *flag.FlagSet, filter *gorkflow.RunFilter, status *string, limit int) {
+	fs.StringVar(&filter.WorkflowID, "workflow", "", "workflow ID")
+	fs.StringVar(status, "status", "", "run status")
+	fs.StringVar(&filter.ResourceID, "resource", "", "resource ID")
+	fs.IntVar(&filter.Limit, "limit", limit, "maximum number of runs")
+}
+
+// applyStatus sets the status of filter from a flag value
+func applyStatus(filter *gorkflow.RunFilter, status string) error {
+	if status == "" {
+		return nil
+	}
+	s, err := parseStatus(status)
+	if err != nil {
+		return err
+	}
+	filter.Status = &s
+	return nil
+}
+
+func (c *cli) runsList(ctx context.Context, args []string) error {
+	fs := c.flags("runs list")
+	var filter gorkflow.RunFilter
+	var status string
+	runFilter(fs, &filter, &status, 50)
+	if _, err := c.parse(fs, args, 0, 0); err != nil {
+		return err
+	}
+	if err := applyStatus(&filter, status); err != nil {
+		return err
+	}
+	if err := c.open(); err != nil {
+		return err
+	}
+
+	runs, err := c.store.ListRuns(ctx, filter)
+	if err != nil {
+		return err
+	}
+	if c.output == "json" {
+		views := make([]*gorkflow.WorkflowRun, len(runs))
+		for i, run := range runs {
+			views[i] = runView(run)
+		}
+		return c.printJSON(views)
+	}
+
+	rows := make([][]string, len(runs))
+	for i, run := range runs {
+		rows[i] = []string{
+			run.RunID,
+			run.WorkflowID,
+			orDash(run.WorkflowVersion),
+			string(run.Status),
+			fmt.Sprintf("%.0f%%", run.Progress*100),
+			formatTime(&run.CreatedAt),
+			formatTime(&run.UpdatedAt),
+		}
+	}
+	return c.printTable([]string{"RUN ID", "WORKFLOW", "VERSION", "STATUS", "PROGRESS", "CREATED", "UPDATED"}, rows)
+}
+
+func (c *cli) runsGet(ctx context.Context, args []string) error {
+	fs := c.flags("runs get")
+	args, err := c.parse(fs, args, 1, 1)
+	if err != nil {
+		return err
+	}
+	if err := c.open(); err != nil {
+		return err
+	}
+
+	run, err := c.store.GetRun(ctx, args[0])
+	if err != nil {
+		return err
+	}
+	steps, err := c.store.ListStepExecutions(ctx, run.RunID)
+	if err != nil {
+		return err
+	}
+
+	if c.output == "json" {
+		views := make([]*gorkflow.StepExecution, len(steps))
+		for i, step := range steps {
+			views[i] = stepView(step)
+		}
+		return c.printJSON(struct {
+			Run   *gorkflow.WorkflowRun     `json:"run"`
+			Steps []*gorkflow.StepExecution `json:"steps"`
+		}{runView(run), views})
+	}
+
+	details := [][]string{
+		{"Run:", run.RunID},
+		{"Workflow:", run.WorkflowID},
+		{"Version:", orDash(run.WorkflowVersion)},
+		{"Status:", string(run.Status)},
+		{"Progress:", fmt.Sprintf("%.0f%%", run.Progress*100)},
+		{"Resource:", orDash(run.ResourceID)},
+		{"Created:", formatTime(&run.CreatedAt)},
+		{"Started:", formatTime(run.StartedAt)},
+		{"Completed:", formatTime(run.CompletedAt)},
+	}
+	if run.Error != nil {
+		details = append(details, []string{"Error:", run.Error.Message})
+	}
+	if len(run.Output) > 0 {
+		details = append(details, []string{"Output:", textPayload(run.Output)})
+	}
+	for _, row := range details {
+		fmt.Fprintf(c.stdout, "%-11s%s\n", row[0], row[1])
+	}
+	fmt.Fprintln(c.stdout)
+
+	rows := make([][]string, len(steps))
+	for i, step := range steps {
+		errMsg := "-"
+		if step.Error != nil {
+			errMsg = step.Error.Message
+		}
+		rows[i] = []string{
+			step.StepID,
+			string(step.Status),
+			fmt.Sprint(step.Attempt + 1),
+			formatDuration(step.DurationMs),
+			formatTime(step.StartedAt),
+			errMsg,
+		}
+	}
+	return c.printTable([]string{"STEP", "STATUS", "ATTEMPTS", "DURATION", "STARTED", "ERROR"}, rows)
+}
+
+// runsCancel marks a run cancelled in the store. It cannot interrupt an engine
+// executing the run in another process.
+func (c *cli) runsCancel(ctx context.Context, args []string) error {
+	fs := c.flags("runs cancel")
+	args, err := c.parse(fs, args, 1, 1)
+	if err != nil {
+		return err
+	}
+	if err := c.open(); err != nil {
+		return err
+	}
+
+	// Only the status changes, so the run is updated as persisted, without
+	// decoding payloads that may be offloaded or encrypted
+	run, err := c.raw.GetRun(ctx, args[0])
+	if err != nil {
+		return err
+	}
+	if run.Status.IsTerminal() {
+		return fmt.Errorf("cannot cancel workflow in %s state: %w", run.Status, gorkflow.ErrRunNotCancellable)
+	}
+	now := time.Now()
+	run.Status = gorkflow.RunStatusCancelled
+	run.CompletedAt = &now
+	run.UpdatedAt = now
+	if err := c.raw.UpdateRun(ctx, run); err != nil {
+		return fmt.Errorf("failed to update run: %w", err)
+	}
+	return c.printResult(run.RunID, run.Status)
+}
+
+// runsRetry makes a failed or cancelled run pending again; a worker with the
+// workflow registered executes it with Engine.ResumeRun
+func (c *cli) runsRetry(ctx context.Context, args []string) error {
+	fs := c.flags("runs retry")
+	args, err := c.parse(fs, args, 1, 1)
+	if err != nil {
+		return err
+	}
+	if err := c.open(); err != nil {
+		return err
+	}
+
+	run, err := gorkflow.ResetRun(ctx, c.store, args[0], time.Now())
+	if err != nil {
+		return err
+	}
+	return c.printResult(run.RunID, run.Status)
+}
+
+// printResult reports the new status of a run
+func (c *cli) printResult(runID string, status gorkflow.RunStatus) error {
+	if c.output == "json" {
+		return c.printJSON(map[string]string{"runId": runID, "status": string(status)})
+	}
+	_, err := fmt.Fprintf(c.stdout, "run %s is %s\n", runID, status)
+	return err
+}
+
+// runView returns a copy of run with its payloads as JSON
+func runView(run *gorkflow.WorkflowRun) *gorkflow.WorkflowRun {
+	view := *run
+	view.Input = jsonPayload(run.Input)
+	view.Output = jsonPayload(run.Output)
+	view.Context = jsonPayload(run.Context)
+	return &view
+}
+
+// stepView returns a copy of step with its payloads as JSON
+func stepView(step *gorkflow.StepExecution) *gorkflow.StepExecution {
+	view := *step
+	view.Input = jsonPayload(step.Input)
+	view.Output = jsonPayload(step.Output)
+	return &view
+}
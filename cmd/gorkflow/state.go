@@ -0,0 +1,98 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+)
+
+func (c *cli) stepsOutput(ctx context.Context, args []string) error {
+	fs := c.flags("steps output")
+	args, err := c.parse(fs, args, 2, 2)
+	if err != nil {
+		return err
+	}
+	if err := c.open(); err != nil {
+		return err
+	}
+
+	output, err := c.store.LoadStepOutput(ctx, args[0], args[1])
+	if err != nil {
+		return err
+	}
+	return c.printPayload(output)
+}
+
+func (c *cli) stateGet(ctx context.Context, args []string) error {
+	fs := c.flags("state get")
+	args, err := c.parse(fs, args, 1, 2)
+	if err != nil {
+		return err
+	}
+	if err := c.open(); err != nil {
+		return err
+	}
+
+	if len(args) == 2 {
+		value, err := c.store.LoadState(ctx, args[0], args[1])
+		if err != nil {
+			return err
+		}
+		return c.printPayload(value)
+	}
+
+	state, err := c.store.GetAllState(ctx, args[0])
+	if err != nil {
+		return err
+	}
+	keys := make([]string, 0, len(state))
+	for key := range state {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	if c.output == "json" {
+		values := make(map[string]json.RawMessage, len(state))
+		for key, value := range state {
+			values[key] = jsonPayload(value)
+		}
+		return c.printJSON(values)
+	}
+	rows := make([][]string, len(keys))
+	for i, key := range keys {
+		rows[i] = []string{key, textPayload(state[key])}
+	}
+	return c.printTable([]string{"KEY", "VALUE"}, rows)
+}
+
+// stateSet stores a JSON value under a state key of a run
+func (c *cli) stateSet(ctx context.Context, args []string) error {
+	fs := c.flags("state set")
+	args, err := c.parse(fs, args, 3, 3)
+	if err != nil {
+		return err
+	}
+	value := []byte(args[2])
+	if !json.Valid(value) {
+		return fmt.Errorf("%w: state value must be JSON, e.g. '\"text\"' for a string", errUsage)
+	}
+	if err := c.open(); err != nil {
+		return err
+	}
+
+	if _, err := c.store.GetRun(ctx, args[0]); err != nil {
+		return err
+	}
+	return c.store.SaveState(ctx, args[0], args[1], value)
+}
+
+// printPayload writes a payload as JSON, indented in JSON mode
+func (c *cli) printPayload(data []byte) error {
+	payload := jsonPayload(data)
+	if c.output == "json" {
+		return c.printJSON(payload)
+	}
+	_, err := fmt.Fprintf(c.stdout, "%s\n", payload)
+	return err
+}
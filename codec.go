@@ -0,0 +1,18 @@
+package gorkflow
+
+import "context"
+
+// PayloadCodec transforms payloads on their way to and from the workflow store,
+// for example to encrypt or compress them. The name of the codec is recorded
+// with every encoded payload and selects the codec that decodes it, so it must
+// stay stable once payloads have been written.
+type PayloadCodec interface {
+	// Name identifies the codec in stored payloads (at most 255 bytes)
+	Name() string
+
+	// Encode transforms a payload before it is stored
+	Encode(ctx context.Context, data []byte) ([]byte, error)
+
+	// Decode reverses Encode
+	Decode(ctx context.Context, data []byte) ([]byte, error)
+}
@@ -0,0 +1,53 @@
+package gorkflow
+
+import "context"
+
+// Compression selects how persisted payloads are compressed
+type Compression string
+
+const (
+	// CompressionDefault uses the engine's compression
+	CompressionDefault Compression = ""
+
+	// CompressionNone stores payloads uncompressed
+	CompressionNone Compression = "none"
+
+	// CompressionGzip compresses payloads with gzip
+	CompressionGzip Compression = "gzip"
+
+	// CompressionZstd compresses payloads with zstd
+	CompressionZstd Compression = "zstd"
+)
+
+// WithCompression overrides the engine's payload compression for the step's
+// persisted input and output. CompressionNone disables it, for example for
+// outputs that are already compressed.
+func WithCompression(compression Compression) StepOption {
+	return stepOptionFunc(func(s interface{}) {
+		if step, ok := s.(interface{ SetCompression(Compression) }); ok {
+			step.SetCompression(compression)
+		}
+	})
+}
+
+// StepCompression returns the payload compression configured on a step, or CompressionDefault
+func StepCompression(step StepExecutor) Compression {
+	if s, ok := step.(interface{ GetCompression() Compression }); ok {
+		return s.GetCompression()
+	}
+	return CompressionDefault
+}
+
+type compressionKey struct{}
+
+// ContextWithCompression returns a context that makes payload-aware stores
+// compress payloads written with it using compression
+func ContextWithCompression(ctx context.Context, compression Compression) context.Context {
+	return context.WithValue(ctx, compressionKey{}, compression)
+}
+
+// CompressionFromContext returns the compression set by ContextWithCompression, or CompressionDefault
+func CompressionFromContext(ctx context.Context) Compression {
+	compression, _ := ctx.Value(compressionKey{}).(Compression)
+	return compression
+}
@@ -0,0 +1,35 @@
+package gorkflow
+
+import (
+	"context"
+	"testing"
+)
+
+func TestWithCompression(t *testing.T) {
+	step := NewStep("s", "S", func(ctx *StepContext, in int) (int, error) { return in, nil },
+		WithCompression(CompressionZstd))
+	if got := StepCompression(step); got != CompressionZstd {
+		t.Errorf("StepCompression() = %q, want %q", got, CompressionZstd)
+	}
+
+	conditional := WrapStepWithCondition(step, func(*StepContext) (bool, error) { return true, nil }, nil)
+	if got := StepCompression(conditional); got != CompressionZstd {
+		t.Errorf("StepCompression(conditional) = %q, want %q", got, CompressionZstd)
+	}
+
+	plain := NewStep("p", "P", func(ctx *StepContext, in int) (int, error) { return in, nil })
+	if got := StepCompression(plain); got != CompressionDefault {
+		t.Errorf("StepCompression() without option = %q, want default", got)
+	}
+}
+
+func TestContextWithCompression(t *testing.T) {
+	ctx := context.Background()
+	if got := CompressionFromContext(ctx); got != CompressionDefault {
+		t.Errorf("CompressionFromContext() = %q, want default", got)
+	}
+	ctx = ContextWithCompression(ctx, CompressionGzip)
+	if got := CompressionFromContext(ctx); got != CompressionGzip {
+		t.Errorf("CompressionFromContext() = %q, want %q", got, CompressionGzip)
+	}
+}
@@ -2,7 +2,6 @@ package gorkflow
 
 import (
 	"context"
-	"encoding/json"
 	"fmt"
 	"sync"
 
@@ -27,8 +26,23 @@ type StepContext struct {
 	// Access to workflow-level state
 	State StateAccessor
 
+	// Serializer of the workflow's payloads (nil means JSONSerializer)
+	Serializer Serializer
+
 	// Custom context (user-defined)
 	CustomContext any
+
+	// Set when a condition decided the step should not run
+	skipReason string
+
+	// Result of StepConditionMet for checkedStep, consumed by its next execution
+	checkedStep  StepExecutor
+	conditionMet bool
+}
+
+// SkipReason returns why the step's condition skipped execution, or "" if it ran
+func (c *StepContext) SkipReason() string {
+	return c.skipReason
 }
 
 // GetContext retrieves the custom context from the step context
@@ -129,7 +143,7 @@ func NewStepAccessor(runID string, wfStore WorkflowStore) StepDataAccessor {
 func (a *stepAccessor) GetOutput(stepID string, target interface{}) error {
 	// Check cache first
 	if data, ok := a.outputCache[stepID]; ok {
-		return json.Unmarshal(data, target)
+		return UnmarshalPayload(data, target)
 	}
 
 	// Load from store
@@ -142,7 +156,7 @@ func (a *stepAccessor) GetOutput(stepID string, target interface{}) error {
 	a.outputCache[stepID] = data
 
 	// Unmarshal
-	if err := json.Unmarshal(data, target); err != nil {
+	if err := UnmarshalPayload(data, target); err != nil {
 		return fmt.Errorf("failed to unmarshal output for step %s: %w", stepID, err)
 	}
 
@@ -163,7 +177,7 @@ func (a *stepAccessor) HasOutput(stepID string) bool {
 func (a *stepAccessor) GetInput(stepID string, target interface{}) error {
 	// Check cache first
 	if data, ok := a.inputCache[stepID]; ok {
-		return json.Unmarshal(data, target)
+		return UnmarshalPayload(data, target)
 	}
 
 	// Load step execution to get the input
@@ -180,7 +194,7 @@ func (a *stepAccessor) GetInput(stepID string, target interface{}) error {
 	a.inputCache[stepID] = exec.Input
 
 	// Unmarshal
-	if err := json.Unmarshal(exec.Input, target); err != nil {
+	if err := UnmarshalPayload(exec.Input, target); err != nil {
 		return fmt.Errorf("failed to unmarshal input for step %s: %w", stepID, err)
 	}
 
@@ -189,11 +203,12 @@ func (a *stepAccessor) GetInput(stepID string, target interface{}) error {
 
 // stateAccessor implements StateAccessor
 type stateAccessor struct {
-	runID string
-	store WorkflowStore
-	ctx   context.Context
-	mu    sync.RWMutex
-	cache map[string][]byte
+	runID      string
+	store      WorkflowStore
+	ctx        context.Context
+	serializer Serializer
+	mu         sync.RWMutex
+	cache      map[string][]byte
 }
 
 // NewStateAccessor creates a new state accessor
@@ -208,7 +223,10 @@ func NewStateAccessor(runID string, wfStore WorkflowStore) StateAccessor {
 
 func (a *stateAccessor) Set(key string, value interface{}) error {
 	// Marshal value
-	data, err := json.Marshal(value)
+	a.mu.RLock()
+	serializer := a.serializer
+	a.mu.RUnlock()
+	data, err := MarshalPayload(serializer, value)
 	if err != nil {
 		return fmt.Errorf("failed to marshal state value for key %s: %w", key, err)
 	}
@@ -234,7 +252,7 @@ func (a *stateAccessor) Get(key string, target interface{}) error {
 	ctx := a.ctx
 	a.mu.RUnlock()
 	if ok {
-		return json.Unmarshal(data, target)
+		return UnmarshalPayload(data, target)
 	}
 
 	// Load from store
@@ -249,7 +267,7 @@ func (a *stateAccessor) Get(key string, target interface{}) error {
 	a.mu.Unlock()
 
 	// Unmarshal
-	if err := json.Unmarshal(data, target); err != nil {
+	if err := UnmarshalPayload(data, target); err != nil {
 		return fmt.Errorf("failed to unmarshal state for key %s: %w", key, err)
 	}
 
@@ -315,6 +333,16 @@ func SetStepAccessorCtx(accessor StepDataAccessor, ctx context.Context) {
 	}
 }
 
+// SetStateAccessorSerializer sets the serializer a StateAccessor uses for new values.
+// Values are always read with the serializer recorded when they were written.
+func SetStateAccessorSerializer(accessor StateAccessor, serializer Serializer) {
+	if sa, ok := accessor.(*stateAccessor); ok {
+		sa.mu.Lock()
+		sa.serializer = serializer
+		sa.mu.Unlock()
+	}
+}
+
 // SetStateAccessorCtx updates the context used by a StateAccessor for store calls.
 // Safe to call concurrently with other accessor methods.
 func SetStateAccessorCtx(accessor StateAccessor, ctx context.Context) {
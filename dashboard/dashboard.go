@@ -0,0 +1,130 @@
+// Package dashboard serves a web dashboard for the runs of an engine: a run
+// list with filters and a detail page with the workflow graph, a step
+// timeline, payloads, errors and state, with buttons to cancel and retry runs.
+// Pages are rendered on the server from embedded templates and use no external
+// assets or JavaScript.
+//
+//	mux := http.NewServeMux()
+//	dashboard.New(eng, dashboard.WithBasePath("/admin/workflows")).Mount(mux)
+//
+// The dashboard has no authentication of its own; wrap it with your
+// authentication middleware before exposing it.
+package dashboard
+
+import (
+	"embed"
+	"html/template"
+	"io/fs"
+	"net/http"
+	"strings"
+
+	"github.com/sicko7947/gorkflow/engine"
+)
+
+//go:embed templates/*.html static/*
+var assets embed.FS
+
+// Handler serves the dashboard of an engine
+type Handler struct {
+	engine   *engine.Engine
+	basePath string
+	title    string
+	readOnly bool
+	pageSize int
+
+	templates map[string]*template.Template
+	mux       *http.ServeMux
+}
+
+// Option configures a Handler
+type Option func(*Handler)
+
+// WithBasePath serves the dashboard under path, e.g. "/admin/workflows"
+func WithBasePath(path string) Option {
+	return func(h *Handler) {
+		h.basePath = strings.TrimSuffix(path, "/")
+	}
+}
+
+// WithTitle sets the title shown in the header of every page
+func WithTitle(title string) Option {
+	return func(h *Handler) {
+		h.title = title
+	}
+}
+
+// WithReadOnly hides the cancel and retry buttons and rejects their requests
+func WithReadOnly() Option {
+	return func(h *Handler) {
+		h.readOnly = true
+	}
+}
+
+// WithPageSize sets the number of runs listed per page (default 50)
+func WithPageSize(n int) Option {
+	return func(h *Handler) {
+		if n > 0 {
+			h.pageSize = n
+		}
+	}
+}
+
+// New creates a dashboard for eng. Runs of workflows registered on eng are
+// shown with their graph and can be retried.
+func New(eng *engine.Engine, opts ...Option) *Handler {
+	h := &Handler{
+		engine:   eng,
+		title:    "Gorkflow",
+		pageSize: 50,
+		mux:      http.NewServeMux(),
+	}
+	for _, opt := range opts {
+		opt(h)
+	}
+	h.templates = parseTemplates()
+
+	static, _ := fs.Sub(assets, "static")
+	protect := http.NewCrossOriginProtection()
+
+	h.mux.HandleFunc("GET "+h.path("/{$}"), h.runsPage)
+	h.mux.HandleFunc("GET "+h.path("/runs/{runID}"), h.runPage)
+	h.mux.Handle("POST "+h.path("/runs/{runID}/cancel"), protect.Handler(http.HandlerFunc(h.cancelRun)))
+	h.mux.Handle("POST "+h.path("/runs/{runID}/retry"), protect.Handler(http.HandlerFunc(h.retryRun)))
+	h.mux.Handle("GET "+h.path("/static/"), http.StripPrefix(h.path("/static/"), http.FileServerFS(static)))
+	return h
+}
+
+// BasePath returns the path the dashboard is served under
+func (h *Handler) BasePath() string {
+	return h.basePath
+}
+
+// Mount registers the handler on mux under its base path
+func (h *Handler) Mount(mux *http.ServeMux) {
+	mux.Handle(h.path("/"), h)
+}
+
+// ServeHTTP implements http.Handler
+func (h *Handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if _, pattern := h.mux.Handler(r); pattern == "" {
+		h.renderError(w, http.StatusNotFound, "Page not found")
+		return
+	}
+	h.mux.ServeHTTP(w, r)
+}
+
+// path returns a path relative to the base path
+func (h *Handler) path(p string) string {
+	return h.basePath + p
+}
+
+// parseTemplates parses each page together with the layout
+func parseTemplates() map[string]*template.Template {
+	pages := []string{"runs.html", "run.html", "error.html"}
+	templates := make(map[string]*template.Template, len(pages))
+	for _, page := range pages {
+		templates[page] = template.Must(template.New("layout.html").Funcs(templateFuncs).
+			ParseFS(assets, "templates/layout.html", "templates/"+page))
+	}
+	return templates
+}
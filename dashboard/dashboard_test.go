@@ -0,0 +1,203 @@
+package dashboard
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/sicko7947/gorkflow"
+	"github.com/sicko7947/gorkflow/engine"
+	"github.com/sicko7947/gorkflow/store"
+)
+
+type order struct {
+	Items int `json:"items"`
+}
+
+type receipt struct {
+	Total int `json:"total"`
+}
+
+// newServer serves the dashboard of an engine with an "orders" workflow whose
+// charge step fails while failCharge is set
+func newServer(t *testing.T, opts ...Option) (*httptest.Server, *engine.Engine, *atomic.Bool) {
+	t.Helper()
+	eng := engine.NewEngine(store.NewMemoryStore(), engine.WithLogger(zerolog.Nop()))
+	t.Cleanup(func() { eng.Close() })
+
+	var failCharge atomic.Bool
+	price := gorkflow.NewStep("price", "Price Order", func(ctx *gorkflow.StepContext, in order) (receipt, error) {
+		if err := ctx.State.Set("items", in.Items); err != nil {
+			return receipt{}, err
+		}
+		return receipt{Total: in.Items * 10}, nil
+	})
+	charge := gorkflow.NewStep("charge", "Charge Card", func(ctx *gorkflow.StepContext, in receipt) (receipt, error) {
+		if failCharge.Load() {
+			return receipt{}, errors.New("card declined")
+		}
+		return in, nil
+	}, gorkflow.WithRetries(0))
+	wf, err := gorkflow.NewWorkflow("orders", "Orders").WithVersion("1").ThenStep(price).ThenStep(charge).Build()
+	require.NoError(t, err)
+	require.NoError(t, eng.Register(wf))
+
+	mux := http.NewServeMux()
+	New(eng, opts...).Mount(mux)
+	srv := httptest.NewServer(mux)
+	t.Cleanup(srv.Close)
+	return srv, eng, &failCharge
+}
+
+// startRun executes a run of the orders workflow until it finishes
+func startRun(t *testing.T, eng *engine.Engine, items int) *gorkflow.WorkflowRun {
+	t.Helper()
+	wf, err := eng.GetWorkflow("orders", "")
+	require.NoError(t, err)
+	// Failed runs are returned with their error
+	runID, _ := eng.StartWorkflow(t.Context(), wf, order{Items: items}, gorkflow.WithSynchronousExecution())
+	require.NotEmpty(t, runID)
+	run, err := eng.GetRun(t.Context(), runID)
+	require.NoError(t, err)
+	return run
+}
+
+// get fetches a page and returns its status and body
+func get(t *testing.T, srv *httptest.Server, path string) (int, string) {
+	t.Helper()
+	resp, err := srv.Client().Get(srv.URL + path)
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	body, err := io.ReadAll(resp.Body)
+	require.NoError(t, err)
+	return resp.StatusCode, string(body)
+}
+
+// post submits a dashboard form without following the redirect
+func post(t *testing.T, srv *httptest.Server, path string, header http.Header) *http.Response {
+	t.Helper()
+	req, err := http.NewRequest(http.MethodPost, srv.URL+path, nil)
+	require.NoError(t, err)
+	for key, values := range header {
+		req.Header[key] = values
+	}
+	client := *srv.Client()
+	client.CheckRedirect = func(*http.Request, []*http.Request) error { return http.ErrUseLastResponse }
+	resp, err := client.Do(req)
+	require.NoError(t, err)
+	resp.Body.Close()
+	return resp
+}
+
+func TestDashboard_Runs(t *testing.T) {
+	srv, eng, failCharge := newServer(t, WithBasePath("/admin"), WithPageSize(2))
+	completed := startRun(t, eng, 1)
+	failCharge.Store(true)
+	failed := startRun(t, eng, 2)
+	startRun(t, eng, 3)
+
+	status, body := get(t, srv, "/admin/")
+	require.Equal(t, http.StatusOK, status)
+	assert.Contains(t, body, `href="/admin/static/dashboard.css"`)
+	assert.Contains(t, body, "/admin/?offset=2")
+	assert.NotContains(t, body, completed.RunID)
+
+	status, body = get(t, srv, "/admin/?offset=2")
+	require.Equal(t, http.StatusOK, status)
+	assert.Contains(t, body, completed.RunID)
+
+	status, body = get(t, srv, "/admin/?status=completed&workflow=orders")
+	require.Equal(t, http.StatusOK, status)
+	assert.Contains(t, body, completed.RunID)
+	assert.NotContains(t, body, failed.RunID)
+	assert.Contains(t, body, `<option value="COMPLETED" selected>`)
+}
+
+func TestDashboard_RunPage(t *testing.T) {
+	srv, eng, failCharge := newServer(t)
+	failCharge.Store(true)
+	run := startRun(t, eng, 4)
+	require.Equal(t, gorkflow.RunStatusFailed, run.Status)
+
+	status, body := get(t, srv, "/runs/"+run.RunID)
+	require.Equal(t, http.StatusOK, status)
+	for _, want := range []string{
+		"<svg",                        // graph
+		"Price Order",                 // step names
+		"Charge Card",                 //
+		`class="lane"`,                // timeline
+		"card declined",               // step error
+		`&#34;total&#34;: 40`,         // step output
+		`<td class="mono">items</td>`, // state keys
+		"/runs/" + run.RunID + "/retry",
+	} {
+		assert.Contains(t, body, want)
+	}
+	assert.NotContains(t, body, "/runs/"+run.RunID+"/cancel")
+	assert.NotContains(t, body, `http-equiv="refresh"`)
+
+	status, body = get(t, srv, "/runs/missing")
+	assert.Equal(t, http.StatusNotFound, status)
+	assert.Contains(t, body, "Run not found")
+
+	status, _ = get(t, srv, "/nothing/here")
+	assert.Equal(t, http.StatusNotFound, status)
+
+	status, body = get(t, srv, "/static/dashboard.css")
+	assert.Equal(t, http.StatusOK, status)
+	assert.Contains(t, body, ".badge")
+}
+
+func TestDashboard_Commands(t *testing.T) {
+	srv, eng, failCharge := newServer(t)
+	failCharge.Store(true)
+	run := startRun(t, eng, 2)
+
+	// Cancelling a failed run is reported on the run page
+	resp := post(t, srv, "/runs/"+run.RunID+"/cancel", nil)
+	require.Equal(t, http.StatusSeeOther, resp.StatusCode)
+	location, err := url.Parse(resp.Header.Get("Location"))
+	require.NoError(t, err)
+	assert.Equal(t, "/runs/"+run.RunID, location.Path)
+	assert.Contains(t, location.Query().Get("error"), "cannot cancel")
+
+	_, body := get(t, srv, location.String())
+	assert.Contains(t, body, `class="flash error"`)
+
+	failCharge.Store(false)
+	resp = post(t, srv, "/runs/"+run.RunID+"/retry", nil)
+	require.Equal(t, http.StatusSeeOther, resp.StatusCode)
+	assert.True(t, strings.HasSuffix(resp.Header.Get("Location"), "?notice=Run+retried"))
+	finished, err := eng.WaitForRun(t.Context(), run.RunID)
+	require.NoError(t, err)
+	assert.Equal(t, gorkflow.RunStatusCompleted, finished.Status)
+
+	// Cross-origin form posts are rejected
+	resp = post(t, srv, "/runs/"+run.RunID+"/retry", http.Header{"Sec-Fetch-Site": {"cross-site"}})
+	assert.Equal(t, http.StatusForbidden, resp.StatusCode)
+}
+
+func TestDashboard_ReadOnly(t *testing.T) {
+	srv, eng, failCharge := newServer(t, WithReadOnly(), WithTitle("Support"))
+	failCharge.Store(true)
+	run := startRun(t, eng, 2)
+
+	_, body := get(t, srv, "/runs/"+run.RunID)
+	assert.Contains(t, body, "<title>Support</title>")
+	assert.NotContains(t, body, "/retry")
+
+	resp := post(t, srv, "/runs/"+run.RunID+"/retry", nil)
+	assert.Equal(t, http.StatusForbidden, resp.StatusCode)
+	unchanged, err := eng.GetRun(t.Context(), run.RunID)
+	require.NoError(t, err)
+	assert.Equal(t, gorkflow.RunStatusFailed, unchanged.Status)
+}
@@ -0,0 +1,125 @@
+package dashboard
+
+import (
+	"sort"
+
+	"github.com/sicko7947/gorkflow"
+)
+
+// Dimensions of the graph drawing in SVG units
+const (
+	nodeWidth  = 180
+	nodeHeight = 48
+	columnGap  = 32
+	rowGap     = 44
+	graphPad   = 12
+)
+
+// graphView is a workflow graph laid out top to bottom, one row per level
+type graphView struct {
+	Width  int
+	Height int
+	Nodes  []graphNode
+	Edges  []graphEdge
+}
+
+type graphNode struct {
+	StepID      string
+	Label       string
+	Status      gorkflow.StepStatus // empty if the step has not run
+	Detail      string
+	X, Y        int
+	Parallel    bool
+	Conditional bool
+}
+
+type graphEdge struct {
+	X1, Y1, X2, Y2 int
+	Conditional    bool
+}
+
+// layoutGraph places the steps of wf by level and overlays the status of each
+// step's latest execution
+func layoutGraph(wf *gorkflow.Workflow, executions []*gorkflow.StepExecution) *graphView {
+	graph := wf.Graph()
+	levels, err := graph.ComputeLevels()
+	if err != nil || len(levels) == 0 {
+		return nil
+	}
+
+	latest := make(map[string]*gorkflow.StepExecution, len(executions))
+	for _, exec := range executions {
+		if prev, ok := latest[exec.StepID]; !ok || exec.ExecutionIndex >= prev.ExecutionIndex {
+			latest[exec.StepID] = exec
+		}
+	}
+
+	widest := 0
+	for _, level := range levels {
+		widest = max(widest, len(level))
+	}
+	view := &graphView{
+		Width:  2*graphPad + widest*nodeWidth + (widest-1)*columnGap,
+		Height: 2*graphPad + len(levels)*nodeHeight + (len(levels)-1)*rowGap,
+	}
+
+	positions := make(map[string]graphNode)
+	for row, level := range levels {
+		ids := append([]string(nil), level...)
+		sort.Strings(ids)
+		rowWidth := len(ids)*nodeWidth + (len(ids)-1)*columnGap
+		x := (view.Width - rowWidth) / 2
+		y := graphPad + row*(nodeHeight+rowGap)
+		for _, id := range ids {
+			node := graphNode{
+				StepID:   id,
+				Label:    id,
+				X:        x,
+				Y:        y,
+				Parallel: graph.Nodes[id].Type == gorkflow.NodeTypeParallel,
+			}
+			if step, err := wf.GetStep(id); err == nil {
+				node.Label = truncate(step.GetName(), 24)
+				node.Conditional = gorkflow.IsConditionalStep(step)
+			}
+			if exec, ok := latest[id]; ok {
+				node.Status = exec.Status
+				node.Detail = string(exec.Status)
+				if exec.DurationMs > 0 {
+					node.Detail += " · " + formatDuration(exec.DurationMs)
+				}
+			}
+			positions[id] = node
+			view.Nodes = append(view.Nodes, node)
+			x += nodeWidth + columnGap
+		}
+	}
+
+	for _, from := range view.Nodes {
+		next := append([]string(nil), graph.Nodes[from.StepID].Next...)
+		sort.Strings(next)
+		for _, id := range next {
+			to, ok := positions[id]
+			if !ok {
+				continue
+			}
+			view.Edges = append(view.Edges, graphEdge{
+				X1:          from.X + nodeWidth/2,
+				Y1:          from.Y + nodeHeight,
+				X2:          to.X + nodeWidth/2,
+				Y2:          to.Y,
+				Conditional: to.Conditional,
+			})
+		}
+	}
+	return view
+}
+
+// truncate shortens s to n runes
+func truncate(s string, n int) string {
+	runes := []rune(s)
+	if len(runes) <= n {
+		return s
+	}
+	return string(runes[:n-1]) + "…"
+}
@@ -0,0 +1,383 @@
+package dashboard
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"html/template"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/sicko7947/gorkflow"
+	"github.com/sicko7947/gorkflow/httpapi"
+)
+
+// runStatuses are the statuses offered by the run list filter
+var runStatuses = []gorkflow.RunStatus{
+	gorkflow.RunStatusPending,
+	gorkflow.RunStatusRunning,
+	gorkflow.RunStatusCompleted,
+	gorkflow.RunStatusFailed,
+	gorkflow.RunStatusCancelled,
+}
+
+// page holds the fields shared by all pages
+type page struct {
+	Title    string
+	Base     string
+	ReadOnly bool
+	Notice   string
+	Error    string
+	Refresh  int // seconds between automatic reloads, 0 for none
+}
+
+type runsPage struct {
+	page
+	Runs      []*gorkflow.WorkflowRun
+	Statuses  []gorkflow.RunStatus
+	Status    string
+	Workflow  string
+	Workflows []string
+	PrevURL   string
+	NextURL   string
+	Offset    int
+}
+
+type runPage struct {
+	page
+	Run      *gorkflow.WorkflowRun
+	Workflow *gorkflow.Workflow
+	Graph    *graphView
+	Timeline []timelineRow
+	Steps    []stepRow
+	State    []stateEntry
+	Input    string
+	Output   string
+	Context  string
+}
+
+type stepRow struct {
+	*gorkflow.StepExecution
+	Name   string
+	Input  string
+	Output string
+}
+
+type stateEntry struct {
+	Key   string
+	Value string
+}
+
+// timelineRow places a step execution on the time axis of its run, in percent
+type timelineRow struct {
+	StepID string
+	Status gorkflow.StepStatus
+	Left   float64
+	Width  float64
+	Label  string
+}
+
+func (h *Handler) newPage(r *http.Request) page {
+	query := r.URL.Query()
+	return page{
+		Title:    h.title,
+		Base:     h.basePath,
+		ReadOnly: h.readOnly,
+		Notice:   query.Get("notice"),
+		Error:    query.Get("error"),
+	}
+}
+
+func (h *Handler) runsPage(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	data := runsPage{
+		page:     h.newPage(r),
+		Statuses: runStatuses,
+		Workflow: query.Get("workflow"),
+	}
+	for _, wf := range h.engine.Workflows() {
+		if n := len(data.Workflows); n == 0 || data.Workflows[n-1] != wf.ID() {
+			data.Workflows = append(data.Workflows, wf.ID())
+		}
+	}
+
+	filter := gorkflow.RunFilter{WorkflowID: data.Workflow, Limit: h.pageSize + 1}
+	if s := strings.ToUpper(query.Get("status")); s != "" {
+		status := gorkflow.RunStatus(s)
+		filter.Status = &status
+		data.Status = s
+	}
+	if offset, err := strconv.Atoi(query.Get("offset")); err == nil && offset > 0 {
+		filter.Offset = offset
+		data.Offset = offset
+	}
+
+	runs, err := h.engine.ListRuns(r.Context(), filter)
+	if err != nil {
+		h.renderError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	if len(runs) > h.pageSize {
+		runs = runs[:h.pageSize]
+		data.NextURL = h.listURL(data.Status, data.Workflow, data.Offset+h.pageSize)
+	}
+	if data.Offset > 0 {
+		data.PrevURL = h.listURL(data.Status, data.Workflow, max(data.Offset-h.pageSize, 0))
+	}
+	data.Runs = runs
+	h.render(w, http.StatusOK, "runs.html", data)
+}
+
+// listURL returns the URL of a page of the run list
+func (h *Handler) listURL(status, workflow string, offset int) string {
+	query := url.Values{}
+	if status != "" {
+		query.Set("status", status)
+	}
+	if workflow != "" {
+		query.Set("workflow", workflow)
+	}
+	if offset > 0 {
+		query.Set("offset", strconv.Itoa(offset))
+	}
+	if len(query) == 0 {
+		return h.path("/")
+	}
+	return h.path("/?" + query.Encode())
+}
+
+func (h *Handler) runPage(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	run, err := h.engine.GetRun(ctx, r.PathValue("runID"))
+	if err != nil {
+		h.renderErr(w, err)
+		return
+	}
+	executions, err := h.engine.GetStepExecutions(ctx, run.RunID)
+	if err != nil {
+		h.renderErr(w, err)
+		return
+	}
+	state, err := h.engine.Store().GetAllState(ctx, run.RunID)
+	if err != nil {
+		h.renderErr(w, err)
+		return
+	}
+
+	data := runPage{
+		page:    h.newPage(r),
+		Run:     run,
+		Input:   prettyPayload(run.Input),
+		Output:  prettyPayload(run.Output),
+		Context: prettyPayload(run.Context),
+	}
+	if !run.Status.IsTerminal() {
+		data.Refresh = 5
+	}
+	if wf, err := h.engine.GetWorkflow(run.WorkflowID, run.WorkflowVersion); err == nil {
+		data.Workflow = wf
+		data.Graph = layoutGraph(wf, executions)
+	}
+
+	for _, exec := range executions {
+		row := stepRow{
+			StepExecution: exec,
+			Input:         prettyPayload(exec.Input),
+			Output:        prettyPayload(exec.Output),
+		}
+		if data.Workflow != nil {
+			if step, err := data.Workflow.GetStep(exec.StepID); err == nil {
+				row.Name = step.GetName()
+			}
+		}
+		data.Steps = append(data.Steps, row)
+	}
+	data.Timeline = timeline(run, executions, time.Now())
+
+	keys := make([]string, 0, len(state))
+	for key := range state {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		data.State = append(data.State, stateEntry{Key: key, Value: prettyPayload(state[key])})
+	}
+
+	h.render(w, http.StatusOK, "run.html", data)
+}
+
+func (h *Handler) cancelRun(w http.ResponseWriter, r *http.Request) {
+	h.runCommand(w, r, "Run cancelled", func(runID string) error {
+		return h.engine.Cancel(r.Context(), runID)
+	})
+}
+
+func (h *Handler) retryRun(w http.ResponseWriter, r *http.Request) {
+	h.runCommand(w, r, "Run retried", func(runID string) error {
+		// The run continues in the background after the request returns
+		return h.engine.RetryRun(r.Context(), runID)
+	})
+}
+
+// runCommand executes a command on a run and redirects to the run page with
+// the outcome
+func (h *Handler) runCommand(w http.ResponseWriter, r *http.Request, notice string, command func(runID string) error) {
+	if h.readOnly {
+		h.renderError(w, http.StatusForbidden, "The dashboard is read-only")
+		return
+	}
+	runID := r.PathValue("runID")
+	query := url.Values{}
+	if err := command(runID); err != nil {
+		query.Set("error", err.Error())
+	} else {
+		query.Set("notice", notice)
+	}
+	http.Redirect(w, r, h.path("/runs/"+url.PathEscape(runID)+"?"+query.Encode()), http.StatusSeeOther)
+}
+
+// timeline positions the step executions of a run between the start of the
+// run and its completion, or now while it runs
+func timeline(run *gorkflow.WorkflowRun, executions []*gorkflow.StepExecution, now time.Time) []timelineRow {
+	start := run.CreatedAt
+	if run.StartedAt != nil {
+		start = *run.StartedAt
+	}
+	end := now
+	if run.CompletedAt != nil {
+		end = *run.CompletedAt
+	}
+	for _, exec := range executions {
+		if exec.CompletedAt != nil && exec.CompletedAt.After(end) {
+			end = *exec.CompletedAt
+		}
+	}
+	span := end.Sub(start)
+	if span <= 0 {
+		span = time.Millisecond
+	}
+
+	var rows []timelineRow
+	for _, exec := range executions {
+		if exec.StartedAt == nil {
+			continue
+		}
+		finish := now
+		if exec.CompletedAt != nil {
+			finish = *exec.CompletedAt
+		}
+		if finish.After(end) {
+			finish = end
+		}
+		left := percent(exec.StartedAt.Sub(start), span)
+		width := max(percent(finish.Sub(*exec.StartedAt), span), 0.5)
+		rows = append(rows, timelineRow{
+			StepID: exec.StepID,
+			Status: exec.Status,
+			Left:   left,
+			Width:  min(width, 100-left),
+			Label:  finish.Sub(*exec.StartedAt).Round(time.Millisecond).String(),
+		})
+	}
+	return rows
+}
+
+// percent returns d as a percentage of span, clamped to [0, 100]
+func percent(d, span time.Duration) float64 {
+	p := float64(d) / float64(span) * 100
+	return min(max(p, 0), 100)
+}
+
+// prettyPayload returns a payload as indented JSON; payloads that cannot be
+// decoded are shown as base64
+func prettyPayload(data []byte) string {
+	if len(data) == 0 {
+		return ""
+	}
+	out, err := gorkflow.PayloadJSON(data)
+	if err != nil {
+		return "base64:" + base64.StdEncoding.EncodeToString(data)
+	}
+	var buf bytes.Buffer
+	if err := json.Indent(&buf, out, "", "  "); err != nil {
+		return string(out)
+	}
+	return buf.String()
+}
+
+func (h *Handler) render(w http.ResponseWriter, status int, name string, data any) {
+	var buf bytes.Buffer
+	if err := h.templates[name].Execute(&buf, data); err != nil {
+		http.Error(w, "failed to render page: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	w.WriteHeader(status)
+	w.Write(buf.Bytes())
+}
+
+// renderErr renders an error page with the status matching err
+func (h *Handler) renderErr(w http.ResponseWriter, err error) {
+	we := httpapi.AsWorkflowError(err)
+	message := we.Message
+	if errors.Is(err, gorkflow.ErrRunNotFound) {
+		message = "Run not found"
+	}
+	h.renderError(w, httpapi.StatusCode(we.Code), message)
+}
+
+func (h *Handler) renderError(w http.ResponseWriter, status int, message string) {
+	h.render(w, status, "error.html", struct {
+		page
+		Status  int
+		Message string
+	}{page{Title: h.title, Base: h.basePath}, status, message})
+}
+
+func formatTime(t *time.Time) string {
+	if t == nil || t.IsZero() {
+		return "—"
+	}
+	return t.UTC().Format("2006-01-02 15:04:05 UTC")
+}
+
+func formatDuration(ms int64) string {
+	if ms <= 0 {
+		return "—"
+	}
+	return (time.Duration(ms) * time.Millisecond).String()
+}
+
+// templateFuncs are available to every template
+var templateFuncs = template.FuncMap{
+	"lower": func(s any) string {
+		switch v := s.(type) {
+		case gorkflow.RunStatus:
+			return strings.ToLower(string(v))
+		case gorkflow.StepStatus:
+			return strings.ToLower(string(v))
+		}
+		return ""
+	},
+	"time": func(t any) string {
+		switch v := t.(type) {
+		case time.Time:
+			return formatTime(&v)
+		case *time.Time:
+			return formatTime(v)
+		}
+		return "—"
+	},
+	"duration": formatDuration,
+	"percent": func(progress float64) string {
+		return strconv.Itoa(int(progress*100+0.5)) + "%"
+	},
+	"add": func(a, b int) int {
+		return a + b
+	},
+}
@@ -0,0 +1,193 @@
+package definition
+
+import (
+	"fmt"
+
+	"github.com/sicko7947/gorkflow"
+)
+
+// plannedStep is a step of a definition resolved against a registry
+type plannedStep struct {
+	step         gorkflow.StepExecutor
+	condition    gorkflow.Condition
+	defaultValue any
+}
+
+// Build validates the definition against reg and builds its workflow. All
+// problems found are reported together as an *Error.
+func (d *Definition) Build(reg *Registry) (*gorkflow.Workflow, error) {
+	b := &builder{def: d, reg: reg, ids: make(map[string]bool)}
+	groups := b.plan()
+	if len(b.problems) > 0 {
+		return nil, &Error{Problems: b.problems}
+	}
+
+	name := d.Name
+	if name == "" {
+		name = d.ID
+	}
+	wb := gorkflow.NewWorkflow(d.ID, name).
+		WithDescription(d.Description).
+		WithVersion(d.Version).
+		WithConfig(b.workflowConfig())
+	if d.Tags != nil {
+		wb.WithTags(d.Tags)
+	}
+
+	for i, group := range groups {
+		if d.Steps[i].Parallel != nil {
+			steps := make([]gorkflow.StepExecutor, len(group))
+			for j, planned := range group {
+				steps[j] = planned.wrap()
+			}
+			wb.Parallel(steps...)
+			continue
+		}
+		wb.ThenStep(group[0].wrap())
+	}
+	return wb.Build()
+}
+
+// wrap returns the step, wrapped with its condition if it has one
+func (p plannedStep) wrap() gorkflow.StepExecutor {
+	if p.condition == nil {
+		return p.step
+	}
+	return gorkflow.WrapStepWithCondition(p.step, p.condition, p.defaultValue)
+}
+
+// builder resolves a definition, collecting problems
+type builder struct {
+	def      *Definition
+	reg      *Registry
+	ids      map[string]bool
+	problems []Problem
+}
+
+func (b *builder) addf(line, column int, format string, args ...any) {
+	b.problems = append(b.problems, Problem{Line: line, Column: column, Message: fmt.Sprintf(format, args...)})
+}
+
+// workflowConfig returns the default execution config of the workflow's steps
+func (b *builder) workflowConfig() gorkflow.ExecutionConfig {
+	return b.def.Config.apply(gorkflow.DefaultExecutionConfig)
+}
+
+// plan validates the definition and creates its steps, one group per entry
+func (b *builder) plan() [][]plannedStep {
+	d := b.def
+	if b.reg == nil {
+		b.addf(0, 0, "no registry")
+		return nil
+	}
+	if d.ID == "" {
+		b.addf(d.line, 0, "workflow id is required")
+	}
+	b.checkConfig(d.Config)
+	if len(d.Steps) == 0 {
+		b.addf(d.line, 0, "workflow has no steps")
+	}
+
+	groups := make([][]plannedStep, 0, len(d.Steps))
+	for i := range d.Steps {
+		entry := &d.Steps[i]
+		switch {
+		case entry.Parallel == nil:
+			planned, ok := b.step(entry)
+			if ok {
+				groups = append(groups, []plannedStep{planned})
+			}
+		case entry.Step != "" || entry.ID != "" || entry.If != "" || entry.Config != nil || entry.Default != nil:
+			b.addf(entry.line, entry.column, "a parallel group only takes parallel; set step, id, if, config and default on its steps")
+		case len(entry.Parallel) == 0:
+			b.addf(entry.line, entry.column, "parallel group is empty")
+		case i == 0:
+			b.addf(entry.line, entry.column, "the first entry must be a single step, the entry point of the workflow")
+		default:
+			var group []plannedStep
+			for j := range entry.Parallel {
+				branch := &entry.Parallel[j]
+				if branch.Parallel != nil {
+					b.addf(branch.line, branch.column, "parallel groups cannot be nested")
+					continue
+				}
+				if planned, ok := b.step(branch); ok {
+					group = append(group, planned)
+				}
+			}
+			groups = append(groups, group)
+		}
+	}
+	return groups
+}
+
+// step resolves a step entry
+func (b *builder) step(entry *Entry) (plannedStep, bool) {
+	if entry.Step == "" {
+		b.addf(entry.line, entry.column, "step entry needs step or parallel")
+		return plannedStep{}, false
+	}
+	id := entry.StepID()
+	if b.ids[id] {
+		b.addf(entry.line, entry.column, "duplicate step id %q; set id to use a step more than once", id)
+		return plannedStep{}, false
+	}
+	b.ids[id] = true
+	b.checkConfig(entry.Config)
+
+	var planned plannedStep
+	ok := true
+	if entry.If != "" {
+		if planned.condition, ok = b.reg.condition(entry.If); !ok {
+			b.addf(entry.line, entry.column, "unknown condition %q", entry.If)
+		}
+		planned.defaultValue = entry.Default
+	} else if entry.Default != nil {
+		b.addf(entry.line, entry.column, "default is only used with if")
+		ok = false
+	}
+
+	factory, found := b.reg.step(entry.Step)
+	if !found {
+		b.addf(entry.line, entry.column, "unknown step %q", entry.Step)
+		return plannedStep{}, false
+	}
+	step := factory(id)
+	if step == nil || step.GetID() != id {
+		b.addf(entry.line, entry.column, "factory of step %q must return a step with id %q", entry.Step, id)
+		return plannedStep{}, false
+	}
+
+	// Overrides apply on top of the workflow config, which the builder only
+	// gives to steps still using the default config
+	base := step.GetConfig()
+	if base == gorkflow.DefaultExecutionConfig {
+		base = b.workflowConfig()
+	}
+	if entry.Config != nil {
+		step.SetConfig(entry.Config.apply(base))
+	}
+	planned.step = step
+	return planned, ok
+}
+
+// checkConfig reports negative values in a config
+func (b *builder) checkConfig(c *Config) {
+	if c == nil {
+		return
+	}
+	fields := []struct {
+		name  string
+		value *int
+	}{
+		{"max_retries", c.MaxRetries},
+		{"retry_delay_ms", c.RetryDelayMs},
+		{"timeout_seconds", c.TimeoutSeconds},
+		{"max_concurrency", c.MaxConcurrency},
+	}
+	for _, f := range fields {
+		if f.value != nil && *f.value < 0 {
+			b.addf(c.lines[f.name], 0, "%s must not be negative", f.name)
+		}
+	}
+}
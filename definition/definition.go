@@ -0,0 +1,315 @@
+// Package definition builds workflows from declarative YAML or JSON documents,
+// so step ordering, retries and timeouts can change without recompiling.
+// Documents reference steps and conditions by name from a Registry populated
+// in Go.
+//
+//	reg := definition.NewRegistry()
+//	reg.RegisterStep("fetch", func(id string) gorkflow.StepExecutor {
+//		return gorkflow.NewStep(id, "Fetch Orders", fetchOrders)
+//	})
+//	reg.RegisterCondition("has_items", hasItems)
+//
+//	wf, err := definition.LoadFile("orders.yaml", reg)
+//
+// A document lists the steps to run in order. An entry is either a step
+// reference or a group of steps running in parallel:
+//
+//	id: orders
+//	name: Process Orders
+//	version: "2"
+//	config:
+//	  max_retries: 3
+//	  retry_backoff: EXPONENTIAL
+//	steps:
+//	  - step: fetch
+//	    config:
+//	      timeout_seconds: 60
+//	  - parallel:
+//	      - step: price
+//	      - step: reserve
+//	  - step: notify
+//	    if: has_items
+package definition
+
+import (
+	"fmt"
+	"os"
+	"slices"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/sicko7947/gorkflow"
+)
+
+// Definition is a declarative workflow document
+type Definition struct {
+	ID          string            `yaml:"id" json:"id"`
+	Name        string            `yaml:"name" json:"name"`
+	Description string            `yaml:"description,omitempty" json:"description,omitempty"`
+	Version     string            `yaml:"version,omitempty" json:"version,omitempty"`
+	Tags        map[string]string `yaml:"tags,omitempty" json:"tags,omitempty"`
+
+	// Config is the default execution config of the workflow's steps
+	Config *Config `yaml:"config,omitempty" json:"config,omitempty"`
+
+	// Steps run in order
+	Steps []Entry `yaml:"steps" json:"steps"`
+
+	line int
+}
+
+// Entry is an item of a step list: a step reference or a parallel group
+type Entry struct {
+	// Step is the registry name of the step
+	Step string `yaml:"step,omitempty" json:"step,omitempty"`
+
+	// ID of the step in the workflow (default Step). Set it to use a
+	// registered step more than once.
+	ID string `yaml:"id,omitempty" json:"id,omitempty"`
+
+	// Config overrides fields of the workflow config for this step
+	Config *Config `yaml:"config,omitempty" json:"config,omitempty"`
+
+	// If is the registry name of a condition; the step is skipped when it is false
+	If string `yaml:"if,omitempty" json:"if,omitempty"`
+
+	// Default is the output of the step when it is skipped (zero value if unset)
+	Default any `yaml:"default,omitempty" json:"default,omitempty"`
+
+	// Parallel lists steps that run concurrently after the previous entry
+	Parallel []Entry `yaml:"parallel,omitempty" json:"parallel,omitempty"`
+
+	line, column int
+}
+
+// StepID returns the ID of the step in the workflow
+func (e *Entry) StepID() string {
+	if e.ID != "" {
+		return e.ID
+	}
+	return e.Step
+}
+
+// Config holds execution config fields; unset fields keep their inherited value
+type Config struct {
+	MaxRetries      *int                     `yaml:"max_retries,omitempty" json:"max_retries,omitempty"`
+	RetryDelayMs    *int                     `yaml:"retry_delay_ms,omitempty" json:"retry_delay_ms,omitempty"`
+	RetryBackoff    gorkflow.BackoffStrategy `yaml:"retry_backoff,omitempty" json:"retry_backoff,omitempty"`
+	TimeoutSeconds  *int                     `yaml:"timeout_seconds,omitempty" json:"timeout_seconds,omitempty"`
+	MaxConcurrency  *int                     `yaml:"max_concurrency,omitempty" json:"max_concurrency,omitempty"`
+	ContinueOnError *bool                    `yaml:"continue_on_error,omitempty" json:"continue_on_error,omitempty"`
+
+	lines map[string]int // line of each field
+}
+
+// apply returns base with the fields set in c
+func (c *Config) apply(base gorkflow.ExecutionConfig) gorkflow.ExecutionConfig {
+	if c == nil {
+		return base
+	}
+	if c.MaxRetries != nil {
+		base.MaxRetries = *c.MaxRetries
+	}
+	if c.RetryDelayMs != nil {
+		base.RetryDelayMs = *c.RetryDelayMs
+	}
+	if c.RetryBackoff != "" {
+		base.RetryBackoff = c.RetryBackoff
+	}
+	if c.TimeoutSeconds != nil {
+		base.TimeoutSeconds = *c.TimeoutSeconds
+	}
+	if c.MaxConcurrency != nil {
+		base.MaxConcurrency = *c.MaxConcurrency
+	}
+	if c.ContinueOnError != nil {
+		base.ContinueOnError = *c.ContinueOnError
+	}
+	return base
+}
+
+// Problem is an error at a position of a document. Line and Column are 1-based
+// and zero when unknown, e.g. for definitions built in Go.
+type Problem struct {
+	Line    int
+	Column  int
+	Message string
+}
+
+func (p Problem) String() string {
+	if p.Line == 0 {
+		return p.Message
+	}
+	return fmt.Sprintf("line %d: %s", p.Line, p.Message)
+}
+
+// Error lists the problems found in a definition
+type Error struct {
+	Problems []Problem
+}
+
+func (e *Error) Error() string {
+	lines := make([]string, len(e.Problems))
+	for i, p := range e.Problems {
+		lines[i] = p.String()
+	}
+	return "invalid workflow definition: " + strings.Join(lines, "; ")
+}
+
+// Load parses a YAML or JSON document and builds its workflow
+func Load(data []byte, reg *Registry) (*gorkflow.Workflow, error) {
+	def, err := Parse(data)
+	if err != nil {
+		return nil, err
+	}
+	return def.Build(reg)
+}
+
+// LoadFile reads a YAML or JSON document and builds its workflow
+func LoadFile(path string, reg *Registry) (*gorkflow.Workflow, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read workflow definition: %w", err)
+	}
+	return Load(data, reg)
+}
+
+// Parse parses a YAML or JSON document. Syntax errors, unknown fields and
+// values of the wrong type are reported as an *Error with line numbers.
+func Parse(data []byte) (*Definition, error) {
+	var root yaml.Node
+	if err := yaml.Unmarshal(data, &root); err != nil {
+		return nil, fmt.Errorf("failed to parse workflow definition: %w", err)
+	}
+	if len(root.Content) == 0 {
+		return nil, &Error{Problems: []Problem{{Message: "document is empty"}}}
+	}
+
+	p := &parser{}
+	def := p.definition(root.Content[0])
+	if len(p.problems) > 0 {
+		return nil, &Error{Problems: p.problems}
+	}
+	return def, nil
+}
+
+// parser converts YAML nodes into a Definition, collecting problems
+type parser struct {
+	problems []Problem
+}
+
+func (p *parser) addf(node *yaml.Node, format string, args ...any) {
+	p.problems = append(p.problems, Problem{Line: node.Line, Column: node.Column, Message: fmt.Sprintf(format, args...)})
+}
+
+// fields calls fn for each key of a mapping node, reporting keys not in allowed
+func (p *parser) fields(node *yaml.Node, what string, allowed []string, fn func(key string, value *yaml.Node)) {
+	if node.Kind != yaml.MappingNode {
+		p.addf(node, "%s must be a mapping", what)
+		return
+	}
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		key, value := node.Content[i], node.Content[i+1]
+		if !slices.Contains(allowed, key.Value) {
+			p.addf(key, "unknown field %q in %s", key.Value, what)
+			continue
+		}
+		fn(key.Value, value)
+	}
+}
+
+// decode decodes a value node into out, reporting a type mismatch
+func (p *parser) decode(node *yaml.Node, field, want string, out any) {
+	if err := node.Decode(out); err != nil {
+		p.addf(node, "%s must be %s", field, want)
+	}
+}
+
+func (p *parser) definition(node *yaml.Node) *Definition {
+	def := &Definition{line: node.Line}
+	allowed := []string{"id", "name", "description", "version", "tags", "config", "steps"}
+	p.fields(node, "workflow", allowed, func(key string, value *yaml.Node) {
+		switch key {
+		case "id":
+			p.decode(value, key, "a string", &def.ID)
+		case "name":
+			p.decode(value, key, "a string", &def.Name)
+		case "description":
+			p.decode(value, key, "a string", &def.Description)
+		case "version":
+			p.decode(value, key, "a string", &def.Version)
+		case "tags":
+			p.decode(value, key, "a mapping of strings", &def.Tags)
+		case "config":
+			def.Config = p.config(value)
+		case "steps":
+			def.Steps = p.entries(value, "steps")
+		}
+	})
+	return def
+}
+
+func (p *parser) entries(node *yaml.Node, field string) []Entry {
+	if node.Kind != yaml.SequenceNode {
+		p.addf(node, "%s must be a list", field)
+		return nil
+	}
+	entries := make([]Entry, 0, len(node.Content))
+	for _, item := range node.Content {
+		entries = append(entries, p.entry(item))
+	}
+	return entries
+}
+
+func (p *parser) entry(node *yaml.Node) Entry {
+	entry := Entry{line: node.Line, column: node.Column}
+	allowed := []string{"step", "id", "config", "if", "default", "parallel"}
+	p.fields(node, "step entry", allowed, func(key string, value *yaml.Node) {
+		switch key {
+		case "step":
+			p.decode(value, key, "a string", &entry.Step)
+		case "id":
+			p.decode(value, key, "a string", &entry.ID)
+		case "config":
+			entry.Config = p.config(value)
+		case "if":
+			p.decode(value, key, "a string", &entry.If)
+		case "default":
+			p.decode(value, key, "a value", &entry.Default)
+		case "parallel":
+			entry.Parallel = p.entries(value, "parallel")
+		}
+	})
+	return entry
+}
+
+func (p *parser) config(node *yaml.Node) *Config {
+	config := &Config{lines: make(map[string]int)}
+	allowed := []string{"max_retries", "retry_delay_ms", "retry_backoff", "timeout_seconds", "max_concurrency", "continue_on_error"}
+	p.fields(node, "config", allowed, func(key string, value *yaml.Node) {
+		config.lines[key] = value.Line
+		switch key {
+		case "max_retries":
+			p.decode(value, key, "an integer", &config.MaxRetries)
+		case "retry_delay_ms":
+			p.decode(value, key, "an integer", &config.RetryDelayMs)
+		case "retry_backoff":
+			var backoff string
+			p.decode(value, key, "a string", &backoff)
+			config.RetryBackoff = gorkflow.BackoffStrategy(strings.ToUpper(backoff))
+			switch config.RetryBackoff {
+			case gorkflow.BackoffLinear, gorkflow.BackoffExponential, gorkflow.BackoffNone, "":
+			default:
+				p.addf(value, "retry_backoff must be LINEAR, EXPONENTIAL or NONE, got %q", backoff)
+			}
+		case "timeout_seconds":
+			p.decode(value, key, "an integer", &config.TimeoutSeconds)
+		case "max_concurrency":
+			p.decode(value, key, "an integer", &config.MaxConcurrency)
+		case "continue_on_error":
+			p.decode(value, key, "a boolean", &config.ContinueOnError)
+		}
+	})
+	return config
+}
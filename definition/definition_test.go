@@ -0,0 +1,200 @@
+package definition_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/sicko7947/gorkflow"
+	"github.com/sicko7947/gorkflow/definition"
+	"github.com/sicko7947/gorkflow/gorkflowtest"
+)
+
+// newRegistry registers steps that add their increment to the input
+func newRegistry() *definition.Registry {
+	reg := definition.NewRegistry()
+	for name, inc := range map[string]int{"fetch": 1, "price": 10, "stock": 100, "notify": 1000} {
+		reg.RegisterStep(name, func(id string) gorkflow.StepExecutor {
+			return gorkflow.NewStep(id, name, func(ctx *gorkflow.StepContext, in int) (int, error) {
+				return in + inc, nil
+			})
+		})
+	}
+	reg.RegisterCondition("never", func(*gorkflow.StepContext) (bool, error) { return false, nil })
+	return reg
+}
+
+const ordersYAML = `
+id: orders
+name: Process Orders
+version: "2"
+tags:
+  team: ops
+config:
+  max_retries: 5
+  retry_backoff: exponential
+steps:
+  - step: fetch
+    config:
+      timeout_seconds: 60
+  - parallel:
+      - step: price
+      - step: stock
+        config:
+          continue_on_error: true
+  - step: notify
+    if: never
+    default: 7
+`
+
+func TestLoad_YAML(t *testing.T) {
+	wf, err := definition.Load([]byte(ordersYAML), newRegistry())
+	require.NoError(t, err)
+
+	assert.Equal(t, "orders", wf.ID())
+	assert.Equal(t, "Process Orders", wf.Name())
+	assert.Equal(t, "2", wf.Version())
+	assert.Equal(t, map[string]string{"team": "ops"}, wf.Tags())
+
+	graph := wf.Graph()
+	assert.Equal(t, "fetch", graph.EntryPoint)
+	assert.ElementsMatch(t, []string{"price", "stock"}, graph.Nodes["fetch"].Next)
+	assert.Equal(t, gorkflow.NodeTypeParallel, graph.Nodes["price"].Type)
+	assert.Equal(t, []string{"notify"}, graph.Nodes["stock"].Next)
+
+	fetch, err := wf.GetStep("fetch")
+	require.NoError(t, err)
+	assert.Equal(t, 5, fetch.GetConfig().MaxRetries)
+	assert.Equal(t, gorkflow.BackoffExponential, fetch.GetConfig().RetryBackoff)
+	assert.Equal(t, 60, fetch.GetConfig().TimeoutSeconds)
+
+	price, err := wf.GetStep("price")
+	require.NoError(t, err)
+	assert.Equal(t, 5, price.GetConfig().MaxRetries)
+	assert.Equal(t, gorkflow.DefaultExecutionConfig.TimeoutSeconds, price.GetConfig().TimeoutSeconds)
+
+	stock, err := wf.GetStep("stock")
+	require.NoError(t, err)
+	assert.True(t, stock.GetConfig().ContinueOnError)
+
+	notify, err := wf.GetStep("notify")
+	require.NoError(t, err)
+	assert.True(t, gorkflow.IsConditionalStep(notify))
+
+	h := gorkflowtest.New(t)
+	run := h.Run(wf, 0)
+	run.AssertStatus(gorkflow.RunStatusCompleted)
+	gorkflowtest.AssertOutput(run, "fetch", 1)
+	gorkflowtest.AssertOutput(run, "notify", 7)
+}
+
+func TestLoad_JSON(t *testing.T) {
+	doc := `{
+	"id": "twice",
+	"steps": [
+		{"step": "fetch"},
+		{"step": "fetch", "id": "refetch", "config": {"max_retries": 0}}
+	]
+}`
+	wf, err := definition.Load([]byte(doc), newRegistry())
+	require.NoError(t, err)
+
+	assert.Equal(t, "twice", wf.Name())
+	refetch, err := wf.GetStep("refetch")
+	require.NoError(t, err)
+	assert.Equal(t, 0, refetch.GetConfig().MaxRetries)
+
+	h := gorkflowtest.New(t)
+	run := h.Run(wf, 0)
+	run.AssertStepOrder("fetch", "refetch")
+	gorkflowtest.AssertOutput(run, "refetch", 2)
+}
+
+func TestLoad_StepInstancesAreNotShared(t *testing.T) {
+	reg := newRegistry()
+	doc := "id: a\nsteps:\n  - step: fetch\n    config:\n      max_retries: 9\n"
+	first, err := definition.Load([]byte(doc), reg)
+	require.NoError(t, err)
+	second, err := definition.Load([]byte("id: b\nsteps:\n  - step: fetch\n"), reg)
+	require.NoError(t, err)
+
+	a, _ := first.GetStep("fetch")
+	b, _ := second.GetStep("fetch")
+	assert.Equal(t, 9, a.GetConfig().MaxRetries)
+	assert.Equal(t, gorkflow.DefaultExecutionConfig.MaxRetries, b.GetConfig().MaxRetries)
+}
+
+func TestParse_Problems(t *testing.T) {
+	doc := `id: broken
+colour: blue
+config:
+  max_retries: lots
+steps:
+  - step: fetch
+    retries: 3
+  - parallel: fetch
+`
+	_, err := definition.Parse([]byte(doc))
+	var defErr *definition.Error
+	require.True(t, errors.As(err, &defErr), "error %v", err)
+
+	assert.Equal(t, []definition.Problem{
+		{Line: 2, Column: 1, Message: `unknown field "colour" in workflow`},
+		{Line: 4, Column: 16, Message: "max_retries must be an integer"},
+		{Line: 7, Column: 5, Message: `unknown field "retries" in step entry`},
+		{Line: 8, Column: 15, Message: "parallel must be a list"},
+	}, defErr.Problems)
+	assert.Contains(t, err.Error(), "line 4: max_retries must be an integer")
+}
+
+func TestBuild_Problems(t *testing.T) {
+	doc := `id: broken
+config:
+  timeout_seconds: -1
+steps:
+  - step: fetch
+  - step: missing
+  - step: fetch
+  - parallel:
+      - step: price
+        if: sometimes
+      - parallel:
+          - step: stock
+  - step: notify
+    default: 1
+`
+	_, err := definition.Load([]byte(doc), newRegistry())
+	var defErr *definition.Error
+	require.True(t, errors.As(err, &defErr), "error %v", err)
+
+	messages := make(map[int]string)
+	for _, p := range defErr.Problems {
+		messages[p.Line] = p.Message
+	}
+	assert.Equal(t, map[int]string{
+		3:  "timeout_seconds must not be negative",
+		6:  `unknown step "missing"`,
+		7:  `duplicate step id "fetch"; set id to use a step more than once`,
+		9:  `unknown condition "sometimes"`,
+		11: "parallel groups cannot be nested",
+		13: "default is only used with if",
+	}, messages)
+}
+
+func TestParse_SyntaxError(t *testing.T) {
+	_, err := definition.Parse([]byte("id: [unclosed\n"))
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "line")
+}
+
+func TestBuild_FactoryMustHonourID(t *testing.T) {
+	reg := definition.NewRegistry()
+	reg.RegisterStep("fixed", func(string) gorkflow.StepExecutor {
+		return gorkflow.NewStep("fixed", "Fixed", func(ctx *gorkflow.StepContext, in int) (int, error) { return in, nil })
+	})
+	_, err := definition.Load([]byte("id: w\nsteps:\n  - step: fixed\n    id: other\n"), reg)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), `line 3: factory of step "fixed" must return a step with id "other"`)
+}
@@ -0,0 +1,152 @@
+package definition
+
+import (
+	"fmt"
+
+	"github.com/sicko7947/gorkflow"
+)
+
+// Export converts a built workflow back into a definition. Steps are
+// referenced by their IDs, so the workflow round-trips through Build with a
+// registry holding each step under its ID. Conditional steps reference a
+// condition named after the step; their default outputs are not exported.
+//
+// Only graphs the builder produces from sequences and parallel groups can be
+// exported.
+func Export(wf *gorkflow.Workflow) (*Definition, error) {
+	def := &Definition{
+		ID:          wf.ID(),
+		Name:        wf.Name(),
+		Description: wf.Description(),
+		Version:     wf.Version(),
+		Config:      configDiff(gorkflow.DefaultExecutionConfig, wf.GetConfig()),
+	}
+
+	if len(wf.Tags()) > 0 {
+		def.Tags = wf.Tags()
+	}
+
+	graph := wf.Graph()
+	entry, ok := graph.Nodes[graph.EntryPoint]
+	if !ok {
+		return nil, fmt.Errorf("workflow %s has no entry point", wf.ID())
+	}
+	if len(graph.EntryPoints()) > 1 {
+		return nil, fmt.Errorf("workflow %s cannot be exported: it has several entry points", wf.ID())
+	}
+
+	visited := 0
+	group := []*gorkflow.GraphNode{entry}
+	for len(group) > 0 {
+		visited += len(group)
+		var entries []Entry
+		for _, node := range group {
+			e, err := exportStep(wf, node.StepID)
+			if err != nil {
+				return nil, err
+			}
+			entries = append(entries, e)
+		}
+		if len(group) > 1 || group[0].Type == gorkflow.NodeTypeParallel {
+			def.Steps = append(def.Steps, Entry{Parallel: entries})
+		} else {
+			def.Steps = append(def.Steps, entries[0])
+		}
+
+		next, err := nextGroup(graph, group)
+		if err != nil {
+			return nil, fmt.Errorf("workflow %s cannot be exported: %w", wf.ID(), err)
+		}
+		group = next
+	}
+
+	if visited != len(graph.Nodes) {
+		return nil, fmt.Errorf("workflow %s cannot be exported: not all steps follow from the entry point", wf.ID())
+	}
+	return def, nil
+}
+
+// nextGroup returns the steps following group. Every step of group must lead to
+// every step of the next group and nothing else, as the builder chains them.
+func nextGroup(graph *gorkflow.ExecutionGraph, group []*gorkflow.GraphNode) ([]*gorkflow.GraphNode, error) {
+	current := make(map[string]bool, len(group))
+	for _, node := range group {
+		current[node.StepID] = true
+	}
+
+	first := group[0].Next
+	var next []*gorkflow.GraphNode
+	for _, stepID := range first {
+		node := graph.Nodes[stepID]
+		if !sameSet(node.Previous, current) {
+			return nil, fmt.Errorf("step %s does not follow a sequence or parallel group", stepID)
+		}
+		next = append(next, node)
+	}
+
+	following := make(map[string]bool, len(first))
+	for _, stepID := range first {
+		following[stepID] = true
+	}
+	for _, node := range group[1:] {
+		if !sameSet(node.Next, following) {
+			return nil, fmt.Errorf("steps %s and %s lead to different steps", group[0].StepID, node.StepID)
+		}
+	}
+	return next, nil
+}
+
+// sameSet reports whether ids holds exactly the IDs in set
+func sameSet(ids []string, set map[string]bool) bool {
+	if len(ids) != len(set) {
+		return false
+	}
+	for _, id := range ids {
+		if !set[id] {
+			return false
+		}
+	}
+	return true
+}
+
+func exportStep(wf *gorkflow.Workflow, stepID string) (Entry, error) {
+	step, err := wf.GetStep(stepID)
+	if err != nil {
+		return Entry{}, err
+	}
+	e := Entry{
+		Step:   stepID,
+		Config: configDiff(wf.GetConfig(), step.GetConfig()),
+	}
+	if gorkflow.IsConditionalStep(step) {
+		e.If = stepID
+	}
+	return e, nil
+}
+
+// configDiff returns the fields of config that differ from base, or nil
+func configDiff(base, config gorkflow.ExecutionConfig) *Config {
+	if base == config {
+		return nil
+	}
+	diff := &Config{}
+	if config.MaxRetries != base.MaxRetries {
+		diff.MaxRetries = &config.MaxRetries
+	}
+	if config.RetryDelayMs != base.RetryDelayMs {
+		diff.RetryDelayMs = &config.RetryDelayMs
+	}
+	if config.RetryBackoff != base.RetryBackoff {
+		diff.RetryBackoff = config.RetryBackoff
+	}
+	if config.TimeoutSeconds != base.TimeoutSeconds {
+		diff.TimeoutSeconds = &config.TimeoutSeconds
+	}
+	if config.MaxConcurrency != base.MaxConcurrency {
+		diff.MaxConcurrency = &config.MaxConcurrency
+	}
+	if config.ContinueOnError != base.ContinueOnError {
+		diff.ContinueOnError = &config.ContinueOnError
+	}
+	return diff
+}
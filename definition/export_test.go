@@ -0,0 +1,115 @@
+package definition_test
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gopkg.in/yaml.v3"
+
+	"github.com/sicko7947/gorkflow"
+	"github.com/sicko7947/gorkflow/definition"
+)
+
+func newStep(id string, opts ...gorkflow.StepOption) *gorkflow.Step[int, int] {
+	return gorkflow.NewStep(id, id, func(ctx *gorkflow.StepContext, in int) (int, error) { return in, nil }, opts...)
+}
+
+// registryOf registers steps under their IDs and a condition per conditional step
+func registryOf(ids ...string) *definition.Registry {
+	reg := definition.NewRegistry()
+	for _, id := range ids {
+		reg.RegisterStep(id, func(id string) gorkflow.StepExecutor { return newStep(id) })
+		reg.RegisterCondition(id, func(*gorkflow.StepContext) (bool, error) { return true, nil })
+	}
+	return reg
+}
+
+func mustStep(t *testing.T, wf *gorkflow.Workflow, id string) gorkflow.StepExecutor {
+	t.Helper()
+	step, err := wf.GetStep(id)
+	require.NoError(t, err)
+	return step
+}
+
+func TestExport_RoundTrip(t *testing.T) {
+	always := func(*gorkflow.StepContext) (bool, error) { return true, nil }
+	wf, err := gorkflow.NewWorkflow("orders", "Orders").
+		WithDescription("Processes orders").
+		WithVersion("3").
+		WithTags(map[string]string{"team": "ops"}).
+		WithConfig(gorkflow.ExecutionConfig{MaxRetries: 5, RetryDelayMs: 200, RetryBackoff: gorkflow.BackoffExponential, TimeoutSeconds: 30, MaxConcurrency: 1}).
+		ThenStep(newStep("fetch")).
+		Parallel(newStep("price"), newStep("stock", gorkflow.WithTimeout(90*time.Second))).
+		ThenStepIf(newStep("notify"), always, nil).
+		Build()
+	require.NoError(t, err)
+
+	def, err := definition.Export(wf)
+	require.NoError(t, err)
+
+	data, err := yaml.Marshal(def)
+	require.NoError(t, err)
+	assert.Equal(t, `id: orders
+name: Orders
+description: Processes orders
+version: "3"
+tags:
+    team: ops
+config:
+    max_retries: 5
+    retry_delay_ms: 200
+    retry_backoff: EXPONENTIAL
+steps:
+    - step: fetch
+    - parallel:
+        - step: price
+        - step: stock
+          config:
+            max_retries: 3
+            retry_delay_ms: 1000
+            retry_backoff: LINEAR
+            timeout_seconds: 90
+    - step: notify
+      if: notify
+`, string(data))
+
+	loaded, err := definition.Load(data, registryOf("fetch", "price", "stock", "notify"))
+	require.NoError(t, err)
+	assert.Equal(t, wf.Mermaid(), loaded.Mermaid())
+	assert.Equal(t, mustStep(t, wf, "stock").GetConfig(), mustStep(t, loaded, "stock").GetConfig())
+	assert.Equal(t, mustStep(t, wf, "fetch").GetConfig(), mustStep(t, loaded, "fetch").GetConfig())
+
+	// JSON round-trips the same way
+	jsonData, err := json.Marshal(def)
+	require.NoError(t, err)
+	fromJSON, err := definition.Load(jsonData, registryOf("fetch", "price", "stock", "notify"))
+	require.NoError(t, err)
+	assert.Equal(t, wf.DOT(), fromJSON.DOT())
+}
+
+func TestExport_UnsupportedGraph(t *testing.T) {
+	wf := gorkflow.NewWorkflowInstance("diamond", "Diamond")
+	for _, id := range []string{"a", "b", "c", "d"} {
+		wf.AddStep(newStep(id))
+	}
+	graph := wf.Graph()
+	require.NoError(t, graph.AddEdge("a", "b"))
+	require.NoError(t, graph.AddEdge("a", "c"))
+	require.NoError(t, graph.AddEdge("b", "d"))
+
+	_, err := definition.Export(wf)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "cannot be exported")
+
+	dag, err := gorkflow.NewWorkflow("dag", "DAG").
+		Step(newStep("a")).
+		Step(newStep("b")).
+		Step(newStep("c")).DependsOn("a", "b").
+		Build()
+	require.NoError(t, err)
+	_, err = definition.Export(dag)
+	assert.ErrorContains(t, err, "several entry points")
+}
@@ -0,0 +1,70 @@
+package definition
+
+import (
+	"sort"
+	"sync"
+
+	"github.com/sicko7947/gorkflow"
+)
+
+// StepFactory creates a step with the given ID. Each workflow built from a
+// definition gets its own step instances, so per-step config overrides never
+// leak between workflows.
+type StepFactory func(id string) gorkflow.StepExecutor
+
+// Registry holds the steps and conditions that definitions can reference
+type Registry struct {
+	mu         sync.RWMutex
+	steps      map[string]StepFactory
+	conditions map[string]gorkflow.Condition
+}
+
+// NewRegistry creates an empty registry
+func NewRegistry() *Registry {
+	return &Registry{
+		steps:      make(map[string]StepFactory),
+		conditions: make(map[string]gorkflow.Condition),
+	}
+}
+
+// RegisterStep makes a step available under name, replacing any step
+// registered under the same name
+func (r *Registry) RegisterStep(name string, factory StepFactory) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.steps[name] = factory
+}
+
+// RegisterCondition makes a condition available under name for the if field
+// of step entries
+func (r *Registry) RegisterCondition(name string, cond gorkflow.Condition) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.conditions[name] = cond
+}
+
+// Steps returns the names of the registered steps in sorted order
+func (r *Registry) Steps() []string {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	names := make([]string, 0, len(r.steps))
+	for name := range r.steps {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+func (r *Registry) step(name string) (StepFactory, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	factory, ok := r.steps[name]
+	return factory, ok
+}
+
+func (r *Registry) condition(name string) (gorkflow.Condition, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	cond, ok := r.conditions[name]
+	return cond, ok
+}
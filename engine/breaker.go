@@ -0,0 +1,88 @@
+package engine
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	"github.com/sicko7947/gorkflow"
+)
+
+// WithCircuitBreaker registers a named circuit breaker that steps can attach with
+// gorkflow.WithCircuitBreaker. State transitions are logged and emitted as events.
+func WithCircuitBreaker(name string, config gorkflow.BreakerConfig) EngineOption {
+	return func(e *Engine) {
+		breaker := gorkflow.NewCircuitBreaker(name, config)
+		breaker.OnStateChange(e.circuitStateChanged)
+		e.breakers[name] = breaker
+	}
+}
+
+// circuitStateChanged logs a breaker transition and emits the matching event
+func (e *Engine) circuitStateChanged(name string, from, to gorkflow.CircuitState) {
+	gorkflow.LogCircuitStateChanged(e.logger, name, from, to)
+	e.emit(gorkflow.Event{
+		Type:          gorkflow.CircuitEventType(to),
+		Circuit:       name,
+		PreviousState: from,
+	})
+}
+
+// CircuitBreaker returns the registered breaker with the given name
+func (e *Engine) CircuitBreaker(name string) (*gorkflow.CircuitBreaker, bool) {
+	breaker, ok := e.breakers[name]
+	return breaker, ok
+}
+
+// CircuitBreakers returns a snapshot of every registered breaker, sorted by name
+func (e *Engine) CircuitBreakers() []gorkflow.CircuitSnapshot {
+	snapshots := make([]gorkflow.CircuitSnapshot, 0, len(e.breakers))
+	for _, breaker := range e.breakers {
+		snapshots = append(snapshots, breaker.Snapshot())
+	}
+	sort.Slice(snapshots, func(i, j int) bool {
+		return snapshots[i].Name < snapshots[j].Name
+	})
+	return snapshots
+}
+
+// stepBreaker resolves the circuit breaker attached to a step, if any
+func (e *Engine) stepBreaker(step gorkflow.StepExecutor) (*gorkflow.CircuitBreaker, error) {
+	name := gorkflow.StepCircuitBreaker(step)
+	if name == "" {
+		return nil, nil
+	}
+	breaker, ok := e.breakers[name]
+	if !ok {
+		return nil, fmt.Errorf("step %s: %w: %q", step.GetID(), gorkflow.ErrCircuitBreakerNotFound, name)
+	}
+	return breaker, nil
+}
+
+// admitAttempt asks the breaker to admit an attempt, waiting for the circuit to
+// half-open when the breaker is configured to park attempts
+func admitAttempt(ctx context.Context, breaker *gorkflow.CircuitBreaker) error {
+	var err error
+	if breaker.Config().WaitWhenOpen {
+		err = breaker.Wait(ctx)
+	} else {
+		err = breaker.Allow()
+	}
+	if err != nil && ctx.Err() == nil {
+		return fmt.Errorf("%w: %s", err, breaker.Name())
+	}
+	return err
+}
+
+// recordAttempt records the outcome of an admitted attempt. Attempts aborted by
+// workflow cancellation release their slot without counting as failures.
+func recordAttempt(ctx context.Context, breaker *gorkflow.CircuitBreaker, err error) {
+	switch {
+	case ctx.Err() != nil:
+		breaker.Release()
+	case err == nil:
+		breaker.Record(true)
+	default:
+		breaker.Record(false)
+	}
+}
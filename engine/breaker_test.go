@@ -0,0 +1,188 @@
+package engine
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/sicko7947/gorkflow"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func failingVendorWorkflow(t *testing.T, calls *atomic.Int32, opts ...gorkflow.StepOption) *gorkflow.Workflow {
+	t.Helper()
+	opts = append([]gorkflow.StepOption{
+		gorkflow.WithCircuitBreaker("vendor"),
+		gorkflow.WithRetries(3),
+		gorkflow.WithRetryDelay(time.Millisecond),
+		gorkflow.WithBackoff(gorkflow.BackoffNone),
+	}, opts...)
+
+	wf, err := gorkflow.NewWorkflow("breaker", "Breaker").
+		ThenStep(gorkflow.NewStep("call", "Call Vendor", func(ctx *gorkflow.StepContext, input DiscoverInput) (DiscoverOutput, error) {
+			calls.Add(1)
+			return DiscoverOutput{}, errors.New("vendor unavailable")
+		}, opts...)).
+		Build()
+	require.NoError(t, err)
+	return wf
+}
+
+func TestEngine_CircuitBreaker_FailsFast(t *testing.T) {
+	engine, listener := createListeningEngine(t, WithCircuitBreaker("vendor", gorkflow.BreakerConfig{
+		WindowSize:   10,
+		MinRequests:  2,
+		FailureRate:  1,
+		OpenDuration: time.Minute,
+	}))
+
+	var calls atomic.Int32
+	wf := failingVendorWorkflow(t, &calls)
+
+	// First run: two failed attempts open the circuit, remaining retries fail fast
+	runID, err := engine.StartWorkflow(context.Background(), wf, DiscoverInput{}, gorkflow.WithSynchronousExecution())
+	require.Error(t, err)
+	assert.ErrorIs(t, err, gorkflow.ErrCircuitOpen)
+	assert.Equal(t, int32(2), calls.Load())
+
+	run, err := engine.GetRun(context.Background(), runID)
+	require.NoError(t, err)
+	require.NotNil(t, run.Error)
+	assert.Equal(t, gorkflow.ErrCodeCircuitOpen, run.Error.Code)
+
+	execs, err := engine.GetStepExecutions(context.Background(), runID)
+	require.NoError(t, err)
+	require.Len(t, execs, 1)
+	assert.Equal(t, gorkflow.ErrCodeCircuitOpen, execs[0].Error.Code)
+
+	// Second run never reaches the handler
+	_, err = engine.StartWorkflow(context.Background(), wf, DiscoverInput{}, gorkflow.WithSynchronousExecution())
+	require.ErrorIs(t, err, gorkflow.ErrCircuitOpen)
+	assert.Equal(t, int32(2), calls.Load())
+
+	opened := listener.find(gorkflow.EventCircuitOpened)
+	require.Len(t, opened, 1)
+	assert.Equal(t, "vendor", opened[0].Circuit)
+	assert.Equal(t, gorkflow.CircuitClosed, opened[0].PreviousState)
+
+	snapshots := engine.CircuitBreakers()
+	require.Len(t, snapshots, 1)
+	assert.Equal(t, "vendor", snapshots[0].Name)
+	assert.Equal(t, gorkflow.CircuitOpen, snapshots[0].State)
+}
+
+func TestEngine_CircuitBreaker_RecoversAfterHalfOpen(t *testing.T) {
+	engine, listener := createListeningEngine(t, WithCircuitBreaker("vendor", gorkflow.BreakerConfig{
+		MinRequests:  1,
+		FailureRate:  1,
+		OpenDuration: 20 * time.Millisecond,
+	}))
+
+	var healthy atomic.Bool
+	wf, err := gorkflow.NewWorkflow("breaker_recover", "Breaker Recover").
+		ThenStep(gorkflow.NewStep("call", "Call Vendor", func(ctx *gorkflow.StepContext, input DiscoverInput) (DiscoverOutput, error) {
+			if !healthy.Load() {
+				return DiscoverOutput{}, errors.New("vendor unavailable")
+			}
+			return DiscoverOutput{Count: 1}, nil
+		}, gorkflow.WithCircuitBreaker("vendor"), gorkflow.WithRetries(0))).
+		Build()
+	require.NoError(t, err)
+
+	_, err = engine.StartWorkflow(context.Background(), wf, DiscoverInput{}, gorkflow.WithSynchronousExecution())
+	require.Error(t, err)
+
+	breaker, ok := engine.CircuitBreaker("vendor")
+	require.True(t, ok)
+	assert.Equal(t, gorkflow.CircuitOpen, breaker.State())
+
+	healthy.Store(true)
+	time.Sleep(30 * time.Millisecond)
+
+	_, err = engine.StartWorkflow(context.Background(), wf, DiscoverInput{}, gorkflow.WithSynchronousExecution())
+	require.NoError(t, err)
+	assert.Equal(t, gorkflow.CircuitClosed, breaker.State())
+
+	assert.Len(t, listener.find(gorkflow.EventCircuitHalfOpen), 1)
+	assert.Len(t, listener.find(gorkflow.EventCircuitClosed), 1)
+}
+
+func TestEngine_CircuitBreaker_WaitWhenOpen(t *testing.T) {
+	engine, _ := createListeningEngine(t, WithCircuitBreaker("vendor", gorkflow.BreakerConfig{
+		MinRequests:  1,
+		FailureRate:  1,
+		OpenDuration: 50 * time.Millisecond,
+		WaitWhenOpen: true,
+	}))
+
+	var calls atomic.Int32
+	wf, err := gorkflow.NewWorkflow("breaker_wait", "Breaker Wait").
+		ThenStep(gorkflow.NewStep("call", "Call Vendor", func(ctx *gorkflow.StepContext, input DiscoverInput) (DiscoverOutput, error) {
+			if calls.Add(1) == 1 {
+				return DiscoverOutput{}, errors.New("vendor unavailable")
+			}
+			return DiscoverOutput{Count: 1}, nil
+		},
+			gorkflow.WithCircuitBreaker("vendor"),
+			gorkflow.WithRetries(1),
+			gorkflow.WithBackoff(gorkflow.BackoffNone),
+		)).
+		Build()
+	require.NoError(t, err)
+
+	// The retry is parked until the circuit half-opens instead of failing fast
+	start := time.Now()
+	_, err = engine.StartWorkflow(context.Background(), wf, DiscoverInput{}, gorkflow.WithSynchronousExecution())
+	require.NoError(t, err)
+	assert.Equal(t, int32(2), calls.Load())
+	assert.GreaterOrEqual(t, time.Since(start), 40*time.Millisecond)
+}
+
+func TestEngine_CircuitBreaker_NotRegistered(t *testing.T) {
+	engine, _ := createListeningEngine(t)
+
+	var calls atomic.Int32
+	wf := failingVendorWorkflow(t, &calls)
+
+	_, err := engine.StartWorkflow(context.Background(), wf, DiscoverInput{}, gorkflow.WithSynchronousExecution())
+	require.ErrorIs(t, err, gorkflow.ErrCircuitBreakerNotFound)
+	assert.Equal(t, int32(0), calls.Load())
+}
+
+// countingLimiter counts acquisitions
+type countingLimiter struct {
+	acquired atomic.Int32
+}
+
+func (l *countingLimiter) Acquire(ctx context.Context) (func(), error) {
+	l.acquired.Add(1)
+	return func() {}, nil
+}
+
+func TestEngine_CircuitBreaker_OpenCircuitSkipsLimiters(t *testing.T) {
+	limiter := &countingLimiter{}
+	engine, _ := createListeningEngine(t,
+		WithLimiter("vendor-rate", limiter),
+		WithCircuitBreaker("vendor", gorkflow.BreakerConfig{
+			WindowSize:   10,
+			MinRequests:  2,
+			FailureRate:  1,
+			OpenDuration: time.Minute,
+		}))
+
+	var calls atomic.Int32
+	wf := failingVendorWorkflow(t, &calls, gorkflow.WithLimiters("vendor-rate"))
+
+	// Attempts rejected by the open circuit do not take limiter capacity
+	_, err := engine.StartWorkflow(context.Background(), wf, DiscoverInput{}, gorkflow.WithSynchronousExecution())
+	require.ErrorIs(t, err, gorkflow.ErrCircuitOpen)
+	assert.Equal(t, int32(2), calls.Load())
+	assert.Equal(t, int32(2), limiter.acquired.Load())
+
+	_, err = engine.StartWorkflow(context.Background(), wf, DiscoverInput{}, gorkflow.WithSynchronousExecution())
+	require.ErrorIs(t, err, gorkflow.ErrCircuitOpen)
+	assert.Equal(t, int32(2), limiter.acquired.Load())
+}
@@ -0,0 +1,99 @@
+package engine
+
+import (
+	"context"
+	"errors"
+
+	"github.com/sicko7947/gorkflow"
+)
+
+// WithStepCache sets the cache used by steps configured with gorkflow.WithCache.
+// By default the engine uses its store when the store implements gorkflow.StepCache.
+func WithStepCache(cache gorkflow.StepCache) EngineOption {
+	return func(e *Engine) {
+		e.cache = cache
+	}
+}
+
+// cacheKey returns the cache key for a step input, or "" when the step is not
+// cached or the key cannot be derived
+func (e *Engine) cacheKey(run *gorkflow.WorkflowRun, step gorkflow.StepExecutor, input []byte) string {
+	policy := gorkflow.StepCachePolicy(step)
+	if policy == nil || e.cache == nil {
+		return ""
+	}
+	key, err := policy.CacheKey(run.WorkflowID, run.WorkflowVersion, step.GetID(), input)
+	if err != nil {
+		e.logger.Warn().
+			Err(err).
+			Str("run_id", run.RunID).
+			Str("step_id", step.GetID()).
+			Msg("Failed to derive step cache key, executing step")
+		return ""
+	}
+	return key
+}
+
+// lookupCachedOutput returns the cached output for key, if any
+func (e *Engine) lookupCachedOutput(ctx context.Context, run *gorkflow.WorkflowRun, key string) ([]byte, bool) {
+	output, err := e.cache.GetCachedOutput(ctx, key)
+	if err != nil {
+		if !errors.Is(err, gorkflow.ErrCacheMiss) {
+			e.logPersistenceError(run, "get_cached_output", err)
+		}
+		return nil, false
+	}
+	return output, true
+}
+
+// completeFromCache records a step execution satisfied by a cached output
+func (e *Engine) completeFromCache(
+	ctx context.Context,
+	run *gorkflow.WorkflowRun,
+	step gorkflow.StepExecutor,
+	stepExec *gorkflow.StepExecution,
+	output []byte,
+) *StepExecutionResult {
+	now := e.clock.Now()
+	stepExec.Status = gorkflow.StepStatusCompleted
+	stepExec.Output = output
+	stepExec.CacheHit = true
+	stepExec.StartedAt = &now
+	stepExec.CompletedAt = &now
+	stepExec.UpdatedAt = now
+
+	if err := e.store.UpdateStepExecution(ctx, stepExec); err != nil {
+		e.logPersistenceError(run, "update_step_execution_cache_hit", err)
+	}
+
+	e.logger.Debug().
+		Str("run_id", run.RunID).
+		Str("step_id", step.GetID()).
+		Msg("Step output served from cache")
+
+	if err := e.store.SaveStepOutput(ctx, run.RunID, step.GetID(), output); err != nil {
+		e.logPersistenceError(run, "save_step_output", err)
+	}
+
+	completedEvent := stepEvent(gorkflow.EventStepCompleted, run, step, 0)
+	completedEvent.OutputSize = len(output)
+	e.emit(completedEvent)
+
+	return &StepExecutionResult{
+		StepID:       step.GetID(),
+		Status:       gorkflow.StepStatusCompleted,
+		Output:       output,
+		AttemptsMade: 0,
+	}
+}
+
+// storeCachedOutput caches the output of a successful step execution
+func (e *Engine) storeCachedOutput(ctx context.Context, run *gorkflow.WorkflowRun, step gorkflow.StepExecutor, key string, output []byte) {
+	if key == "" {
+		return
+	}
+	ttl := gorkflow.StepCachePolicy(step).TTL
+	if err := e.cache.PutCachedOutput(ctx, key, output, ttl); err != nil {
+		e.logPersistenceError(run, "put_cached_output", err)
+	}
+}
@@ -0,0 +1,170 @@
+package engine
+
+import (
+	"context"
+	"encoding/json"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/rs/zerolog"
+	"github.com/sicko7947/gorkflow"
+	"github.com/sicko7947/gorkflow/store"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// cachedWorkflow builds a workflow whose discover step is cached and counts its calls
+func cachedWorkflow(t *testing.T, calls *int32, opts ...gorkflow.StepOption) *gorkflow.Workflow {
+	t.Helper()
+	step := gorkflow.NewStep("discover", "Discover", func(ctx *gorkflow.StepContext, input DiscoverInput) (DiscoverOutput, error) {
+		atomic.AddInt32(calls, 1)
+		return discoverCompanies(ctx, input)
+	}, opts...)
+	wf, err := gorkflow.NewWorkflow("cached", "Cached").ThenStep(step).Build()
+	require.NoError(t, err)
+	return wf
+}
+
+func runSync(t *testing.T, engine *Engine, wf *gorkflow.Workflow, input DiscoverInput) string {
+	t.Helper()
+	runID, err := engine.StartWorkflow(context.Background(), wf, input, gorkflow.WithSynchronousExecution())
+	require.NoError(t, err)
+	return runID
+}
+
+func TestEngine_StepCache_ReusesOutput(t *testing.T) {
+	engine, listener := createListeningEngine(t)
+	var calls int32
+	wf := cachedWorkflow(t, &calls, gorkflow.WithCache(time.Hour, nil))
+
+	first := runSync(t, engine, wf, DiscoverInput{Query: "q", Limit: 3})
+	second := runSync(t, engine, wf, DiscoverInput{Query: "q", Limit: 3})
+	assert.Equal(t, int32(1), atomic.LoadInt32(&calls), "second run should be served from cache")
+
+	exec, err := engine.GetStepExecutions(context.Background(), first)
+	require.NoError(t, err)
+	assert.False(t, exec[0].CacheHit)
+
+	exec, err = engine.GetStepExecutions(context.Background(), second)
+	require.NoError(t, err)
+	require.Len(t, exec, 1)
+	assert.True(t, exec[0].CacheHit)
+	assert.Equal(t, gorkflow.StepStatusCompleted, exec[0].Status)
+
+	result, err := gorkflow.GetResult[DiscoverOutput](context.Background(), engine, second)
+	require.NoError(t, err)
+	assert.Equal(t, 3, result.Count)
+
+	var completed int
+	for _, event := range listener.find(gorkflow.EventStepCompleted) {
+		if event.RunID == second {
+			completed++
+		}
+	}
+	assert.Equal(t, 1, completed, "cache hits should emit step_completed")
+
+	// A different input misses the cache
+	runSync(t, engine, wf, DiscoverInput{Query: "other", Limit: 3})
+	assert.Equal(t, int32(2), atomic.LoadInt32(&calls))
+}
+
+func TestEngine_StepCache_CustomKey(t *testing.T) {
+	engine, _ := createListeningEngine(t)
+	var calls int32
+
+	// Key on the query only, ignoring the limit
+	byQuery := func(input []byte) (string, error) {
+		var in DiscoverInput
+		if err := json.Unmarshal(input, &in); err != nil {
+			return "", err
+		}
+		return in.Query, nil
+	}
+	wf := cachedWorkflow(t, &calls, gorkflow.WithCache(0, byQuery))
+
+	runSync(t, engine, wf, DiscoverInput{Query: "q", Limit: 1})
+	runSync(t, engine, wf, DiscoverInput{Query: "q", Limit: 5})
+	assert.Equal(t, int32(1), atomic.LoadInt32(&calls))
+}
+
+func TestEngine_StepCache_Expires(t *testing.T) {
+	engine, _ := createListeningEngine(t)
+	var calls int32
+	wf := cachedWorkflow(t, &calls, gorkflow.WithCache(10*time.Millisecond, nil))
+
+	runSync(t, engine, wf, DiscoverInput{Query: "q"})
+	time.Sleep(20 * time.Millisecond)
+	runSync(t, engine, wf, DiscoverInput{Query: "q"})
+	assert.Equal(t, int32(2), atomic.LoadInt32(&calls))
+}
+
+func TestEngine_StepCache_NotCachedByDefault(t *testing.T) {
+	engine, _ := createListeningEngine(t)
+	var calls int32
+	wf := cachedWorkflow(t, &calls)
+
+	runSync(t, engine, wf, DiscoverInput{Query: "q"})
+	runSync(t, engine, wf, DiscoverInput{Query: "q"})
+	assert.Equal(t, int32(2), atomic.LoadInt32(&calls))
+}
+
+func TestEngine_WithStepCache(t *testing.T) {
+	cache := store.NewMemoryStore().(gorkflow.StepCache)
+	var calls int32
+	wf := cachedWorkflow(t, &calls, gorkflow.WithCache(0, nil))
+
+	// Separate engines and stores share results through the dedicated cache
+	first, _ := createListeningEngine(t, WithStepCache(cache))
+	second, _ := createListeningEngine(t, WithStepCache(cache))
+	runSync(t, first, wf, DiscoverInput{Query: "q"})
+	runSync(t, second, wf, DiscoverInput{Query: "q"})
+	assert.Equal(t, int32(1), atomic.LoadInt32(&calls))
+}
+
+func TestEngine_StepCache_ConditionalStep(t *testing.T) {
+	for _, tc := range []struct {
+		name string
+		add  func(b *gorkflow.WorkflowBuilder, step *gorkflow.Step[DiscoverOutput, EnrichOutput], cond gorkflow.Condition) *gorkflow.WorkflowBuilder
+	}{
+		{"ThenStepIf", func(b *gorkflow.WorkflowBuilder, step *gorkflow.Step[DiscoverOutput, EnrichOutput], cond gorkflow.Condition) *gorkflow.WorkflowBuilder {
+			return b.ThenStepIf(step, cond, nil)
+		}},
+		{"NewConditionalStep", func(b *gorkflow.WorkflowBuilder, step *gorkflow.Step[DiscoverOutput, EnrichOutput], cond gorkflow.Condition) *gorkflow.WorkflowBuilder {
+			return b.ThenStep(gorkflow.NewConditionalStep(step, cond, nil))
+		}},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			engine := NewEngine(store.NewMemoryStore(), WithLogger(zerolog.Nop()))
+			var calls int32
+			var enabled atomic.Bool
+			enrich := gorkflow.NewStep("enrich", "Enrich", func(ctx *gorkflow.StepContext, input DiscoverOutput) (EnrichOutput, error) {
+				atomic.AddInt32(&calls, 1)
+				return EnrichOutput{Enriched: map[string]any{"count": input.Count}}, nil
+			}, gorkflow.WithCache(time.Hour, nil))
+			cond := func(ctx *gorkflow.StepContext) (bool, error) { return enabled.Load(), nil }
+
+			b := gorkflow.NewWorkflow("cached", "Cached").ThenStep(gorkflow.NewStep("discover", "Discover", discoverCompanies))
+			wf, err := tc.add(b, enrich, cond).Build()
+			require.NoError(t, err)
+			input := DiscoverInput{Query: "q", Limit: 3}
+
+			enabled.Store(true)
+			runSync(t, engine, wf, input)
+			second := runSync(t, engine, wf, input)
+			assert.Equal(t, int32(1), atomic.LoadInt32(&calls), "second run should be served from cache")
+			exec, err := engine.store.GetStepExecution(context.Background(), second, "enrich")
+			require.NoError(t, err)
+			assert.True(t, exec.CacheHit)
+
+			// A false condition must skip the step, not serve the cached output
+			enabled.Store(false)
+			third := runSync(t, engine, wf, input)
+			assert.Equal(t, int32(1), atomic.LoadInt32(&calls))
+			exec, err = engine.store.GetStepExecution(context.Background(), third, "enrich")
+			require.NoError(t, err)
+			assert.False(t, exec.CacheHit)
+			assert.JSONEq(t, `{"enriched":null}`, string(exec.Output))
+		})
+	}
+}
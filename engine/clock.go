@@ -0,0 +1,17 @@
+package engine
+
+import (
+	"github.com/sicko7947/gorkflow"
+)
+
+// WithClock sets the clock used for run and step timestamps, retry backoff,
+// step timeouts, circuit breaker open periods and background timers.
+// Defaults to gorkflow.SystemClock; tests can pass a fake clock such as
+// gorkflowtest.FakeClock to make retries and timeouts instant and deterministic.
+func WithClock(clock gorkflow.Clock) EngineOption {
+	return func(e *Engine) {
+		if clock != nil {
+			e.clock = clock
+		}
+	}
+}
@@ -0,0 +1,182 @@
+package engine_test
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/sicko7947/gorkflow"
+	"github.com/sicko7947/gorkflow/engine"
+	"github.com/sicko7947/gorkflow/gorkflowtest"
+	"github.com/sicko7947/gorkflow/store"
+)
+
+var epoch = time.Date(2024, time.March, 1, 12, 0, 0, 0, time.UTC)
+
+type payload struct {
+	Value int `json:"value"`
+}
+
+// newClockEngine creates an engine whose store and engine share a manual fake clock
+func newClockEngine(t *testing.T, opts ...engine.EngineOption) (*engine.Engine, gorkflow.WorkflowStore, *gorkflowtest.FakeClock) {
+	t.Helper()
+	clock := gorkflowtest.NewFakeClock(epoch)
+	wfStore := store.NewMemoryStoreWithOptions(store.MemoryStoreOptions{Clock: clock})
+	opts = append([]engine.EngineOption{engine.WithLogger(zerolog.Nop()), engine.WithClock(clock)}, opts...)
+	eng := engine.NewEngine(wfStore, opts...)
+	t.Cleanup(func() { eng.Close() })
+	return eng, wfStore, clock
+}
+
+func singleStepWorkflow(t *testing.T, step gorkflow.StepExecutor) *gorkflow.Workflow {
+	t.Helper()
+	wf, err := gorkflow.NewWorkflow("clocked", "Clocked").ThenStep(step).Build()
+	require.NoError(t, err)
+	return wf
+}
+
+func TestEngine_WithClock_Timestamps(t *testing.T) {
+	eng, _, clock := newClockEngine(t)
+
+	step := gorkflow.NewStep("work", "Work", func(ctx *gorkflow.StepContext, in payload) (payload, error) {
+		return in, nil
+	})
+	runID, err := eng.StartWorkflow(context.Background(), singleStepWorkflow(t, step), payload{Value: 1},
+		gorkflow.WithSynchronousExecution())
+	require.NoError(t, err)
+
+	run, err := eng.GetRun(context.Background(), runID)
+	require.NoError(t, err)
+	assert.Equal(t, epoch, run.CreatedAt)
+	assert.Equal(t, epoch, *run.StartedAt)
+	assert.Equal(t, epoch, *run.CompletedAt)
+
+	execs, err := eng.GetStepExecutions(context.Background(), runID)
+	require.NoError(t, err)
+	assert.Equal(t, epoch, execs[0].CreatedAt)
+	assert.Equal(t, epoch, *execs[0].CompletedAt)
+	assert.Equal(t, epoch, clock.Now(), "nothing should advance a manual clock")
+}
+
+func TestEngine_WithClock_Backoff(t *testing.T) {
+	retrying := make(chan struct{}, 1)
+	eng, _, clock := newClockEngine(t, engine.WithListener(gorkflow.EventListenerFunc(func(event gorkflow.Event) {
+		if event.Type == gorkflow.EventStepRetrying {
+			retrying <- struct{}{}
+		}
+	})))
+
+	var attempts int32
+	step := gorkflow.NewStep("flaky", "Flaky", func(ctx *gorkflow.StepContext, in payload) (payload, error) {
+		if atomic.AddInt32(&attempts, 1) == 1 {
+			return payload{}, errors.New("transient")
+		}
+		return in, nil
+	}, gorkflow.WithRetries(1), gorkflow.WithRetryDelay(time.Hour), gorkflow.WithBackoff(gorkflow.BackoffLinear))
+
+	runID, err := eng.StartWorkflow(context.Background(), singleStepWorkflow(t, step), payload{})
+	require.NoError(t, err)
+
+	<-retrying
+	clock.BlockUntil(1) // the backoff sleep
+	assert.Equal(t, int32(1), atomic.LoadInt32(&attempts), "the retry must wait for the clock")
+	clock.Advance(time.Hour)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	run, err := eng.WaitForRun(ctx, runID)
+	require.NoError(t, err)
+	assert.Equal(t, gorkflow.RunStatusCompleted, run.Status)
+	assert.Equal(t, epoch.Add(time.Hour), *run.CompletedAt)
+}
+
+func TestEngine_WithClock_StepTimeout(t *testing.T) {
+	eng, _, clock := newClockEngine(t)
+
+	step := gorkflow.NewStep("slow", "Slow", func(ctx *gorkflow.StepContext, in payload) (payload, error) {
+		<-ctx.Done()
+		return payload{}, ctx.Err()
+	}, gorkflow.WithRetries(0), gorkflow.WithTimeout(time.Minute))
+
+	runID, err := eng.StartWorkflow(context.Background(), singleStepWorkflow(t, step), payload{})
+	require.NoError(t, err)
+
+	clock.BlockUntil(1) // the step timeout
+	clock.Advance(time.Minute)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	run, err := eng.WaitForRun(ctx, runID)
+	require.NoError(t, err)
+	assert.Equal(t, gorkflow.RunStatusFailed, run.Status)
+
+	execs, err := eng.GetStepExecutions(context.Background(), runID)
+	require.NoError(t, err)
+	assert.Contains(t, execs[0].Error.Message, "timed out")
+	assert.Equal(t, int64(time.Minute/time.Millisecond), execs[0].DurationMs)
+}
+
+func TestEngine_WithClock_CircuitBreaker(t *testing.T) {
+	eng, _, clock := newClockEngine(t, engine.WithCircuitBreaker("api", gorkflow.BreakerConfig{
+		WindowSize:   1,
+		MinRequests:  1,
+		FailureRate:  1,
+		OpenDuration: time.Minute,
+	}))
+
+	step := gorkflow.NewStep("call", "Call", func(ctx *gorkflow.StepContext, in payload) (payload, error) {
+		return payload{}, errors.New("down")
+	}, gorkflow.WithRetries(0), gorkflow.WithCircuitBreaker("api"))
+	_, _ = eng.StartWorkflow(context.Background(), singleStepWorkflow(t, step), payload{}, gorkflow.WithSynchronousExecution())
+
+	breaker, ok := eng.CircuitBreaker("api")
+	require.True(t, ok)
+	assert.Equal(t, gorkflow.CircuitOpen, breaker.State())
+	assert.ErrorIs(t, breaker.Allow(), gorkflow.ErrCircuitOpen)
+
+	clock.Advance(time.Minute)
+	assert.NoError(t, breaker.Allow(), "the open period is measured on the engine clock")
+}
+
+func TestEngine_WithClock_RateLimiter(t *testing.T) {
+	// One call a minute: the second and third calls wait on the engine clock
+	eng, _, clock := newClockEngine(t, engine.WithLimiter("api", gorkflow.NewRateLimiter(1.0/60, 1)))
+	clock.SetAutoAdvance(true)
+
+	step := gorkflow.NewStep("call", "Call", func(ctx *gorkflow.StepContext, in payload) (payload, error) {
+		return in, nil
+	}, gorkflow.WithLimiters("api"))
+	wf := singleStepWorkflow(t, step)
+
+	start := time.Now()
+	for i := 0; i < 3; i++ {
+		_, err := eng.StartWorkflow(context.Background(), wf, payload{Value: i}, gorkflow.WithSynchronousExecution())
+		require.NoError(t, err)
+	}
+	assert.Less(t, time.Since(start), time.Second, "waits should take no real time")
+	assert.GreaterOrEqual(t, clock.Now().Sub(epoch), 2*time.Minute)
+}
+
+func TestEngine_WithClock_CacheExpiry(t *testing.T) {
+	eng, _, clock := newClockEngine(t)
+
+	var calls int32
+	step := gorkflow.NewStep("cached", "Cached", func(ctx *gorkflow.StepContext, in payload) (payload, error) {
+		atomic.AddInt32(&calls, 1)
+		return in, nil
+	}, gorkflow.WithCache(time.Hour, nil))
+	wf := singleStepWorkflow(t, step)
+
+	for _, advance := range []time.Duration{0, 59 * time.Minute, 2 * time.Minute} {
+		clock.Advance(advance)
+		_, err := eng.StartWorkflow(context.Background(), wf, payload{Value: 1}, gorkflow.WithSynchronousExecution())
+		require.NoError(t, err)
+	}
+	assert.Equal(t, int32(2), atomic.LoadInt32(&calls), "the entry expires an hour after the first run")
+}
@@ -11,7 +11,10 @@ import (
 
 	"github.com/google/uuid"
 	"github.com/rs/zerolog"
+	"go.opentelemetry.io/otel/trace"
+
 	"github.com/sicko7947/gorkflow"
+	"github.com/sicko7947/gorkflow/payload"
 )
 
 // Engine orchestrates workflow execution
@@ -21,6 +24,46 @@ type Engine struct {
 	config     gorkflow.EngineConfig
 	activeRuns map[string]context.CancelFunc
 	runsMu     sync.Mutex
+
+	// Lifecycle event listeners
+	listeners []gorkflow.EventListener
+
+	// OpenTelemetry tracing
+	tracer  trace.Tracer
+	tracing bool
+
+	// Engine-level step interceptors
+	interceptors []gorkflow.StepInterceptor
+
+	// Registered workflow definitions
+	registry *registry
+
+	// Named limiters and circuit breakers shared by all runs
+	limiters map[string]gorkflow.Limiter
+	breakers map[string]*gorkflow.CircuitBreaker
+
+	// Source of time for timestamps, sleeps, timeouts and tickers
+	clock gorkflow.Clock
+
+	// Cross-run step output cache
+	cache gorkflow.StepCache
+
+	// Payload encoding, compression and offloading, wrapped around the store by NewEngine
+	payload payload.Options
+
+	// Callers blocked in WaitForRun
+	waiters          *runWaiters
+	waitPollInterval time.Duration
+
+	// Live run update subscribers
+	subscriptions      *subscriptions
+	subscriptionBuffer int
+
+	// Retention policy enforced by the background janitor
+	retention *RetentionPolicy
+
+	// Cleanup hooks run by Close
+	closers []func() error
 }
 
 // NewEngine creates a new workflow engine
@@ -57,6 +100,16 @@ func NewEngine(store gorkflow.WorkflowStore, opts ...EngineOption) *Engine {
 		logger:     defaultLogger,
 		config:     gorkflow.DefaultEngineConfig,
 		activeRuns: make(map[string]context.CancelFunc),
+		registry:   newRegistry(),
+		waiters:    newRunWaiters(),
+		limiters:   make(map[string]gorkflow.Limiter),
+		breakers:   make(map[string]*gorkflow.CircuitBreaker),
+		tracer:     defaultTracer(),
+		clock:      gorkflow.SystemClock,
+
+		waitPollInterval:   DefaultWaitPollInterval,
+		subscriptions:      newSubscriptions(),
+		subscriptionBuffer: DefaultSubscriptionBuffer,
 	}
 
 	// Apply options
@@ -64,6 +117,32 @@ func NewEngine(store gorkflow.WorkflowStore, opts ...EngineOption) *Engine {
 		opt(eng)
 	}
 
+	for _, breaker := range eng.breakers {
+		breaker.SetClock(eng.clock)
+	}
+	for _, limiter := range eng.limiters {
+		if l, ok := limiter.(interface{ SetClock(gorkflow.Clock) }); ok {
+			l.SetClock(eng.clock)
+		}
+	}
+
+	eng.store = payload.NewStore(eng.store, eng.payload)
+
+	// Default to the store's own cache, before it is wrapped for tracing
+	if eng.cache == nil {
+		if cache, ok := eng.store.(gorkflow.StepCache); ok {
+			eng.cache = cache
+		}
+	}
+
+	if eng.tracing {
+		eng.store = newTracingStore(eng.store, eng.tracer)
+	}
+
+	if eng.retention != nil {
+		eng.startJanitor()
+	}
+
 	return eng
 }
 
@@ -84,7 +163,7 @@ func (e *Engine) StartWorkflow(
 	runID := uuid.New().String()
 
 	// Serialize input
-	inputBytes, err := json.Marshal(input)
+	inputBytes, err := gorkflow.MarshalPayload(wf.Serializer(), input)
 	if err != nil {
 		return "", fmt.Errorf("failed to serialize workflow input: %w", err)
 	}
@@ -92,14 +171,14 @@ func (e *Engine) StartWorkflow(
 	// Serialize context if present
 	var contextBytes json.RawMessage
 	if wf.GetContext() != nil {
-		contextBytes, err = json.Marshal(wf.GetContext())
+		contextBytes, err = gorkflow.MarshalPayload(wf.Serializer(), wf.GetContext())
 		if err != nil {
 			return "", fmt.Errorf("failed to serialize workflow context: %w", err)
 		}
 	}
 
 	// Create workflow run
-	now := time.Now()
+	now := e.clock.Now()
 	run := &gorkflow.WorkflowRun{
 		RunID:           runID,
 		WorkflowID:      wf.ID(),
@@ -112,6 +191,7 @@ func (e *Engine) StartWorkflow(
 		Context:         contextBytes,
 		ResourceID:      options.ResourceID,
 		Tags:            options.Tags,
+		TraceContext:    injectTraceContext(ctx),
 	}
 
 	// Persist run
@@ -120,48 +200,78 @@ func (e *Engine) StartWorkflow(
 	}
 
 	gorkflow.LogWorkflowCreated(e.logger, runID, wf.ID(), options.ResourceID)
+	e.emit(runEvent(gorkflow.EventWorkflowCreated, run))
+
+	return runID, e.launch(ctx, wf, run, options.Synchronous, nil)
+}
 
-	// Launch execution in background
-	if !options.Synchronous {
-		bgCtx, cancel := context.WithCancel(context.Background())
+// launch executes the run synchronously, or in the background. Either way the
+// run is tracked in activeRuns while it executes; launch fails with
+// ErrRunNotResumable if the run is already executing in this engine
+func (e *Engine) launch(ctx context.Context, wf *gorkflow.Workflow, run *gorkflow.WorkflowRun, synchronous bool, finished map[string]*gorkflow.StepExecution) error {
+	runID := run.RunID
+	parent := context.Background()
+	if synchronous {
+		parent = ctx
+	}
+	runCtx, cancel := context.WithCancel(parent)
+
+	e.runsMu.Lock()
+	if _, active := e.activeRuns[runID]; active {
+		e.runsMu.Unlock()
+		cancel()
+		return fmt.Errorf("run %s is already executing: %w", runID, gorkflow.ErrRunNotResumable)
+	}
+	e.activeRuns[runID] = cancel
+	e.runsMu.Unlock()
+
+	release := func() {
 		e.runsMu.Lock()
-		e.activeRuns[runID] = cancel
+		delete(e.activeRuns, runID)
 		e.runsMu.Unlock()
-		go func() {
-			defer func() {
-				e.runsMu.Lock()
-				delete(e.activeRuns, runID)
-				e.runsMu.Unlock()
-				cancel()
-			}()
-			e.executeWorkflow(bgCtx, wf, run)
-		}()
-	} else {
-		return runID, e.executeWorkflow(ctx, wf, run)
-	}
-
-	return runID, nil
+		cancel()
+	}
+
+	if synchronous {
+		defer release()
+		return e.executeWorkflow(runCtx, wf, run, finished)
+	}
+
+	go func() {
+		defer release()
+		e.executeWorkflow(runCtx, wf, run, finished)
+	}()
+	return nil
 }
 
-// executeWorkflow runs the workflow (called asynchronously)
-func (e *Engine) executeWorkflow(ctx context.Context, wf *gorkflow.Workflow, run *gorkflow.WorkflowRun) error {
+// executeWorkflow runs the workflow (called asynchronously).
+// Steps present in finished (completed or skipped by a previous execution of a
+// resumed run) are not executed again.
+func (e *Engine) executeWorkflow(ctx context.Context, wf *gorkflow.Workflow, run *gorkflow.WorkflowRun, finished map[string]*gorkflow.StepExecution) error {
 	workflowLogger := gorkflow.WorkflowLogger(e.logger, run.RunID, run.WorkflowID, run.ResourceID)
 
+	ctx, span := e.startRunSpan(ctx, run)
+	defer endRunSpan(span, run)
+
 	gorkflow.LogWorkflowStarted(e.logger, run.RunID, run.WorkflowID, run.ResourceID)
 
-	// Update status to running
-	startTime := time.Now()
+	// Update status to running (resumed runs keep their original start time)
+	startTime := e.clock.Now()
 	run.Status = gorkflow.RunStatusRunning
-	run.StartedAt = &startTime
+	if run.StartedAt == nil {
+		run.StartedAt = &startTime
+	}
 	run.UpdatedAt = startTime
 
 	if err := e.store.UpdateRun(ctx, run); err != nil {
-		gorkflow.LogPersistenceError(e.logger, run.RunID, "update_run_status", err)
+		e.logPersistenceError(run, "update_run_status", err)
 		return err
 	}
+	e.emit(runEvent(gorkflow.EventWorkflowStarted, run))
 
 	// Build execution context - create shared state accessor
 	state := gorkflow.NewStateAccessor(run.RunID, e.store)
+	gorkflow.SetStateAccessorSerializer(state, wf.Serializer())
 
 	// Get execution levels (groups of steps that can run concurrently)
 	graph := wf.Graph()
@@ -187,6 +297,12 @@ func (e *Engine) executeWorkflow(ctx context.Context, wf *gorkflow.Workflow, run
 		default:
 		}
 
+		// Skip steps finished by a previous execution of a resumed run
+		level = e.skipFinished(ctx, run, level, finished, &completedSteps)
+		if len(level) == 0 {
+			continue
+		}
+
 		if len(level) == 1 {
 			// Single step — sequential path
 			stepID := level[0]
@@ -202,7 +318,7 @@ func (e *Engine) executeWorkflow(ctx context.Context, wf *gorkflow.Workflow, run
 
 			gorkflow.LogStepStarted(e.logger, run.RunID, stepID, step.GetName(), int(atomic.LoadInt64(&completedSteps))+1, totalSteps)
 
-			result, err := e.executeStep(ctx, run, step, stepInput, state, wf.GetContext(), int(atomic.LoadInt64(&completedSteps)))
+			result, err := e.executeStep(ctx, run, step, stepInput, state, wf, int(atomic.LoadInt64(&completedSteps)))
 			if err != nil {
 				if ctx.Err() != nil {
 					gorkflow.LogWorkflowCancelled(e.logger, run.RunID)
@@ -222,12 +338,13 @@ func (e *Engine) executeWorkflow(ctx context.Context, wf *gorkflow.Workflow, run
 			atomic.AddInt64(&completedSteps, 1)
 			progress := float64(atomic.LoadInt64(&completedSteps)) / float64(totalSteps)
 			run.Progress = progress
-			run.UpdatedAt = time.Now()
+			run.UpdatedAt = e.clock.Now()
 			// Progress update is best-effort; a failure here doesn't stop execution.
 			if err := e.store.UpdateRun(ctx, run); err != nil {
-				gorkflow.LogPersistenceError(e.logger, run.RunID, "update_run_progress", err)
+				e.logPersistenceError(run, "update_run_progress", err)
 			}
 			gorkflow.LogWorkflowProgress(e.logger, run.RunID, progress)
+			e.emitProgress(run)
 
 		} else {
 			// Multiple steps in this level — run concurrently
@@ -259,7 +376,7 @@ func (e *Engine) executeWorkflow(ctx context.Context, wf *gorkflow.Workflow, run
 					defer func() { <-sem }()
 
 					gorkflow.LogStepStarted(e.logger, run.RunID, sID, s.GetName(), idx+1, totalSteps)
-					result, err := e.executeStep(ctx, run, s, input, state, wf.GetContext(), idx)
+					result, err := e.executeStep(ctx, run, s, input, state, wf, idx)
 					resultsCh <- stepResult{stepID: sID, result: result, err: err}
 				}(stepID, step, stepInput, execIndex)
 			}
@@ -284,11 +401,12 @@ func (e *Engine) executeWorkflow(ctx context.Context, wf *gorkflow.Workflow, run
 			// Single progress update after all steps in this level complete.
 			progress := float64(atomic.LoadInt64(&completedSteps)) / float64(totalSteps)
 			run.Progress = progress
-			run.UpdatedAt = time.Now()
+			run.UpdatedAt = e.clock.Now()
 			if err := e.store.UpdateRun(ctx, run); err != nil {
-				gorkflow.LogPersistenceError(e.logger, run.RunID, "update_run_progress", err)
+				e.logPersistenceError(run, "update_run_progress", err)
 			}
 			gorkflow.LogWorkflowProgress(e.logger, run.RunID, progress)
+			e.emitProgress(run)
 
 			if fatalErr != nil {
 				if ctx.Err() != nil {
@@ -304,6 +422,13 @@ func (e *Engine) executeWorkflow(ctx context.Context, wf *gorkflow.Workflow, run
 	return e.completeWorkflow(ctx, run)
 }
 
+// emitProgress emits a progress event for the run's current progress
+func (e *Engine) emitProgress(run *gorkflow.WorkflowRun) {
+	event := runEvent(gorkflow.EventWorkflowProgress, run)
+	event.Progress = run.Progress
+	e.emit(event)
+}
+
 // resolveStepInput determines what input a step should receive
 func (e *Engine) resolveStepInput(ctx context.Context, run *gorkflow.WorkflowRun, wf *gorkflow.Workflow, stepID string, isFirst bool) ([]byte, error) {
 	if isFirst {
@@ -330,7 +455,7 @@ func (e *Engine) resolveStepInput(ctx context.Context, run *gorkflow.WorkflowRun
 
 // completeWorkflow marks workflow as completed
 func (e *Engine) completeWorkflow(ctx context.Context, run *gorkflow.WorkflowRun) error {
-	completedAt := time.Now()
+	completedAt := e.clock.Now()
 	run.Status = gorkflow.RunStatusCompleted
 	run.Progress = 1.0
 	run.CompletedAt = &completedAt
@@ -343,33 +468,47 @@ func (e *Engine) completeWorkflow(ctx context.Context, run *gorkflow.WorkflowRun
 	duration := completedAt.Sub(*run.StartedAt)
 	gorkflow.LogWorkflowCompleted(e.logger, run.RunID, duration)
 
+	event := runEvent(gorkflow.EventWorkflowCompleted, run)
+	event.Duration = duration
+	event.OutputSize = len(run.Output)
+	e.emit(event)
+	e.waiters.notify(run.RunID)
+
 	return nil
 }
 
 // failWorkflow marks workflow as failed
 func (e *Engine) failWorkflow(ctx context.Context, run *gorkflow.WorkflowRun, err error) error {
-	completedAt := time.Now()
+	completedAt := e.clock.Now()
 	run.Status = gorkflow.RunStatusFailed
 	run.CompletedAt = &completedAt
 	run.UpdatedAt = completedAt
 	run.Error = &gorkflow.WorkflowError{
 		Message:   err.Error(),
-		Code:      gorkflow.ErrCodeExecutionFailed,
+		Code:      failureCode(err),
 		Timestamp: completedAt,
 	}
 
 	if updateErr := e.store.UpdateRun(ctx, run); updateErr != nil {
-		gorkflow.LogPersistenceError(e.logger, run.RunID, "update_run_failure", updateErr)
+		e.logPersistenceError(run, "update_run_failure", updateErr)
 	}
 
 	gorkflow.LogWorkflowFailed(e.logger, run.RunID, err)
 
+	event := runEvent(gorkflow.EventWorkflowFailed, run)
+	event.Error = err
+	if run.StartedAt != nil {
+		event.Duration = completedAt.Sub(*run.StartedAt)
+	}
+	e.emit(event)
+	e.waiters.notify(run.RunID)
+
 	return err
 }
 
 // cancelWorkflow marks workflow as cancelled
 func (e *Engine) cancelWorkflow(ctx context.Context, run *gorkflow.WorkflowRun) error {
-	completedAt := time.Now()
+	completedAt := e.clock.Now()
 	run.Status = gorkflow.RunStatusCancelled
 	run.CompletedAt = &completedAt
 	run.UpdatedAt = completedAt
@@ -379,10 +518,17 @@ func (e *Engine) cancelWorkflow(ctx context.Context, run *gorkflow.WorkflowRun)
 	}
 
 	gorkflow.LogWorkflowCancelled(e.logger, run.RunID)
+	e.emit(runEvent(gorkflow.EventWorkflowCancelled, run))
+	e.waiters.notify(run.RunID)
 
 	return nil
 }
 
+// Store returns the store as the engine sees it, with payloads decoded
+func (e *Engine) Store() gorkflow.WorkflowStore {
+	return e.store
+}
+
 // GetRun retrieves workflow run status
 func (e *Engine) GetRun(ctx context.Context, runID string) (*gorkflow.WorkflowRun, error) {
 	return e.store.GetRun(ctx, runID)
@@ -401,9 +547,9 @@ func (e *Engine) LoadStepOutput(ctx context.Context, runID, stepID string) ([]by
 }
 
 // Cancel cancels a running workflow.
-// If an async goroutine is active, signals it and returns immediately — the goroutine
-// handles the DB update via its ctx.Done() path, avoiding a double-update.
-// If no goroutine is active (sync execution, already finished), updates DB directly.
+// If the run is executing in this engine, signals it and returns immediately — the
+// execution handles the DB update via its ctx.Done() path, avoiding a double-update.
+// Otherwise (executing elsewhere, or not executing at all), updates DB directly.
 func (e *Engine) Cancel(ctx context.Context, runID string) error {
 	e.runsMu.Lock()
 	cancelFn, hasActive := e.activeRuns[runID]
@@ -413,17 +559,17 @@ func (e *Engine) Cancel(ctx context.Context, runID string) error {
 	e.runsMu.Unlock()
 
 	if hasActive {
-		// The goroutine's ctx.Done() path calls cancelWorkflow — don't double-update.
+		// The execution's ctx.Done() path calls cancelWorkflow — don't double-update.
 		return nil
 	}
 
-	// No active goroutine: update DB directly.
+	// No active execution: update DB directly.
 	run, err := e.store.GetRun(ctx, runID)
 	if err != nil {
 		return fmt.Errorf("failed to get run: %w", err)
 	}
 	if run.Status.IsTerminal() {
-		return fmt.Errorf("cannot cancel workflow in %s state", run.Status)
+		return fmt.Errorf("cannot cancel workflow in %s state: %w", run.Status, gorkflow.ErrRunNotCancellable)
 	}
 	return e.cancelWorkflow(ctx, run)
 }
@@ -432,3 +578,28 @@ func (e *Engine) Cancel(ctx context.Context, runID string) error {
 func (e *Engine) ListRuns(ctx context.Context, filter gorkflow.RunFilter) ([]*gorkflow.WorkflowRun, error) {
 	return e.store.ListRuns(ctx, filter)
 }
+
+// DeleteRun removes a finished run with its step executions, outputs and state
+func (e *Engine) DeleteRun(ctx context.Context, runID string) error {
+	run, err := e.store.GetRun(ctx, runID)
+	if err != nil {
+		return fmt.Errorf("failed to get run: %w", err)
+	}
+	if !run.Status.IsTerminal() {
+		return fmt.Errorf("cannot delete workflow in %s state", run.Status)
+	}
+	return e.store.DeleteRun(ctx, runID)
+}
+
+// Close releases background resources held by the engine, such as async
+// event dispatchers and the retention janitor. Queued events are delivered
+// before Close returns.
+func (e *Engine) Close() error {
+	var firstErr error
+	for _, closeFn := range e.closers {
+		if err := closeFn(); err != nil && firstErr == nil {
+			firstErr = err
+		}
+	}
+	return firstErr
+}
@@ -145,6 +145,47 @@ func TestEngine_StepOutputPassing(t *testing.T) {
 	assert.NotEmpty(t, enrichOutput.Enriched)
 }
 
+func TestEngine_DependsOnWorkflow(t *testing.T) {
+	engine, wfStore := createTestEngine(t)
+
+	plusOne := gorkflow.NewStep("plus-one", "Plus One", func(ctx *gorkflow.StepContext, in int) (int, error) {
+		return in + 1, nil
+	})
+	timesTen := gorkflow.NewStep("times-ten", "Times Ten", func(ctx *gorkflow.StepContext, in int) (int, error) {
+		return in * 10, nil
+	})
+	// sum receives the output of its first dependency and reads the other one
+	sum := gorkflow.NewStep("sum", "Sum", func(ctx *gorkflow.StepContext, in int) (int, error) {
+		other, err := gorkflow.GetOutput[int](ctx, "times-ten")
+		return in + other, err
+	})
+	double := gorkflow.NewStep("double", "Double", func(ctx *gorkflow.StepContext, in int) (int, error) {
+		return in * 2, nil
+	})
+
+	wf, err := gorkflow.NewWorkflow("dag_test", "DAG Test").
+		Step(plusOne).
+		Step(timesTen).
+		Step(sum).DependsOn("plus-one", "times-ten").
+		Step(double).DependsOn("plus-one").
+		Build()
+	require.NoError(t, err)
+
+	ctx := context.Background()
+	runID, err := engine.StartWorkflow(ctx, wf, 5, gorkflow.WithSynchronousExecution())
+	require.NoError(t, err)
+
+	run, err := engine.GetRun(ctx, runID)
+	require.NoError(t, err)
+	assert.Equal(t, gorkflow.RunStatusCompleted, run.Status)
+
+	for stepID, want := range map[string]string{"plus-one": "6", "times-ten": "50", "sum": "56", "double": "12"} {
+		output, err := wfStore.LoadStepOutput(ctx, runID, stepID)
+		require.NoError(t, err)
+		assert.JSONEq(t, want, string(output), stepID)
+	}
+}
+
 func TestEngine_GetStepExecutions(t *testing.T) {
 	engine, _ := createTestEngine(t)
 
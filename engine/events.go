@@ -0,0 +1,64 @@
+package engine
+
+import (
+	"github.com/sicko7947/gorkflow"
+)
+
+// WithListener registers a listener that receives lifecycle events synchronously
+// on the executing goroutine. Multiple listeners are called in registration order.
+func WithListener(listener gorkflow.EventListener) EngineOption {
+	return func(e *Engine) {
+		e.listeners = append(e.listeners, listener)
+	}
+}
+
+// WithAsyncListener registers a listener that receives lifecycle events from a
+// background dispatcher with the given buffer size. Call Engine.Close to flush
+// pending events on shutdown.
+func WithAsyncListener(listener gorkflow.EventListener, bufferSize int) EngineOption {
+	return func(e *Engine) {
+		async := gorkflow.NewAsyncListener(listener, bufferSize)
+		e.listeners = append(e.listeners, async)
+		e.closers = append(e.closers, async.Close)
+	}
+}
+
+// emit stamps the event and delivers it to all registered listeners and run subscribers
+func (e *Engine) emit(event gorkflow.Event) {
+	if event.Timestamp.IsZero() {
+		event.Timestamp = e.clock.Now()
+	}
+	e.subscriptions.publish(event)
+	for _, listener := range e.listeners {
+		listener.OnEvent(event)
+	}
+}
+
+// runEvent builds an event carrying the identity of the given run
+func runEvent(eventType string, run *gorkflow.WorkflowRun) gorkflow.Event {
+	return gorkflow.Event{
+		Type:            eventType,
+		RunID:           run.RunID,
+		WorkflowID:      run.WorkflowID,
+		WorkflowVersion: run.WorkflowVersion,
+		ResourceID:      run.ResourceID,
+	}
+}
+
+// stepEvent builds an event carrying the identity of the given run and step
+func stepEvent(eventType string, run *gorkflow.WorkflowRun, step gorkflow.StepExecutor, attempt int) gorkflow.Event {
+	event := runEvent(eventType, run)
+	event.StepID = step.GetID()
+	event.StepName = step.GetName()
+	event.Attempt = attempt
+	return event
+}
+
+// logPersistenceError logs a failed store operation and emits a persistence event
+func (e *Engine) logPersistenceError(run *gorkflow.WorkflowRun, operation string, err error) {
+	gorkflow.LogPersistenceError(e.logger, run.RunID, operation, err)
+	event := runEvent(gorkflow.EventPersistenceError, run)
+	event.Operation = operation
+	event.Error = err
+	e.emit(event)
+}
@@ -0,0 +1,203 @@
+package engine
+
+import (
+	"context"
+	"errors"
+	"os"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/rs/zerolog"
+	"github.com/sicko7947/gorkflow"
+	"github.com/sicko7947/gorkflow/store"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// recordingListener collects events for assertions
+type recordingListener struct {
+	mu     sync.Mutex
+	events []gorkflow.Event
+}
+
+func (l *recordingListener) OnEvent(event gorkflow.Event) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.events = append(l.events, event)
+}
+
+func (l *recordingListener) types() []string {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	types := make([]string, len(l.events))
+	for i, event := range l.events {
+		types[i] = event.Type
+	}
+	return types
+}
+
+func (l *recordingListener) find(eventType string) []gorkflow.Event {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	var found []gorkflow.Event
+	for _, event := range l.events {
+		if event.Type == eventType {
+			found = append(found, event)
+		}
+	}
+	return found
+}
+
+func createListeningEngine(t *testing.T, opts ...EngineOption) (*Engine, *recordingListener) {
+	t.Helper()
+	listener := &recordingListener{}
+	logger := zerolog.New(os.Stdout).With().Timestamp().Logger()
+	opts = append([]EngineOption{WithLogger(logger), WithListener(listener)}, opts...)
+	return NewEngine(store.NewMemoryStore(), opts...), listener
+}
+
+func TestEngine_Events_SuccessfulRun(t *testing.T) {
+	engine, listener := createListeningEngine(t)
+
+	wf, err := gorkflow.NewWorkflow("events_success", "Events Success").
+		WithVersion("2.0").
+		ThenStep(gorkflow.NewStep("discover", "Discover", discoverCompanies)).
+		Build()
+	require.NoError(t, err)
+
+	runID, err := engine.StartWorkflow(context.Background(), wf, DiscoverInput{Query: "test", Limit: 10},
+		gorkflow.WithSynchronousExecution(),
+		gorkflow.WithResourceID("res-1"),
+	)
+	require.NoError(t, err)
+
+	assert.Equal(t, []string{
+		gorkflow.EventWorkflowCreated,
+		gorkflow.EventWorkflowStarted,
+		gorkflow.EventStepStarted,
+		gorkflow.EventStepCompleted,
+		gorkflow.EventWorkflowProgress,
+		gorkflow.EventWorkflowCompleted,
+	}, listener.types())
+
+	completed := listener.find(gorkflow.EventStepCompleted)
+	require.Len(t, completed, 1)
+	assert.Equal(t, runID, completed[0].RunID)
+	assert.Equal(t, "events_success", completed[0].WorkflowID)
+	assert.Equal(t, "2.0", completed[0].WorkflowVersion)
+	assert.Equal(t, "res-1", completed[0].ResourceID)
+	assert.Equal(t, "discover", completed[0].StepID)
+	assert.Equal(t, "Discover", completed[0].StepName)
+	assert.Positive(t, completed[0].OutputSize)
+	assert.False(t, completed[0].Timestamp.IsZero())
+
+	progress := listener.find(gorkflow.EventWorkflowProgress)
+	require.Len(t, progress, 1)
+	assert.Equal(t, 1.0, progress[0].Progress)
+}
+
+func TestEngine_Events_RetriesAndFailure(t *testing.T) {
+	engine, listener := createListeningEngine(t)
+
+	failStep := gorkflow.NewStep("fail", "Always Fail",
+		func(ctx *gorkflow.StepContext, input DiscoverInput) (DiscoverOutput, error) {
+			return DiscoverOutput{}, errors.New("boom")
+		},
+		gorkflow.WithRetries(2),
+		gorkflow.WithRetryDelay(10*time.Millisecond),
+		gorkflow.WithBackoff(gorkflow.BackoffNone),
+	)
+
+	wf, err := gorkflow.NewWorkflow("events_failure", "Events Failure").
+		ThenStep(failStep).
+		Build()
+	require.NoError(t, err)
+
+	_, err = engine.StartWorkflow(context.Background(), wf, DiscoverInput{}, gorkflow.WithSynchronousExecution())
+	require.Error(t, err)
+
+	assert.Len(t, listener.find(gorkflow.EventStepStarted), 3)
+
+	retrying := listener.find(gorkflow.EventStepRetrying)
+	require.Len(t, retrying, 2)
+	assert.Equal(t, 1, retrying[0].Attempt)
+	assert.Equal(t, 2, retrying[1].Attempt)
+	assert.EqualError(t, retrying[0].Error, "boom")
+
+	failed := listener.find(gorkflow.EventStepFailed)
+	require.Len(t, failed, 1)
+	assert.Equal(t, 2, failed[0].Attempt)
+	assert.EqualError(t, failed[0].Error, "boom")
+
+	wfFailed := listener.find(gorkflow.EventWorkflowFailed)
+	require.Len(t, wfFailed, 1)
+	assert.Error(t, wfFailed[0].Error)
+}
+
+func TestEngine_Events_SkippedStep(t *testing.T) {
+	engine, listener := createListeningEngine(t)
+
+	step := gorkflow.NewStep("maybe", "Maybe", discoverCompanies)
+
+	wf, err := gorkflow.NewWorkflow("events_skip", "Events Skip").
+		ThenStepIf(step, func(ctx *gorkflow.StepContext) (bool, error) { return false, nil }, nil).
+		Build()
+	require.NoError(t, err)
+
+	_, err = engine.StartWorkflow(context.Background(), wf, DiscoverInput{}, gorkflow.WithSynchronousExecution())
+	require.NoError(t, err)
+
+	skipped := listener.find(gorkflow.EventStepSkipped)
+	require.Len(t, skipped, 1)
+	assert.Equal(t, "maybe", skipped[0].StepID)
+	assert.Equal(t, "condition_not_met", skipped[0].Reason)
+	assert.Empty(t, listener.find(gorkflow.EventStepCompleted))
+}
+
+func TestEngine_Events_CompletedAfterOutputSaved(t *testing.T) {
+	s := store.NewMemoryStore()
+	var loaded, missing int32
+	listener := gorkflow.EventListenerFunc(func(event gorkflow.Event) {
+		if event.Type != gorkflow.EventStepCompleted {
+			return
+		}
+		if _, err := s.LoadStepOutput(context.Background(), event.RunID, event.StepID); err != nil {
+			atomic.AddInt32(&missing, 1)
+			return
+		}
+		atomic.AddInt32(&loaded, 1)
+	})
+	engine := NewEngine(s, WithLogger(zerolog.Nop()), WithListener(listener))
+
+	var calls int32
+	wf := cachedWorkflow(t, &calls, gorkflow.WithCache(time.Hour, nil))
+	runSync(t, engine, wf, DiscoverInput{Query: "q", Limit: 3})
+	runSync(t, engine, wf, DiscoverInput{Query: "q", Limit: 3}) // served from cache
+
+	assert.Equal(t, int32(1), atomic.LoadInt32(&calls))
+	assert.Equal(t, int32(2), atomic.LoadInt32(&loaded))
+	assert.Zero(t, atomic.LoadInt32(&missing), "output must be saved before step_completed is emitted")
+}
+
+func TestEngine_Events_AsyncListener(t *testing.T) {
+	var delivered int32
+	slow := gorkflow.EventListenerFunc(func(event gorkflow.Event) {
+		time.Sleep(time.Millisecond)
+		atomic.AddInt32(&delivered, 1)
+	})
+
+	engine, listener := createListeningEngine(t, WithAsyncListener(slow, 1))
+
+	wf, err := gorkflow.NewWorkflow("events_async", "Events Async").
+		ThenStep(gorkflow.NewStep("discover", "Discover", discoverCompanies)).
+		Build()
+	require.NoError(t, err)
+
+	_, err = engine.StartWorkflow(context.Background(), wf, DiscoverInput{}, gorkflow.WithSynchronousExecution())
+	require.NoError(t, err)
+
+	require.NoError(t, engine.Close())
+	assert.Equal(t, int32(len(listener.types())), atomic.LoadInt32(&delivered))
+}
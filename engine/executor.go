@@ -26,9 +26,10 @@ func (e *Engine) executeStep(
 	step gorkflow.StepExecutor,
 	inputBytes []byte,
 	state gorkflow.StateAccessor,
-	customContext any,
+	wf *gorkflow.Workflow,
 	executionIndex int,
 ) (*StepExecutionResult, error) {
+	ctx = stepPayloadContext(ctx, step)
 	outputs := gorkflow.NewStepAccessor(run.RunID, e.store)
 	config := step.GetConfig()
 
@@ -41,12 +42,20 @@ func (e *Engine) executeStep(
 		Input:          inputBytes,
 		StartedAt:      nil,
 		CompletedAt:    nil,
-		CreatedAt:      time.Now(),
-		UpdatedAt:      time.Now(),
+		CreatedAt:      e.clock.Now(),
+		UpdatedAt:      e.clock.Now(),
 	}
 
 	if err := e.store.CreateStepExecution(ctx, stepExec); err != nil {
-		return nil, fmt.Errorf("failed to create step execution: %w", err)
+		// A resumed run may already have a record for an interrupted step; reuse it
+		existing, getErr := e.store.GetStepExecution(ctx, run.RunID, step.GetID())
+		if getErr != nil {
+			return nil, fmt.Errorf("failed to create step execution: %w", err)
+		}
+		stepExec.CreatedAt = existing.CreatedAt
+		if err := e.store.UpdateStepExecution(ctx, stepExec); err != nil {
+			return nil, fmt.Errorf("failed to reset step execution: %w", err)
+		}
 	}
 
 	// Build step context
@@ -59,13 +68,35 @@ func (e *Engine) executeStep(
 		Logger:        stepLogger,
 		Data:          outputs,
 		State:         state,
-		CustomContext: customContext,
+		CustomContext: wf.GetContext(),
+		Serializer:    wf.Serializer(),
+	}
+
+	// Reuse the output of a previous execution with the same cache key. Conditional
+	// steps are only looked up when their condition holds; a condition error is
+	// reported when the step executes.
+	cacheKey := e.cacheKey(run, step, inputBytes)
+	if cacheKey != "" {
+		if met, err := gorkflow.StepConditionMet(stepCtx, step); err == nil && met {
+			if output, ok := e.lookupCachedOutput(ctx, run, cacheKey); ok {
+				return e.completeFromCache(ctx, run, step, stepExec, output), nil
+			}
+		}
 	}
 
+	// Wrap the step with engine, workflow and step interceptors (outermost first)
+	invoke := e.stepInvoker(wf, step)
+
 	var outputBytes []byte
 	var lastErr error
 	var attemptsMade int
 
+	breaker, err := e.stepBreaker(step)
+	if err != nil {
+		lastErr = err
+		goto retryExhausted
+	}
+
 	// Retry loop
 	for attempt := 0; attempt <= config.MaxRetries; attempt++ {
 		// Short-circuit if the workflow context is already cancelled.
@@ -83,45 +114,75 @@ func (e *Engine) executeStep(
 
 			gorkflow.LogStepRetrying(e.logger, run.RunID, step.GetID(), attempt, delay)
 
+			retryEvent := stepEvent(gorkflow.EventStepRetrying, run, step, attempt)
+			retryEvent.Delay = delay
+			retryEvent.Error = lastErr
+			e.emit(retryEvent)
+
 			stepExec.Status = gorkflow.StepStatusRetrying
 			stepExec.Attempt = attempt
-			stepExec.UpdatedAt = time.Now()
+			stepExec.UpdatedAt = e.clock.Now()
 
 			if err := e.store.UpdateStepExecution(ctx, stepExec); err != nil {
-				gorkflow.LogPersistenceError(e.logger, run.RunID, "update_step_execution_retry", err)
+				e.logPersistenceError(run, "update_step_execution_retry", err)
 			}
 
 			if delay > 0 {
+				backoffSpan := e.startBackoffSpan(ctx, step, attempt, delay)
 				select {
 				case <-ctx.Done():
+					backoffSpan.End()
 					lastErr = ctx.Err()
 					goto retryExhausted
-				case <-time.After(delay):
+				case <-e.clock.After(delay):
 				}
+				backoffSpan.End()
+			}
+		}
+
+		// Fail fast (or wait) while the step's circuit is open, before taking any
+		// limiter capacity
+		if breaker != nil {
+			if err := admitAttempt(ctx, breaker); err != nil {
+				lastErr = err
+				goto retryExhausted
 			}
 		}
 
+		// Acquire shared limiters before the timeout starts, so waiting for a slot
+		// does not count against the step timeout
+		release, err := e.acquireLimiters(ctx, step)
+		if err != nil {
+			if breaker != nil {
+				breaker.Release()
+			}
+			lastErr = err
+			goto retryExhausted
+		}
+
 		// Update to running
 		stepExec.Status = gorkflow.StepStatusRunning
-		now := time.Now()
+		now := e.clock.Now()
 		stepExec.StartedAt = &now
 		stepExec.Attempt = attempt
 		stepExec.UpdatedAt = now
 
 		if err := e.store.UpdateStepExecution(ctx, stepExec); err != nil {
-			gorkflow.LogPersistenceError(e.logger, run.RunID, "update_step_execution_running", err)
+			e.logPersistenceError(run, "update_step_execution_running", err)
 		}
+		e.emit(stepEvent(gorkflow.EventStepStarted, run, step, attempt))
 
 		// Execute with timeout
-		execCtx, cancel := context.WithTimeout(
-			ctx,
+		attemptCtx, attemptSpan := e.startAttemptSpan(ctx, run, step, attempt)
+		execCtx, cancel := e.clock.WithTimeout(
+			attemptCtx,
 			time.Duration(config.TimeoutSeconds)*time.Second,
 		)
 
 		stepCtx.Context = execCtx
 		gorkflow.SetStepAccessorCtx(outputs, execCtx)
 		gorkflow.SetStateAccessorCtx(state, execCtx)
-		startTime := time.Now()
+		startTime := e.clock.Now()
 
 		// Execute step (with panic recovery)
 		func() {
@@ -132,30 +193,41 @@ func (e *Engine) executeStep(
 				}
 			}()
 
-			outputBytes, lastErr = step.Execute(stepCtx, inputBytes)
+			outputBytes, lastErr = invoke(stepCtx, inputBytes)
 		}()
 
 		cancel() // Clean up timeout context
-		duration := time.Since(startTime)
+		release()
+		if breaker != nil {
+			if errors.Is(lastErr, gorkflow.ErrStepSkipped) {
+				recordAttempt(ctx, breaker, nil)
+			} else {
+				recordAttempt(ctx, breaker, lastErr)
+			}
+		}
+		duration := e.clock.Since(startTime)
 		stepExec.DurationMs = duration.Milliseconds()
 
 		// Check if step was skipped
 		if errors.Is(lastErr, gorkflow.ErrStepSkipped) {
 			stepExec.Status = gorkflow.StepStatusSkipped
 			stepExec.Output = outputBytes
-			completedAt := time.Now()
+			completedAt := e.clock.Now()
 			stepExec.CompletedAt = &completedAt
 			stepExec.UpdatedAt = completedAt
 
 			if err := e.store.UpdateStepExecution(ctx, stepExec); err != nil {
-				gorkflow.LogPersistenceError(e.logger, run.RunID, "update_step_execution_skipped", err)
+				e.logPersistenceError(run, "update_step_execution_skipped", err)
 			}
+			endAttemptSpan(attemptSpan, gorkflow.StepStatusSkipped, stepCtx.SkipReason(), nil)
 
 			// Save output for downstream steps (even if skipped, we might have pass-through output)
 			if err := e.store.SaveStepOutput(ctx, run.RunID, step.GetID(), outputBytes); err != nil {
-				gorkflow.LogPersistenceError(e.logger, run.RunID, "save_step_output_skipped", err)
+				e.logPersistenceError(run, "save_step_output_skipped", err)
 			}
 
+			e.emitStepSkipped(run, step, stepCtx, attempt, duration)
+
 			return &StepExecutionResult{
 				StepID:       step.GetID(),
 				Status:       gorkflow.StepStatusSkipped,
@@ -170,19 +242,34 @@ func (e *Engine) executeStep(
 			// Success
 			stepExec.Status = gorkflow.StepStatusCompleted
 			stepExec.Output = outputBytes
-			completedAt := time.Now()
+			completedAt := e.clock.Now()
 			stepExec.CompletedAt = &completedAt
 			stepExec.UpdatedAt = completedAt
 
 			if err := e.store.UpdateStepExecution(ctx, stepExec); err != nil {
-				gorkflow.LogPersistenceError(e.logger, run.RunID, "update_step_execution_success", err)
+				e.logPersistenceError(run, "update_step_execution_success", err)
 			}
+			endAttemptSpan(attemptSpan, gorkflow.StepStatusCompleted, stepCtx.SkipReason(), nil)
 
 			gorkflow.LogStepCompleted(e.logger, run.RunID, step.GetID(), duration.Milliseconds(), attemptsMade)
 
 			// Save output for downstream steps
 			if err := e.store.SaveStepOutput(ctx, run.RunID, step.GetID(), outputBytes); err != nil {
-				gorkflow.LogPersistenceError(e.logger, run.RunID, "save_step_output", err)
+				e.logPersistenceError(run, "save_step_output", err)
+			}
+
+			if stepCtx.SkipReason() != "" {
+				e.emitStepSkipped(run, step, stepCtx, attempt, duration)
+			} else {
+				completedEvent := stepEvent(gorkflow.EventStepCompleted, run, step, attempt)
+				completedEvent.Duration = duration
+				completedEvent.OutputSize = len(outputBytes)
+				e.emit(completedEvent)
+			}
+
+			// Outputs of steps that chose to skip are not reused
+			if stepCtx.SkipReason() == "" {
+				e.storeCachedOutput(ctx, run, step, cacheKey, outputBytes)
 			}
 
 			return &StepExecutionResult{
@@ -204,24 +291,30 @@ func (e *Engine) executeStep(
 		}
 
 		gorkflow.LogStepFailed(e.logger, run.RunID, step.GetID(), lastErr, attempt, duration.Milliseconds())
+		endAttemptSpan(attemptSpan, gorkflow.StepStatusFailed, "", lastErr)
 	}
 
 retryExhausted:
 	// All retries exhausted (or context cancelled)
 	stepExec.Status = gorkflow.StepStatusFailed
-	completedAt := time.Now()
+	completedAt := e.clock.Now()
 	stepExec.CompletedAt = &completedAt
 	stepExec.UpdatedAt = completedAt
 	stepExec.Error = &gorkflow.StepError{
 		Message: lastErr.Error(),
-		Code:    gorkflow.ErrCodeExecutionFailed,
+		Code:    failureCode(lastErr),
 		Attempt: config.MaxRetries,
 	}
 
 	if err := e.store.UpdateStepExecution(ctx, stepExec); err != nil {
-		gorkflow.LogPersistenceError(e.logger, run.RunID, "update_step_execution_failure", err)
+		e.logPersistenceError(run, "update_step_execution_failure", err)
 	}
 
+	failedEvent := stepEvent(gorkflow.EventStepFailed, run, step, max(attemptsMade-1, 0))
+	failedEvent.Duration = time.Duration(stepExec.DurationMs) * time.Millisecond
+	failedEvent.Error = lastErr
+	e.emit(failedEvent)
+
 	stepLogger.Error().
 		Int("max_retries", config.MaxRetries).
 		Int("attempts_made", attemptsMade).
@@ -236,3 +329,22 @@ retryExhausted:
 		AttemptsMade: attemptsMade,
 	}, fmt.Errorf("step %s failed after %d attempts: %w", step.GetID(), attemptsMade, lastErr)
 }
+
+// emitStepSkipped emits a skip event, using the condition's reason when one was recorded
+func (e *Engine) emitStepSkipped(run *gorkflow.WorkflowRun, step gorkflow.StepExecutor, stepCtx *gorkflow.StepContext, attempt int, duration time.Duration) {
+	event := stepEvent(gorkflow.EventStepSkipped, run, step, attempt)
+	event.Duration = duration
+	event.Reason = stepCtx.SkipReason()
+	if event.Reason == "" {
+		event.Reason = "step_skipped"
+	}
+	e.emit(event)
+}
+
+// failureCode maps a step or workflow failure to its error code
+func failureCode(err error) string {
+	if errors.Is(err, gorkflow.ErrCircuitOpen) {
+		return gorkflow.ErrCodeCircuitOpen
+	}
+	return gorkflow.ErrCodeExecutionFailed
+}
@@ -0,0 +1,24 @@
+package engine
+
+import (
+	"github.com/sicko7947/gorkflow"
+)
+
+// WithStepInterceptor adds engine-level interceptors that wrap every step attempt
+// of every workflow. They run outside workflow-level and step-level interceptors.
+func WithStepInterceptor(interceptors ...gorkflow.StepInterceptor) EngineOption {
+	return func(e *Engine) {
+		e.interceptors = append(e.interceptors, interceptors...)
+	}
+}
+
+// stepInvoker builds the invocation chain for a step: engine, workflow and
+// step interceptors in that order, with step.Execute innermost
+func (e *Engine) stepInvoker(wf *gorkflow.Workflow, step gorkflow.StepExecutor) gorkflow.StepInvoker {
+	var chain []gorkflow.StepInterceptor
+	chain = append(chain, e.interceptors...)
+	chain = append(chain, wf.Interceptors()...)
+	chain = append(chain, gorkflow.StepInterceptors(step)...)
+
+	return gorkflow.ChainInterceptors(step.Execute, chain...)
+}
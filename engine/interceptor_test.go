@@ -0,0 +1,182 @@
+package engine
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/sicko7947/gorkflow"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// orderRecorder returns an interceptor that records its name before and after the call
+func orderRecorder(mu *sync.Mutex, calls *[]string, name string) gorkflow.StepInterceptor {
+	return func(ctx *gorkflow.StepContext, input []byte, next gorkflow.StepInvoker) ([]byte, error) {
+		mu.Lock()
+		*calls = append(*calls, name+":before")
+		mu.Unlock()
+
+		output, err := next(ctx, input)
+
+		mu.Lock()
+		*calls = append(*calls, name+":after")
+		mu.Unlock()
+		return output, err
+	}
+}
+
+func TestEngine_Interceptors_Order(t *testing.T) {
+	var mu sync.Mutex
+	var calls []string
+
+	engine, _ := createListeningEngine(t, WithStepInterceptor(
+		orderRecorder(&mu, &calls, "engine1"),
+		orderRecorder(&mu, &calls, "engine2"),
+	))
+
+	step := gorkflow.NewStep("discover", "Discover",
+		func(ctx *gorkflow.StepContext, input DiscoverInput) (DiscoverOutput, error) {
+			mu.Lock()
+			calls = append(calls, "handler")
+			mu.Unlock()
+			return discoverCompanies(ctx, input)
+		},
+		gorkflow.WithInterceptors(orderRecorder(&mu, &calls, "step")),
+	)
+
+	wf, err := gorkflow.NewWorkflow("interceptor_order", "Interceptor Order").
+		Use(orderRecorder(&mu, &calls, "workflow")).
+		ThenStep(step).
+		Build()
+	require.NoError(t, err)
+
+	_, err = engine.StartWorkflow(context.Background(), wf, DiscoverInput{Query: "test", Limit: 1},
+		gorkflow.WithSynchronousExecution())
+	require.NoError(t, err)
+
+	assert.Equal(t, []string{
+		"engine1:before", "engine2:before", "workflow:before", "step:before",
+		"handler",
+		"step:after", "workflow:after", "engine2:after", "engine1:after",
+	}, calls)
+}
+
+func TestEngine_Interceptors_SeeBytesAndErrors(t *testing.T) {
+	var seenInput, seenOutput []byte
+	var seenErrs []error
+
+	observe := func(ctx *gorkflow.StepContext, input []byte, next gorkflow.StepInvoker) ([]byte, error) {
+		output, err := next(ctx, input)
+		seenInput = input
+		if err == nil {
+			seenOutput = output
+		}
+		seenErrs = append(seenErrs, err)
+		return output, err
+	}
+
+	engine, _ := createListeningEngine(t, WithStepInterceptor(observe))
+
+	attempts := 0
+	step := gorkflow.NewStep("flaky", "Flaky",
+		func(ctx *gorkflow.StepContext, input DiscoverInput) (DiscoverOutput, error) {
+			attempts++
+			if attempts == 1 {
+				return DiscoverOutput{}, errors.New("transient")
+			}
+			return DiscoverOutput{Count: input.Limit}, nil
+		},
+		gorkflow.WithRetries(1),
+		gorkflow.WithRetryDelay(time.Millisecond),
+	)
+
+	wf, err := gorkflow.NewWorkflow("interceptor_bytes", "Interceptor Bytes").ThenStep(step).Build()
+	require.NoError(t, err)
+
+	_, err = engine.StartWorkflow(context.Background(), wf, DiscoverInput{Query: "q", Limit: 3},
+		gorkflow.WithSynchronousExecution())
+	require.NoError(t, err)
+
+	// Interceptors run once per attempt
+	require.Len(t, seenErrs, 2)
+	assert.EqualError(t, seenErrs[0], "transient")
+	assert.NoError(t, seenErrs[1])
+	assert.JSONEq(t, `{"query":"q","limit":3}`, string(seenInput))
+	assert.Contains(t, string(seenOutput), `"count":3`)
+}
+
+func TestEngine_Interceptors_ShortCircuit(t *testing.T) {
+	handlerCalled := false
+	step := gorkflow.NewStep("discover", "Discover",
+		func(ctx *gorkflow.StepContext, input DiscoverInput) (DiscoverOutput, error) {
+			handlerCalled = true
+			return DiscoverOutput{}, nil
+		},
+		gorkflow.WithInterceptors(func(ctx *gorkflow.StepContext, input []byte, next gorkflow.StepInvoker) ([]byte, error) {
+			return []byte(`{"companies":null,"count":42}`), nil
+		}),
+	)
+
+	engine, _ := createListeningEngine(t)
+	wf, err := gorkflow.NewWorkflow("interceptor_short", "Interceptor Short").ThenStep(step).Build()
+	require.NoError(t, err)
+
+	runID, err := engine.StartWorkflow(context.Background(), wf, DiscoverInput{Query: "q", Limit: 1},
+		gorkflow.WithSynchronousExecution())
+	require.NoError(t, err)
+	assert.False(t, handlerCalled)
+
+	raw, err := engine.LoadStepOutput(context.Background(), runID, "discover")
+	require.NoError(t, err)
+	var out DiscoverOutput
+	require.NoError(t, json.Unmarshal(raw, &out))
+	assert.Equal(t, 42, out.Count)
+}
+
+func TestEngine_Interceptors_ReplaceError(t *testing.T) {
+	engine, _ := createListeningEngine(t)
+
+	wf, err := gorkflow.NewWorkflow("interceptor_fail", "Interceptor Fail").
+		Use(func(ctx *gorkflow.StepContext, input []byte, next gorkflow.StepInvoker) ([]byte, error) {
+			if _, err := next(ctx, input); err != nil {
+				return nil, err
+			}
+			return nil, errors.New("rejected by interceptor")
+		}).
+		ThenStep(gorkflow.NewStep("discover", "Discover", discoverCompanies)).
+		Build()
+	require.NoError(t, err)
+
+	runID, err := engine.StartWorkflow(context.Background(), wf, DiscoverInput{Query: "q", Limit: 1},
+		gorkflow.WithSynchronousExecution())
+	require.Error(t, err)
+
+	run, err := engine.GetRun(context.Background(), runID)
+	require.NoError(t, err)
+	assert.Equal(t, gorkflow.RunStatusFailed, run.Status)
+	require.NotNil(t, run.Error)
+	assert.Contains(t, run.Error.Message, "rejected by interceptor")
+}
+
+func TestEngine_Interceptors_ConditionalStep(t *testing.T) {
+	var mu sync.Mutex
+	var calls []string
+
+	engine, _ := createListeningEngine(t)
+	step := gorkflow.NewStep("discover", "Discover", discoverCompanies,
+		gorkflow.WithInterceptors(orderRecorder(&mu, &calls, "step")))
+
+	wf, err := gorkflow.NewWorkflow("interceptor_cond", "Interceptor Conditional").
+		ThenStepIf(step, func(ctx *gorkflow.StepContext) (bool, error) { return true, nil }, nil).
+		Build()
+	require.NoError(t, err)
+
+	_, err = engine.StartWorkflow(context.Background(), wf, DiscoverInput{Query: "q", Limit: 1},
+		gorkflow.WithSynchronousExecution())
+	require.NoError(t, err)
+	assert.Equal(t, []string{"step:before", "step:after"}, calls)
+}
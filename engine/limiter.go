@@ -0,0 +1,55 @@
+package engine
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/sicko7947/gorkflow"
+)
+
+// WithLimiter registers a named limiter that steps can attach with gorkflow.WithLimiters.
+// The limiter is shared by every run executed by the engine.
+func WithLimiter(name string, limiter gorkflow.Limiter) EngineOption {
+	return func(e *Engine) {
+		e.limiters[name] = limiter
+	}
+}
+
+// acquireLimiters acquires the step's limiters in order and returns a function
+// releasing them in reverse order. On error, already acquired limiters are released.
+func (e *Engine) acquireLimiters(ctx context.Context, step gorkflow.StepExecutor) (func(), error) {
+	names := gorkflow.StepLimiters(step)
+	if len(names) == 0 {
+		return func() {}, nil
+	}
+
+	releases := make([]func(), 0, len(names))
+	releaseAll := func() {
+		for i := len(releases) - 1; i >= 0; i-- {
+			releases[i]()
+		}
+	}
+
+	start := e.clock.Now()
+	for _, name := range names {
+		limiter, ok := e.limiters[name]
+		if !ok {
+			releaseAll()
+			return nil, fmt.Errorf("step %s: %w: %q", step.GetID(), gorkflow.ErrLimiterNotFound, name)
+		}
+		release, err := limiter.Acquire(ctx)
+		if err != nil {
+			releaseAll()
+			return nil, err
+		}
+		releases = append(releases, release)
+	}
+
+	e.logger.Debug().
+		Str("step_id", step.GetID()).
+		Strs("limiters", names).
+		Dur("waited", e.clock.Since(start)).
+		Msg("Acquired step limiters")
+
+	return releaseAll, nil
+}
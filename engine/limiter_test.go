@@ -0,0 +1,123 @@
+package engine
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/sicko7947/gorkflow"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// concurrencyProbe tracks how many handlers run at the same time
+type concurrencyProbe struct {
+	current atomic.Int32
+	peak    atomic.Int32
+}
+
+func (p *concurrencyProbe) handler(hold time.Duration) gorkflow.StepHandler[DiscoverInput, DiscoverOutput] {
+	return func(ctx *gorkflow.StepContext, input DiscoverInput) (DiscoverOutput, error) {
+		n := p.current.Add(1)
+		defer p.current.Add(-1)
+		for {
+			peak := p.peak.Load()
+			if n <= peak || p.peak.CompareAndSwap(peak, n) {
+				break
+			}
+		}
+		time.Sleep(hold)
+		return DiscoverOutput{Count: input.Limit}, nil
+	}
+}
+
+func TestEngine_Limiter_ConcurrencyPoolAcrossRuns(t *testing.T) {
+	engine, _ := createListeningEngine(t, WithLimiter("vendor", gorkflow.NewConcurrencyPool(2)))
+	probe := &concurrencyProbe{}
+
+	wf, err := gorkflow.NewWorkflow("limited", "Limited").
+		ThenStep(gorkflow.NewStep("call", "Call Vendor", probe.handler(50*time.Millisecond),
+			gorkflow.WithLimiters("vendor"))).
+		Build()
+	require.NoError(t, err)
+
+	runIDs := make([]string, 6)
+	for i := range runIDs {
+		runIDs[i], err = engine.StartWorkflow(context.Background(), wf, DiscoverInput{Query: "q", Limit: i})
+		require.NoError(t, err)
+	}
+	for _, runID := range runIDs {
+		run := waitForCompletion(t, engine, runID, 5*time.Second)
+		assert.Equal(t, gorkflow.RunStatusCompleted, run.Status)
+	}
+
+	assert.Equal(t, int32(2), probe.peak.Load())
+}
+
+func TestEngine_Limiter_WaitDoesNotCountAgainstTimeout(t *testing.T) {
+	engine, _ := createListeningEngine(t, WithLimiter("exclusive", gorkflow.NewConcurrencyPool(1)))
+	probe := &concurrencyProbe{}
+
+	// Each step holds the slot for 700ms with a 1s timeout; the steps waiting
+	// behind it would time out if waiting counted
+	var steps []gorkflow.StepExecutor
+	for i := 0; i < 3; i++ {
+		steps = append(steps, gorkflow.NewStep(fmt.Sprintf("call_%d", i), "Call", probe.handler(700*time.Millisecond),
+			gorkflow.WithLimiters("exclusive"),
+			gorkflow.WithTimeout(time.Second),
+			gorkflow.WithRetries(0),
+		))
+	}
+
+	wf, err := gorkflow.NewWorkflow("limited_parallel", "Limited Parallel").
+		ThenStep(gorkflow.NewStep("discover", "Discover", discoverCompanies)).
+		Parallel(steps...).
+		Build()
+	require.NoError(t, err)
+
+	runID, err := engine.StartWorkflow(context.Background(), wf, DiscoverInput{Query: "q", Limit: 1})
+	require.NoError(t, err)
+
+	run := waitForCompletion(t, engine, runID, 10*time.Second)
+	assert.Equal(t, gorkflow.RunStatusCompleted, run.Status)
+	assert.Equal(t, int32(1), probe.peak.Load())
+}
+
+func TestEngine_Limiter_RateLimiter(t *testing.T) {
+	// 20/s with burst 1: five calls need at least ~200ms
+	engine, _ := createListeningEngine(t, WithLimiter("api", gorkflow.NewRateLimiter(20, 1)))
+
+	var calls atomic.Int32
+	wf, err := gorkflow.NewWorkflow("rate_limited", "Rate Limited").
+		ThenStep(gorkflow.NewStep("call", "Call", func(ctx *gorkflow.StepContext, input DiscoverInput) (DiscoverOutput, error) {
+			calls.Add(1)
+			return DiscoverOutput{}, nil
+		}, gorkflow.WithLimiters("api"))).
+		Build()
+	require.NoError(t, err)
+
+	start := time.Now()
+	for i := 0; i < 5; i++ {
+		_, err := engine.StartWorkflow(context.Background(), wf, DiscoverInput{Query: "q"}, gorkflow.WithSynchronousExecution())
+		require.NoError(t, err)
+	}
+
+	assert.Equal(t, int32(5), calls.Load())
+	assert.GreaterOrEqual(t, time.Since(start), 180*time.Millisecond)
+}
+
+func TestEngine_Limiter_NotRegistered(t *testing.T) {
+	engine, _ := createListeningEngine(t)
+
+	wf, err := gorkflow.NewWorkflow("unknown_limiter", "Unknown Limiter").
+		ThenStep(gorkflow.NewStep("discover", "Discover", discoverCompanies, gorkflow.WithLimiters("missing"))).
+		Build()
+	require.NoError(t, err)
+
+	_, err = engine.StartWorkflow(context.Background(), wf, DiscoverInput{Query: "q", Limit: 1}, gorkflow.WithSynchronousExecution())
+	require.Error(t, err)
+	assert.True(t, errors.Is(err, gorkflow.ErrLimiterNotFound))
+}
@@ -0,0 +1,46 @@
+package engine
+
+import (
+	"context"
+
+	"github.com/sicko7947/gorkflow"
+)
+
+// WithBlobStore moves payloads larger than threshold bytes to blobs and keeps
+// only a reference in the workflow store. Readers through the engine, step
+// accessors and downstream step inputs see the original payloads. A threshold
+// of zero uses payload.DefaultOffloadThreshold.
+func WithBlobStore(blobs gorkflow.BlobStore, threshold int) EngineOption {
+	return func(e *Engine) {
+		e.payload.Blobs = blobs
+		e.payload.OffloadThreshold = threshold
+	}
+}
+
+// WithPayloadCodec encodes every persisted payload (run, step and state data
+// and cached step outputs) with the given codecs, applied in order. Payloads
+// are decoded transparently on read.
+func WithPayloadCodec(codecs ...gorkflow.PayloadCodec) EngineOption {
+	return func(e *Engine) {
+		e.payload.Codecs = append(e.payload.Codecs, codecs...)
+	}
+}
+
+// WithPayloadCompression compresses persisted payloads of at least minSize
+// bytes. Steps can override it with gorkflow.WithCompression. A minSize of
+// zero uses payload.DefaultCompressionMinSize.
+func WithPayloadCompression(compression gorkflow.Compression, minSize int) EngineOption {
+	return func(e *Engine) {
+		e.payload.Compression = compression
+		e.payload.CompressionMinSize = minSize
+	}
+}
+
+// stepPayloadContext applies the step's compression override to payloads
+// persisted with the returned context
+func stepPayloadContext(ctx context.Context, step gorkflow.StepExecutor) context.Context {
+	if compression := gorkflow.StepCompression(step); compression != gorkflow.CompressionDefault {
+		return gorkflow.ContextWithCompression(ctx, compression)
+	}
+	return ctx
+}
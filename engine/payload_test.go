@@ -0,0 +1,166 @@
+package engine
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/rs/zerolog"
+	"github.com/sicko7947/gorkflow"
+	"github.com/sicko7947/gorkflow/payload"
+	"github.com/sicko7947/gorkflow/store"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEngine_BlobStore_OffloadsLargeOutputs(t *testing.T) {
+	wfStore := store.NewMemoryStore()
+	blobs := store.NewMemoryBlobStore()
+	engine := NewEngine(wfStore, WithLogger(zerolog.Nop()), WithBlobStore(blobs, 1024))
+
+	big := strings.Repeat("x", 4096)
+	produce := gorkflow.NewStep("produce", "Produce", func(ctx *gorkflow.StepContext, input DiscoverInput) (DiscoverOutput, error) {
+		return DiscoverOutput{Companies: []string{big}, Count: 1}, nil
+	})
+	var received DiscoverOutput
+	consume := gorkflow.NewStep("consume", "Consume", func(ctx *gorkflow.StepContext, input DiscoverOutput) (FilterOutput, error) {
+		received = input
+		var fromAccessor DiscoverOutput
+		if err := ctx.Data.GetOutput("produce", &fromAccessor); err != nil {
+			return FilterOutput{}, err
+		}
+		return FilterOutput{Filtered: []string{fromAccessor.Companies[0][:3]}}, nil
+	})
+	wf, err := gorkflow.NewWorkflow("offload", "Offload").ThenStep(produce).ThenStep(consume).Build()
+	require.NoError(t, err)
+
+	runID, err := engine.StartWorkflow(context.Background(), wf, DiscoverInput{Query: "q"}, gorkflow.WithSynchronousExecution())
+	require.NoError(t, err)
+
+	// The downstream step got the full output through resolveStepInput
+	assert.Equal(t, []string{big}, received.Companies)
+	result, err := gorkflow.GetResult[FilterOutput](context.Background(), engine, runID)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"xxx"}, result.Filtered)
+
+	// The workflow store only holds references
+	raw, err := wfStore.LoadStepOutput(context.Background(), runID, "produce")
+	require.NoError(t, err)
+	assert.Less(t, len(raw), 1024)
+	assert.Contains(t, blobs.Keys(), "runs/"+runID+"/steps/produce/output")
+
+	output, err := engine.LoadStepOutput(context.Background(), runID, "produce")
+	require.NoError(t, err)
+	assert.Greater(t, len(output), 4096)
+}
+
+func TestEngine_PayloadCodec_EncryptsPersistedData(t *testing.T) {
+	wfStore := store.NewMemoryStore()
+	codec, err := payload.NewAESGCMCodec("k1", map[string][]byte{"k1": make([]byte, 32)})
+	require.NoError(t, err)
+	engine := NewEngine(wfStore, WithLogger(zerolog.Nop()), WithPayloadCodec(codec))
+
+	wf, err := gorkflow.NewWorkflow("encrypted", "Encrypted").
+		ThenStep(gorkflow.NewStep("discover", "Discover", discoverCompanies)).
+		Build()
+	require.NoError(t, err)
+
+	runID, err := engine.StartWorkflow(context.Background(), wf, DiscoverInput{Query: "confidential"}, gorkflow.WithSynchronousExecution())
+	require.NoError(t, err)
+
+	raw, err := wfStore.GetRun(context.Background(), runID)
+	require.NoError(t, err)
+	assert.NotContains(t, string(raw.Input), "confidential")
+
+	run, err := engine.GetRun(context.Background(), runID)
+	require.NoError(t, err)
+	assert.Contains(t, string(run.Input), "confidential")
+
+	result, err := gorkflow.GetResult[DiscoverOutput](context.Background(), engine, runID)
+	require.NoError(t, err)
+	assert.Equal(t, 3, result.Count)
+}
+
+func TestEngine_PayloadCompression_StepOverride(t *testing.T) {
+	wfStore := store.NewMemoryStore()
+	engine := NewEngine(wfStore, WithLogger(zerolog.Nop()), WithPayloadCompression(gorkflow.CompressionZstd, 64))
+
+	companies := make([]string, 200)
+	for i := range companies {
+		companies[i] = "company"
+	}
+	produce := func(ctx *gorkflow.StepContext, input DiscoverInput) (DiscoverOutput, error) {
+		return DiscoverOutput{Companies: companies, Count: len(companies)}, nil
+	}
+	var received DiscoverOutput
+	wf, err := gorkflow.NewWorkflow("compressed", "Compressed").
+		ThenStep(gorkflow.NewStep("compressed", "Compressed", produce)).
+		ThenStep(gorkflow.NewStep("plain", "Plain", func(ctx *gorkflow.StepContext, input DiscoverOutput) (DiscoverOutput, error) {
+			received = input
+			return input, nil
+		}, gorkflow.WithCompression(gorkflow.CompressionNone))).
+		Build()
+	require.NoError(t, err)
+
+	runID, err := engine.StartWorkflow(context.Background(), wf, DiscoverInput{}, gorkflow.WithSynchronousExecution())
+	require.NoError(t, err)
+	assert.Equal(t, companies, received.Companies)
+
+	compressed, err := wfStore.LoadStepOutput(context.Background(), runID, "compressed")
+	require.NoError(t, err)
+	plain, err := wfStore.LoadStepOutput(context.Background(), runID, "plain")
+	require.NoError(t, err)
+	assert.Less(t, len(compressed), len(plain)/5)
+	assert.Contains(t, string(plain), "company")
+
+	output, err := engine.LoadStepOutput(context.Background(), runID, "compressed")
+	require.NoError(t, err)
+	assert.Equal(t, plain, output)
+}
+
+func TestEngine_Serializer_Msgpack(t *testing.T) {
+	wfStore := store.NewMemoryStore()
+	engine := NewEngine(wfStore, WithLogger(zerolog.Nop()))
+
+	var stored DiscoverOutput
+	record := gorkflow.NewStep("record", "Record", func(ctx *gorkflow.StepContext, input DiscoverOutput) (FilterOutput, error) {
+		if err := ctx.State.Set("count", input.Count); err != nil {
+			return FilterOutput{}, err
+		}
+		if err := ctx.Data.GetOutput("discover", &stored); err != nil {
+			return FilterOutput{}, err
+		}
+		return FilterOutput{Filtered: input.Companies[:1]}, nil
+	})
+	wf, err := gorkflow.NewWorkflow("msgpack", "Msgpack").
+		WithSerializer(gorkflow.MsgpackSerializer).
+		ThenStep(gorkflow.NewStep("discover", "Discover", discoverCompanies)).
+		ThenStep(record).
+		Build()
+	require.NoError(t, err)
+
+	runID, err := engine.StartWorkflow(context.Background(), wf, DiscoverInput{Query: "q", Limit: 3}, gorkflow.WithSynchronousExecution())
+	require.NoError(t, err)
+	assert.Equal(t, 3, stored.Count)
+
+	// Every payload records its format
+	run, err := engine.GetRun(context.Background(), runID)
+	require.NoError(t, err)
+	format, err := gorkflow.PayloadFormat(run.Input)
+	require.NoError(t, err)
+	assert.Equal(t, "msgpack", format)
+
+	output, err := engine.LoadStepOutput(context.Background(), runID, "discover")
+	require.NoError(t, err)
+	format, err = gorkflow.PayloadFormat(output)
+	require.NoError(t, err)
+	assert.Equal(t, "msgpack", format)
+
+	count, err := gorkflow.GetTyped[int](gorkflow.NewStateAccessor(runID, engine.Store()), "count")
+	require.NoError(t, err)
+	assert.Equal(t, 3, count)
+
+	result, err := gorkflow.GetResult[FilterOutput](context.Background(), engine, runID)
+	require.NoError(t, err)
+	assert.Len(t, result.Filtered, 1)
+}
@@ -0,0 +1,197 @@
+package engine
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/sicko7947/gorkflow"
+)
+
+// registry holds workflow definitions keyed by ID and version
+type registry struct {
+	mu        sync.RWMutex
+	workflows map[string]map[string]*gorkflow.Workflow // workflowID -> version -> definition
+}
+
+func newRegistry() *registry {
+	return &registry{workflows: make(map[string]map[string]*gorkflow.Workflow)}
+}
+
+// Register adds a workflow definition to the engine registry, keyed by its ID and
+// Version(). Registering the same ID and version twice is an error; register a new
+// version instead. New runs started by ID use the latest version, while existing
+// runs stay pinned to the version recorded in WorkflowRun.WorkflowVersion.
+func (e *Engine) Register(wf *gorkflow.Workflow) error {
+	if wf == nil {
+		return fmt.Errorf("cannot register nil workflow")
+	}
+	if wf.ID() == "" {
+		return fmt.Errorf("cannot register workflow without an ID")
+	}
+
+	e.registry.mu.Lock()
+	defer e.registry.mu.Unlock()
+
+	versions, exists := e.registry.workflows[wf.ID()]
+	if !exists {
+		versions = make(map[string]*gorkflow.Workflow)
+		e.registry.workflows[wf.ID()] = versions
+	}
+	if _, exists := versions[wf.Version()]; exists {
+		return fmt.Errorf("workflow %s version %s: %w", wf.ID(), wf.Version(), gorkflow.ErrWorkflowAlreadyRegistered)
+	}
+	versions[wf.Version()] = wf
+
+	e.logger.Debug().
+		Str("workflow_id", wf.ID()).
+		Str("version", wf.Version()).
+		Msg("Workflow registered")
+	return nil
+}
+
+// MustRegister registers a workflow definition, panics on error
+func (e *Engine) MustRegister(wf *gorkflow.Workflow) {
+	if err := e.Register(wf); err != nil {
+		panic(fmt.Sprintf("failed to register workflow: %v", err))
+	}
+}
+
+// GetWorkflow returns a registered workflow definition.
+// An empty version selects the latest registered version.
+func (e *Engine) GetWorkflow(id, version string) (*gorkflow.Workflow, error) {
+	e.registry.mu.RLock()
+	defer e.registry.mu.RUnlock()
+
+	versions, exists := e.registry.workflows[id]
+	if !exists || len(versions) == 0 {
+		return nil, fmt.Errorf("workflow %s: %w", id, gorkflow.ErrWorkflowNotFound)
+	}
+
+	if version == "" {
+		version = latestVersion(versions)
+	}
+	wf, exists := versions[version]
+	if !exists {
+		return nil, fmt.Errorf("workflow %s version %s: %w", id, version, gorkflow.ErrWorkflowNotFound)
+	}
+	return wf, nil
+}
+
+// Workflows returns all registered workflow definitions, sorted by ID and version
+func (e *Engine) Workflows() []*gorkflow.Workflow {
+	e.registry.mu.RLock()
+	defer e.registry.mu.RUnlock()
+
+	var workflows []*gorkflow.Workflow
+	for _, versions := range e.registry.workflows {
+		for _, wf := range versions {
+			workflows = append(workflows, wf)
+		}
+	}
+	sort.Slice(workflows, func(i, j int) bool {
+		if workflows[i].ID() != workflows[j].ID() {
+			return workflows[i].ID() < workflows[j].ID()
+		}
+		return compareVersions(workflows[i].Version(), workflows[j].Version()) < 0
+	})
+	return workflows
+}
+
+// StartWorkflowByID starts a run of a registered workflow with raw JSON input.
+// An empty version selects the latest registered version.
+func (e *Engine) StartWorkflowByID(
+	ctx context.Context,
+	id, version string,
+	input json.RawMessage,
+	opts ...gorkflow.StartOption,
+) (string, error) {
+	wf, err := e.GetWorkflow(id, version)
+	if err != nil {
+		return "", err
+	}
+	if len(input) == 0 {
+		input = json.RawMessage("null")
+	}
+	if wf.Serializer().Name() == gorkflow.JSONSerializer.Name() {
+		return e.StartWorkflow(ctx, wf, input, opts...)
+	}
+	value, err := decodeInput(wf, input)
+	if err != nil {
+		return "", gorkflow.NewWorkflowError(gorkflow.ErrCodeValidation, fmt.Sprintf("invalid workflow input: %v", err))
+	}
+	return e.StartWorkflow(ctx, wf, value, opts...)
+}
+
+// decodeInput decodes JSON input into the input type of the workflow's entry
+// step, so serializers other than JSON encode the value instead of the raw JSON.
+// Build ensures every root step of the workflow takes that type.
+func decodeInput(wf *gorkflow.Workflow, input json.RawMessage) (any, error) {
+	var target reflect.Type
+	if step, err := wf.GetStep(wf.Graph().EntryPoint); err == nil {
+		target = step.InputType()
+	}
+	if target == nil {
+		var v any
+		err := json.Unmarshal(input, &v)
+		return v, err
+	}
+	v := reflect.New(target)
+	if err := json.Unmarshal(input, v.Interface()); err != nil {
+		return nil, err
+	}
+	return v.Elem().Interface(), nil
+}
+
+// latestVersion returns the highest version key
+func latestVersion(versions map[string]*gorkflow.Workflow) string {
+	latest := ""
+	for version := range versions {
+		if latest == "" || compareVersions(version, latest) > 0 {
+			latest = version
+		}
+	}
+	return latest
+}
+
+// compareVersions compares dot-separated versions such as "1.2" and "1.10".
+// Numeric segments compare numerically, others lexically; an optional "v" prefix is ignored.
+func compareVersions(a, b string) int {
+	as := strings.Split(strings.TrimPrefix(a, "v"), ".")
+	bs := strings.Split(strings.TrimPrefix(b, "v"), ".")
+
+	for i := 0; i < len(as) || i < len(bs); i++ {
+		var x, y string
+		if i < len(as) {
+			x = as[i]
+		}
+		if i < len(bs) {
+			y = bs[i]
+		}
+		if x == y {
+			continue
+		}
+
+		xn, xErr := strconv.Atoi(x)
+		yn, yErr := strconv.Atoi(y)
+		switch {
+		case x == "":
+			return -1
+		case y == "":
+			return 1
+		case xErr == nil && yErr == nil:
+			if xn < yn {
+				return -1
+			}
+			return 1
+		default:
+			return strings.Compare(x, y)
+		}
+	}
+	return 0
+}
@@ -0,0 +1,410 @@
+package engine
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"path/filepath"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/rs/zerolog"
+	"github.com/sicko7947/gorkflow"
+	"github.com/sicko7947/gorkflow/store"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func versionedWorkflow(t *testing.T, version string, steps ...gorkflow.StepExecutor) *gorkflow.Workflow {
+	t.Helper()
+	b := gorkflow.NewWorkflow("registry", "Registry").WithVersion(version)
+	for _, step := range steps {
+		b.ThenStep(step)
+	}
+	wf, err := b.Build()
+	require.NoError(t, err)
+	return wf
+}
+
+func TestEngine_Register(t *testing.T) {
+	engine, _ := createListeningEngine(t)
+
+	v1 := versionedWorkflow(t, "1.9", gorkflow.NewStep("discover", "Discover", discoverCompanies))
+	v2 := versionedWorkflow(t, "1.10", gorkflow.NewStep("discover", "Discover", discoverCompanies))
+	require.NoError(t, engine.Register(v2))
+	require.NoError(t, engine.Register(v1))
+
+	err := engine.Register(versionedWorkflow(t, "1.9", gorkflow.NewStep("discover", "Discover", discoverCompanies)))
+	assert.ErrorIs(t, err, gorkflow.ErrWorkflowAlreadyRegistered)
+
+	latest, err := engine.GetWorkflow("registry", "")
+	require.NoError(t, err)
+	assert.Same(t, v2, latest, "1.10 is newer than 1.9")
+
+	pinned, err := engine.GetWorkflow("registry", "1.9")
+	require.NoError(t, err)
+	assert.Same(t, v1, pinned)
+
+	_, err = engine.GetWorkflow("registry", "3.0")
+	assert.ErrorIs(t, err, gorkflow.ErrWorkflowNotFound)
+	_, err = engine.GetWorkflow("unknown", "")
+	assert.ErrorIs(t, err, gorkflow.ErrWorkflowNotFound)
+
+	all := engine.Workflows()
+	require.Len(t, all, 2)
+	assert.Equal(t, "1.9", all[0].Version())
+	assert.Equal(t, "1.10", all[1].Version())
+}
+
+func TestCompareVersions(t *testing.T) {
+	tests := []struct {
+		a, b string
+		want int
+	}{
+		{"1.0", "1.0", 0},
+		{"1.2", "1.10", -1},
+		{"v2", "1.9.9", 1},
+		{"1.0", "1.0.1", -1},
+		{"2024-01", "2024-02", -1},
+	}
+	for _, tt := range tests {
+		assert.Equal(t, tt.want, compareVersions(tt.a, tt.b), "%s vs %s", tt.a, tt.b)
+	}
+}
+
+func TestEngine_StartWorkflowByID(t *testing.T) {
+	engine, _ := createListeningEngine(t)
+	require.NoError(t, engine.Register(versionedWorkflow(t, "1.0", gorkflow.NewStep("discover", "Discover", discoverCompanies))))
+	require.NoError(t, engine.Register(versionedWorkflow(t, "2.0", gorkflow.NewStep("discover", "Discover", discoverCompanies))))
+
+	runID, err := engine.StartWorkflowByID(context.Background(), "registry", "", json.RawMessage(`{"query":"q","limit":3}`),
+		gorkflow.WithSynchronousExecution())
+	require.NoError(t, err)
+
+	run, err := engine.GetRun(context.Background(), runID)
+	require.NoError(t, err)
+	assert.Equal(t, gorkflow.RunStatusCompleted, run.Status)
+	assert.Equal(t, "2.0", run.WorkflowVersion)
+	assert.JSONEq(t, `{"query":"q","limit":3}`, string(run.Input))
+
+	runID, err = engine.StartWorkflowByID(context.Background(), "registry", "1.0", json.RawMessage(`{"query":"q","limit":1}`),
+		gorkflow.WithSynchronousExecution())
+	require.NoError(t, err)
+	run, err = engine.GetRun(context.Background(), runID)
+	require.NoError(t, err)
+	assert.Equal(t, "1.0", run.WorkflowVersion)
+
+	_, err = engine.StartWorkflowByID(context.Background(), "missing", "", nil)
+	assert.ErrorIs(t, err, gorkflow.ErrWorkflowNotFound)
+}
+
+func TestEngine_StartWorkflowByID_Serializers(t *testing.T) {
+	for _, serializer := range []gorkflow.Serializer{gorkflow.MsgpackSerializer, gorkflow.GobSerializer} {
+		t.Run(serializer.Name(), func(t *testing.T) {
+			engine, _ := createListeningEngine(t)
+			wf, err := gorkflow.NewWorkflow("registry", "Registry").
+				WithSerializer(serializer).
+				ThenStep(gorkflow.NewStep("discover", "Discover", discoverCompanies)).
+				Build()
+			require.NoError(t, err)
+			require.NoError(t, engine.Register(wf))
+
+			runID, err := engine.StartWorkflowByID(context.Background(), "registry", "", json.RawMessage(`{"query":"q","limit":3}`),
+				gorkflow.WithSynchronousExecution())
+			require.NoError(t, err)
+
+			result, err := gorkflow.GetResult[DiscoverOutput](context.Background(), engine, runID)
+			require.NoError(t, err)
+			assert.Equal(t, 3, result.Count)
+
+			run, err := engine.GetRun(context.Background(), runID)
+			require.NoError(t, err)
+			format, err := gorkflow.PayloadFormat(run.Input)
+			require.NoError(t, err)
+			assert.Equal(t, serializer.Name(), format)
+			var input DiscoverInput
+			require.NoError(t, gorkflow.UnmarshalPayload(run.Input, &input))
+			assert.Equal(t, DiscoverInput{Query: "q", Limit: 3}, input)
+
+			_, err = engine.StartWorkflowByID(context.Background(), "registry", "", json.RawMessage(`{"limit":"three"}`))
+			var we *gorkflow.WorkflowError
+			require.ErrorAs(t, err, &we)
+			assert.Equal(t, gorkflow.ErrCodeValidation, we.Code)
+		})
+	}
+}
+
+func TestEngine_StartWorkflowByID_MultipleRoots(t *testing.T) {
+	engine, _ := createListeningEngine(t)
+
+	// Both roots receive the decoded workflow input
+	query := gorkflow.NewStep("query", "Query", func(ctx *gorkflow.StepContext, in DiscoverInput) (string, error) {
+		return in.Query, nil
+	})
+	limit := gorkflow.NewStep("limit", "Limit", func(ctx *gorkflow.StepContext, in DiscoverInput) (int, error) {
+		return in.Limit, nil
+	})
+	wf, err := gorkflow.NewWorkflow("registry", "Registry").
+		WithSerializer(gorkflow.MsgpackSerializer).
+		Step(query).
+		Step(limit).
+		Build()
+	require.NoError(t, err)
+	require.NoError(t, engine.Register(wf))
+
+	runID, err := engine.StartWorkflowByID(context.Background(), "registry", "", json.RawMessage(`{"query":"q","limit":2}`),
+		gorkflow.WithSynchronousExecution())
+	require.NoError(t, err)
+
+	output, err := engine.LoadStepOutput(context.Background(), runID, "query")
+	require.NoError(t, err)
+	var gotQuery string
+	require.NoError(t, gorkflow.UnmarshalPayload(output, &gotQuery))
+	assert.Equal(t, "q", gotQuery)
+
+	output, err = engine.LoadStepOutput(context.Background(), runID, "limit")
+	require.NoError(t, err)
+	var gotLimit int
+	require.NoError(t, gorkflow.UnmarshalPayload(output, &gotLimit))
+	assert.Equal(t, 2, gotLimit)
+}
+
+// interruptedRun persists a run that crashed after its first step completed
+func interruptedRun(t *testing.T, s gorkflow.WorkflowStore, version string) string {
+	t.Helper()
+	ctx := context.Background()
+	now := time.Now()
+	run := &gorkflow.WorkflowRun{
+		RunID:           "interrupted-" + version,
+		WorkflowID:      "registry",
+		WorkflowVersion: version,
+		Status:          gorkflow.RunStatusRunning,
+		CreatedAt:       now,
+		UpdatedAt:       now,
+		StartedAt:       &now,
+		Input:           json.RawMessage(`{"query":"q","limit":2}`),
+	}
+	require.NoError(t, s.CreateRun(ctx, run))
+
+	output := []byte(`{"companies":["a","b"],"count":2}`)
+	require.NoError(t, s.CreateStepExecution(ctx, &gorkflow.StepExecution{
+		RunID: run.RunID, StepID: "discover", Status: gorkflow.StepStatusCompleted,
+		Output: output, CreatedAt: now, UpdatedAt: now, CompletedAt: &now,
+	}))
+	require.NoError(t, s.SaveStepOutput(ctx, run.RunID, "discover", output))
+
+	// The second step was running when the process died
+	require.NoError(t, s.CreateStepExecution(ctx, &gorkflow.StepExecution{
+		RunID: run.RunID, StepID: "enrich", ExecutionIndex: 1, Status: gorkflow.StepStatusRunning,
+		CreatedAt: now, UpdatedAt: now, StartedAt: &now,
+	}))
+	return run.RunID
+}
+
+func testResumeRun(t *testing.T, s gorkflow.WorkflowStore) {
+	engine := NewEngine(s, WithLogger(zerolog.Nop()))
+
+	var discovered, enrichedV1, enrichedV2 atomic.Int32
+	discover := func(ctx *gorkflow.StepContext, input DiscoverInput) (DiscoverOutput, error) {
+		discovered.Add(1)
+		return discoverCompanies(ctx, input)
+	}
+	enrich := func(counter *atomic.Int32) gorkflow.StepHandler[DiscoverOutput, EnrichOutput] {
+		return func(ctx *gorkflow.StepContext, input DiscoverOutput) (EnrichOutput, error) {
+			counter.Add(1)
+			return EnrichOutput{Enriched: map[string]any{"count": input.Count}}, nil
+		}
+	}
+
+	require.NoError(t, engine.Register(versionedWorkflow(t, "1.0",
+		gorkflow.NewStep("discover", "Discover", discover),
+		gorkflow.NewStep("enrich", "Enrich", enrich(&enrichedV1)),
+	)))
+	require.NoError(t, engine.Register(versionedWorkflow(t, "2.0",
+		gorkflow.NewStep("discover", "Discover", discover),
+		gorkflow.NewStep("enrich", "Enrich", enrich(&enrichedV2)),
+	)))
+
+	runID := interruptedRun(t, s, "1.0")
+	require.NoError(t, engine.ResumeRun(context.Background(), runID, gorkflow.WithSynchronousExecution()))
+
+	run, err := engine.GetRun(context.Background(), runID)
+	require.NoError(t, err)
+	assert.Equal(t, gorkflow.RunStatusCompleted, run.Status)
+	assert.JSONEq(t, `{"enriched":{"count":2}}`, string(run.Output))
+
+	assert.Equal(t, int32(0), discovered.Load(), "completed step must not run again")
+	assert.Equal(t, int32(1), enrichedV1.Load(), "run stays pinned to its version")
+	assert.Equal(t, int32(0), enrichedV2.Load())
+
+	exec, err := s.GetStepExecution(context.Background(), runID, "enrich")
+	require.NoError(t, err)
+	assert.Equal(t, gorkflow.StepStatusCompleted, exec.Status)
+
+	// Terminal runs cannot be resumed
+	err = engine.ResumeRun(context.Background(), runID)
+	assert.ErrorIs(t, err, gorkflow.ErrRunNotResumable)
+}
+
+func TestEngine_ResumeRun_MemoryStore(t *testing.T) {
+	testResumeRun(t, store.NewMemoryStore())
+}
+
+func TestEngine_ResumeRun_LibSQLStore(t *testing.T) {
+	s, err := store.NewLibSQLStore("file:" + filepath.Join(t.TempDir(), "resume.db"))
+	require.NoError(t, err)
+	t.Cleanup(func() { s.Close() })
+	testResumeRun(t, s)
+}
+
+// barrierStore holds the first ListStepExecutions callers until all of them have arrived
+type barrierStore struct {
+	gorkflow.WorkflowStore
+	callers int32
+	calls   atomic.Int32
+	arrived sync.WaitGroup
+}
+
+func newBarrierStore(s gorkflow.WorkflowStore, callers int) *barrierStore {
+	b := &barrierStore{WorkflowStore: s, callers: int32(callers)}
+	b.arrived.Add(callers)
+	return b
+}
+
+func (s *barrierStore) ListStepExecutions(ctx context.Context, runID string) ([]*gorkflow.StepExecution, error) {
+	if s.calls.Add(1) <= s.callers {
+		s.arrived.Done()
+		s.arrived.Wait()
+	}
+	return s.WorkflowStore.ListStepExecutions(ctx, runID)
+}
+
+func TestEngine_ResumeRun_Concurrent(t *testing.T) {
+	const callers = 8
+	s := store.NewMemoryStore()
+	engine := NewEngine(newBarrierStore(s, callers), WithLogger(zerolog.Nop()))
+
+	var enriched atomic.Int32
+	release := make(chan struct{})
+	enrich := func(ctx *gorkflow.StepContext, input DiscoverOutput) (EnrichOutput, error) {
+		enriched.Add(1)
+		<-release
+		return EnrichOutput{Enriched: map[string]any{"count": input.Count}}, nil
+	}
+	require.NoError(t, engine.Register(versionedWorkflow(t, "1.0",
+		gorkflow.NewStep("discover", "Discover", discoverCompanies),
+		gorkflow.NewStep("enrich", "Enrich", enrich),
+	)))
+
+	runID := interruptedRun(t, s, "1.0")
+	ctx := context.Background()
+
+	// Every caller passes the early activeRuns check before any of them launches
+	errs := make(chan error, callers)
+	var wg sync.WaitGroup
+	for i := 0; i < callers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			errs <- engine.ResumeRun(ctx, runID)
+		}()
+	}
+	wg.Wait()
+	close(errs)
+	close(release)
+
+	var resumed int
+	for err := range errs {
+		if err == nil {
+			resumed++
+			continue
+		}
+		assert.ErrorIs(t, err, gorkflow.ErrRunNotResumable)
+	}
+	assert.Equal(t, 1, resumed, "only one caller may resume the run")
+
+	run, err := engine.WaitForRun(ctx, runID)
+	require.NoError(t, err)
+	assert.Equal(t, gorkflow.RunStatusCompleted, run.Status)
+	assert.Equal(t, int32(1), enriched.Load())
+}
+
+func TestEngine_ResumeRun_VersionNotRegistered(t *testing.T) {
+	s := store.NewMemoryStore()
+	engine := NewEngine(s, WithLogger(zerolog.Nop()))
+	require.NoError(t, engine.Register(versionedWorkflow(t, "2.0", gorkflow.NewStep("discover", "Discover", discoverCompanies))))
+
+	runID := interruptedRun(t, s, "1.0")
+	err := engine.ResumeRun(context.Background(), runID)
+	assert.ErrorIs(t, err, gorkflow.ErrWorkflowNotFound)
+}
+
+func TestEngine_RetryRun(t *testing.T) {
+	s := store.NewMemoryStore()
+	engine := NewEngine(s, WithLogger(zerolog.Nop()))
+
+	var discovered, enriched atomic.Int32
+	discover := func(ctx *gorkflow.StepContext, input DiscoverInput) (DiscoverOutput, error) {
+		discovered.Add(1)
+		return discoverCompanies(ctx, input)
+	}
+	enrich := func(ctx *gorkflow.StepContext, input DiscoverOutput) (EnrichOutput, error) {
+		if enriched.Add(1) == 1 {
+			return EnrichOutput{}, errors.New("enrichment API down")
+		}
+		return EnrichOutput{Enriched: map[string]any{"count": input.Count}}, nil
+	}
+	require.NoError(t, engine.Register(versionedWorkflow(t, "1.0",
+		gorkflow.NewStep("discover", "Discover", discover),
+		gorkflow.NewStep("enrich", "Enrich", enrich, gorkflow.WithRetries(0)),
+	)))
+
+	ctx := context.Background()
+	runID, err := engine.StartWorkflowByID(ctx, "registry", "", json.RawMessage(`{"query":"q","limit":3}`), gorkflow.WithSynchronousExecution())
+	require.Error(t, err)
+
+	// Only finished runs can be retried
+	require.NoError(t, engine.RetryRun(ctx, runID, gorkflow.WithSynchronousExecution()))
+	err = engine.RetryRun(ctx, runID)
+	assert.ErrorIs(t, err, gorkflow.ErrRunNotRetryable)
+
+	run, err := engine.GetRun(ctx, runID)
+	require.NoError(t, err)
+	assert.Equal(t, gorkflow.RunStatusCompleted, run.Status)
+	assert.Nil(t, run.Error)
+	assert.Equal(t, int32(1), discovered.Load(), "completed step must not run again")
+	assert.Equal(t, int32(2), enriched.Load())
+}
+
+// failingListStore fails ListStepExecutions, so resumed runs cannot launch
+type failingListStore struct {
+	gorkflow.WorkflowStore
+}
+
+func (s *failingListStore) ListStepExecutions(ctx context.Context, runID string) ([]*gorkflow.StepExecution, error) {
+	return nil, errors.New("store unavailable")
+}
+
+func TestEngine_RetryRun_RestoresStatusWhenLaunchFails(t *testing.T) {
+	s := store.NewMemoryStore()
+	engine := NewEngine(&failingListStore{WorkflowStore: s}, WithLogger(zerolog.Nop()))
+	require.NoError(t, engine.Register(versionedWorkflow(t, "1.0",
+		gorkflow.NewStep("discover", "Discover", discoverCompanies),
+	)))
+
+	ctx := context.Background()
+	runID := interruptedRun(t, s, "1.0")
+	run, err := s.GetRun(ctx, runID)
+	require.NoError(t, err)
+	run.Status = gorkflow.RunStatusFailed
+	require.NoError(t, s.UpdateRun(ctx, run))
+
+	err = engine.RetryRun(ctx, runID)
+	require.Error(t, err)
+
+	run, err = s.GetRun(ctx, runID)
+	require.NoError(t, err)
+	assert.Equal(t, gorkflow.RunStatusFailed, run.Status, "a run that could not launch must not be left pending")
+}
@@ -0,0 +1,133 @@
+package engine
+
+import (
+	"context"
+	"fmt"
+	"sync/atomic"
+
+	"github.com/sicko7947/gorkflow"
+)
+
+// ResumeRun continues a pending or running run, for example after the process that
+// started it crashed. The run executes on the registered definition matching its
+// pinned WorkflowID and WorkflowVersion; steps that already completed or were
+// skipped are not executed again. Runs execute in the background unless
+// gorkflow.WithSynchronousExecution is passed.
+func (e *Engine) ResumeRun(ctx context.Context, runID string, opts ...gorkflow.StartOption) error {
+	options := &gorkflow.StartOptions{}
+	for _, opt := range opts {
+		opt(options)
+	}
+
+	run, err := e.store.GetRun(ctx, runID)
+	if err != nil {
+		return err
+	}
+	if run.Status.IsTerminal() {
+		return fmt.Errorf("run %s is %s: %w", runID, run.Status, gorkflow.ErrRunNotResumable)
+	}
+
+	if e.isActive(runID) {
+		return fmt.Errorf("run %s is already executing: %w", runID, gorkflow.ErrRunNotResumable)
+	}
+
+	wf, err := e.GetWorkflow(run.WorkflowID, run.WorkflowVersion)
+	if err != nil {
+		return fmt.Errorf("cannot resume run %s: %w", runID, err)
+	}
+
+	execs, err := e.store.ListStepExecutions(ctx, runID)
+	if err != nil {
+		return fmt.Errorf("failed to load step executions: %w", err)
+	}
+	finished := make(map[string]*gorkflow.StepExecution)
+	for _, exec := range execs {
+		if exec.Status == gorkflow.StepStatusCompleted || exec.Status == gorkflow.StepStatusSkipped {
+			finished[exec.StepID] = exec
+		}
+	}
+
+	e.logger.Info().
+		Str("run_id", runID).
+		Str("workflow_id", run.WorkflowID).
+		Str("workflow_version", run.WorkflowVersion).
+		Int("finished_steps", len(finished)).
+		Msg("Resuming workflow run")
+
+	return e.launch(ctx, wf, run, options.Synchronous, finished)
+}
+
+// RetryRun executes a failed or cancelled run again. Steps that completed or
+// were skipped keep their outputs; the others run again from their first
+// attempt. The run must belong to a registered workflow version.
+func (e *Engine) RetryRun(ctx context.Context, runID string, opts ...gorkflow.StartOption) error {
+	run, err := e.store.GetRun(ctx, runID)
+	if err != nil {
+		return err
+	}
+	if _, err := e.GetWorkflow(run.WorkflowID, run.WorkflowVersion); err != nil {
+		return fmt.Errorf("cannot retry run %s: %w", runID, err)
+	}
+	if e.isActive(runID) {
+		return fmt.Errorf("run %s is already executing: %w", runID, gorkflow.ErrRunNotRetryable)
+	}
+	if _, err := gorkflow.ResetRun(ctx, e.store, runID, e.clock.Now()); err != nil {
+		return err
+	}
+	if err := e.ResumeRun(ctx, runID, opts...); err != nil {
+		e.restoreRun(ctx, run)
+		return err
+	}
+	return nil
+}
+
+// restoreRun puts a run reset by RetryRun back in its previous state when it
+// could not be launched, so it is not left pending. Runs that were launched,
+// by this call or a concurrent one, are left alone
+func (e *Engine) restoreRun(ctx context.Context, previous *gorkflow.WorkflowRun) {
+	if e.isActive(previous.RunID) {
+		return
+	}
+	current, err := e.store.GetRun(ctx, previous.RunID)
+	if err != nil || current.Status != gorkflow.RunStatusPending {
+		return
+	}
+	previous.UpdatedAt = e.clock.Now()
+	if err := e.store.UpdateRun(ctx, previous); err != nil {
+		e.logPersistenceError(previous, "restore_run_status", err)
+	}
+}
+
+// isActive reports whether the run is executing in this engine. launch repeats
+// the check atomically, so this only lets callers fail early
+func (e *Engine) isActive(runID string) bool {
+	e.runsMu.Lock()
+	defer e.runsMu.Unlock()
+	_, active := e.activeRuns[runID]
+	return active
+}
+
+// skipFinished removes steps finished by a previous execution from level,
+// counting them as completed and carrying their output forward
+func (e *Engine) skipFinished(ctx context.Context, run *gorkflow.WorkflowRun, level []string, finished map[string]*gorkflow.StepExecution, completedSteps *int64) []string {
+	if len(finished) == 0 {
+		return level
+	}
+
+	remaining := make([]string, 0, len(level))
+	for _, stepID := range level {
+		exec, ok := finished[stepID]
+		if !ok {
+			remaining = append(remaining, stepID)
+			continue
+		}
+		if exec.Status == gorkflow.StepStatusCompleted {
+			// Not every store keeps outputs on the execution record, so read the saved output
+			if output, err := e.store.LoadStepOutput(ctx, run.RunID, stepID); err == nil {
+				run.Output = output
+			}
+		}
+		atomic.AddInt64(completedSteps, 1)
+	}
+	return remaining
+}
@@ -0,0 +1,130 @@
+package engine
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/sicko7947/gorkflow"
+)
+
+// DefaultRetentionInterval is how often the retention janitor runs when no interval is set
+const DefaultRetentionInterval = time.Hour
+
+// RetentionPolicy configures how long finished runs are kept
+type RetentionPolicy struct {
+	// Default is the retention window for workflows without their own window.
+	// Zero keeps those runs forever.
+	Default time.Duration
+
+	// Workflows overrides the retention window per workflow ID.
+	// A zero window keeps that workflow's runs forever.
+	Workflows map[string]time.Duration
+
+	// Statuses to purge; empty means the terminal statuses
+	Statuses []gorkflow.RunStatus
+
+	// Interval between janitor passes, DefaultRetentionInterval if zero
+	Interval time.Duration
+}
+
+// WithRetention starts a background janitor that purges runs older than their
+// retention window, together with their step executions, outputs and state.
+// The janitor runs once at startup and then every policy.Interval until Close.
+func WithRetention(policy RetentionPolicy) EngineOption {
+	return func(e *Engine) {
+		if policy.Interval <= 0 {
+			policy.Interval = DefaultRetentionInterval
+		}
+		e.retention = &policy
+	}
+}
+
+// EnforceRetention purges the runs that have outlived the configured retention
+// policy and returns how many were removed. It is a no-op without WithRetention.
+func (e *Engine) EnforceRetention(ctx context.Context) (int, error) {
+	if e.retention == nil {
+		return 0, nil
+	}
+	policy := e.retention
+	now := e.clock.Now()
+
+	workflowIDs := make([]string, 0, len(policy.Workflows))
+	for id := range policy.Workflows {
+		workflowIDs = append(workflowIDs, id)
+	}
+	sort.Strings(workflowIDs)
+
+	purged := 0
+	for _, id := range workflowIDs {
+		window := policy.Workflows[id]
+		if window <= 0 {
+			continue
+		}
+		n, err := e.store.PurgeRuns(ctx, gorkflow.PurgeFilter{
+			OlderThan:  now.Add(-window),
+			Statuses:   policy.Statuses,
+			WorkflowID: id,
+		})
+		purged += n
+		if err != nil {
+			return purged, err
+		}
+	}
+
+	if policy.Default > 0 {
+		n, err := e.store.PurgeRuns(ctx, gorkflow.PurgeFilter{
+			OlderThan:          now.Add(-policy.Default),
+			Statuses:           policy.Statuses,
+			ExcludeWorkflowIDs: workflowIDs,
+		})
+		purged += n
+		if err != nil {
+			return purged, err
+		}
+	}
+	return purged, nil
+}
+
+// startJanitor runs EnforceRetention in the background until Close
+func (e *Engine) startJanitor() {
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+
+	go func() {
+		defer close(done)
+		ticker := e.clock.NewTicker(e.retention.Interval)
+		defer ticker.Stop()
+
+		for {
+			e.purgeExpiredRuns(ctx)
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C():
+			}
+		}
+	}()
+
+	var once sync.Once
+	e.closers = append(e.closers, func() error {
+		once.Do(func() {
+			cancel()
+			<-done
+		})
+		return nil
+	})
+}
+
+// purgeExpiredRuns runs one janitor pass and logs the outcome
+func (e *Engine) purgeExpiredRuns(ctx context.Context) {
+	purged, err := e.EnforceRetention(ctx)
+	if err != nil && ctx.Err() == nil {
+		e.logger.Error().Err(err).Int("purged", purged).Msg("Failed to enforce run retention")
+		return
+	}
+	if purged > 0 {
+		e.logger.Info().Int("purged", purged).Msg("Purged expired workflow runs")
+	}
+}
@@ -0,0 +1,89 @@
+package engine
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/rs/zerolog"
+	"github.com/sicko7947/gorkflow"
+	"github.com/sicko7947/gorkflow/store"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func seedRun(t *testing.T, s gorkflow.WorkflowStore, runID, workflowID string, status gorkflow.RunStatus, age time.Duration) {
+	t.Helper()
+	updated := time.Now().Add(-age)
+	require.NoError(t, s.CreateRun(context.Background(), &gorkflow.WorkflowRun{
+		RunID: runID, WorkflowID: workflowID, Status: status, CreatedAt: updated, UpdatedAt: updated,
+	}))
+}
+
+func runExists(s gorkflow.WorkflowStore, runID string) bool {
+	_, err := s.GetRun(context.Background(), runID)
+	return err == nil
+}
+
+func TestEngine_EnforceRetention(t *testing.T) {
+	s := store.NewMemoryStore()
+	seedRun(t, s, "default-old", "orders", gorkflow.RunStatusCompleted, 2*time.Hour)
+	seedRun(t, s, "default-new", "orders", gorkflow.RunStatusCompleted, 10*time.Minute)
+	seedRun(t, s, "default-running", "orders", gorkflow.RunStatusRunning, 2*time.Hour)
+	seedRun(t, s, "short-old", "pings", gorkflow.RunStatusFailed, 10*time.Minute)
+	seedRun(t, s, "forever-old", "audit", gorkflow.RunStatusCompleted, 48*time.Hour)
+
+	engine := NewEngine(s, WithLogger(zerolog.Nop()), WithRetention(RetentionPolicy{
+		Default: time.Hour,
+		Workflows: map[string]time.Duration{
+			"pings": time.Minute,
+			"audit": 0,
+		},
+		Interval: time.Hour,
+	}))
+	defer engine.Close()
+
+	// The janitor runs once at startup
+	require.Eventually(t, func() bool {
+		return !runExists(s, "default-old") && !runExists(s, "short-old")
+	}, time.Second, 5*time.Millisecond)
+
+	assert.True(t, runExists(s, "default-new"), "run within retention window")
+	assert.True(t, runExists(s, "default-running"), "non-terminal runs are kept")
+	assert.True(t, runExists(s, "forever-old"), "zero window keeps runs forever")
+
+	seedRun(t, s, "short-old-2", "pings", gorkflow.RunStatusCancelled, 5*time.Minute)
+	purged, err := engine.EnforceRetention(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, 1, purged)
+}
+
+func TestEngine_EnforceRetention_Disabled(t *testing.T) {
+	s := store.NewMemoryStore()
+	seedRun(t, s, "old", "orders", gorkflow.RunStatusCompleted, 24*time.Hour)
+
+	engine := NewEngine(s, WithLogger(zerolog.Nop()))
+	purged, err := engine.EnforceRetention(context.Background())
+	require.NoError(t, err)
+	assert.Zero(t, purged)
+	assert.True(t, runExists(s, "old"))
+}
+
+func TestEngine_DeleteRun(t *testing.T) {
+	engine, _ := createListeningEngine(t)
+	wf, err := gorkflow.NewWorkflow("delete", "Delete").
+		ThenStep(gorkflow.NewStep("discover", "Discover", discoverCompanies)).
+		Build()
+	require.NoError(t, err)
+
+	runID, err := engine.StartWorkflow(context.Background(), wf, DiscoverInput{Query: "q", Limit: 1},
+		gorkflow.WithSynchronousExecution())
+	require.NoError(t, err)
+
+	require.NoError(t, engine.DeleteRun(context.Background(), runID))
+	_, err = engine.GetRun(context.Background(), runID)
+	assert.ErrorIs(t, err, gorkflow.ErrRunNotFound)
+
+	seedRun(t, engine.store, "running", "delete", gorkflow.RunStatusRunning, 0)
+	assert.Error(t, engine.DeleteRun(context.Background(), "running"), "active runs cannot be deleted")
+}
@@ -0,0 +1,35 @@
+package engine
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/sicko7947/gorkflow"
+)
+
+// Signal delivers a named payload to a pending or running run. The payload is
+// stored as JSON in the run's state, where steps read it with
+// gorkflow.ReceiveSignal; sending a signal again replaces its payload.
+func (e *Engine) Signal(ctx context.Context, runID, name string, payload any) error {
+	if name == "" {
+		return errors.New("signal name is required")
+	}
+	run, err := e.store.GetRun(ctx, runID)
+	if err != nil {
+		return fmt.Errorf("failed to get run: %w", err)
+	}
+	if run.Status.IsTerminal() {
+		return fmt.Errorf("run %s is %s: %w", runID, run.Status, gorkflow.ErrRunNotSignalable)
+	}
+
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return fmt.Errorf("failed to marshal signal payload: %w", err)
+	}
+	if err := e.store.SaveState(ctx, runID, gorkflow.SignalStateKey(name), data); err != nil {
+		return fmt.Errorf("failed to save signal: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,51 @@
+package engine
+
+import (
+	"context"
+	"testing"
+
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/sicko7947/gorkflow"
+	"github.com/sicko7947/gorkflow/store"
+)
+
+type approval struct {
+	By string `json:"by"`
+}
+
+func TestEngine_Signal(t *testing.T) {
+	s := store.NewMemoryStore()
+	engine := NewEngine(s, WithLogger(zerolog.Nop()))
+
+	enrich := func(ctx *gorkflow.StepContext, input DiscoverOutput) (EnrichOutput, error) {
+		signal, ok, err := gorkflow.ReceiveSignal[approval](ctx, "approval")
+		if err != nil || !ok {
+			return EnrichOutput{}, assert.AnError
+		}
+		return EnrichOutput{Enriched: map[string]any{"approvedBy": signal.By}}, nil
+	}
+	require.NoError(t, engine.Register(versionedWorkflow(t, "1.0",
+		gorkflow.NewStep("discover", "Discover", discoverCompanies),
+		gorkflow.NewStep("enrich", "Enrich", enrich),
+	)))
+
+	ctx := context.Background()
+	runID := interruptedRun(t, s, "1.0")
+	require.NoError(t, engine.Signal(ctx, runID, "approval", approval{By: "ada"}))
+	require.NoError(t, engine.ResumeRun(ctx, runID, gorkflow.WithSynchronousExecution()))
+
+	run, err := engine.GetRun(ctx, runID)
+	require.NoError(t, err)
+	require.Equal(t, gorkflow.RunStatusCompleted, run.Status)
+	output, err := engine.LoadStepOutput(ctx, runID, "enrich")
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"enriched":{"approvedBy":"ada"}}`, string(output))
+
+	err = engine.Signal(ctx, runID, "approval", approval{By: "bob"})
+	assert.ErrorIs(t, err, gorkflow.ErrRunNotSignalable)
+	err = engine.Signal(ctx, "missing", "approval", nil)
+	assert.ErrorIs(t, err, gorkflow.ErrRunNotFound)
+}
@@ -0,0 +1,358 @@
+package engine
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	"github.com/sicko7947/gorkflow"
+)
+
+// DefaultSubscriptionBuffer is the number of updates buffered per subscriber
+const DefaultSubscriptionBuffer = 64
+
+// WithSubscriptionBuffer sets the number of updates buffered per subscriber.
+// When a subscriber falls further behind, its oldest pending update is dropped.
+func WithSubscriptionBuffer(size int) EngineOption {
+	return func(e *Engine) {
+		if size > 0 {
+			e.subscriptionBuffer = size
+		}
+	}
+}
+
+// runState is the last known status and progress of a run
+type runState struct {
+	status   gorkflow.RunStatus
+	progress float64
+}
+
+// subscription delivers updates for one run or all runs of a workflow
+type subscription struct {
+	runID      string // set for run subscriptions
+	workflowID string // set for workflow subscriptions
+
+	mu        sync.Mutex
+	ch        chan gorkflow.RunUpdate
+	done      chan struct{} // closed together with ch
+	runs      map[string]*runState
+	replaying bool
+	pending   []gorkflow.Event // live events held back until the replay is sent
+	dropped   int
+	closed    bool
+}
+
+// matches reports whether the subscription wants events of the given run
+func (s *subscription) matches(event gorkflow.Event) bool {
+	if s.runID != "" {
+		return event.RunID == s.runID
+	}
+	return event.WorkflowID == s.workflowID
+}
+
+// deliver queues the update for an event, or holds the event back while the
+// replay is being prepared
+func (s *subscription) deliver(event gorkflow.Event) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.closed {
+		return
+	}
+	if s.replaying {
+		s.pending = append(s.pending, event)
+		return
+	}
+	s.applyLocked(event)
+}
+
+// applyLocked advances the run state for an event and sends the resulting
+// update. Run subscriptions end with the run; s.mu must be held.
+func (s *subscription) applyLocked(event gorkflow.Event) {
+	state, ok := s.runs[event.RunID]
+	if !ok {
+		state = &runState{status: gorkflow.RunStatusPending}
+		s.runs[event.RunID] = state
+	}
+	update, ok := updateFromEvent(state, event)
+	if !ok {
+		return
+	}
+	s.sendLocked(update)
+
+	if update.Status.IsTerminal() && !update.IsStepUpdate() {
+		if s.runID != "" {
+			s.closeLocked()
+		} else {
+			delete(s.runs, event.RunID)
+		}
+	}
+}
+
+// sendLocked queues an update without blocking, dropping the oldest queued
+// update when the buffer is full; s.mu must be held
+func (s *subscription) sendLocked(update gorkflow.RunUpdate) {
+	for {
+		update.Dropped = s.dropped
+		select {
+		case s.ch <- update:
+			s.dropped = 0
+			return
+		default:
+		}
+		select {
+		case <-s.ch:
+			s.dropped++
+		default:
+		}
+	}
+}
+
+// closeLocked closes the update channel once; s.mu must be held
+func (s *subscription) closeLocked() {
+	if !s.closed {
+		s.closed = true
+		close(s.ch)
+		close(s.done)
+	}
+}
+
+// finishReplay sends the replay followed by live events that arrived meanwhile
+func (s *subscription) finishReplay(replay []gorkflow.RunUpdate) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, update := range replay {
+		if !update.IsStepUpdate() {
+			s.runs[update.RunID] = &runState{status: update.Status, progress: update.Progress}
+		}
+		s.sendLocked(update)
+	}
+	s.replaying = false
+
+	pending := s.pending
+	s.pending = nil
+	for _, event := range pending {
+		if s.closed {
+			return
+		}
+		s.applyLocked(event)
+	}
+}
+
+// updateFromEvent converts a lifecycle event into a subscriber update,
+// advancing the tracked run state. Events that do not change run or step
+// status are ignored.
+func updateFromEvent(state *runState, event gorkflow.Event) (gorkflow.RunUpdate, bool) {
+	var stepStatus gorkflow.StepStatus
+
+	switch event.Type {
+	case gorkflow.EventWorkflowCreated:
+		state.status = gorkflow.RunStatusPending
+	case gorkflow.EventWorkflowStarted:
+		state.status = gorkflow.RunStatusRunning
+	case gorkflow.EventWorkflowProgress:
+		state.progress = event.Progress
+	case gorkflow.EventWorkflowCompleted:
+		state.status = gorkflow.RunStatusCompleted
+		state.progress = 1.0
+	case gorkflow.EventWorkflowFailed:
+		state.status = gorkflow.RunStatusFailed
+	case gorkflow.EventWorkflowCancelled:
+		state.status = gorkflow.RunStatusCancelled
+	case gorkflow.EventStepStarted:
+		stepStatus = gorkflow.StepStatusRunning
+	case gorkflow.EventStepRetrying:
+		stepStatus = gorkflow.StepStatusRetrying
+	case gorkflow.EventStepCompleted:
+		stepStatus = gorkflow.StepStatusCompleted
+	case gorkflow.EventStepFailed:
+		stepStatus = gorkflow.StepStatusFailed
+	case gorkflow.EventStepSkipped:
+		stepStatus = gorkflow.StepStatusSkipped
+	default:
+		return gorkflow.RunUpdate{}, false
+	}
+
+	// Step activity means the run is executing, even if its start was not observed
+	if stepStatus != "" && state.status == gorkflow.RunStatusPending {
+		state.status = gorkflow.RunStatusRunning
+	}
+
+	update := gorkflow.RunUpdate{
+		RunID:      event.RunID,
+		WorkflowID: event.WorkflowID,
+		Timestamp:  event.Timestamp,
+		Status:     state.status,
+		Progress:   state.progress,
+		StepID:     event.StepID,
+		StepName:   event.StepName,
+		StepStatus: stepStatus,
+		Attempt:    event.Attempt,
+	}
+	if event.Error != nil {
+		update.Error = event.Error.Error()
+	}
+	return update, true
+}
+
+// subscriptions is the set of active subscribers
+type subscriptions struct {
+	mu   sync.RWMutex
+	subs map[*subscription]struct{}
+}
+
+func newSubscriptions() *subscriptions {
+	return &subscriptions{subs: make(map[*subscription]struct{})}
+}
+
+// publish delivers an event to every matching subscriber
+func (s *subscriptions) publish(event gorkflow.Event) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	for sub := range s.subs {
+		if sub.matches(event) {
+			sub.deliver(event)
+		}
+	}
+}
+
+func (s *subscriptions) add(sub *subscription) {
+	s.mu.Lock()
+	s.subs[sub] = struct{}{}
+	s.mu.Unlock()
+}
+
+func (s *subscriptions) remove(sub *subscription) {
+	s.mu.Lock()
+	delete(s.subs, sub)
+	s.mu.Unlock()
+}
+
+// Subscribe streams status and progress updates of a run. The channel first
+// replays the run's current state (marked Replay) and then delivers live
+// updates from this engine until the run finishes or ctx is done, after which
+// it is closed. Slow subscribers never block the engine: when the buffer is
+// full the oldest pending update is dropped and counted in the next update.
+func (e *Engine) Subscribe(ctx context.Context, runID string) (<-chan gorkflow.RunUpdate, error) {
+	sub := e.newSubscription()
+	sub.runID = runID
+
+	// Register before reading the store so no update is missed in between
+	e.subscriptions.add(sub)
+
+	run, err := e.store.GetRun(ctx, runID)
+	if err != nil {
+		e.subscriptions.remove(sub)
+		return nil, fmt.Errorf("failed to get run: %w", err)
+	}
+	replay, err := e.replayRun(ctx, run)
+	if err != nil {
+		e.subscriptions.remove(sub)
+		return nil, err
+	}
+
+	e.startSubscription(ctx, sub, replay)
+	if run.Status.IsTerminal() {
+		sub.mu.Lock()
+		sub.closeLocked()
+		sub.mu.Unlock()
+	}
+	return sub.ch, nil
+}
+
+// SubscribeWorkflow streams status and progress updates of every run of a
+// workflow until ctx is done. The channel first replays the state of the
+// workflow's pending and running runs, then delivers live updates, including
+// runs started after subscribing.
+func (e *Engine) SubscribeWorkflow(ctx context.Context, workflowID string) (<-chan gorkflow.RunUpdate, error) {
+	sub := e.newSubscription()
+	sub.workflowID = workflowID
+	e.subscriptions.add(sub)
+
+	var replay []gorkflow.RunUpdate
+	for _, status := range []gorkflow.RunStatus{gorkflow.RunStatusPending, gorkflow.RunStatusRunning} {
+		status := status
+		runs, err := e.store.ListRuns(ctx, gorkflow.RunFilter{WorkflowID: workflowID, Status: &status})
+		if err != nil {
+			e.subscriptions.remove(sub)
+			return nil, fmt.Errorf("failed to list runs: %w", err)
+		}
+		for _, run := range runs {
+			updates, err := e.replayRun(ctx, run)
+			if err != nil {
+				e.subscriptions.remove(sub)
+				return nil, err
+			}
+			replay = append(replay, updates...)
+		}
+	}
+
+	e.startSubscription(ctx, sub, replay)
+	return sub.ch, nil
+}
+
+func (e *Engine) newSubscription() *subscription {
+	return &subscription{
+		done:      make(chan struct{}),
+		runs:      make(map[string]*runState),
+		replaying: true,
+	}
+}
+
+// startSubscription sends the replay and unregisters the subscription once it
+// is closed or ctx is done
+func (e *Engine) startSubscription(ctx context.Context, sub *subscription, replay []gorkflow.RunUpdate) {
+	// Room for the whole replay on top of the live buffer
+	sub.mu.Lock()
+	sub.ch = make(chan gorkflow.RunUpdate, e.subscriptionBuffer+len(replay))
+	sub.mu.Unlock()
+
+	sub.finishReplay(replay)
+
+	go func() {
+		select {
+		case <-ctx.Done():
+		case <-sub.done:
+		}
+		e.subscriptions.remove(sub)
+		sub.mu.Lock()
+		sub.closeLocked()
+		sub.mu.Unlock()
+	}()
+}
+
+// replayRun describes the stored state of a run as updates
+func (e *Engine) replayRun(ctx context.Context, run *gorkflow.WorkflowRun) ([]gorkflow.RunUpdate, error) {
+	execs, err := e.store.ListStepExecutions(ctx, run.RunID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list step executions: %w", err)
+	}
+
+	base := gorkflow.RunUpdate{
+		RunID:      run.RunID,
+		WorkflowID: run.WorkflowID,
+		Timestamp:  run.UpdatedAt,
+		Status:     run.Status,
+		Progress:   run.Progress,
+		Replay:     true,
+	}
+
+	updates := make([]gorkflow.RunUpdate, 0, len(execs)+1)
+	for _, exec := range execs {
+		update := base
+		update.Timestamp = exec.UpdatedAt
+		update.StepID = exec.StepID
+		update.StepStatus = exec.Status
+		update.Attempt = exec.Attempt
+		if exec.Error != nil {
+			update.Error = exec.Error.Message
+		}
+		updates = append(updates, update)
+	}
+
+	// The run-level update comes last so it reflects the latest state
+	if run.Error != nil {
+		base.Error = run.Error.Message
+	}
+	return append(updates, base), nil
+}
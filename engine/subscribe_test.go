@@ -0,0 +1,166 @@
+package engine
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/sicko7947/gorkflow"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// collectUpdates reads updates until the channel is closed or the timeout passes
+func collectUpdates(t *testing.T, updates <-chan gorkflow.RunUpdate, timeout time.Duration) []gorkflow.RunUpdate {
+	t.Helper()
+	var got []gorkflow.RunUpdate
+	deadline := time.After(timeout)
+	for {
+		select {
+		case update, ok := <-updates:
+			if !ok {
+				return got
+			}
+			got = append(got, update)
+		case <-deadline:
+			t.Fatalf("subscription not closed after %v, got %d updates", timeout, len(got))
+		}
+	}
+}
+
+func blockingWorkflow(t *testing.T, id string, release <-chan struct{}) *gorkflow.Workflow {
+	t.Helper()
+	first := gorkflow.NewStep("discover", "Discover", func(ctx *gorkflow.StepContext, input DiscoverInput) (DiscoverOutput, error) {
+		<-release
+		return discoverCompanies(ctx, input)
+	})
+	second := gorkflow.NewStep("enrich", "Enrich", func(ctx *gorkflow.StepContext, input DiscoverOutput) (EnrichOutput, error) {
+		return EnrichOutput{Enriched: map[string]any{"count": input.Count}}, nil
+	})
+	wf, err := gorkflow.NewWorkflow(id, "Blocking").ThenStep(first).ThenStep(second).Build()
+	require.NoError(t, err)
+	return wf
+}
+
+func TestEngine_Subscribe(t *testing.T) {
+	engine, _ := createListeningEngine(t)
+	release := make(chan struct{})
+	wf := blockingWorkflow(t, "subscribe", release)
+
+	runID, err := engine.StartWorkflow(context.Background(), wf, DiscoverInput{Query: "q"})
+	require.NoError(t, err)
+	require.Eventually(t, func() bool {
+		execs, _ := engine.GetStepExecutions(context.Background(), runID)
+		return len(execs) == 1
+	}, time.Second, 5*time.Millisecond)
+
+	updates, err := engine.Subscribe(context.Background(), runID)
+	require.NoError(t, err)
+	close(release)
+
+	got := collectUpdates(t, updates, 2*time.Second)
+	require.NotEmpty(t, got)
+
+	// Current state is replayed first
+	assert.True(t, got[0].Replay)
+	assert.Equal(t, "discover", got[0].StepID)
+	assert.Equal(t, gorkflow.StepStatusRunning, got[0].StepStatus)
+	assert.True(t, got[1].Replay)
+	assert.Equal(t, gorkflow.RunStatusRunning, got[1].Status)
+	assert.False(t, got[1].IsStepUpdate())
+
+	var completedSteps []string
+	for _, update := range got {
+		if update.StepStatus == gorkflow.StepStatusCompleted && !update.Replay {
+			completedSteps = append(completedSteps, update.StepID)
+		}
+	}
+	assert.Equal(t, []string{"discover", "enrich"}, completedSteps)
+
+	last := got[len(got)-1]
+	assert.Equal(t, gorkflow.RunStatusCompleted, last.Status)
+	assert.Equal(t, 1.0, last.Progress)
+	assert.False(t, last.Replay)
+}
+
+func TestEngine_Subscribe_FinishedRun(t *testing.T) {
+	engine, _ := createListeningEngine(t)
+	release := make(chan struct{})
+	close(release)
+
+	runID, err := engine.StartWorkflow(context.Background(), blockingWorkflow(t, "finished", release), DiscoverInput{},
+		gorkflow.WithSynchronousExecution())
+	require.NoError(t, err)
+
+	updates, err := engine.Subscribe(context.Background(), runID)
+	require.NoError(t, err)
+
+	got := collectUpdates(t, updates, time.Second)
+	require.Len(t, got, 3, "two steps and the run")
+	for _, update := range got {
+		assert.True(t, update.Replay)
+		assert.Equal(t, gorkflow.RunStatusCompleted, update.Status)
+	}
+
+	_, err = engine.Subscribe(context.Background(), "missing")
+	assert.ErrorIs(t, err, gorkflow.ErrRunNotFound)
+}
+
+func TestEngine_SubscribeWorkflow(t *testing.T) {
+	engine, _ := createListeningEngine(t)
+	release := make(chan struct{})
+	close(release)
+	wf := blockingWorkflow(t, "subscribe-workflow", release)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	updates, err := engine.SubscribeWorkflow(ctx, wf.ID())
+	require.NoError(t, err)
+
+	var runIDs []string
+	for i := 0; i < 2; i++ {
+		runID, err := engine.StartWorkflow(context.Background(), wf, DiscoverInput{}, gorkflow.WithSynchronousExecution())
+		require.NoError(t, err)
+		runIDs = append(runIDs, runID)
+	}
+
+	// Runs of other workflows are not delivered
+	_, err = engine.StartWorkflow(context.Background(), blockingWorkflow(t, "other", release), DiscoverInput{},
+		gorkflow.WithSynchronousExecution())
+	require.NoError(t, err)
+
+	cancel()
+	got := collectUpdates(t, updates, time.Second)
+
+	completed := map[string]bool{}
+	for _, update := range got {
+		assert.Contains(t, runIDs, update.RunID)
+		assert.Equal(t, wf.ID(), update.WorkflowID)
+		if update.Status == gorkflow.RunStatusCompleted && !update.IsStepUpdate() {
+			completed[update.RunID] = true
+		}
+	}
+	assert.Len(t, completed, 2)
+}
+
+func TestEngine_Subscribe_SlowConsumer(t *testing.T) {
+	engine, _ := createListeningEngine(t, WithSubscriptionBuffer(1))
+	release := make(chan struct{})
+	wf := blockingWorkflow(t, "slow", release)
+
+	runID, err := engine.StartWorkflow(context.Background(), wf, DiscoverInput{})
+	require.NoError(t, err)
+
+	updates, err := engine.Subscribe(context.Background(), runID)
+	require.NoError(t, err)
+
+	// Nobody reads while the run finishes; the engine must not block
+	close(release)
+	run, err := engine.WaitForRun(context.Background(), runID)
+	require.NoError(t, err)
+	assert.Equal(t, gorkflow.RunStatusCompleted, run.Status)
+
+	got := collectUpdates(t, updates, time.Second)
+	last := got[len(got)-1]
+	assert.Equal(t, gorkflow.RunStatusCompleted, last.Status, "the latest update survives")
+	assert.Positive(t, last.Dropped)
+}
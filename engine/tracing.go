@@ -0,0 +1,143 @@
+package engine
+
+import (
+	"context"
+	"time"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/trace"
+	"go.opentelemetry.io/otel/trace/noop"
+
+	"github.com/sicko7947/gorkflow"
+)
+
+// tracerName is the instrumentation scope used for all engine spans
+const tracerName = "github.com/sicko7947/gorkflow/engine"
+
+// Span attribute keys
+const (
+	AttrRunID           = attribute.Key("gorkflow.run.id")
+	AttrWorkflowID      = attribute.Key("gorkflow.workflow.id")
+	AttrWorkflowVersion = attribute.Key("gorkflow.workflow.version")
+	AttrResourceID      = attribute.Key("gorkflow.resource.id")
+	AttrStepID          = attribute.Key("gorkflow.step.id")
+	AttrStepName        = attribute.Key("gorkflow.step.name")
+	AttrStepAttempt     = attribute.Key("gorkflow.step.attempt")
+	AttrStepStatus      = attribute.Key("gorkflow.step.status")
+	AttrSkipReason      = attribute.Key("gorkflow.step.skip_reason")
+	AttrBackoffDelay    = attribute.Key("gorkflow.step.backoff_ms")
+	AttrStoreOperation  = attribute.Key("gorkflow.store.operation")
+
+	// attrTagPrefix prefixes run tags, e.g. gorkflow.tag.tenant
+	attrTagPrefix = "gorkflow.tag."
+)
+
+// traceContextPropagator serializes span contexts into WorkflowRun.TraceContext
+var traceContextPropagator = propagation.TraceContext{}
+
+// WithTracerProvider enables OpenTelemetry tracing. The engine creates a span per
+// workflow run with child spans for each step attempt, backoff wait and store call.
+func WithTracerProvider(provider trace.TracerProvider) EngineOption {
+	return func(e *Engine) {
+		e.tracer = provider.Tracer(tracerName)
+		e.tracing = true
+	}
+}
+
+// defaultTracer is used when tracing is not configured
+func defaultTracer() trace.Tracer {
+	return noop.NewTracerProvider().Tracer(tracerName)
+}
+
+// injectTraceContext captures the span context of ctx so it can be persisted with a run
+func injectTraceContext(ctx context.Context) map[string]string {
+	carrier := propagation.MapCarrier{}
+	traceContextPropagator.Inject(ctx, carrier)
+	if len(carrier) == 0 {
+		return nil
+	}
+	return carrier
+}
+
+// startRunSpan starts the run span as a child of the trace context persisted with the run,
+// so async and resumed runs link to the trace of the original StartWorkflow caller.
+func (e *Engine) startRunSpan(ctx context.Context, run *gorkflow.WorkflowRun) (context.Context, trace.Span) {
+	if len(run.TraceContext) > 0 {
+		ctx = traceContextPropagator.Extract(ctx, propagation.MapCarrier(run.TraceContext))
+	}
+
+	attrs := []attribute.KeyValue{
+		AttrRunID.String(run.RunID),
+		AttrWorkflowID.String(run.WorkflowID),
+		AttrWorkflowVersion.String(run.WorkflowVersion),
+	}
+	if run.ResourceID != "" {
+		attrs = append(attrs, AttrResourceID.String(run.ResourceID))
+	}
+	for k, v := range run.Tags {
+		attrs = append(attrs, attribute.String(attrTagPrefix+k, v))
+	}
+
+	return e.tracer.Start(ctx, "workflow "+run.WorkflowID,
+		trace.WithSpanKind(trace.SpanKindInternal),
+		trace.WithAttributes(attrs...),
+	)
+}
+
+// endRunSpan records the final run status on the span and ends it
+func endRunSpan(span trace.Span, run *gorkflow.WorkflowRun) {
+	span.SetAttributes(attribute.String("gorkflow.run.status", run.Status.String()))
+	switch run.Status {
+	case gorkflow.RunStatusCompleted:
+		span.SetStatus(codes.Ok, "")
+	case gorkflow.RunStatusFailed:
+		if run.Error != nil {
+			span.SetStatus(codes.Error, run.Error.Message)
+		} else {
+			span.SetStatus(codes.Error, "workflow failed")
+		}
+	case gorkflow.RunStatusCancelled:
+		span.SetStatus(codes.Error, "workflow cancelled")
+	}
+	span.End()
+}
+
+// startAttemptSpan starts a span covering a single step attempt
+func (e *Engine) startAttemptSpan(ctx context.Context, run *gorkflow.WorkflowRun, step gorkflow.StepExecutor, attempt int) (context.Context, trace.Span) {
+	return e.tracer.Start(ctx, "step "+step.GetID(),
+		trace.WithAttributes(
+			AttrRunID.String(run.RunID),
+			AttrStepID.String(step.GetID()),
+			AttrStepName.String(step.GetName()),
+			AttrStepAttempt.Int(attempt),
+		),
+	)
+}
+
+// endAttemptSpan records the attempt outcome on the span and ends it
+func endAttemptSpan(span trace.Span, status gorkflow.StepStatus, skipReason string, err error) {
+	span.SetAttributes(AttrStepStatus.String(status.String()))
+	if skipReason != "" {
+		span.AddEvent("step skipped", trace.WithAttributes(AttrSkipReason.String(skipReason)))
+		span.SetAttributes(AttrSkipReason.String(skipReason))
+	}
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	span.End()
+}
+
+// startBackoffSpan starts a span covering the backoff wait before a retry
+func (e *Engine) startBackoffSpan(ctx context.Context, step gorkflow.StepExecutor, attempt int, delay time.Duration) trace.Span {
+	_, span := e.tracer.Start(ctx, "backoff "+step.GetID(),
+		trace.WithAttributes(
+			AttrStepID.String(step.GetID()),
+			AttrStepAttempt.Int(attempt),
+			AttrBackoffDelay.Int64(delay.Milliseconds()),
+		),
+	)
+	return span
+}
@@ -0,0 +1,136 @@
+package engine
+
+import (
+	"context"
+
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/sicko7947/gorkflow"
+)
+
+// tracingStore wraps a WorkflowStore with a span per store call
+type tracingStore struct {
+	store  gorkflow.WorkflowStore
+	tracer trace.Tracer
+}
+
+func newTracingStore(store gorkflow.WorkflowStore, tracer trace.Tracer) gorkflow.WorkflowStore {
+	return &tracingStore{store: store, tracer: tracer}
+}
+
+// start starts a client span for the given store operation
+func (s *tracingStore) start(ctx context.Context, operation, runID string) (context.Context, trace.Span) {
+	return s.tracer.Start(ctx, "store "+operation,
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(
+			AttrStoreOperation.String(operation),
+			AttrRunID.String(runID),
+		),
+	)
+}
+
+// endStoreSpan records err on the span and ends it
+func endStoreSpan(span trace.Span, err error) {
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	span.End()
+}
+
+func (s *tracingStore) CreateRun(ctx context.Context, run *gorkflow.WorkflowRun) (err error) {
+	ctx, span := s.start(ctx, "CreateRun", run.RunID)
+	defer func() { endStoreSpan(span, err) }()
+	return s.store.CreateRun(ctx, run)
+}
+
+func (s *tracingStore) GetRun(ctx context.Context, runID string) (_ *gorkflow.WorkflowRun, err error) {
+	ctx, span := s.start(ctx, "GetRun", runID)
+	defer func() { endStoreSpan(span, err) }()
+	return s.store.GetRun(ctx, runID)
+}
+
+func (s *tracingStore) UpdateRun(ctx context.Context, run *gorkflow.WorkflowRun) (err error) {
+	ctx, span := s.start(ctx, "UpdateRun", run.RunID)
+	defer func() { endStoreSpan(span, err) }()
+	return s.store.UpdateRun(ctx, run)
+}
+
+func (s *tracingStore) ListRuns(ctx context.Context, filter gorkflow.RunFilter) (_ []*gorkflow.WorkflowRun, err error) {
+	ctx, span := s.start(ctx, "ListRuns", "")
+	defer func() { endStoreSpan(span, err) }()
+	return s.store.ListRuns(ctx, filter)
+}
+
+func (s *tracingStore) CreateStepExecution(ctx context.Context, exec *gorkflow.StepExecution) (err error) {
+	ctx, span := s.start(ctx, "CreateStepExecution", exec.RunID)
+	defer func() { endStoreSpan(span, err) }()
+	return s.store.CreateStepExecution(ctx, exec)
+}
+
+func (s *tracingStore) GetStepExecution(ctx context.Context, runID, stepID string) (_ *gorkflow.StepExecution, err error) {
+	ctx, span := s.start(ctx, "GetStepExecution", runID)
+	defer func() { endStoreSpan(span, err) }()
+	return s.store.GetStepExecution(ctx, runID, stepID)
+}
+
+func (s *tracingStore) UpdateStepExecution(ctx context.Context, exec *gorkflow.StepExecution) (err error) {
+	ctx, span := s.start(ctx, "UpdateStepExecution", exec.RunID)
+	defer func() { endStoreSpan(span, err) }()
+	return s.store.UpdateStepExecution(ctx, exec)
+}
+
+func (s *tracingStore) ListStepExecutions(ctx context.Context, runID string) (_ []*gorkflow.StepExecution, err error) {
+	ctx, span := s.start(ctx, "ListStepExecutions", runID)
+	defer func() { endStoreSpan(span, err) }()
+	return s.store.ListStepExecutions(ctx, runID)
+}
+
+func (s *tracingStore) SaveStepOutput(ctx context.Context, runID, stepID string, output []byte) (err error) {
+	ctx, span := s.start(ctx, "SaveStepOutput", runID)
+	defer func() { endStoreSpan(span, err) }()
+	return s.store.SaveStepOutput(ctx, runID, stepID, output)
+}
+
+func (s *tracingStore) LoadStepOutput(ctx context.Context, runID, stepID string) (_ []byte, err error) {
+	ctx, span := s.start(ctx, "LoadStepOutput", runID)
+	defer func() { endStoreSpan(span, err) }()
+	return s.store.LoadStepOutput(ctx, runID, stepID)
+}
+
+func (s *tracingStore) SaveState(ctx context.Context, runID, key string, value []byte) (err error) {
+	ctx, span := s.start(ctx, "SaveState", runID)
+	defer func() { endStoreSpan(span, err) }()
+	return s.store.SaveState(ctx, runID, key, value)
+}
+
+func (s *tracingStore) LoadState(ctx context.Context, runID, key string) (_ []byte, err error) {
+	ctx, span := s.start(ctx, "LoadState", runID)
+	defer func() { endStoreSpan(span, err) }()
+	return s.store.LoadState(ctx, runID, key)
+}
+
+func (s *tracingStore) DeleteState(ctx context.Context, runID, key string) (err error) {
+	ctx, span := s.start(ctx, "DeleteState", runID)
+	defer func() { endStoreSpan(span, err) }()
+	return s.store.DeleteState(ctx, runID, key)
+}
+
+func (s *tracingStore) GetAllState(ctx context.Context, runID string) (_ map[string][]byte, err error) {
+	ctx, span := s.start(ctx, "GetAllState", runID)
+	defer func() { endStoreSpan(span, err) }()
+	return s.store.GetAllState(ctx, runID)
+}
+
+func (s *tracingStore) DeleteRun(ctx context.Context, runID string) (err error) {
+	ctx, span := s.start(ctx, "DeleteRun", runID)
+	defer func() { endStoreSpan(span, err) }()
+	return s.store.DeleteRun(ctx, runID)
+}
+
+func (s *tracingStore) PurgeRuns(ctx context.Context, filter gorkflow.PurgeFilter) (_ int, err error) {
+	ctx, span := s.start(ctx, "PurgeRuns", "")
+	defer func() { endStoreSpan(span, err) }()
+	return s.store.PurgeRuns(ctx, filter)
+}
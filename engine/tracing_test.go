@@ -0,0 +1,159 @@
+package engine
+
+import (
+	"context"
+	"errors"
+	"os"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+
+	"github.com/sicko7947/gorkflow"
+	"github.com/sicko7947/gorkflow/store"
+)
+
+func createTracingEngine(t *testing.T) (*Engine, *tracetest.InMemoryExporter, *sdktrace.TracerProvider) {
+	t.Helper()
+	exporter := tracetest.NewInMemoryExporter()
+	provider := sdktrace.NewTracerProvider(sdktrace.WithSyncer(exporter))
+	t.Cleanup(func() { provider.Shutdown(context.Background()) })
+
+	logger := zerolog.New(os.Stdout).With().Timestamp().Logger()
+	eng := NewEngine(store.NewMemoryStore(), WithLogger(logger), WithTracerProvider(provider))
+	return eng, exporter, provider
+}
+
+func spansNamed(spans tracetest.SpanStubs, name string) tracetest.SpanStubs {
+	var found tracetest.SpanStubs
+	for _, span := range spans {
+		if span.Name == name {
+			found = append(found, span)
+		}
+	}
+	return found
+}
+
+func spanAttr(span tracetest.SpanStub, key attribute.Key) attribute.Value {
+	for _, kv := range span.Attributes {
+		if kv.Key == key {
+			return kv.Value
+		}
+	}
+	return attribute.Value{}
+}
+
+func TestEngine_Tracing_AsyncRunIsChildOfCallerSpan(t *testing.T) {
+	eng, exporter, provider := createTracingEngine(t)
+
+	var attempts int32
+	flaky := gorkflow.NewStep("flaky", "Flaky",
+		func(ctx *gorkflow.StepContext, input DiscoverInput) (DiscoverOutput, error) {
+			if atomic.AddInt32(&attempts, 1) == 1 {
+				return DiscoverOutput{}, errors.New("transient")
+			}
+			return DiscoverOutput{Count: 1}, nil
+		},
+		gorkflow.WithRetries(1),
+		gorkflow.WithRetryDelay(10*time.Millisecond),
+	)
+
+	wf, err := gorkflow.NewWorkflow("traced", "Traced").
+		WithVersion("3").
+		ThenStep(flaky).
+		Build()
+	require.NoError(t, err)
+
+	ctx, parent := provider.Tracer("test").Start(context.Background(), "http request")
+	runID, err := eng.StartWorkflow(ctx, wf, DiscoverInput{},
+		gorkflow.WithResourceID("res-9"),
+		gorkflow.WithTags(map[string]string{"tenant": "acme"}),
+	)
+	require.NoError(t, err)
+	parent.End()
+
+	run := waitForCompletion(t, eng, runID, 5*time.Second)
+	require.Equal(t, gorkflow.RunStatusCompleted, run.Status)
+	assert.NotEmpty(t, run.TraceContext["traceparent"])
+
+	require.Eventually(t, func() bool {
+		return len(spansNamed(exporter.GetSpans(), "workflow traced")) == 1
+	}, 2*time.Second, 10*time.Millisecond)
+	spans := exporter.GetSpans()
+
+	runSpan := spansNamed(spans, "workflow traced")[0]
+	assert.Equal(t, parent.SpanContext().TraceID(), runSpan.SpanContext.TraceID())
+	assert.Equal(t, parent.SpanContext().SpanID(), runSpan.Parent.SpanID())
+	assert.Equal(t, codes.Ok, runSpan.Status.Code)
+	assert.Equal(t, runID, spanAttr(runSpan, AttrRunID).AsString())
+	assert.Equal(t, "traced", spanAttr(runSpan, AttrWorkflowID).AsString())
+	assert.Equal(t, "3", spanAttr(runSpan, AttrWorkflowVersion).AsString())
+	assert.Equal(t, "res-9", spanAttr(runSpan, AttrResourceID).AsString())
+	assert.Equal(t, "acme", spanAttr(runSpan, attribute.Key("gorkflow.tag.tenant")).AsString())
+
+	attemptSpans := spansNamed(spans, "step flaky")
+	require.Len(t, attemptSpans, 2)
+	for i, span := range attemptSpans {
+		assert.Equal(t, runSpan.SpanContext.SpanID(), span.Parent.SpanID())
+		assert.Equal(t, int64(i), spanAttr(span, AttrStepAttempt).AsInt64())
+	}
+	assert.Equal(t, codes.Error, attemptSpans[0].Status.Code)
+	assert.Equal(t, "COMPLETED", spanAttr(attemptSpans[1], AttrStepStatus).AsString())
+
+	backoff := spansNamed(spans, "backoff flaky")
+	require.Len(t, backoff, 1)
+	assert.Equal(t, runSpan.SpanContext.SpanID(), backoff[0].Parent.SpanID())
+
+	assert.NotEmpty(t, spansNamed(spans, "store SaveStepOutput"))
+	assert.NotEmpty(t, spansNamed(spans, "store UpdateRun"))
+}
+
+func TestEngine_Tracing_SkipDecisionRecorded(t *testing.T) {
+	eng, exporter, _ := createTracingEngine(t)
+
+	wf, err := gorkflow.NewWorkflow("traced_skip", "Traced Skip").
+		ThenStepIf(gorkflow.NewStep("maybe", "Maybe", discoverCompanies),
+			func(ctx *gorkflow.StepContext) (bool, error) { return false, nil }, nil).
+		Build()
+	require.NoError(t, err)
+
+	_, err = eng.StartWorkflow(context.Background(), wf, DiscoverInput{}, gorkflow.WithSynchronousExecution())
+	require.NoError(t, err)
+
+	attemptSpans := spansNamed(exporter.GetSpans(), "step maybe")
+	require.Len(t, attemptSpans, 1)
+	assert.Equal(t, "condition_not_met", spanAttr(attemptSpans[0], AttrSkipReason).AsString())
+	require.Len(t, attemptSpans[0].Events, 1)
+	assert.Equal(t, "step skipped", attemptSpans[0].Events[0].Name)
+}
+
+func TestEngine_Tracing_StepContextCarriesAttemptSpan(t *testing.T) {
+	eng, exporter, provider := createTracingEngine(t)
+
+	step := gorkflow.NewStep("inner", "Inner",
+		func(ctx *gorkflow.StepContext, input DiscoverInput) (DiscoverOutput, error) {
+			_, span := provider.Tracer("handler").Start(ctx, "handler work")
+			span.End()
+			return DiscoverOutput{}, nil
+		},
+	)
+	wf, err := gorkflow.NewWorkflow("traced_inner", "Traced Inner").ThenStep(step).Build()
+	require.NoError(t, err)
+
+	_, err = eng.StartWorkflow(context.Background(), wf, DiscoverInput{}, gorkflow.WithSynchronousExecution())
+	require.NoError(t, err)
+
+	spans := exporter.GetSpans()
+	attempt := spansNamed(spans, "step inner")
+	handler := spansNamed(spans, "handler work")
+	require.Len(t, attempt, 1)
+	require.Len(t, handler, 1)
+	assert.Equal(t, attempt[0].SpanContext.SpanID(), handler[0].Parent.SpanID())
+}
@@ -0,0 +1,100 @@
+package engine
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/sicko7947/gorkflow"
+)
+
+// DefaultWaitPollInterval is how often WaitForRun checks the store for runs
+// that finish outside this engine
+const DefaultWaitPollInterval = time.Second
+
+// WithWaitPollInterval sets how often WaitForRun polls the store. Runs executed
+// by this engine wake their waiters immediately; polling only matters for runs
+// executed by another engine sharing the store.
+func WithWaitPollInterval(interval time.Duration) EngineOption {
+	return func(e *Engine) {
+		if interval > 0 {
+			e.waitPollInterval = interval
+		}
+	}
+}
+
+// runWaiters tracks callers waiting for runs to finish
+type runWaiters struct {
+	mu      sync.Mutex
+	waiting map[string][]chan struct{}
+}
+
+func newRunWaiters() *runWaiters {
+	return &runWaiters{waiting: make(map[string][]chan struct{})}
+}
+
+// add registers a waiter for runID; the channel is closed when the run finishes
+func (w *runWaiters) add(runID string) chan struct{} {
+	ch := make(chan struct{})
+	w.mu.Lock()
+	w.waiting[runID] = append(w.waiting[runID], ch)
+	w.mu.Unlock()
+	return ch
+}
+
+// remove unregisters a waiter that stopped waiting
+func (w *runWaiters) remove(runID string, ch chan struct{}) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	chans := w.waiting[runID]
+	for i, c := range chans {
+		if c == ch {
+			chans = append(chans[:i], chans[i+1:]...)
+			break
+		}
+	}
+	if len(chans) == 0 {
+		delete(w.waiting, runID)
+	} else {
+		w.waiting[runID] = chans
+	}
+}
+
+// notify wakes every waiter of runID
+func (w *runWaiters) notify(runID string) {
+	w.mu.Lock()
+	chans := w.waiting[runID]
+	delete(w.waiting, runID)
+	w.mu.Unlock()
+	for _, ch := range chans {
+		close(ch)
+	}
+}
+
+// WaitForRun blocks until the run reaches a terminal status and returns it.
+// Runs executed by this engine are reported as soon as they finish; runs
+// executed elsewhere are detected by polling the store.
+func (e *Engine) WaitForRun(ctx context.Context, runID string) (*gorkflow.WorkflowRun, error) {
+	ticker := e.clock.NewTicker(e.waitPollInterval)
+	defer ticker.Stop()
+
+	for {
+		// Register before reading so a run finishing in between is not missed
+		done := e.waiters.add(runID)
+
+		run, err := e.store.GetRun(ctx, runID)
+		if err != nil || run.Status.IsTerminal() {
+			e.waiters.remove(runID, done)
+			return run, err
+		}
+
+		select {
+		case <-ctx.Done():
+			e.waiters.remove(runID, done)
+			return nil, ctx.Err()
+		case <-done:
+		case <-ticker.C():
+			e.waiters.remove(runID, done)
+		}
+	}
+}
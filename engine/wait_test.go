@@ -0,0 +1,123 @@
+package engine
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/rs/zerolog"
+	"github.com/sicko7947/gorkflow"
+	"github.com/sicko7947/gorkflow/store"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEngine_WaitForRun(t *testing.T) {
+	engine, _ := createListeningEngine(t)
+
+	release := make(chan struct{})
+	step := gorkflow.NewStep("discover", "Discover", func(ctx *gorkflow.StepContext, input DiscoverInput) (DiscoverOutput, error) {
+		<-release
+		return discoverCompanies(ctx, input)
+	})
+	wf, err := gorkflow.NewWorkflow("wait", "Wait").ThenStep(step).Build()
+	require.NoError(t, err)
+
+	runID, err := engine.StartWorkflow(context.Background(), wf, DiscoverInput{Query: "q", Limit: 2})
+	require.NoError(t, err)
+
+	go func() {
+		time.Sleep(20 * time.Millisecond)
+		close(release)
+	}()
+
+	start := time.Now()
+	run, err := engine.WaitForRun(context.Background(), runID)
+	require.NoError(t, err)
+	assert.Equal(t, gorkflow.RunStatusCompleted, run.Status)
+	assert.Less(t, time.Since(start), DefaultWaitPollInterval, "in-process runs should not wait for polling")
+
+	result, err := gorkflow.GetResult[DiscoverOutput](context.Background(), engine, runID)
+	require.NoError(t, err)
+	assert.Equal(t, 3, result.Count)
+}
+
+func TestEngine_WaitForRun_Failed(t *testing.T) {
+	engine, _ := createListeningEngine(t)
+
+	step := gorkflow.NewStep("fail", "Fail", func(ctx *gorkflow.StepContext, input DiscoverInput) (DiscoverOutput, error) {
+		return DiscoverOutput{}, assert.AnError
+	}, gorkflow.WithRetries(0))
+	wf, err := gorkflow.NewWorkflow("wait-fail", "Wait Fail").ThenStep(step).Build()
+	require.NoError(t, err)
+
+	runID, err := engine.StartWorkflow(context.Background(), wf, DiscoverInput{})
+	require.NoError(t, err)
+
+	_, err = gorkflow.GetResult[DiscoverOutput](context.Background(), engine, runID)
+	var wfErr *gorkflow.WorkflowError
+	require.ErrorAs(t, err, &wfErr)
+	assert.Equal(t, gorkflow.ErrCodeExecutionFailed, wfErr.Code)
+}
+
+func TestEngine_WaitForRun_Cancelled(t *testing.T) {
+	engine, _ := createListeningEngine(t)
+
+	step := gorkflow.NewStep("block", "Block", func(ctx *gorkflow.StepContext, input DiscoverInput) (DiscoverOutput, error) {
+		<-ctx.Done()
+		return DiscoverOutput{}, ctx.Err()
+	})
+	wf, err := gorkflow.NewWorkflow("wait-cancel", "Wait Cancel").ThenStep(step).Build()
+	require.NoError(t, err)
+
+	runID, err := engine.StartWorkflow(context.Background(), wf, DiscoverInput{})
+	require.NoError(t, err)
+
+	go func() {
+		time.Sleep(20 * time.Millisecond)
+		engine.Cancel(context.Background(), runID)
+	}()
+
+	run, err := engine.WaitForRun(context.Background(), runID)
+	require.NoError(t, err)
+	assert.Equal(t, gorkflow.RunStatusCancelled, run.Status)
+}
+
+func TestEngine_WaitForRun_PollsStore(t *testing.T) {
+	s := store.NewMemoryStore()
+	engine := NewEngine(s, WithLogger(zerolog.Nop()), WithWaitPollInterval(5*time.Millisecond))
+
+	// A run executed by another process sharing the store
+	now := time.Now()
+	run := &gorkflow.WorkflowRun{RunID: "remote", WorkflowID: "wf", Status: gorkflow.RunStatusRunning, CreatedAt: now, UpdatedAt: now}
+	require.NoError(t, s.CreateRun(context.Background(), run))
+
+	go func() {
+		time.Sleep(20 * time.Millisecond)
+		run.Status = gorkflow.RunStatusCompleted
+		s.UpdateRun(context.Background(), run)
+	}()
+
+	got, err := engine.WaitForRun(context.Background(), "remote")
+	require.NoError(t, err)
+	assert.Equal(t, gorkflow.RunStatusCompleted, got.Status)
+}
+
+func TestEngine_WaitForRun_ContextDone(t *testing.T) {
+	s := store.NewMemoryStore()
+	engine := NewEngine(s, WithLogger(zerolog.Nop()))
+
+	now := time.Now()
+	require.NoError(t, s.CreateRun(context.Background(), &gorkflow.WorkflowRun{
+		RunID: "stuck", WorkflowID: "wf", Status: gorkflow.RunStatusRunning, CreatedAt: now, UpdatedAt: now,
+	}))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	_, err := engine.WaitForRun(ctx, "stuck")
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+	assert.Empty(t, engine.waiters.waiting, "waiters are removed when callers give up")
+
+	_, err = engine.WaitForRun(context.Background(), "missing")
+	assert.ErrorIs(t, err, gorkflow.ErrRunNotFound)
+}
@@ -16,6 +16,32 @@ var (
 	ErrStepExecutionNotFound = errors.New("step execution not found")
 	ErrStepOutputNotFound    = errors.New("step output not found")
 	ErrStateNotFound         = errors.New("state not found")
+
+	// Registry sentinel errors
+	ErrWorkflowNotFound          = errors.New("workflow not registered")
+	ErrWorkflowAlreadyRegistered = errors.New("workflow version already registered")
+	ErrRunNotResumable           = errors.New("run cannot be resumed")
+	ErrRunNotRetryable           = errors.New("run cannot be retried")
+	ErrRunNotSignalable          = errors.New("run cannot be signalled")
+	ErrRunNotCancellable         = errors.New("run cannot be cancelled")
+
+	// ErrUnknownDependency indicates a step declared with WorkflowBuilder.Step depends on a step that was not added
+	ErrUnknownDependency = errors.New("dependency on unknown step")
+
+	// ErrLimiterNotFound indicates a step references a limiter that is not registered
+	ErrLimiterNotFound = errors.New("limiter not registered")
+
+	// ErrCircuitOpen indicates an attempt was rejected by an open circuit breaker
+	ErrCircuitOpen = errors.New("circuit breaker is open")
+
+	// ErrCircuitBreakerNotFound indicates a step references a circuit breaker that is not registered
+	ErrCircuitBreakerNotFound = errors.New("circuit breaker not registered")
+
+	// ErrCacheMiss indicates no cached step output exists for a key
+	ErrCacheMiss = errors.New("cache miss")
+
+	// ErrBlobNotFound indicates no blob exists for a key
+	ErrBlobNotFound = errors.New("blob not found")
 )
 
 // Error codes
@@ -28,6 +54,8 @@ const (
 	ErrCodeCancelled       = "CANCELLED"
 	ErrCodePanic           = "PANIC"
 	ErrCodeInternalError   = "INTERNAL_ERROR"
+	ErrCodeCircuitOpen     = "CIRCUIT_OPEN"
+	ErrCodeConflict        = "CONFLICT"
 )
 
 // WorkflowError represents an error during workflow execution
@@ -0,0 +1,120 @@
+package gorkflow
+
+import (
+	"sync"
+	"time"
+)
+
+// Event is a structured lifecycle notification emitted by the engine.
+// Type is one of the Event* constants declared in logging.go; fields that
+// do not apply to a given event type are left at their zero value.
+type Event struct {
+	Type      string
+	Timestamp time.Time
+
+	// Run identity
+	RunID           string
+	WorkflowID      string
+	WorkflowVersion string
+	ResourceID      string
+
+	// Step identity (step-level events only)
+	StepID   string
+	StepName string
+	Attempt  int
+
+	// Measurements
+	Duration   time.Duration // step or workflow duration
+	Delay      time.Duration // backoff delay before a retry
+	Progress   float64       // 0.0 to 1.0
+	OutputSize int           // output size in bytes
+
+	// Failure details
+	Error     error
+	Reason    string // skip reason
+	Operation string // failed persistence operation
+
+	// Circuit breaker events only
+	Circuit       string       // breaker name
+	PreviousState CircuitState // state before the transition
+}
+
+// IsStepEvent returns true if the event describes a single step
+func (e Event) IsStepEvent() bool {
+	return e.StepID != ""
+}
+
+// EventListener receives lifecycle events from the engine.
+// OnEvent is called synchronously on the executing goroutine, possibly from
+// several goroutines at once when steps run in parallel. Slow listeners should
+// be wrapped with NewAsyncListener.
+type EventListener interface {
+	OnEvent(event Event)
+}
+
+// EventListenerFunc adapts a plain function to the EventListener interface
+type EventListenerFunc func(event Event)
+
+// OnEvent implements EventListener
+func (f EventListenerFunc) OnEvent(event Event) {
+	f(event)
+}
+
+// AsyncListener delivers events to a wrapped listener from a background
+// goroutine through a buffered queue. Events are delivered in emission order.
+// When the buffer is full OnEvent blocks until there is room, so no event is dropped.
+type AsyncListener struct {
+	listener EventListener
+	events   chan Event
+	done     chan struct{}
+	closeMu  sync.RWMutex
+	closed   bool
+}
+
+// NewAsyncListener starts a dispatcher goroutine delivering to listener.
+// bufferSize values below 1 default to 256.
+func NewAsyncListener(listener EventListener, bufferSize int) *AsyncListener {
+	if bufferSize < 1 {
+		bufferSize = 256
+	}
+	a := &AsyncListener{
+		listener: listener,
+		events:   make(chan Event, bufferSize),
+		done:     make(chan struct{}),
+	}
+	go a.dispatch()
+	return a
+}
+
+func (a *AsyncListener) dispatch() {
+	defer close(a.done)
+	for event := range a.events {
+		a.listener.OnEvent(event)
+	}
+}
+
+// OnEvent queues the event for delivery. Events emitted after Close are discarded.
+func (a *AsyncListener) OnEvent(event Event) {
+	a.closeMu.RLock()
+	defer a.closeMu.RUnlock()
+	if a.closed {
+		return
+	}
+	a.events <- event
+}
+
+// Close stops accepting events and waits until all queued events are delivered
+func (a *AsyncListener) Close() error {
+	a.closeMu.Lock()
+	if a.closed {
+		a.closeMu.Unlock()
+		<-a.done
+		return nil
+	}
+	a.closed = true
+	close(a.events)
+	a.closeMu.Unlock()
+
+	<-a.done
+	return nil
+}
@@ -0,0 +1,43 @@
+package gorkflow
+
+import (
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAsyncListener_DeliversInOrder(t *testing.T) {
+	var mu sync.Mutex
+	var received []string
+	async := NewAsyncListener(EventListenerFunc(func(event Event) {
+		mu.Lock()
+		received = append(received, event.RunID)
+		mu.Unlock()
+	}), 2)
+
+	for _, id := range []string{"a", "b", "c", "d"} {
+		async.OnEvent(Event{Type: EventWorkflowCreated, RunID: id})
+	}
+	require.NoError(t, async.Close())
+
+	assert.Equal(t, []string{"a", "b", "c", "d"}, received)
+}
+
+func TestAsyncListener_CloseIsIdempotent(t *testing.T) {
+	count := 0
+	async := NewAsyncListener(EventListenerFunc(func(event Event) { count++ }), 0)
+
+	require.NoError(t, async.Close())
+	require.NoError(t, async.Close())
+
+	// Events after close are discarded
+	async.OnEvent(Event{Type: EventWorkflowCreated})
+	assert.Equal(t, 0, count)
+}
+
+func TestEvent_IsStepEvent(t *testing.T) {
+	assert.False(t, Event{Type: EventWorkflowStarted, RunID: "r"}.IsStepEvent())
+	assert.True(t, Event{Type: EventStepStarted, RunID: "r", StepID: "s"}.IsStepEvent())
+}
@@ -0,0 +1,228 @@
+package gorkflowtest
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/sicko7947/gorkflow"
+)
+
+// FakeClock is a gorkflow.Clock whose time only moves when told to. Sleeps,
+// tickers and timeouts fire as Advance moves the clock past their deadlines.
+//
+// With auto-advance enabled, every sleep started with After moves the clock
+// straight to its deadline, so retry backoff completes instantly. Timeouts and
+// tickers never advance the clock themselves, but fire when a sleep passes them.
+type FakeClock struct {
+	mu          sync.Mutex
+	cond        *sync.Cond // signalled when timers are added
+	now         time.Time
+	timers      []*fakeTimer
+	autoAdvance bool
+}
+
+// fakeTimer is a pending sleep, ticker or timeout
+type fakeTimer struct {
+	deadline time.Time
+	period   time.Duration // non-zero for tickers
+	fire     func(now time.Time)
+}
+
+var _ gorkflow.Clock = (*FakeClock)(nil)
+
+// NewFakeClock creates a fake clock set to start
+func NewFakeClock(start time.Time) *FakeClock {
+	c := &FakeClock{now: start}
+	c.cond = sync.NewCond(&c.mu)
+	return c
+}
+
+// SetAutoAdvance enables or disables moving the clock to the deadline of each sleep
+func (c *FakeClock) SetAutoAdvance(enabled bool) {
+	c.mu.Lock()
+	c.autoAdvance = enabled
+	c.mu.Unlock()
+}
+
+// Now returns the clock's current time
+func (c *FakeClock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.now
+}
+
+// Since returns the clock time elapsed since t
+func (c *FakeClock) Since(t time.Time) time.Duration {
+	return c.Now().Sub(t)
+}
+
+// After returns a channel receiving the clock time once d has elapsed
+func (c *FakeClock) After(d time.Duration) <-chan time.Time {
+	ch := make(chan time.Time, 1)
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if d <= 0 {
+		ch <- c.now
+		return ch
+	}
+	deadline := c.now.Add(d)
+	c.addLocked(&fakeTimer{deadline: deadline, fire: func(now time.Time) { ch <- now }})
+	if c.autoAdvance {
+		c.advanceLocked(deadline)
+	}
+	return ch
+}
+
+// NewTicker returns a ticker firing every d of clock time. Ticks are dropped
+// while the previous one has not been received, like time.Ticker.
+func (c *FakeClock) NewTicker(d time.Duration) gorkflow.Ticker {
+	if d <= 0 {
+		panic("gorkflowtest: non-positive interval for NewTicker")
+	}
+	t := &fakeTicker{clock: c, ch: make(chan time.Time, 1)}
+	t.timer = &fakeTimer{period: d, fire: func(now time.Time) {
+		select {
+		case t.ch <- now:
+		default:
+		}
+	}}
+
+	c.mu.Lock()
+	t.timer.deadline = c.now.Add(d)
+	c.addLocked(t.timer)
+	c.mu.Unlock()
+	return t
+}
+
+// WithTimeout returns a copy of parent cancelled with context.DeadlineExceeded
+// once the clock passes now+d
+func (c *FakeClock) WithTimeout(parent context.Context, d time.Duration) (context.Context, context.CancelFunc) {
+	c.mu.Lock()
+	ctx := &timeoutContext{Context: parent, deadline: c.now.Add(d), done: make(chan struct{})}
+	timer := &fakeTimer{deadline: ctx.deadline, fire: func(time.Time) { ctx.cancel(context.DeadlineExceeded) }}
+	if d <= 0 {
+		ctx.cancel(context.DeadlineExceeded)
+	} else {
+		c.addLocked(timer)
+	}
+	c.mu.Unlock()
+
+	stop := context.AfterFunc(parent, func() {
+		c.remove(timer)
+		ctx.cancel(parent.Err())
+	})
+	return ctx, func() {
+		stop()
+		c.remove(timer)
+		ctx.cancel(context.Canceled)
+	}
+}
+
+// Advance moves the clock forward by d, firing every timer due on the way in
+// deadline order
+func (c *FakeClock) Advance(d time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.advanceLocked(c.now.Add(d))
+}
+
+// Pending returns the number of sleeps, tickers and timeouts waiting on the clock
+func (c *FakeClock) Pending() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return len(c.timers)
+}
+
+// BlockUntil waits until at least n timers are waiting on the clock. Use it
+// before Advance to make sure the code under test has started its sleep.
+func (c *FakeClock) BlockUntil(n int) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for len(c.timers) < n {
+		c.cond.Wait()
+	}
+}
+
+func (c *FakeClock) addLocked(t *fakeTimer) {
+	c.timers = append(c.timers, t)
+	c.cond.Broadcast()
+}
+
+func (c *FakeClock) remove(t *fakeTimer) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.removeLocked(t)
+}
+
+func (c *FakeClock) removeLocked(t *fakeTimer) {
+	for i, pending := range c.timers {
+		if pending == t {
+			c.timers = append(c.timers[:i], c.timers[i+1:]...)
+			return
+		}
+	}
+}
+
+// advanceLocked moves the clock to target, firing due timers in deadline order
+func (c *FakeClock) advanceLocked(target time.Time) {
+	for {
+		sort.SliceStable(c.timers, func(i, j int) bool { return c.timers[i].deadline.Before(c.timers[j].deadline) })
+		if len(c.timers) == 0 || c.timers[0].deadline.After(target) {
+			break
+		}
+		t := c.timers[0]
+		if t.deadline.After(c.now) {
+			c.now = t.deadline
+		}
+		if t.period > 0 {
+			t.deadline = t.deadline.Add(t.period)
+		} else {
+			c.timers = c.timers[1:]
+		}
+		t.fire(c.now)
+	}
+	if target.After(c.now) {
+		c.now = target
+	}
+}
+
+type fakeTicker struct {
+	clock *FakeClock
+	timer *fakeTimer
+	ch    chan time.Time
+}
+
+func (t *fakeTicker) C() <-chan time.Time { return t.ch }
+
+func (t *fakeTicker) Stop() { t.clock.remove(t.timer) }
+
+// timeoutContext is a context whose deadline is measured on a FakeClock. The
+// fake deadline is not reported by Deadline, since callers compare it with real time.
+type timeoutContext struct {
+	context.Context
+	deadline time.Time
+	done     chan struct{}
+
+	mu  sync.Mutex
+	err error
+}
+
+func (c *timeoutContext) Done() <-chan struct{} { return c.done }
+
+func (c *timeoutContext) Err() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.err
+}
+
+func (c *timeoutContext) cancel(err error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.err == nil {
+		c.err = err
+		close(c.done)
+	}
+}
@@ -0,0 +1,113 @@
+package gorkflowtest_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/sicko7947/gorkflow/gorkflowtest"
+)
+
+var epoch = time.Date(2024, time.January, 1, 0, 0, 0, 0, time.UTC)
+
+func TestFakeClock_Advance(t *testing.T) {
+	clock := gorkflowtest.NewFakeClock(epoch)
+
+	late := clock.After(2 * time.Second)
+	early := clock.After(time.Second)
+	assert.Equal(t, 2, clock.Pending())
+
+	clock.Advance(1500 * time.Millisecond)
+	select {
+	case now := <-early:
+		assert.Equal(t, epoch.Add(time.Second), now)
+	default:
+		t.Fatal("timer due at 1s should have fired")
+	}
+	select {
+	case <-late:
+		t.Fatal("timer due at 2s fired early")
+	default:
+	}
+	assert.Equal(t, epoch.Add(1500*time.Millisecond), clock.Now())
+
+	clock.Advance(time.Second)
+	assert.Equal(t, epoch.Add(2*time.Second), <-late)
+	assert.Equal(t, 0, clock.Pending())
+}
+
+func TestFakeClock_AutoAdvance(t *testing.T) {
+	clock := gorkflowtest.NewFakeClock(epoch)
+	clock.SetAutoAdvance(true)
+
+	start := time.Now()
+	<-clock.After(time.Hour)
+	assert.Less(t, time.Since(start), time.Second)
+	assert.Equal(t, epoch.Add(time.Hour), clock.Now())
+}
+
+func TestFakeClock_Ticker(t *testing.T) {
+	clock := gorkflowtest.NewFakeClock(epoch)
+	ticker := clock.NewTicker(time.Minute)
+
+	clock.Advance(time.Minute)
+	assert.Equal(t, epoch.Add(time.Minute), <-ticker.C())
+
+	// Ticks are dropped while one is unread
+	clock.Advance(3 * time.Minute)
+	assert.Equal(t, epoch.Add(2*time.Minute), <-ticker.C())
+	select {
+	case <-ticker.C():
+		t.Fatal("ticker should drop ticks that were not received")
+	default:
+	}
+
+	ticker.Stop()
+	assert.Equal(t, 0, clock.Pending())
+}
+
+func TestFakeClock_WithTimeout(t *testing.T) {
+	clock := gorkflowtest.NewFakeClock(epoch)
+
+	ctx, cancel := clock.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	_, hasDeadline := ctx.Deadline()
+	assert.False(t, hasDeadline, "fake deadlines are not exposed")
+
+	clock.Advance(999 * time.Millisecond)
+	require.NoError(t, ctx.Err())
+
+	clock.Advance(time.Millisecond)
+	<-ctx.Done()
+	assert.Equal(t, context.DeadlineExceeded, ctx.Err())
+
+	// Cancelling the parent or the timeout ends the context with Canceled
+	parent, cancelParent := context.WithCancel(context.Background())
+	ctx, cancel = clock.WithTimeout(parent, time.Second)
+	defer cancel()
+	cancelParent()
+	<-ctx.Done()
+	assert.Equal(t, context.Canceled, ctx.Err())
+
+	ctx, cancel = clock.WithTimeout(context.Background(), time.Second)
+	cancel()
+	assert.Equal(t, context.Canceled, ctx.Err())
+	assert.Equal(t, 0, clock.Pending())
+}
+
+func TestFakeClock_BlockUntil(t *testing.T) {
+	clock := gorkflowtest.NewFakeClock(epoch)
+
+	done := make(chan struct{})
+	go func() {
+		<-clock.After(time.Minute)
+		close(done)
+	}()
+
+	clock.BlockUntil(1)
+	clock.Advance(time.Minute)
+	<-done
+}
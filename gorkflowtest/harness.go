@@ -0,0 +1,243 @@
+// Package gorkflowtest provides a test harness for workflows: an engine on a
+// MemoryStore driven by a fake clock, step mocks, fault injection and
+// assertions on the recorded run.
+//
+//	func TestOrder(t *testing.T) {
+//		h := gorkflowtest.New(t)
+//		gorkflowtest.Mock(h, "charge", func(ctx *gorkflow.StepContext, in ChargeInput) (ChargeOutput, error) {
+//			return ChargeOutput{ID: "ch_1"}, nil
+//		})
+//		h.FailAttempt("ship", 0, errors.New("carrier down"))
+//
+//		run := h.Run(orderWorkflow, OrderInput{ID: "o-1"})
+//		run.AssertStatus(gorkflow.RunStatusCompleted)
+//		run.AssertStepOrder("validate", "charge", "ship")
+//		run.AssertAttempts("ship", 2)
+//	}
+package gorkflowtest
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/rs/zerolog"
+
+	"github.com/sicko7947/gorkflow"
+	"github.com/sicko7947/gorkflow/engine"
+	"github.com/sicko7947/gorkflow/store"
+)
+
+// DefaultStartTime is the initial time of the harness clock
+var DefaultStartTime = time.Date(2024, time.January, 1, 0, 0, 0, 0, time.UTC)
+
+// AnyAttempt makes an injected fault apply to every attempt of a step
+const AnyAttempt = -1
+
+// Harness runs workflows in tests. Mocks and faults are matched by step ID and
+// apply to every workflow run by the harness.
+type Harness struct {
+	t testing.TB
+
+	Engine *engine.Engine
+	Clock  *FakeClock
+
+	// Store is the engine's view of the store, with payloads decoded
+	Store gorkflow.WorkflowStore
+
+	mu     sync.Mutex
+	mocks  map[string]gorkflow.StepInvoker
+	faults map[string][]fault
+}
+
+// fault is an injected failure, panic or delay for a step attempt
+type fault struct {
+	attempt int
+	err     error
+	panic   any
+	delay   time.Duration
+}
+
+// Option configures a Harness
+type Option func(*config)
+
+type config struct {
+	store      gorkflow.WorkflowStore
+	clock      *FakeClock
+	engineOpts []engine.EngineOption
+}
+
+// WithStore runs the harness on a custom store instead of a new MemoryStore
+func WithStore(s gorkflow.WorkflowStore) Option {
+	return func(c *config) {
+		c.store = s
+	}
+}
+
+// WithClock runs the harness on the given clock instead of an auto-advancing
+// clock starting at DefaultStartTime
+func WithClock(clock *FakeClock) Option {
+	return func(c *config) {
+		c.clock = clock
+	}
+}
+
+// WithEngineOptions passes additional options to the harness engine
+func WithEngineOptions(opts ...engine.EngineOption) Option {
+	return func(c *config) {
+		c.engineOpts = append(c.engineOpts, opts...)
+	}
+}
+
+// New creates a harness whose engine is closed when the test ends. By default
+// the engine logs nothing and runs on a MemoryStore, both driven by an
+// auto-advancing fake clock, so retry backoff takes no real time.
+func New(t testing.TB, opts ...Option) *Harness {
+	t.Helper()
+
+	cfg := &config{}
+	for _, opt := range opts {
+		opt(cfg)
+	}
+	if cfg.clock == nil {
+		cfg.clock = NewFakeClock(DefaultStartTime)
+		cfg.clock.SetAutoAdvance(true)
+	}
+	if cfg.store == nil {
+		cfg.store = store.NewMemoryStoreWithOptions(store.MemoryStoreOptions{Clock: cfg.clock})
+	}
+
+	h := &Harness{
+		t:      t,
+		Clock:  cfg.clock,
+		mocks:  make(map[string]gorkflow.StepInvoker),
+		faults: make(map[string][]fault),
+	}
+
+	engineOpts := []engine.EngineOption{
+		engine.WithLogger(zerolog.Nop()),
+		engine.WithClock(h.Clock),
+		engine.WithStepInterceptor(h.intercept),
+	}
+	h.Engine = engine.NewEngine(cfg.store, append(engineOpts, cfg.engineOpts...)...)
+	h.Store = h.Engine.Store()
+	t.Cleanup(func() { h.Engine.Close() })
+	return h
+}
+
+// Override replaces the handler of a step with invoker. The override receives
+// and returns serialized data and replaces the whole step, including any condition.
+func (h *Harness) Override(stepID string, invoker gorkflow.StepInvoker) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.mocks[stepID] = invoker
+}
+
+// Mock replaces the handler of a step with a typed function
+func Mock[TIn, TOut any](h *Harness, stepID string, fn func(ctx *gorkflow.StepContext, input TIn) (TOut, error)) {
+	h.Override(stepID, func(ctx *gorkflow.StepContext, input []byte) ([]byte, error) {
+		var in TIn
+		if err := gorkflow.UnmarshalPayload(input, &in); err != nil {
+			return nil, fmt.Errorf("mock %s: failed to unmarshal input: %w", stepID, err)
+		}
+		out, err := fn(ctx, in)
+		if err != nil {
+			return nil, err
+		}
+		return gorkflow.MarshalPayload(ctx.Serializer, out)
+	})
+}
+
+// MockOutput makes a step return output without running its handler
+func (h *Harness) MockOutput(stepID string, output any) {
+	if _, err := json.Marshal(output); err != nil {
+		h.t.Fatalf("gorkflowtest: failed to marshal mock output of %s: %v", stepID, err)
+	}
+	h.Override(stepID, func(ctx *gorkflow.StepContext, _ []byte) ([]byte, error) {
+		return gorkflow.MarshalPayload(ctx.Serializer, output)
+	})
+}
+
+// FailAttempt makes the given attempt (0-based, or AnyAttempt) of a step fail with err
+func (h *Harness) FailAttempt(stepID string, attempt int, err error) {
+	h.addFault(stepID, fault{attempt: attempt, err: err})
+}
+
+// PanicAttempt makes the given attempt of a step panic with value
+func (h *Harness) PanicAttempt(stepID string, attempt int, value any) {
+	h.addFault(stepID, fault{attempt: attempt, panic: value})
+}
+
+// DelayAttempt delays the given attempt of a step by d of clock time before it
+// runs. A delay beyond the step timeout makes the attempt time out.
+func (h *Harness) DelayAttempt(stepID string, attempt int, d time.Duration) {
+	h.addFault(stepID, fault{attempt: attempt, delay: d})
+}
+
+func (h *Harness) addFault(stepID string, f fault) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.faults[stepID] = append(h.faults[stepID], f)
+}
+
+// intercept applies injected faults and mocks around every step attempt
+func (h *Harness) intercept(ctx *gorkflow.StepContext, input []byte, next gorkflow.StepInvoker) ([]byte, error) {
+	h.mu.Lock()
+	var faults []fault
+	for _, f := range h.faults[ctx.StepID] {
+		if f.attempt == AnyAttempt || f.attempt == ctx.Attempt {
+			faults = append(faults, f)
+		}
+	}
+	mock := h.mocks[ctx.StepID]
+	h.mu.Unlock()
+
+	for _, f := range faults {
+		if f.delay > 0 {
+			select {
+			case <-ctx.Done():
+			case <-h.Clock.After(f.delay):
+			}
+			// The timeout may fire together with the delay; it wins
+			if err := ctx.Err(); err != nil {
+				return nil, err
+			}
+		}
+		if f.panic != nil {
+			panic(f.panic)
+		}
+		if f.err != nil {
+			return nil, f.err
+		}
+	}
+
+	if mock != nil {
+		return mock(ctx, input)
+	}
+	return next(ctx, input)
+}
+
+// Run executes a workflow synchronously and returns the recorded run. A
+// failing workflow does not fail the test; its error is in Run.Err.
+func (h *Harness) Run(wf *gorkflow.Workflow, input any, opts ...gorkflow.StartOption) *Run {
+	h.t.Helper()
+	opts = append(opts, gorkflow.WithSynchronousExecution())
+	runID, err := h.Engine.StartWorkflow(context.Background(), wf, input, opts...)
+	if runID == "" {
+		h.t.Fatalf("gorkflowtest: failed to start workflow %s: %v", wf.ID(), err)
+	}
+	return &Run{ID: runID, Err: err, h: h}
+}
+
+// Start starts a workflow in the background. Call Wait before asserting on the run.
+func (h *Harness) Start(wf *gorkflow.Workflow, input any, opts ...gorkflow.StartOption) *Run {
+	h.t.Helper()
+	runID, err := h.Engine.StartWorkflow(context.Background(), wf, input, opts...)
+	if err != nil {
+		h.t.Fatalf("gorkflowtest: failed to start workflow %s: %v", wf.ID(), err)
+	}
+	return &Run{ID: runID, h: h}
+}
@@ -0,0 +1,155 @@
+package gorkflowtest_test
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/sicko7947/gorkflow"
+	"github.com/sicko7947/gorkflow/gorkflowtest"
+)
+
+type order struct {
+	ID    string `json:"id"`
+	Total int    `json:"total"`
+}
+
+type charge struct {
+	OrderID string `json:"orderId"`
+	Charged int    `json:"charged"`
+}
+
+type shipment struct {
+	Tracking string `json:"tracking"`
+}
+
+func orderWorkflow(t *testing.T) *gorkflow.Workflow {
+	t.Helper()
+	chargeStep := gorkflow.NewStep("charge", "Charge", func(ctx *gorkflow.StepContext, in order) (charge, error) {
+		return charge{OrderID: in.ID, Charged: in.Total}, nil
+	}, gorkflow.WithRetries(3), gorkflow.WithRetryDelay(time.Minute), gorkflow.WithTimeout(30*time.Second))
+
+	shipStep := gorkflow.NewStep("ship", "Ship", func(ctx *gorkflow.StepContext, in charge) (shipment, error) {
+		if err := ctx.State.Set("shipped", in.OrderID); err != nil {
+			return shipment{}, err
+		}
+		return shipment{Tracking: "T-" + in.OrderID}, nil
+	})
+
+	wf, err := gorkflow.NewWorkflow("orders", "Orders").
+		ThenStep(chargeStep).
+		ThenStep(shipStep).
+		Build()
+	require.NoError(t, err)
+	return wf
+}
+
+func TestHarness_Run(t *testing.T) {
+	h := gorkflowtest.New(t)
+
+	run := h.Run(orderWorkflow(t), order{ID: "o-1", Total: 42})
+	require.NoError(t, run.Err)
+
+	run.AssertStatus(gorkflow.RunStatusCompleted)
+	run.AssertStepOrder("charge", "ship")
+	run.AssertAttempts("charge", 1)
+	run.AssertStepStatus("ship", gorkflow.StepStatusCompleted)
+	run.AssertState("shipped", "o-1")
+	gorkflowtest.AssertOutput(run, "ship", shipment{Tracking: "T-o-1"})
+	assert.Equal(t, 42, gorkflowtest.Output[charge](run, "charge").Charged)
+	assert.Equal(t, "o-1", gorkflowtest.State[string](run, "shipped"))
+}
+
+func TestHarness_FaultsFastForwardBackoff(t *testing.T) {
+	h := gorkflowtest.New(t)
+	h.FailAttempt("charge", 0, errors.New("card declined"))
+	h.PanicAttempt("charge", 1, "gateway bug")
+
+	start := time.Now()
+	run := h.Run(orderWorkflow(t), order{ID: "o-2"})
+	assert.Less(t, time.Since(start), time.Second, "backoff should run on the fake clock")
+
+	run.AssertStatus(gorkflow.RunStatusCompleted)
+	run.AssertAttempts("charge", 3)
+	assert.True(t, h.Clock.Now().Sub(gorkflowtest.DefaultStartTime) >= 2*time.Minute, "clock should have advanced through both backoffs")
+}
+
+func TestHarness_DelayTimesOut(t *testing.T) {
+	h := gorkflowtest.New(t)
+	h.DelayAttempt("charge", gorkflowtest.AnyAttempt, time.Minute)
+
+	run := h.Run(orderWorkflow(t), order{ID: "o-3"})
+	require.Error(t, run.Err)
+
+	run.AssertStatus(gorkflow.RunStatusFailed)
+	run.AssertStepStatus("charge", gorkflow.StepStatusFailed)
+	run.AssertAttempts("charge", 4)
+	run.AssertNotExecuted("ship")
+	assert.Contains(t, run.Step("charge").Error.Message, "timed out")
+}
+
+func TestHarness_Mocks(t *testing.T) {
+	h := gorkflowtest.New(t)
+
+	var seen order
+	gorkflowtest.Mock(h, "charge", func(ctx *gorkflow.StepContext, in order) (charge, error) {
+		seen = in
+		return charge{OrderID: "mocked", Charged: 1}, nil
+	})
+	h.MockOutput("ship", shipment{Tracking: "fixed"})
+
+	run := h.Run(orderWorkflow(t), order{ID: "o-4", Total: 9})
+	run.AssertStatus(gorkflow.RunStatusCompleted)
+	assert.Equal(t, order{ID: "o-4", Total: 9}, seen)
+	gorkflowtest.AssertOutput(run, "charge", charge{OrderID: "mocked", Charged: 1})
+	gorkflowtest.AssertOutput(run, "ship", shipment{Tracking: "fixed"})
+
+	// The real ship handler never ran, so it wrote no state
+	_, err := h.Store.LoadState(t.Context(), run.ID, "shipped")
+	assert.ErrorIs(t, err, gorkflow.ErrStateNotFound)
+
+	h.Override("charge", func(ctx *gorkflow.StepContext, input []byte) ([]byte, error) {
+		return nil, fmt.Errorf("override")
+	})
+	run = h.Run(orderWorkflow(t), order{ID: "o-5"})
+	run.AssertStatus(gorkflow.RunStatusFailed)
+}
+
+func TestHarness_StartAndWait(t *testing.T) {
+	h := gorkflowtest.New(t)
+
+	run := h.Start(orderWorkflow(t), order{ID: "o-6"}).Wait()
+	run.AssertStatus(gorkflow.RunStatusCompleted)
+	assert.Len(t, run.Steps(), 2)
+}
+
+// recordingT captures assertion failures instead of failing the test
+type recordingT struct {
+	testing.TB
+	errors []string
+}
+
+func (r *recordingT) Helper() {}
+
+func (r *recordingT) Errorf(format string, args ...any) {
+	r.errors = append(r.errors, fmt.Sprintf(format, args...))
+}
+
+func TestHarness_AssertionsReportFailures(t *testing.T) {
+	rt := &recordingT{TB: t}
+	h := gorkflowtest.New(rt)
+
+	run := h.Run(orderWorkflow(t), order{ID: "o-7"})
+	run.AssertStatus(gorkflow.RunStatusFailed)
+	run.AssertStepOrder("ship", "charge")
+	run.AssertAttempts("charge", 2)
+	run.AssertState("shipped", "other")
+	gorkflowtest.AssertOutput(run, "ship", shipment{})
+	run.AssertNotExecuted("ship")
+
+	assert.Len(t, rt.errors, 6)
+}
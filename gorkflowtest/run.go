@@ -0,0 +1,169 @@
+package gorkflowtest
+
+import (
+	"context"
+	"reflect"
+	"sort"
+	"time"
+
+	"github.com/sicko7947/gorkflow"
+)
+
+// DefaultWaitTimeout bounds how long Run.Wait waits in real time
+var DefaultWaitTimeout = 10 * time.Second
+
+// Run is a workflow run started by a Harness
+type Run struct {
+	ID string
+
+	// Err is the error returned by a synchronous run
+	Err error
+
+	h *Harness
+}
+
+// Wait waits for a background run to finish
+func (r *Run) Wait() *Run {
+	r.h.t.Helper()
+	ctx, cancel := context.WithTimeout(context.Background(), DefaultWaitTimeout)
+	defer cancel()
+	if _, err := r.h.Engine.WaitForRun(ctx, r.ID); err != nil {
+		r.h.t.Fatalf("gorkflowtest: run %s did not finish: %v", r.ID, err)
+	}
+	return r
+}
+
+// Get returns the stored run
+func (r *Run) Get() *gorkflow.WorkflowRun {
+	r.h.t.Helper()
+	run, err := r.h.Store.GetRun(context.Background(), r.ID)
+	if err != nil {
+		r.h.t.Fatalf("gorkflowtest: failed to get run %s: %v", r.ID, err)
+	}
+	return run
+}
+
+// Steps returns the run's step executions in execution order
+func (r *Run) Steps() []*gorkflow.StepExecution {
+	r.h.t.Helper()
+	execs, err := r.h.Store.ListStepExecutions(context.Background(), r.ID)
+	if err != nil {
+		r.h.t.Fatalf("gorkflowtest: failed to list step executions of run %s: %v", r.ID, err)
+	}
+	sort.SliceStable(execs, func(i, j int) bool { return execs[i].ExecutionIndex < execs[j].ExecutionIndex })
+	return execs
+}
+
+// Step returns the execution of a step, failing the test if the step did not execute
+func (r *Run) Step(stepID string) *gorkflow.StepExecution {
+	r.h.t.Helper()
+	exec, err := r.h.Store.GetStepExecution(context.Background(), r.ID, stepID)
+	if err != nil {
+		r.h.t.Fatalf("gorkflowtest: step %s has no execution in run %s: %v", stepID, r.ID, err)
+	}
+	return exec
+}
+
+// AssertStatus checks the final status of the run
+func (r *Run) AssertStatus(status gorkflow.RunStatus) {
+	r.h.t.Helper()
+	run := r.Get()
+	if run.Status != status {
+		msg := ""
+		if run.Error != nil {
+			msg = ": " + run.Error.Error()
+		}
+		r.h.t.Errorf("run %s status = %s, want %s%s", r.ID, run.Status, status, msg)
+	}
+}
+
+// AssertStepOrder checks which steps ran (completed or failed), in execution order
+func (r *Run) AssertStepOrder(stepIDs ...string) {
+	r.h.t.Helper()
+	var executed []string
+	for _, exec := range r.Steps() {
+		if exec.Status == gorkflow.StepStatusCompleted || exec.Status == gorkflow.StepStatusFailed {
+			executed = append(executed, exec.StepID)
+		}
+	}
+	if len(executed) == 0 && len(stepIDs) == 0 {
+		return
+	}
+	if !reflect.DeepEqual(executed, stepIDs) {
+		r.h.t.Errorf("run %s executed steps %v, want %v", r.ID, executed, stepIDs)
+	}
+}
+
+// AssertStepStatus checks the status of a step
+func (r *Run) AssertStepStatus(stepID string, status gorkflow.StepStatus) {
+	r.h.t.Helper()
+	if exec := r.Step(stepID); exec.Status != status {
+		r.h.t.Errorf("step %s status = %s, want %s", stepID, exec.Status, status)
+	}
+}
+
+// AssertNotExecuted checks that a step has no execution record
+func (r *Run) AssertNotExecuted(stepID string) {
+	r.h.t.Helper()
+	if exec, err := r.h.Store.GetStepExecution(context.Background(), r.ID, stepID); err == nil {
+		r.h.t.Errorf("step %s executed with status %s, want no execution", stepID, exec.Status)
+	}
+}
+
+// AssertAttempts checks how many attempts a step made
+func (r *Run) AssertAttempts(stepID string, attempts int) {
+	r.h.t.Helper()
+	if got := r.Step(stepID).Attempt + 1; got != attempts {
+		r.h.t.Errorf("step %s made %d attempts, want %d", stepID, got, attempts)
+	}
+}
+
+// AssertState checks a workflow state value, decoding it into the type of expected
+func (r *Run) AssertState(key string, expected any) {
+	r.h.t.Helper()
+	data, err := r.h.Store.LoadState(context.Background(), r.ID, key)
+	if err != nil {
+		r.h.t.Errorf("state %q of run %s: %v", key, r.ID, err)
+		return
+	}
+	got := reflect.New(reflect.TypeOf(expected))
+	if err := gorkflow.UnmarshalPayload(data, got.Interface()); err != nil {
+		r.h.t.Errorf("state %q of run %s: failed to decode %s: %v", key, r.ID, data, err)
+		return
+	}
+	if !reflect.DeepEqual(got.Elem().Interface(), expected) {
+		r.h.t.Errorf("state %q = %v, want %v", key, got.Elem().Interface(), expected)
+	}
+}
+
+// Output returns the decoded output of a step, failing the test if it has none
+func Output[T any](r *Run, stepID string) T {
+	r.h.t.Helper()
+	var out T
+	data, err := r.h.Store.LoadStepOutput(context.Background(), r.ID, stepID)
+	if err != nil {
+		r.h.t.Fatalf("gorkflowtest: output of step %s: %v", stepID, err)
+	}
+	if err := gorkflow.UnmarshalPayload(data, &out); err != nil {
+		r.h.t.Fatalf("gorkflowtest: failed to decode output of step %s: %v", stepID, err)
+	}
+	return out
+}
+
+// AssertOutput checks the decoded output of a step
+func AssertOutput[T any](r *Run, stepID string, expected T) {
+	r.h.t.Helper()
+	if got := Output[T](r, stepID); !reflect.DeepEqual(got, expected) {
+		r.h.t.Errorf("output of step %s = %+v, want %+v", stepID, got, expected)
+	}
+}
+
+// State returns a decoded workflow state value, failing the test if it is missing
+func State[T any](r *Run, key string) T {
+	r.h.t.Helper()
+	value, err := gorkflow.GetTyped[T](gorkflow.NewStateAccessor(r.ID, r.h.Store), key)
+	if err != nil {
+		r.h.t.Fatalf("gorkflowtest: state %q of run %s: %v", key, r.ID, err)
+	}
+	return value
+}
@@ -2,6 +2,7 @@ package gorkflow
 
 import (
 	"fmt"
+	"slices"
 	"sync"
 )
 
@@ -26,6 +27,9 @@ type ExecutionGraph struct {
 	cacheMu    sync.RWMutex
 	sortCache  []string
 	levelCache [][]string
+
+	// extraEntries are entry points besides EntryPoint, for graphs with several roots
+	extraEntries []string
 }
 
 // GraphNode represents a node in the execution graph
@@ -104,11 +108,40 @@ func (g *ExecutionGraph) SetEntryPoint(stepID string) error {
 		return fmt.Errorf("step %s not found in graph", stepID)
 	}
 	g.EntryPoint = stepID
+	g.extraEntries = slices.DeleteFunc(g.extraEntries, func(id string) bool { return id == stepID })
 	g.sortCache = nil
 	g.levelCache = nil
 	return nil
 }
 
+// AddEntryPoint adds another entry point, so the graph can have several root
+// steps that start concurrently. The first entry point becomes EntryPoint.
+func (g *ExecutionGraph) AddEntryPoint(stepID string) error {
+	if _, exists := g.Nodes[stepID]; !exists {
+		return fmt.Errorf("step %s not found in graph", stepID)
+	}
+	switch {
+	case g.EntryPoint == "":
+		g.EntryPoint = stepID
+	case slices.Contains(g.EntryPoints(), stepID):
+		return nil
+	default:
+		g.extraEntries = append(g.extraEntries, stepID)
+	}
+	g.sortCache = nil
+	g.levelCache = nil
+	return nil
+}
+
+// EntryPoints returns EntryPoint followed by the entry points added with
+// AddEntryPoint
+func (g *ExecutionGraph) EntryPoints() []string {
+	if g.EntryPoint == "" {
+		return nil
+	}
+	return append([]string{g.EntryPoint}, g.extraEntries...)
+}
+
 // Validate validates the graph structure
 func (g *ExecutionGraph) Validate() error {
 	if g.EntryPoint == "" {
@@ -118,6 +151,15 @@ func (g *ExecutionGraph) Validate() error {
 	if _, exists := g.Nodes[g.EntryPoint]; !exists {
 		return fmt.Errorf("entry point %s not found in graph", g.EntryPoint)
 	}
+	for _, stepID := range g.extraEntries {
+		node, exists := g.Nodes[stepID]
+		if !exists {
+			return fmt.Errorf("entry point %s not found in graph", stepID)
+		}
+		if len(node.Previous) > 0 {
+			return fmt.Errorf("entry point %s has preceding steps", stepID)
+		}
+	}
 
 	// Check for cycles (simple DFS-based cycle detection)
 	visited := make(map[string]bool)
@@ -131,8 +173,13 @@ func (g *ExecutionGraph) Validate() error {
 		}
 	}
 
-	// Check that all nodes are reachable from entry point
-	reachable := g.getReachableNodes(g.EntryPoint)
+	// Check that all nodes are reachable from the entry points
+	reachable := make(map[string]bool)
+	for _, stepID := range g.EntryPoints() {
+		if !reachable[stepID] {
+			g.dfsReachable(stepID, reachable)
+		}
+	}
 	if len(reachable) != len(g.Nodes) {
 		return fmt.Errorf("not all nodes are reachable from entry point")
 	}
@@ -160,13 +207,6 @@ func (g *ExecutionGraph) hasCycle(nodeID string, visited, recStack map[string]bo
 	return false
 }
 
-// getReachableNodes returns all nodes reachable from the given start node
-func (g *ExecutionGraph) getReachableNodes(startID string) map[string]bool {
-	reachable := make(map[string]bool)
-	g.dfsReachable(startID, reachable)
-	return reachable
-}
-
 // dfsReachable performs DFS to find all reachable nodes
 func (g *ExecutionGraph) dfsReachable(nodeID string, reachable map[string]bool) {
 	reachable[nodeID] = true
@@ -217,9 +257,11 @@ func (g *ExecutionGraph) TopologicalSort() ([]string, error) {
 		return nil
 	}
 
-	// Start from entry point
-	if err := visit(g.EntryPoint); err != nil {
-		return nil, err
+	// Start from the entry points
+	for _, stepID := range g.EntryPoints() {
+		if err := visit(stepID); err != nil {
+			return nil, err
+		}
 	}
 
 	// Reverse in-place: DFS post-order appends children-before-parent; reversing gives topological order.
@@ -247,8 +289,12 @@ func (g *ExecutionGraph) ComputeLevels() ([][]string, error) {
 	if err := g.Validate(); err != nil {
 		return nil, err
 	}
-	levels := map[string]int{g.EntryPoint: 0}
-	queue := []string{g.EntryPoint}
+	entries := g.EntryPoints()
+	levels := make(map[string]int, len(g.Nodes))
+	for _, stepID := range entries {
+		levels[stepID] = 0
+	}
+	queue := append([]string(nil), entries...)
 	maxLevel := 0
 	for len(queue) > 0 {
 		nodeID := queue[0]
@@ -306,8 +352,9 @@ func (g *ExecutionGraph) IsTerminal(stepID string) bool {
 // Clone creates a deep copy of the graph
 func (g *ExecutionGraph) Clone() *ExecutionGraph {
 	clone := &ExecutionGraph{
-		EntryPoint: g.EntryPoint,
-		Nodes:      make(map[string]*GraphNode),
+		EntryPoint:   g.EntryPoint,
+		Nodes:        make(map[string]*GraphNode),
+		extraEntries: append([]string(nil), g.extraEntries...),
 	}
 
 	for stepID, node := range g.Nodes {
@@ -0,0 +1,300 @@
+package gorkflow
+
+import (
+	"fmt"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// GraphExportOption configures the DOT and Mermaid exports of an execution graph
+type GraphExportOption func(*graphExport)
+
+type graphExport struct {
+	title      string
+	steps      map[string]StepExecutor
+	executions map[string]*StepExecution
+}
+
+// WithGraphTitle sets the title of the exported graph
+func WithGraphTitle(title string) GraphExportOption {
+	return func(e *graphExport) {
+		e.title = title
+	}
+}
+
+// WithGraphSteps labels nodes with the names and descriptions of steps and
+// marks conditional steps. Workflow.DOT and Workflow.Mermaid set it.
+func WithGraphSteps(steps map[string]StepExecutor) GraphExportOption {
+	return func(e *graphExport) {
+		e.steps = steps
+	}
+}
+
+// WithGraphRun overlays the status and duration of each step of a run, as
+// returned by ListStepExecutions. Steps without an execution are left plain.
+func WithGraphRun(executions []*StepExecution) GraphExportOption {
+	return func(e *graphExport) {
+		e.executions = make(map[string]*StepExecution, len(executions))
+		for _, exec := range executions {
+			if prev, ok := e.executions[exec.StepID]; !ok || exec.ExecutionIndex >= prev.ExecutionIndex {
+				e.executions[exec.StepID] = exec
+			}
+		}
+	}
+}
+
+// exportNode is a graph node prepared for export
+type exportNode struct {
+	id          string // identifier in the exported graph
+	stepID      string
+	kind        NodeType
+	lines       []string
+	status      StepStatus
+	conditional bool
+}
+
+// statusColors are the fill colors of overlaid step statuses
+var statusColors = map[StepStatus]string{
+	StepStatusPending:   "#f8f9fa",
+	StepStatusRunning:   "#cfe2ff",
+	StepStatusRetrying:  "#fff3cd",
+	StepStatusCompleted: "#d1e7dd",
+	StepStatusFailed:    "#f8d7da",
+	StepStatusSkipped:   "#e2e3e5",
+}
+
+// DOT renders the graph as a Graphviz digraph. Parallel steps are drawn as
+// parallelograms and conditional steps as hexagons reached by dashed edges.
+func (g *ExecutionGraph) DOT(opts ...GraphExportOption) string {
+	cfg, nodes, ids := g.prepareExport(opts)
+
+	var b strings.Builder
+	b.WriteString("digraph workflow {\n")
+	if cfg.title != "" {
+		fmt.Fprintf(&b, "  label=\"%s\";\n  labelloc=t;\n", dotEscape(cfg.title))
+	}
+	b.WriteString("  rankdir=TB;\n")
+	b.WriteString("  node [shape=box, style=rounded, fontname=\"Helvetica\"];\n")
+	b.WriteString("  edge [fontname=\"Helvetica\"];\n\n")
+
+	if _, ok := ids[g.EntryPoint]; ok {
+		b.WriteString("  start [shape=circle, label=\"\", width=0.2, style=filled, fillcolor=black];\n")
+		for _, stepID := range g.EntryPoints() {
+			fmt.Fprintf(&b, "  start -> %s;\n", ids[stepID])
+		}
+	}
+
+	for _, n := range nodes {
+		labels := make([]string, len(n.lines))
+		for i, line := range n.lines {
+			labels[i] = dotEscape(line)
+		}
+		attrs := []string{fmt.Sprintf("label=\"%s\"", strings.Join(labels, `\n`))}
+		switch {
+		case n.conditional:
+			attrs = append(attrs, "shape=hexagon")
+		case n.kind == NodeTypeParallel:
+			attrs = append(attrs, "shape=parallelogram")
+		}
+		if color, ok := statusColors[n.status]; ok {
+			attrs = append(attrs, "style=\"rounded,filled\"", fmt.Sprintf("fillcolor=\"%s\"", color))
+		}
+		fmt.Fprintf(&b, "  %s [%s];\n", n.id, strings.Join(attrs, ", "))
+	}
+
+	b.WriteString("\n")
+	for _, n := range nodes {
+		for _, next := range g.Nodes[n.stepID].Next {
+			if nodeConditional(cfg, g.Nodes[next]) {
+				fmt.Fprintf(&b, "  %s -> %s [style=dashed, label=\"if\"];\n", n.id, ids[next])
+			} else {
+				fmt.Fprintf(&b, "  %s -> %s;\n", n.id, ids[next])
+			}
+		}
+	}
+	b.WriteString("}\n")
+	return b.String()
+}
+
+// Mermaid renders the graph as a Mermaid flowchart, with the same shapes as DOT
+func (g *ExecutionGraph) Mermaid(opts ...GraphExportOption) string {
+	cfg, nodes, ids := g.prepareExport(opts)
+
+	var b strings.Builder
+	if cfg.title != "" {
+		fmt.Fprintf(&b, "---\ntitle: %s\n---\n", strconv.Quote(cfg.title))
+	}
+	b.WriteString("flowchart TD\n")
+
+	if _, ok := ids[g.EntryPoint]; ok {
+		b.WriteString("  start(( ))\n")
+		for _, stepID := range g.EntryPoints() {
+			fmt.Fprintf(&b, "  start --> %s\n", ids[stepID])
+		}
+	}
+
+	byStatus := make(map[StepStatus][]string)
+	for _, n := range nodes {
+		labels := make([]string, len(n.lines))
+		for i, line := range n.lines {
+			labels[i] = mermaidEscape(line)
+		}
+		label := `"` + strings.Join(labels, "<br/>") + `"`
+		switch {
+		case n.conditional:
+			fmt.Fprintf(&b, "  %s{{%s}}\n", n.id, label)
+		case n.kind == NodeTypeParallel:
+			fmt.Fprintf(&b, "  %s[/%s/]\n", n.id, label)
+		default:
+			fmt.Fprintf(&b, "  %s(%s)\n", n.id, label)
+		}
+		if _, ok := statusColors[n.status]; ok {
+			byStatus[n.status] = append(byStatus[n.status], n.id)
+		}
+	}
+
+	for _, n := range nodes {
+		for _, next := range g.Nodes[n.stepID].Next {
+			if nodeConditional(cfg, g.Nodes[next]) {
+				fmt.Fprintf(&b, "  %s -. if .-> %s\n", n.id, ids[next])
+			} else {
+				fmt.Fprintf(&b, "  %s --> %s\n", n.id, ids[next])
+			}
+		}
+	}
+
+	statuses := make([]string, 0, len(byStatus))
+	for status := range byStatus {
+		statuses = append(statuses, string(status))
+	}
+	sort.Strings(statuses)
+	for _, status := range statuses {
+		class := strings.ToLower(status)
+		fmt.Fprintf(&b, "  classDef %s fill:%s\n", class, statusColors[StepStatus(status)])
+		fmt.Fprintf(&b, "  class %s %s\n", strings.Join(byStatus[StepStatus(status)], ","), class)
+	}
+	return b.String()
+}
+
+// prepareExport applies the export options and returns the nodes in export
+// order with their export IDs
+func (g *ExecutionGraph) prepareExport(opts []GraphExportOption) (*graphExport, []exportNode, map[string]string) {
+	cfg := &graphExport{}
+	for _, opt := range opts {
+		opt(cfg)
+	}
+
+	order := g.exportOrder()
+
+	nodes := make([]exportNode, 0, len(order))
+	ids := make(map[string]string, len(order))
+	for i, stepID := range order {
+		node := g.Nodes[stepID]
+		n := exportNode{
+			id:          fmt.Sprintf("s%d", i),
+			stepID:      stepID,
+			kind:        node.Type,
+			conditional: nodeConditional(cfg, node),
+		}
+
+		n.lines = []string{stepID}
+		if step, ok := cfg.steps[stepID]; ok {
+			if name := step.GetName(); name != "" && name != stepID {
+				n.lines = []string{name, "(" + stepID + ")"}
+			}
+			if desc := step.GetDescription(); desc != "" {
+				n.lines = append(n.lines, desc)
+			}
+		}
+		if exec, ok := cfg.executions[stepID]; ok {
+			n.status = exec.Status
+			n.lines = append(n.lines, executionSummary(exec))
+		}
+
+		nodes = append(nodes, n)
+		ids[stepID] = n.id
+	}
+	return cfg, nodes, ids
+}
+
+// exportOrder returns the step IDs in topological order, starting from the
+// entry points and following edges in the order they were added, so exports are
+// stable. A graph with cycles is ordered by step ID.
+func (g *ExecutionGraph) exportOrder() []string {
+	entries := g.EntryPoints()
+	indegree := make(map[string]int, len(g.Nodes))
+	var roots []string
+	for stepID, node := range g.Nodes {
+		indegree[stepID] = len(node.Previous)
+		if len(node.Previous) == 0 && !slices.Contains(entries, stepID) {
+			roots = append(roots, stepID)
+		}
+	}
+	sort.Strings(roots)
+	for i := len(entries) - 1; i >= 0; i-- {
+		if _, ok := g.Nodes[entries[i]]; ok && indegree[entries[i]] == 0 {
+			roots = append([]string{entries[i]}, roots...)
+		}
+	}
+
+	order := make([]string, 0, len(g.Nodes))
+	queue := roots
+	for len(queue) > 0 {
+		stepID := queue[0]
+		queue = queue[1:]
+		order = append(order, stepID)
+		for _, next := range g.Nodes[stepID].Next {
+			indegree[next]--
+			if indegree[next] == 0 {
+				queue = append(queue, next)
+			}
+		}
+	}
+
+	if len(order) != len(g.Nodes) {
+		order = order[:0]
+		for stepID := range g.Nodes {
+			order = append(order, stepID)
+		}
+		sort.Strings(order)
+	}
+	return order
+}
+
+// nodeConditional reports whether a node runs a conditional step
+func nodeConditional(cfg *graphExport, node *GraphNode) bool {
+	if node.Type == NodeTypeConditional {
+		return true
+	}
+	step, ok := cfg.steps[node.StepID]
+	return ok && IsConditionalStep(step)
+}
+
+// executionSummary describes a step execution in one line, e.g.
+// "COMPLETED · 1.5s · 2 attempts"
+func executionSummary(exec *StepExecution) string {
+	parts := []string{string(exec.Status)}
+	if exec.DurationMs > 0 {
+		parts = append(parts, (time.Duration(exec.DurationMs) * time.Millisecond).String())
+	}
+	if exec.Attempt > 0 {
+		parts = append(parts, fmt.Sprintf("%d attempts", exec.Attempt+1))
+	}
+	if exec.CacheHit {
+		parts = append(parts, "cached")
+	}
+	return strings.Join(parts, " · ")
+}
+
+// dotEscape escapes text for a quoted DOT string
+func dotEscape(s string) string {
+	return strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", " ").Replace(s)
+}
+
+// mermaidEscape escapes text for a quoted Mermaid label
+func mermaidEscape(s string) string {
+	return strings.NewReplacer(`"`, "#quot;", "<", "#lt;", ">", "#gt;", "\n", " ").Replace(s)
+}
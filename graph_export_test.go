@@ -0,0 +1,128 @@
+package gorkflow
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func exportTestWorkflow(t *testing.T) *Workflow {
+	t.Helper()
+	handler := func(ctx *StepContext, in int) (int, error) { return in, nil }
+	fetch := NewStep("fetch", "Fetch Orders", handler)
+	fetch.Description = `Reads "open" orders`
+	always := func(*StepContext) (bool, error) { return true, nil }
+
+	wf, err := NewWorkflow("orders", "Orders").
+		ThenStep(fetch).
+		Parallel(NewStep("price", "Price", handler), NewStep("stock", "Stock", handler)).
+		ThenStepIf(NewStep("notify", "Notify", handler), always, nil).
+		Build()
+	require.NoError(t, err)
+	return wf
+}
+
+func TestWorkflow_DOT(t *testing.T) {
+	dot := exportTestWorkflow(t).DOT()
+
+	assert.Equal(t, `digraph workflow {
+  label="Orders";
+  labelloc=t;
+  rankdir=TB;
+  node [shape=box, style=rounded, fontname="Helvetica"];
+  edge [fontname="Helvetica"];
+
+  start [shape=circle, label="", width=0.2, style=filled, fillcolor=black];
+  start -> s0;
+  s0 [label="Fetch Orders\n(fetch)\nReads \"open\" orders"];
+  s1 [label="Price\n(price)", shape=parallelogram];
+  s2 [label="Stock\n(stock)", shape=parallelogram];
+  s3 [label="Notify\n(notify)", shape=hexagon];
+
+  s0 -> s1;
+  s0 -> s2;
+  s1 -> s3 [style=dashed, label="if"];
+  s2 -> s3 [style=dashed, label="if"];
+}
+`, dot)
+}
+
+func TestWorkflow_Mermaid(t *testing.T) {
+	mermaid := exportTestWorkflow(t).Mermaid()
+
+	assert.Equal(t, `---
+title: "Orders"
+---
+flowchart TD
+  start(( ))
+  start --> s0
+  s0("Fetch Orders<br/>(fetch)<br/>Reads #quot;open#quot; orders")
+  s1[/"Price<br/>(price)"/]
+  s2[/"Stock<br/>(stock)"/]
+  s3{{"Notify<br/>(notify)"}}
+  s0 --> s1
+  s0 --> s2
+  s1 -. if .-> s3
+  s2 -. if .-> s3
+`, mermaid)
+}
+
+func TestExport_RunOverlay(t *testing.T) {
+	wf := exportTestWorkflow(t)
+	executions := []*StepExecution{
+		{StepID: "fetch", Status: StepStatusCompleted, DurationMs: 1500},
+		{StepID: "price", Status: StepStatusFailed, DurationMs: 20, Attempt: 2},
+		{StepID: "stock", Status: StepStatusCompleted, CacheHit: true},
+		{StepID: "notify", Status: StepStatusSkipped},
+	}
+
+	mermaid := wf.Mermaid(WithGraphRun(executions))
+	assert.Contains(t, mermaid, `s0("Fetch Orders<br/>(fetch)<br/>Reads #quot;open#quot; orders<br/>COMPLETED · 1.5s")`)
+	assert.Contains(t, mermaid, `s1[/"Price<br/>(price)<br/>FAILED · 20ms · 3 attempts"/]`)
+	assert.Contains(t, mermaid, `s2[/"Stock<br/>(stock)<br/>COMPLETED · cached"/]`)
+	assert.Contains(t, mermaid, "  classDef completed fill:#d1e7dd\n  class s0,s2 completed\n")
+	assert.Contains(t, mermaid, "  class s1 failed\n")
+	assert.Contains(t, mermaid, "  class s3 skipped\n")
+
+	dot := wf.DOT(WithGraphRun(executions))
+	assert.Contains(t, dot, `s1 [label="Price\n(price)\nFAILED · 20ms · 3 attempts", shape=parallelogram, style="rounded,filled", fillcolor="#f8d7da"];`)
+}
+
+func TestExport_MultipleEntryPoints(t *testing.T) {
+	handler := func(ctx *StepContext, in int) (int, error) { return in, nil }
+	wf, err := NewWorkflow("report", "Report").
+		Step(NewStep("users", "Users", handler)).
+		Step(NewStep("orders", "Orders", handler)).
+		Step(NewStep("join", "Join", handler)).DependsOn("users", "orders").
+		Build()
+	require.NoError(t, err)
+
+	dot := wf.DOT()
+	assert.Contains(t, dot, "  start -> s0;\n  start -> s1;\n")
+	assert.Contains(t, dot, `s0 [label="Users\n(users)"];`)
+	assert.Contains(t, dot, `s1 [label="Orders\n(orders)"];`)
+
+	mermaid := wf.Mermaid()
+	assert.Contains(t, mermaid, "  start --> s0\n  start --> s1\n")
+}
+
+func TestExecutionGraph_ExportWithoutSteps(t *testing.T) {
+	graph := NewExecutionGraph()
+	graph.AddNode("a", NodeTypeSequential)
+	graph.AddNode("b", NodeTypeConditional)
+	require.NoError(t, graph.AddEdge("a", "b"))
+
+	mermaid := graph.Mermaid()
+	assert.Contains(t, mermaid, `s0("a")`)
+	assert.Contains(t, mermaid, `s1{{"b"}}`)
+	assert.Contains(t, mermaid, "s0 -. if .-> s1")
+	assert.NotContains(t, mermaid, "title:")
+
+	// The latest execution of a step wins
+	dot := graph.DOT(WithGraphRun([]*StepExecution{
+		{StepID: "a", ExecutionIndex: 1, Status: StepStatusCompleted},
+		{StepID: "a", ExecutionIndex: 0, Status: StepStatusFailed},
+	}))
+	assert.Contains(t, dot, `s0 [label="a\nCOMPLETED"`)
+}
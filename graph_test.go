@@ -1,6 +1,7 @@
 package gorkflow
 
 import (
+	"slices"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -309,6 +310,45 @@ func TestExecutionGraph_ComplexTopologicalSort(t *testing.T) {
 	assert.Equal(t, "step5", order[4])
 }
 
+func TestExecutionGraph_MultipleEntryPoints(t *testing.T) {
+	//   a     b
+	//   | \  /
+	//   d   c
+	graph := NewExecutionGraph()
+	for _, id := range []string{"a", "b", "c", "d"} {
+		graph.AddNode(id, NodeTypeSequential)
+	}
+	require.NoError(t, graph.AddEdge("a", "c"))
+	require.NoError(t, graph.AddEdge("b", "c"))
+	require.NoError(t, graph.AddEdge("a", "d"))
+
+	// b is unreachable until it is an entry point
+	require.Error(t, graph.Validate())
+	require.NoError(t, graph.AddEntryPoint("b"))
+	require.NoError(t, graph.AddEntryPoint("b"))
+	assert.Equal(t, []string{"a", "b"}, graph.EntryPoints())
+	require.NoError(t, graph.Validate())
+
+	levels, err := graph.ComputeLevels()
+	require.NoError(t, err)
+	require.Len(t, levels, 2)
+	assert.ElementsMatch(t, []string{"a", "b"}, levels[0])
+	assert.ElementsMatch(t, []string{"c", "d"}, levels[1])
+
+	order, err := graph.TopologicalSort()
+	require.NoError(t, err)
+	require.Len(t, order, 4)
+	assert.Less(t, slices.Index(order, "b"), slices.Index(order, "c"))
+
+	assert.Equal(t, []string{"a", "b"}, graph.Clone().EntryPoints())
+
+	// Entry points cannot have preceding steps
+	require.NoError(t, graph.AddEntryPoint("c"))
+	assert.ErrorContains(t, graph.Validate(), "entry point c has preceding steps")
+
+	assert.Error(t, graph.AddEntryPoint("missing"))
+}
+
 func TestNodeType_String(t *testing.T) {
 	assert.Equal(t, "SEQUENTIAL", NodeTypeSequential.String())
 	assert.Equal(t, "PARALLEL", NodeTypeParallel.String())
@@ -1,8 +1,9 @@
 package gorkflow
 
 import (
-	"encoding/json"
+	"context"
 	"fmt"
+	"time"
 )
 
 // ToPtr returns a pointer to the given value.
@@ -19,8 +20,30 @@ func GetRunContext[T any](run *WorkflowRun) (T, error) {
 	}
 
 	var result T
-	if err := json.Unmarshal(run.Context, &result); err != nil {
+	if err := UnmarshalPayload(run.Context, &result); err != nil {
 		return zero, fmt.Errorf("failed to unmarshal context: %w", err)
 	}
 	return result, nil
 }
+
+// ResetRun makes a failed or cancelled run pending again, clearing its error
+// and completion time, so that ResumeRun executes the steps that did not
+// complete. Runs in any other state return ErrRunNotRetryable.
+func ResetRun(ctx context.Context, store WorkflowStore, runID string, now time.Time) (*WorkflowRun, error) {
+	run, err := store.GetRun(ctx, runID)
+	if err != nil {
+		return nil, err
+	}
+	if run.Status != RunStatusFailed && run.Status != RunStatusCancelled {
+		return nil, fmt.Errorf("run %s is %s: %w", runID, run.Status, ErrRunNotRetryable)
+	}
+
+	run.Status = RunStatusPending
+	run.Error = nil
+	run.CompletedAt = nil
+	run.UpdatedAt = now
+	if err := store.UpdateRun(ctx, run); err != nil {
+		return nil, fmt.Errorf("failed to reset run: %w", err)
+	}
+	return run, nil
+}
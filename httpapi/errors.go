@@ -0,0 +1,83 @@
+package httpapi
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/sicko7947/gorkflow"
+)
+
+// statusByCode maps WorkflowError codes to HTTP statuses
+var statusByCode = map[string]int{
+	gorkflow.ErrCodeValidation:      http.StatusBadRequest,
+	gorkflow.ErrCodeNotFound:        http.StatusNotFound,
+	gorkflow.ErrCodeConflict:        http.StatusConflict,
+	gorkflow.ErrCodeCancelled:       http.StatusConflict,
+	gorkflow.ErrCodeConcurrency:     http.StatusTooManyRequests,
+	gorkflow.ErrCodeTimeout:         http.StatusGatewayTimeout,
+	gorkflow.ErrCodeCircuitOpen:     http.StatusServiceUnavailable,
+	gorkflow.ErrCodeExecutionFailed: http.StatusInternalServerError,
+	gorkflow.ErrCodePanic:           http.StatusInternalServerError,
+	gorkflow.ErrCodeInternalError:   http.StatusInternalServerError,
+}
+
+// StatusCode returns the HTTP status for a WorkflowError code. Unknown codes
+// map to 500.
+func StatusCode(code string) int {
+	if status, ok := statusByCode[code]; ok {
+		return status
+	}
+	return http.StatusInternalServerError
+}
+
+// AsWorkflowError converts an error returned by the engine or a store into a
+// WorkflowError, classifying the sentinel errors of the gorkflow package
+func AsWorkflowError(err error) *gorkflow.WorkflowError {
+	var we *gorkflow.WorkflowError
+	if errors.As(err, &we) {
+		return we
+	}
+
+	code := gorkflow.ErrCodeInternalError
+	switch {
+	case errors.Is(err, gorkflow.ErrRunNotFound),
+		errors.Is(err, gorkflow.ErrStepExecutionNotFound),
+		errors.Is(err, gorkflow.ErrStepOutputNotFound),
+		errors.Is(err, gorkflow.ErrStateNotFound),
+		errors.Is(err, gorkflow.ErrWorkflowNotFound):
+		code = gorkflow.ErrCodeNotFound
+	case errors.Is(err, gorkflow.ErrRunNotResumable),
+		errors.Is(err, gorkflow.ErrRunNotRetryable),
+		errors.Is(err, gorkflow.ErrRunNotSignalable),
+		errors.Is(err, gorkflow.ErrRunNotCancellable):
+		code = gorkflow.ErrCodeConflict
+	case errors.Is(err, gorkflow.ErrCircuitOpen):
+		code = gorkflow.ErrCodeCircuitOpen
+	case errors.Is(err, context.DeadlineExceeded):
+		code = gorkflow.ErrCodeTimeout
+	case errors.Is(err, context.Canceled):
+		code = gorkflow.ErrCodeCancelled
+	case gorkflow.IsConcurrencyError(err):
+		code = gorkflow.ErrCodeConcurrency
+	}
+	return gorkflow.NewWorkflowError(code, err.Error())
+}
+
+// WriteError writes err as {"error": WorkflowError} with the matching status
+func WriteError(w http.ResponseWriter, err error) {
+	we := AsWorkflowError(err)
+	writeJSON(w, StatusCode(we.Code), map[string]any{"error": we})
+}
+
+// badRequest returns a validation error
+func badRequest(message string) error {
+	return gorkflow.NewWorkflowError(gorkflow.ErrCodeValidation, message)
+}
+
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(v)
+}
@@ -0,0 +1,15 @@
+package httpapi
+
+import (
+	"github.com/gofiber/fiber/v3"
+	"github.com/gofiber/fiber/v3/middleware/adaptor"
+)
+
+// Fiber adapts the handler to a fiber app. Mount it under the handler's base
+// path:
+//
+//	api := httpapi.New(eng, httpapi.WithBasePath("/api"))
+//	app.Use(api.BasePath(), httpapi.Fiber(api))
+func Fiber(h *Handler) fiber.Handler {
+	return adaptor.HTTPHandler(h)
+}
@@ -0,0 +1,326 @@
+package httpapi
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/sicko7947/gorkflow"
+)
+
+// WorkflowInfo describes a registered workflow version
+type WorkflowInfo struct {
+	ID          string            `json:"id"`
+	Name        string            `json:"name"`
+	Version     string            `json:"version"`
+	Description string            `json:"description,omitempty"`
+	Tags        map[string]string `json:"tags,omitempty"`
+}
+
+// StartRequest is the body of POST /workflows/{workflowID}/runs
+type StartRequest struct {
+	// Input of the workflow
+	Input json.RawMessage `json:"input,omitempty"`
+
+	// Version to start; empty selects the latest registered version
+	Version string `json:"version,omitempty"`
+
+	ResourceID       string            `json:"resourceId,omitempty"`
+	Tags             map[string]string `json:"tags,omitempty"`
+	CheckConcurrency bool              `json:"checkConcurrency,omitempty"`
+
+	// Wait executes the run within the request and responds with the finished
+	// run. The run is cancelled if the client disconnects.
+	Wait bool `json:"wait,omitempty"`
+}
+
+// RunStatusResponse reports the status of a run after a command
+type RunStatusResponse struct {
+	RunID  string             `json:"runId"`
+	Status gorkflow.RunStatus `json:"status"`
+}
+
+// RunList is a page of runs
+type RunList struct {
+	Runs   []*gorkflow.WorkflowRun `json:"runs"`
+	Limit  int                     `json:"limit"`
+	Offset int                     `json:"offset"`
+
+	// NextOffset is the offset of the next page, absent on the last page
+	NextOffset *int `json:"nextOffset,omitempty"`
+}
+
+func (h *Handler) listWorkflows(w http.ResponseWriter, r *http.Request) {
+	workflows := h.engine.Workflows()
+	infos := make([]WorkflowInfo, len(workflows))
+	for i, wf := range workflows {
+		infos[i] = WorkflowInfo{
+			ID:          wf.ID(),
+			Name:        wf.Name(),
+			Version:     wf.Version(),
+			Description: wf.Description(),
+			Tags:        wf.Tags(),
+		}
+	}
+	writeJSON(w, http.StatusOK, map[string]any{"workflows": infos})
+}
+
+func (h *Handler) startRun(w http.ResponseWriter, r *http.Request) {
+	var req StartRequest
+	if err := h.decodeBody(w, r, &req); err != nil {
+		WriteError(w, err)
+		return
+	}
+
+	opts := []gorkflow.StartOption{gorkflow.WithConcurrencyCheck(req.CheckConcurrency)}
+	if req.ResourceID != "" {
+		opts = append(opts, gorkflow.WithResourceID(req.ResourceID))
+	}
+	if req.Tags != nil {
+		opts = append(opts, gorkflow.WithTags(req.Tags))
+	}
+	if req.Wait {
+		opts = append(opts, gorkflow.WithSynchronousExecution())
+	}
+
+	runID, err := h.engine.StartWorkflowByID(r.Context(), r.PathValue("workflowID"), req.Version, req.Input, opts...)
+	if runID == "" || (err != nil && !req.Wait) {
+		WriteError(w, err)
+		return
+	}
+	if !req.Wait {
+		writeJSON(w, http.StatusAccepted, RunStatusResponse{RunID: runID, Status: gorkflow.RunStatusPending})
+		return
+	}
+
+	// A run that failed is reported through its status, not as an error
+	run, err := h.engine.GetRun(r.Context(), runID)
+	if err != nil {
+		WriteError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, runView(run))
+}
+
+func (h *Handler) listRuns(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	filter := gorkflow.RunFilter{
+		WorkflowID: query.Get("workflow"),
+		ResourceID: query.Get("resource"),
+		Limit:      DefaultPageSize,
+	}
+	if s := query.Get("status"); s != "" {
+		status := gorkflow.RunStatus(strings.ToUpper(s))
+		switch status {
+		case gorkflow.RunStatusPending, gorkflow.RunStatusRunning, gorkflow.RunStatusCompleted,
+			gorkflow.RunStatusFailed, gorkflow.RunStatusCancelled:
+		default:
+			WriteError(w, badRequest("unknown run status "+strconv.Quote(s)))
+			return
+		}
+		filter.Status = &status
+	}
+	var err error
+	if filter.Limit, err = intParam(query.Get("limit"), DefaultPageSize, 1, MaxPageSize); err != nil {
+		WriteError(w, badRequest("limit "+err.Error()))
+		return
+	}
+	if filter.Offset, err = intParam(query.Get("offset"), 0, 0, -1); err != nil {
+		WriteError(w, badRequest("offset "+err.Error()))
+		return
+	}
+
+	// One extra run tells whether another page follows
+	filter.Limit++
+	runs, err := h.engine.ListRuns(r.Context(), filter)
+	if err != nil {
+		WriteError(w, err)
+		return
+	}
+	filter.Limit--
+
+	list := RunList{Runs: make([]*gorkflow.WorkflowRun, 0, len(runs)), Limit: filter.Limit, Offset: filter.Offset}
+	if len(runs) > filter.Limit {
+		runs = runs[:filter.Limit]
+		next := filter.Offset + filter.Limit
+		list.NextOffset = &next
+	}
+	for _, run := range runs {
+		list.Runs = append(list.Runs, runView(run))
+	}
+	writeJSON(w, http.StatusOK, list)
+}
+
+func (h *Handler) getRun(w http.ResponseWriter, r *http.Request) {
+	run, err := h.engine.GetRun(r.Context(), r.PathValue("runID"))
+	if err != nil {
+		WriteError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, runView(run))
+}
+
+func (h *Handler) listSteps(w http.ResponseWriter, r *http.Request) {
+	runID := r.PathValue("runID")
+	if _, err := h.engine.GetRun(r.Context(), runID); err != nil {
+		WriteError(w, err)
+		return
+	}
+	steps, err := h.engine.GetStepExecutions(r.Context(), runID)
+	if err != nil {
+		WriteError(w, err)
+		return
+	}
+	views := make([]*gorkflow.StepExecution, len(steps))
+	for i, step := range steps {
+		views[i] = stepView(step)
+	}
+	writeJSON(w, http.StatusOK, map[string]any{"steps": views})
+}
+
+func (h *Handler) getStepOutput(w http.ResponseWriter, r *http.Request) {
+	output, err := h.engine.LoadStepOutput(r.Context(), r.PathValue("runID"), r.PathValue("stepID"))
+	if err != nil {
+		WriteError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, jsonPayload(output))
+}
+
+func (h *Handler) cancelRun(w http.ResponseWriter, r *http.Request) {
+	runID := r.PathValue("runID")
+	if err := h.engine.Cancel(r.Context(), runID); err != nil {
+		WriteError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusAccepted, RunStatusResponse{RunID: runID, Status: gorkflow.RunStatusCancelled})
+}
+
+func (h *Handler) retryRun(w http.ResponseWriter, r *http.Request) {
+	runID := r.PathValue("runID")
+	if err := h.engine.RetryRun(r.Context(), runID); err != nil {
+		WriteError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusAccepted, RunStatusResponse{RunID: runID, Status: gorkflow.RunStatusPending})
+}
+
+func (h *Handler) signalRun(w http.ResponseWriter, r *http.Request) {
+	var payload json.RawMessage
+	if err := h.decodeBody(w, r, &payload); err != nil {
+		WriteError(w, err)
+		return
+	}
+	if len(payload) == 0 {
+		payload = json.RawMessage("null")
+	}
+	if err := h.engine.Signal(r.Context(), r.PathValue("runID"), r.PathValue("name"), payload); err != nil {
+		WriteError(w, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (h *Handler) getState(w http.ResponseWriter, r *http.Request) {
+	runID := r.PathValue("runID")
+	if _, err := h.engine.GetRun(r.Context(), runID); err != nil {
+		WriteError(w, err)
+		return
+	}
+	state, err := h.engine.Store().GetAllState(r.Context(), runID)
+	if err != nil {
+		WriteError(w, err)
+		return
+	}
+	values := make(map[string]json.RawMessage, len(state))
+	for key, value := range state {
+		values[key] = jsonPayload(value)
+	}
+	writeJSON(w, http.StatusOK, map[string]any{"state": values})
+}
+
+func (h *Handler) getStateKey(w http.ResponseWriter, r *http.Request) {
+	value, err := h.engine.Store().LoadState(r.Context(), r.PathValue("runID"), r.PathValue("key"))
+	if err != nil {
+		WriteError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, jsonPayload(value))
+}
+
+// decodeBody decodes a JSON request body into v. An empty body leaves v unchanged.
+func (h *Handler) decodeBody(w http.ResponseWriter, r *http.Request, v any) error {
+	body, err := io.ReadAll(http.MaxBytesReader(w, r.Body, h.maxBodySize))
+	if err != nil {
+		var tooLarge *http.MaxBytesError
+		if errors.As(err, &tooLarge) {
+			return badRequest("request body exceeds " + strconv.FormatInt(tooLarge.Limit, 10) + " bytes")
+		}
+		return badRequest("failed to read request body")
+	}
+	if len(body) == 0 {
+		return nil
+	}
+	if err := json.Unmarshal(body, v); err != nil {
+		return badRequest("invalid JSON body: " + err.Error())
+	}
+	return nil
+}
+
+// intParam parses an integer query parameter within [min, max]; max < 0 means
+// no upper bound
+func intParam(s string, def, min, max int) (int, error) {
+	if s == "" {
+		return def, nil
+	}
+	n, err := strconv.Atoi(s)
+	if err != nil || n < min || (max >= 0 && n > max) {
+		if max < 0 {
+			return 0, errors.New("must be an integer of at least " + strconv.Itoa(min))
+		}
+		return 0, errors.New("must be an integer from " + strconv.Itoa(min) + " to " + strconv.Itoa(max))
+	}
+	return n, nil
+}
+
+// jsonPayload returns a payload as JSON; payloads that cannot be decoded
+// become a base64 string
+func jsonPayload(data []byte) json.RawMessage {
+	if len(data) == 0 {
+		return json.RawMessage("null")
+	}
+	if out, err := gorkflow.PayloadJSON(data); err == nil {
+		return out
+	}
+	out, _ := json.Marshal(base64.StdEncoding.EncodeToString(data))
+	return out
+}
+
+// runView returns a copy of run with its payloads as JSON
+func runView(run *gorkflow.WorkflowRun) *gorkflow.WorkflowRun {
+	view := *run
+	view.Input = optionalPayload(run.Input)
+	view.Output = optionalPayload(run.Output)
+	view.Context = optionalPayload(run.Context)
+	return &view
+}
+
+// stepView returns a copy of step with its payloads as JSON
+func stepView(step *gorkflow.StepExecution) *gorkflow.StepExecution {
+	view := *step
+	view.Input = optionalPayload(step.Input)
+	view.Output = optionalPayload(step.Output)
+	return &view
+}
+
+// optionalPayload is jsonPayload for omitempty fields
+func optionalPayload(data []byte) json.RawMessage {
+	if len(data) == 0 {
+		return nil
+	}
+	return jsonPayload(data)
+}
@@ -0,0 +1,108 @@
+// Package httpapi serves a REST API for an engine: starting registered
+// workflows, inspecting runs, steps and state, and cancelling, retrying and
+// signalling runs.
+//
+//	api := httpapi.New(eng, httpapi.WithBasePath("/api"))
+//	mux := http.NewServeMux()
+//	api.Mount(mux)
+//
+// Errors are returned as {"error": WorkflowError} with an HTTP status derived
+// from the error code; see StatusCode.
+package httpapi
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/sicko7947/gorkflow"
+	"github.com/sicko7947/gorkflow/engine"
+)
+
+const (
+	// DefaultMaxBodySize limits request bodies unless WithMaxBodySize is used
+	DefaultMaxBodySize = 1 << 20
+
+	// DefaultPageSize is the number of runs listed when no limit is given
+	DefaultPageSize = 50
+
+	// MaxPageSize caps the limit of a run listing
+	MaxPageSize = 1000
+)
+
+// Handler serves the REST API of an engine
+type Handler struct {
+	engine      *engine.Engine
+	basePath    string
+	maxBodySize int64
+	mux         *http.ServeMux
+}
+
+// Option configures a Handler
+type Option func(*Handler)
+
+// WithBasePath serves the API under path, e.g. "/api/v1"
+func WithBasePath(path string) Option {
+	return func(h *Handler) {
+		h.basePath = strings.TrimSuffix(path, "/")
+	}
+}
+
+// WithMaxBodySize limits the size of request bodies in bytes
+func WithMaxBodySize(n int64) Option {
+	return func(h *Handler) {
+		if n > 0 {
+			h.maxBodySize = n
+		}
+	}
+}
+
+// New creates a handler serving the API of eng. Workflows must be registered
+// on eng to be started by ID.
+func New(eng *engine.Engine, opts ...Option) *Handler {
+	h := &Handler{
+		engine:      eng,
+		maxBodySize: DefaultMaxBodySize,
+		mux:         http.NewServeMux(),
+	}
+	for _, opt := range opts {
+		opt(h)
+	}
+
+	h.handle("GET /workflows", h.listWorkflows)
+	h.handle("POST /workflows/{workflowID}/runs", h.startRun)
+	h.handle("GET /runs", h.listRuns)
+	h.handle("GET /runs/{runID}", h.getRun)
+	h.handle("GET /runs/{runID}/steps", h.listSteps)
+	h.handle("GET /runs/{runID}/steps/{stepID}/output", h.getStepOutput)
+	h.handle("POST /runs/{runID}/cancel", h.cancelRun)
+	h.handle("POST /runs/{runID}/retry", h.retryRun)
+	h.handle("POST /runs/{runID}/signals/{name}", h.signalRun)
+	h.handle("GET /runs/{runID}/state", h.getState)
+	h.handle("GET /runs/{runID}/state/{key}", h.getStateKey)
+	return h
+}
+
+// BasePath returns the path the API is served under
+func (h *Handler) BasePath() string {
+	return h.basePath
+}
+
+// Mount registers the handler on mux under its base path
+func (h *Handler) Mount(mux *http.ServeMux) {
+	mux.Handle(h.basePath+"/", h)
+}
+
+// ServeHTTP implements http.Handler
+func (h *Handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if _, pattern := h.mux.Handler(r); pattern == "" {
+		WriteError(w, gorkflow.NewWorkflowError(gorkflow.ErrCodeNotFound, "no route for "+r.Method+" "+r.URL.Path))
+		return
+	}
+	h.mux.ServeHTTP(w, r)
+}
+
+// handle registers a route relative to the base path
+func (h *Handler) handle(route string, fn http.HandlerFunc) {
+	method, path, _ := strings.Cut(route, " ")
+	h.mux.HandleFunc(method+" "+h.basePath+path, fn)
+}
@@ -0,0 +1,252 @@
+package httpapi
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/gofiber/fiber/v3"
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/sicko7947/gorkflow"
+	"github.com/sicko7947/gorkflow/engine"
+	"github.com/sicko7947/gorkflow/store"
+)
+
+type order struct {
+	Items int `json:"items"`
+}
+
+type receipt struct {
+	Total    int    `json:"total"`
+	Approver string `json:"approver,omitempty"`
+}
+
+// newServer serves an engine with an "orders" workflow whose charge step
+// fails while failCharge is set
+func newServer(t *testing.T, opts ...Option) (*httptest.Server, *engine.Engine, *atomic.Bool) {
+	t.Helper()
+	eng := engine.NewEngine(store.NewMemoryStore(), engine.WithLogger(zerolog.Nop()))
+	t.Cleanup(func() { eng.Close() })
+
+	var failCharge atomic.Bool
+	price := gorkflow.NewStep("price", "Price", func(ctx *gorkflow.StepContext, in order) (receipt, error) {
+		if err := ctx.State.Set("items", in.Items); err != nil {
+			return receipt{}, err
+		}
+		return receipt{Total: in.Items * 10}, nil
+	})
+	charge := gorkflow.NewStep("charge", "Charge", func(ctx *gorkflow.StepContext, in receipt) (receipt, error) {
+		if failCharge.Load() {
+			return receipt{}, errors.New("card declined")
+		}
+		approval, _, err := gorkflow.ReceiveSignal[string](ctx, "approval")
+		in.Approver = approval
+		return in, err
+	}, gorkflow.WithRetries(0))
+	wf, err := gorkflow.NewWorkflow("orders", "Orders").WithVersion("1").ThenStep(price).ThenStep(charge).Build()
+	require.NoError(t, err)
+	require.NoError(t, eng.Register(wf))
+
+	mux := http.NewServeMux()
+	New(eng, opts...).Mount(mux)
+	srv := httptest.NewServer(mux)
+	t.Cleanup(srv.Close)
+	return srv, eng, &failCharge
+}
+
+// call sends a request and decodes the JSON response into out, if given
+func call(t *testing.T, srv *httptest.Server, method, path, body string, out any) int {
+	t.Helper()
+	req, err := http.NewRequest(method, srv.URL+path, strings.NewReader(body))
+	require.NoError(t, err)
+	resp, err := srv.Client().Do(req)
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	data, err := io.ReadAll(resp.Body)
+	require.NoError(t, err)
+	if out != nil {
+		require.NoError(t, json.Unmarshal(data, out), "body: %s", data)
+	}
+	return resp.StatusCode
+}
+
+type errorBody struct {
+	Error gorkflow.WorkflowError `json:"error"`
+}
+
+func TestHandler_StartAndInspect(t *testing.T) {
+	srv, _, _ := newServer(t, WithBasePath("/api"))
+
+	var workflows struct{ Workflows []WorkflowInfo }
+	require.Equal(t, http.StatusOK, call(t, srv, "GET", "/api/workflows", "", &workflows))
+	require.Len(t, workflows.Workflows, 1)
+	assert.Equal(t, "orders", workflows.Workflows[0].ID)
+
+	var run gorkflow.WorkflowRun
+	status := call(t, srv, "POST", "/api/workflows/orders/runs", `{"input":{"items":3},"resourceId":"cart-1","wait":true}`, &run)
+	require.Equal(t, http.StatusOK, status)
+	assert.Equal(t, gorkflow.RunStatusCompleted, run.Status)
+	assert.Equal(t, "cart-1", run.ResourceID)
+	assert.JSONEq(t, `{"total":30}`, string(run.Output))
+
+	var got gorkflow.WorkflowRun
+	require.Equal(t, http.StatusOK, call(t, srv, "GET", "/api/runs/"+run.RunID, "", &got))
+	assert.Equal(t, run.RunID, got.RunID)
+
+	var steps struct{ Steps []gorkflow.StepExecution }
+	require.Equal(t, http.StatusOK, call(t, srv, "GET", "/api/runs/"+run.RunID+"/steps", "", &steps))
+	require.Len(t, steps.Steps, 2)
+	assert.Equal(t, "price", steps.Steps[0].StepID)
+
+	var output receipt
+	require.Equal(t, http.StatusOK, call(t, srv, "GET", "/api/runs/"+run.RunID+"/steps/price/output", "", &output))
+	assert.Equal(t, 30, output.Total)
+
+	var state struct{ State map[string]json.RawMessage }
+	require.Equal(t, http.StatusOK, call(t, srv, "GET", "/api/runs/"+run.RunID+"/state", "", &state))
+	assert.JSONEq(t, `3`, string(state.State["items"]))
+
+	var items int
+	require.Equal(t, http.StatusOK, call(t, srv, "GET", "/api/runs/"+run.RunID+"/state/items", "", &items))
+	assert.Equal(t, 3, items)
+}
+
+func TestHandler_StartAsync(t *testing.T) {
+	srv, eng, _ := newServer(t)
+
+	var started RunStatusResponse
+	require.Equal(t, http.StatusAccepted, call(t, srv, "POST", "/workflows/orders/runs", `{"input":{"items":1}}`, &started))
+	run, err := eng.WaitForRun(t.Context(), started.RunID)
+	require.NoError(t, err)
+	assert.Equal(t, gorkflow.RunStatusCompleted, run.Status)
+}
+
+func TestHandler_ListRuns(t *testing.T) {
+	srv, _, failCharge := newServer(t)
+	for i := 0; i < 5; i++ {
+		failCharge.Store(i%2 == 1)
+		call(t, srv, "POST", "/workflows/orders/runs", fmt.Sprintf(`{"input":{"items":%d},"wait":true}`, i), nil)
+	}
+
+	var page RunList
+	require.Equal(t, http.StatusOK, call(t, srv, "GET", "/runs?limit=2", "", &page))
+	require.Len(t, page.Runs, 2)
+	require.NotNil(t, page.NextOffset)
+	assert.Equal(t, 2, *page.NextOffset)
+
+	var last RunList
+	require.Equal(t, http.StatusOK, call(t, srv, "GET", "/runs?limit=2&offset=4", "", &last))
+	require.Len(t, last.Runs, 1)
+	assert.Nil(t, last.NextOffset)
+	assert.NotEqual(t, page.Runs[0].RunID, last.Runs[0].RunID)
+
+	var failed RunList
+	require.Equal(t, http.StatusOK, call(t, srv, "GET", "/runs?workflow=orders&status=failed", "", &failed))
+	assert.Len(t, failed.Runs, 2)
+
+	var bad errorBody
+	assert.Equal(t, http.StatusBadRequest, call(t, srv, "GET", "/runs?limit=0", "", &bad))
+	assert.Equal(t, gorkflow.ErrCodeValidation, bad.Error.Code)
+	assert.Equal(t, http.StatusBadRequest, call(t, srv, "GET", "/runs?status=done", "", &bad))
+}
+
+func TestHandler_RetrySignalAndCancel(t *testing.T) {
+	srv, eng, failCharge := newServer(t)
+
+	failCharge.Store(true)
+	var run gorkflow.WorkflowRun
+	require.Equal(t, http.StatusOK, call(t, srv, "POST", "/workflows/orders/runs", `{"input":{"items":2},"wait":true}`, &run))
+	require.Equal(t, gorkflow.RunStatusFailed, run.Status)
+	require.NotNil(t, run.Error)
+
+	// Finished runs cannot be signalled or cancelled
+	var conflict errorBody
+	assert.Equal(t, http.StatusConflict, call(t, srv, "POST", "/runs/"+run.RunID+"/signals/approval", `"ada"`, &conflict))
+	assert.Equal(t, gorkflow.ErrCodeConflict, conflict.Error.Code)
+	assert.Equal(t, http.StatusConflict, call(t, srv, "POST", "/runs/"+run.RunID+"/cancel", "", nil))
+
+	// Signal the run while it is pending again, then let it finish
+	_, err := gorkflow.ResetRun(t.Context(), eng.Store(), run.RunID, time.Now())
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusNoContent, call(t, srv, "POST", "/runs/"+run.RunID+"/signals/approval", `"ada"`, nil))
+	require.NoError(t, eng.Cancel(t.Context(), run.RunID))
+
+	failCharge.Store(false)
+	var retried RunStatusResponse
+	require.Equal(t, http.StatusAccepted, call(t, srv, "POST", "/runs/"+run.RunID+"/retry", "", &retried))
+	finished, err := eng.WaitForRun(t.Context(), run.RunID)
+	require.NoError(t, err)
+	assert.Equal(t, gorkflow.RunStatusCompleted, finished.Status)
+	assert.JSONEq(t, `{"total":20,"approver":"ada"}`, string(finished.Output))
+
+	assert.Equal(t, http.StatusConflict, call(t, srv, "POST", "/runs/"+run.RunID+"/retry", "", nil))
+}
+
+func TestHandler_Errors(t *testing.T) {
+	srv, _, _ := newServer(t, WithMaxBodySize(64))
+
+	tests := []struct {
+		name   string
+		method string
+		path   string
+		body   string
+		status int
+		code   string
+	}{
+		{"unknown run", "GET", "/runs/missing", "", http.StatusNotFound, gorkflow.ErrCodeNotFound},
+		{"unknown workflow", "POST", "/workflows/refunds/runs", "", http.StatusNotFound, gorkflow.ErrCodeNotFound},
+		{"unknown route", "GET", "/nothing", "", http.StatusNotFound, gorkflow.ErrCodeNotFound},
+		{"invalid body", "POST", "/workflows/orders/runs", "{", http.StatusBadRequest, gorkflow.ErrCodeValidation},
+		{"body too large", "POST", "/workflows/orders/runs", `{"input":"` + strings.Repeat("x", 64) + `"}`, http.StatusBadRequest, gorkflow.ErrCodeValidation},
+		{"missing output", "GET", "/runs/missing/steps/price/output", "", http.StatusNotFound, gorkflow.ErrCodeNotFound},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var body errorBody
+			assert.Equal(t, tt.status, call(t, srv, tt.method, tt.path, tt.body, &body))
+			assert.Equal(t, tt.code, body.Error.Code)
+			assert.NotEmpty(t, body.Error.Message)
+		})
+	}
+}
+
+func TestStatusCode(t *testing.T) {
+	assert.Equal(t, http.StatusTooManyRequests, StatusCode(gorkflow.ErrCodeConcurrency))
+	assert.Equal(t, http.StatusGatewayTimeout, StatusCode(gorkflow.ErrCodeTimeout))
+	assert.Equal(t, http.StatusInternalServerError, StatusCode("SOMETHING_ELSE"))
+
+	we := AsWorkflowError(fmt.Errorf("loading: %w", gorkflow.ErrStateNotFound))
+	assert.Equal(t, gorkflow.ErrCodeNotFound, we.Code)
+	we = AsWorkflowError(gorkflow.NewWorkflowError(gorkflow.ErrCodeCircuitOpen, "open"))
+	assert.Equal(t, gorkflow.ErrCodeCircuitOpen, we.Code)
+}
+
+func TestFiber(t *testing.T) {
+	eng := engine.NewEngine(store.NewMemoryStore(), engine.WithLogger(zerolog.Nop()))
+	t.Cleanup(func() { eng.Close() })
+	api := New(eng, WithBasePath("/api"))
+
+	app := fiber.New()
+	app.Use(api.BasePath(), Fiber(api))
+
+	resp, err := app.Test(httptest.NewRequest("GET", "/api/workflows", nil))
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+
+	resp, err = app.Test(httptest.NewRequest("GET", "/api/runs/missing", nil))
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusNotFound, resp.StatusCode)
+	var body errorBody
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&body))
+	assert.Equal(t, gorkflow.ErrCodeNotFound, body.Error.Code)
+}
@@ -0,0 +1,44 @@
+package gorkflow
+
+// StepInvoker executes a step with serialized input and returns serialized output
+type StepInvoker func(ctx *StepContext, input []byte) ([]byte, error)
+
+// StepInterceptor wraps the execution of a step attempt. It receives the step
+// context and the raw input bytes, and must call next to continue the chain
+// (or return without calling it to short-circuit). The output bytes and error
+// returned by next can be inspected or replaced.
+//
+// Interceptors run once per attempt, in this order from outermost to innermost:
+// engine-level (engine.WithStepInterceptor), workflow-level (WorkflowBuilder.Use),
+// then step-level (WithInterceptors). Within each level they run in registration order.
+type StepInterceptor func(ctx *StepContext, input []byte, next StepInvoker) ([]byte, error)
+
+// ChainInterceptors wraps invoker with the given interceptors, the first being outermost
+func ChainInterceptors(invoker StepInvoker, interceptors ...StepInterceptor) StepInvoker {
+	for i := len(interceptors) - 1; i >= 0; i-- {
+		interceptor := interceptors[i]
+		next := invoker
+		invoker = func(ctx *StepContext, input []byte) ([]byte, error) {
+			return interceptor(ctx, input, next)
+		}
+	}
+	return invoker
+}
+
+// WithInterceptors adds step-level interceptors that wrap only this step
+func WithInterceptors(interceptors ...StepInterceptor) StepOption {
+	return stepOptionFunc(func(s interface{}) {
+		if step, ok := s.(interface{ AddInterceptors(...StepInterceptor) }); ok {
+			step.AddInterceptors(interceptors...)
+		}
+	})
+}
+
+// StepInterceptors returns the step-level interceptors of a step, looking through
+// conditional wrappers. Steps that do not support interceptors return nil.
+func StepInterceptors(step StepExecutor) []StepInterceptor {
+	if s, ok := step.(interface{ GetInterceptors() []StepInterceptor }); ok {
+		return s.GetInterceptors()
+	}
+	return nil
+}
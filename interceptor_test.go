@@ -0,0 +1,52 @@
+package gorkflow
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestChainInterceptors_Order(t *testing.T) {
+	var calls []string
+	record := func(name string) StepInterceptor {
+		return func(ctx *StepContext, input []byte, next StepInvoker) ([]byte, error) {
+			calls = append(calls, name)
+			return next(ctx, append(input, name...))
+		}
+	}
+
+	invoker := ChainInterceptors(func(ctx *StepContext, input []byte) ([]byte, error) {
+		calls = append(calls, "invoker")
+		return input, nil
+	}, record("a"), record("b"))
+
+	out, err := invoker(&StepContext{}, []byte(">"))
+	require.NoError(t, err)
+	assert.Equal(t, ">ab", string(out))
+	assert.Equal(t, []string{"a", "b", "invoker"}, calls)
+}
+
+func TestChainInterceptors_Empty(t *testing.T) {
+	sentinel := errors.New("boom")
+	invoker := ChainInterceptors(func(ctx *StepContext, input []byte) ([]byte, error) {
+		return nil, sentinel
+	})
+
+	_, err := invoker(&StepContext{}, nil)
+	assert.ErrorIs(t, err, sentinel)
+}
+
+func TestWithInterceptors_ForwardedByConditionalWrappers(t *testing.T) {
+	noop := func(ctx *StepContext, input []byte, next StepInvoker) ([]byte, error) {
+		return next(ctx, input)
+	}
+	step := NewStep("s", "S", func(ctx *StepContext, in TestInput) (TestOutput, error) {
+		return TestOutput{}, nil
+	}, WithInterceptors(noop, noop))
+
+	assert.Len(t, StepInterceptors(step), 2)
+	assert.Len(t, StepInterceptors(NewConditionalStep(step, func(*StepContext) (bool, error) { return true, nil }, nil)), 2)
+	assert.Len(t, StepInterceptors(WrapStepWithCondition(step, func(*StepContext) (bool, error) { return true, nil }, nil)), 2)
+}
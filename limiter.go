@@ -0,0 +1,256 @@
+package gorkflow
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"golang.org/x/time/rate"
+)
+
+// Limiter guards access to a shared resource, such as a third-party API.
+// Acquire blocks until the caller may proceed or ctx is done; the returned
+// release function must be called once the protected work has finished.
+//
+// Limiters are registered on the engine by name (engine.WithLimiter) and
+// attached to steps with WithLimiters, so a single limit holds across all runs.
+type Limiter interface {
+	Acquire(ctx context.Context) (release func(), err error)
+}
+
+// noopRelease is returned by limiters that have nothing to release
+func noopRelease() {}
+
+// rateLimiter is an in-process token bucket
+type rateLimiter struct {
+	limiter *rate.Limiter
+
+	mu    sync.Mutex
+	clock Clock
+}
+
+// NewRateLimiter creates a token bucket limiter that allows ratePerSecond
+// acquisitions per second on average, with bursts of up to burst
+func NewRateLimiter(ratePerSecond float64, burst int) Limiter {
+	if burst < 1 {
+		burst = 1
+	}
+	return &rateLimiter{
+		limiter: rate.NewLimiter(rate.Limit(ratePerSecond), burst),
+		clock:   SystemClock,
+	}
+}
+
+// SetClock sets the clock that refills the bucket and times waits. The engine
+// passes its own clock to the limiters registered on it.
+func (l *rateLimiter) SetClock(clock Clock) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if clock != nil {
+		l.clock = clock
+	}
+}
+
+func (l *rateLimiter) Acquire(ctx context.Context) (func(), error) {
+	l.mu.Lock()
+	clock := l.clock
+	l.mu.Unlock()
+
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	now := clock.Now()
+	r := l.limiter.ReserveN(now, 1)
+	if !r.OK() {
+		return nil, fmt.Errorf("rate limiter cannot grant a token with burst %d", l.limiter.Burst())
+	}
+	delay := r.DelayFrom(now)
+	if delay <= 0 {
+		return noopRelease, nil
+	}
+	select {
+	case <-clock.After(delay):
+		return noopRelease, nil
+	case <-ctx.Done():
+		// Give the token back for the callers still waiting
+		r.CancelAt(clock.Now())
+		return nil, ctx.Err()
+	}
+}
+
+// concurrencyPool is an in-process counting semaphore
+type concurrencyPool struct {
+	slots chan struct{}
+}
+
+// NewConcurrencyPool creates a limiter that allows at most size concurrent holders
+func NewConcurrencyPool(size int) Limiter {
+	if size < 1 {
+		size = 1
+	}
+	return &concurrencyPool{slots: make(chan struct{}, size)}
+}
+
+func (p *concurrencyPool) Acquire(ctx context.Context) (func(), error) {
+	select {
+	case p.slots <- struct{}{}:
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+	var once sync.Once
+	return func() { once.Do(func() { <-p.slots }) }, nil
+}
+
+// LeaseStore is implemented by stores that can coordinate limiters across processes.
+// A lease is one of a bounded number of slots of a named resource, held by a holder
+// until it is released or expires.
+type LeaseStore interface {
+	// AcquireLease takes a slot of resource for holder if fewer than limit unexpired
+	// leases exist, or extends the lease if holder already has one. It reports
+	// whether the lease is held.
+	AcquireLease(ctx context.Context, resource, holder string, limit int, ttl time.Duration) (bool, error)
+
+	// ReleaseLease frees the slot held by holder, if any
+	ReleaseLease(ctx context.Context, resource, holder string) error
+}
+
+// LeaseLimiterOptions configures store-backed limiters
+type LeaseLimiterOptions struct {
+	// TTL bounds how long a slot stays held if its process dies (default 30s).
+	// Concurrency leases are renewed while held.
+	TTL time.Duration
+
+	// PollInterval is how often a waiting caller retries (default 100ms)
+	PollInterval time.Duration
+
+	// Clock times polling and lease renewal (default SystemClock)
+	Clock Clock
+}
+
+// DefaultLeaseLimiterOptions returns sensible defaults
+func DefaultLeaseLimiterOptions() LeaseLimiterOptions {
+	return LeaseLimiterOptions{
+		TTL:          30 * time.Second,
+		PollInterval: 100 * time.Millisecond,
+		Clock:        SystemClock,
+	}
+}
+
+// leaseLimiter implements Limiter on top of a LeaseStore
+type leaseLimiter struct {
+	store    LeaseStore
+	resource string
+	limit    int
+	ttl      time.Duration
+	poll     time.Duration
+	clock    Clock
+
+	// renew keeps the lease alive until release; rate leases simply expire
+	renew bool
+}
+
+// NewStoreConcurrencyPool creates a concurrency pool of size slots shared by every
+// process using the same store and resource name
+func NewStoreConcurrencyPool(store LeaseStore, resource string, size int, opts LeaseLimiterOptions) Limiter {
+	l := newLeaseLimiter(store, resource, size, opts)
+	l.renew = true
+	return l
+}
+
+// NewStoreRateLimiter creates a sliding-window rate limiter allowing at most n
+// acquisitions per window, shared by every process using the same store and resource name
+func NewStoreRateLimiter(store LeaseStore, resource string, n int, window time.Duration, opts LeaseLimiterOptions) Limiter {
+	opts.TTL = window
+	return newLeaseLimiter(store, resource, n, opts)
+}
+
+func newLeaseLimiter(store LeaseStore, resource string, limit int, opts LeaseLimiterOptions) *leaseLimiter {
+	defaults := DefaultLeaseLimiterOptions()
+	if opts.TTL <= 0 {
+		opts.TTL = defaults.TTL
+	}
+	if opts.PollInterval <= 0 {
+		opts.PollInterval = defaults.PollInterval
+	}
+	if opts.Clock == nil {
+		opts.Clock = defaults.Clock
+	}
+	if limit < 1 {
+		limit = 1
+	}
+	return &leaseLimiter{
+		store:    store,
+		resource: resource,
+		limit:    limit,
+		ttl:      opts.TTL,
+		poll:     opts.PollInterval,
+		clock:    opts.Clock,
+	}
+}
+
+func (l *leaseLimiter) Acquire(ctx context.Context) (func(), error) {
+	holder := uuid.New().String()
+
+	for {
+		ok, err := l.store.AcquireLease(ctx, l.resource, holder, l.limit, l.ttl)
+		if err != nil {
+			return nil, fmt.Errorf("failed to acquire lease on %q: %w", l.resource, err)
+		}
+		if ok {
+			break
+		}
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-l.clock.After(l.poll):
+		}
+	}
+
+	if !l.renew {
+		return noopRelease, nil
+	}
+
+	// Renew the lease until released so long-running work keeps its slot
+	done := make(chan struct{})
+	go func() {
+		ticker := l.clock.NewTicker(l.ttl / 3)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-done:
+				return
+			case <-ticker.C():
+				_, _ = l.store.AcquireLease(context.Background(), l.resource, holder, l.limit, l.ttl)
+			}
+		}
+	}()
+
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			close(done)
+			_ = l.store.ReleaseLease(context.Background(), l.resource, holder)
+		})
+	}, nil
+}
+
+// WithLimiters makes each attempt of the step acquire the named engine limiters,
+// in order, before it runs. Time spent waiting does not count against the step timeout.
+func WithLimiters(names ...string) StepOption {
+	return stepOptionFunc(func(s interface{}) {
+		if step, ok := s.(interface{ AddLimiters(...string) }); ok {
+			step.AddLimiters(names...)
+		}
+	})
+}
+
+// StepLimiters returns the limiter names attached to a step, looking through
+// conditional wrappers. Steps that do not support limiters return nil.
+func StepLimiters(step StepExecutor) []string {
+	if s, ok := step.(interface{ GetLimiters() []string }); ok {
+		return s.GetLimiters()
+	}
+	return nil
+}
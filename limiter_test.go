@@ -0,0 +1,115 @@
+package gorkflow
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeLeaseStore is a minimal in-memory LeaseStore
+type fakeLeaseStore struct {
+	mu       sync.Mutex
+	leases   map[string]time.Time
+	released []string
+}
+
+func newFakeLeaseStore() *fakeLeaseStore {
+	return &fakeLeaseStore{leases: make(map[string]time.Time)}
+}
+
+func (s *fakeLeaseStore) AcquireLease(ctx context.Context, resource, holder string, limit int, ttl time.Duration) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	now := time.Now()
+	for h, exp := range s.leases {
+		if !exp.After(now) {
+			delete(s.leases, h)
+		}
+	}
+	if _, held := s.leases[holder]; !held && len(s.leases) >= limit {
+		return false, nil
+	}
+	s.leases[holder] = now.Add(ttl)
+	return true, nil
+}
+
+func (s *fakeLeaseStore) ReleaseLease(ctx context.Context, resource, holder string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.leases, holder)
+	s.released = append(s.released, holder)
+	return nil
+}
+
+func TestConcurrencyPool_BlocksUntilRelease(t *testing.T) {
+	pool := NewConcurrencyPool(1)
+	release, err := pool.Acquire(context.Background())
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	_, err = pool.Acquire(ctx)
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+
+	release()
+	release() // idempotent
+
+	release, err = pool.Acquire(context.Background())
+	require.NoError(t, err)
+	release()
+}
+
+func TestRateLimiter_ContextCancelled(t *testing.T) {
+	limiter := NewRateLimiter(1, 1)
+	_, err := limiter.Acquire(context.Background())
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, err = limiter.Acquire(ctx)
+	assert.ErrorIs(t, err, context.Canceled)
+}
+
+func TestStoreConcurrencyPool(t *testing.T) {
+	store := newFakeLeaseStore()
+	pool := NewStoreConcurrencyPool(store, "vendor", 1, LeaseLimiterOptions{
+		TTL:          30 * time.Millisecond,
+		PollInterval: 5 * time.Millisecond,
+	})
+
+	release, err := pool.Acquire(context.Background())
+	require.NoError(t, err)
+
+	// The lease is renewed while held, so it outlives its TTL
+	ctx, cancel := context.WithTimeout(context.Background(), 80*time.Millisecond)
+	defer cancel()
+	_, err = pool.Acquire(ctx)
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+
+	release()
+	assert.Len(t, store.released, 1)
+
+	release, err = pool.Acquire(context.Background())
+	require.NoError(t, err)
+	release()
+}
+
+func TestStoreRateLimiter(t *testing.T) {
+	store := newFakeLeaseStore()
+	limiter := NewStoreRateLimiter(store, "api", 2, 50*time.Millisecond, LeaseLimiterOptions{PollInterval: 5 * time.Millisecond})
+
+	start := time.Now()
+	for i := 0; i < 3; i++ {
+		release, err := limiter.Acquire(context.Background())
+		require.NoError(t, err)
+		release()
+	}
+
+	// The third acquisition waits for the window to pass; releases do not free slots
+	assert.GreaterOrEqual(t, time.Since(start), 45*time.Millisecond)
+	assert.Empty(t, store.released)
+}
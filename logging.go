@@ -25,6 +25,11 @@ const (
 
 	// Persistence events
 	EventPersistenceError = "persistence_error"
+
+	// Circuit breaker events
+	EventCircuitOpened   = "circuit_opened"
+	EventCircuitHalfOpen = "circuit_half_open"
+	EventCircuitClosed   = "circuit_closed"
 )
 
 // LogWorkflowCreated logs when a workflow run is created
@@ -148,6 +153,28 @@ func LogPersistenceError(logger zerolog.Logger, runID, operation string, err err
 		Msg("Persistence error")
 }
 
+// LogCircuitStateChanged logs a circuit breaker state transition
+func LogCircuitStateChanged(logger zerolog.Logger, name string, from, to CircuitState) {
+	logger.Warn().
+		Str("event", CircuitEventType(to)).
+		Str("circuit", name).
+		Str("from", from.String()).
+		Str("to", to.String()).
+		Msg("Circuit breaker state changed")
+}
+
+// CircuitEventType returns the event type for a transition into state
+func CircuitEventType(state CircuitState) string {
+	switch state {
+	case CircuitOpen:
+		return EventCircuitOpened
+	case CircuitHalfOpen:
+		return EventCircuitHalfOpen
+	default:
+		return EventCircuitClosed
+	}
+}
+
 // WorkflowLogger creates a logger enriched with workflow context
 func WorkflowLogger(baseLogger zerolog.Logger, runID, workflowID, resourceID string) zerolog.Logger {
 	return baseLogger.With().
@@ -0,0 +1,245 @@
+// Package metrics exposes Prometheus metrics for the workflow engine and stores.
+//
+// A Metrics value is an engine event listener and a store decorator at the same time:
+//
+//	m := metrics.New()
+//	prometheus.MustRegister(m)
+//
+//	wfStore := m.InstrumentStore(store.NewMemoryStore())
+//	eng := engine.NewEngine(wfStore, m.EngineOption())
+package metrics
+
+import (
+	"sync"
+
+	"github.com/prometheus/client_golang/prometheus"
+
+	"github.com/sicko7947/gorkflow"
+	"github.com/sicko7947/gorkflow/engine"
+)
+
+// Label names
+const (
+	LabelWorkflowID = "workflow_id"
+	LabelStepID     = "step_id"
+	LabelStatus     = "status"
+	LabelOperation  = "operation"
+)
+
+// Options configures metric names and buckets
+type Options struct {
+	// Namespace prefixes every metric name (default "gorkflow")
+	Namespace string
+
+	// ConstLabels are added to every metric
+	ConstLabels prometheus.Labels
+
+	// Histogram buckets (durations in seconds, attempts as counts)
+	StepDurationBuckets []float64
+	StoreLatencyBuckets []float64
+	StepAttemptsBuckets []float64
+}
+
+// DefaultOptions returns sensible defaults
+func DefaultOptions() Options {
+	return Options{
+		Namespace:           "gorkflow",
+		StepDurationBuckets: prometheus.ExponentialBuckets(0.005, 2, 16),  // 5ms .. ~164s
+		StoreLatencyBuckets: prometheus.ExponentialBuckets(0.0005, 2, 14), // 0.5ms .. ~4s
+		StepAttemptsBuckets: []float64{1, 2, 3, 4, 5, 7, 10},
+	}
+}
+
+// Metrics collects engine and store metrics. It implements prometheus.Collector,
+// gorkflow.EventListener and can wrap any WorkflowStore via InstrumentStore.
+type Metrics struct {
+	runsStarted   *prometheus.CounterVec
+	runsCompleted *prometheus.CounterVec
+	runsFailed    *prometheus.CounterVec
+	runsCancelled *prometheus.CounterVec
+	activeRuns    *prometheus.GaugeVec
+	queueDepth    *prometheus.GaugeVec
+
+	stepDuration *prometheus.HistogramVec
+	stepAttempts *prometheus.HistogramVec
+	stepRetries  *prometheus.CounterVec
+
+	storeLatency *prometheus.HistogramVec
+	storeErrors  *prometheus.CounterVec
+
+	// Tracks in-flight runs so gauges stay consistent when runs finish
+	// without starting (e.g. cancelled while pending)
+	mu      sync.Mutex
+	pending map[string]string // runID -> workflowID
+	active  map[string]string // runID -> workflowID
+}
+
+// New creates a Metrics collector with default options
+func New() *Metrics {
+	return NewWithOptions(DefaultOptions())
+}
+
+// NewWithOptions creates a Metrics collector with custom options
+func NewWithOptions(opts Options) *Metrics {
+	defaults := DefaultOptions()
+	if opts.Namespace == "" {
+		opts.Namespace = defaults.Namespace
+	}
+	if opts.StepDurationBuckets == nil {
+		opts.StepDurationBuckets = defaults.StepDurationBuckets
+	}
+	if opts.StoreLatencyBuckets == nil {
+		opts.StoreLatencyBuckets = defaults.StoreLatencyBuckets
+	}
+	if opts.StepAttemptsBuckets == nil {
+		opts.StepAttemptsBuckets = defaults.StepAttemptsBuckets
+	}
+
+	counter := func(name, help string, labels ...string) *prometheus.CounterVec {
+		return prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace:   opts.Namespace,
+			Name:        name,
+			Help:        help,
+			ConstLabels: opts.ConstLabels,
+		}, labels)
+	}
+	gauge := func(name, help string, labels ...string) *prometheus.GaugeVec {
+		return prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Namespace:   opts.Namespace,
+			Name:        name,
+			Help:        help,
+			ConstLabels: opts.ConstLabels,
+		}, labels)
+	}
+	histogram := func(name, help string, buckets []float64, labels ...string) *prometheus.HistogramVec {
+		return prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace:   opts.Namespace,
+			Name:        name,
+			Help:        help,
+			Buckets:     buckets,
+			ConstLabels: opts.ConstLabels,
+		}, labels)
+	}
+
+	return &Metrics{
+		runsStarted:   counter("runs_started_total", "Workflow runs that started executing.", LabelWorkflowID),
+		runsCompleted: counter("runs_completed_total", "Workflow runs that completed successfully.", LabelWorkflowID),
+		runsFailed:    counter("runs_failed_total", "Workflow runs that failed.", LabelWorkflowID),
+		runsCancelled: counter("runs_cancelled_total", "Workflow runs that were cancelled.", LabelWorkflowID),
+		activeRuns:    gauge("active_runs", "Workflow runs currently executing.", LabelWorkflowID),
+		queueDepth:    gauge("queued_runs", "Workflow runs created but not yet started.", LabelWorkflowID),
+
+		stepDuration: histogram("step_duration_seconds", "Duration of the final step attempt.",
+			opts.StepDurationBuckets, LabelStepID, LabelStatus),
+		stepAttempts: histogram("step_attempts", "Attempts made per step execution.",
+			opts.StepAttemptsBuckets, LabelStepID, LabelStatus),
+		stepRetries: counter("step_retries_total", "Step retry attempts.", LabelStepID),
+
+		storeLatency: histogram("store_operation_duration_seconds", "Latency of WorkflowStore operations.",
+			opts.StoreLatencyBuckets, LabelOperation),
+		storeErrors: counter("store_operation_errors_total", "Failed WorkflowStore operations, excluding not-found results.",
+			LabelOperation),
+
+		pending: make(map[string]string),
+		active:  make(map[string]string),
+	}
+}
+
+// collectors returns all metric vectors
+func (m *Metrics) collectors() []prometheus.Collector {
+	return []prometheus.Collector{
+		m.runsStarted, m.runsCompleted, m.runsFailed, m.runsCancelled,
+		m.activeRuns, m.queueDepth,
+		m.stepDuration, m.stepAttempts, m.stepRetries,
+		m.storeLatency, m.storeErrors,
+	}
+}
+
+// Describe implements prometheus.Collector
+func (m *Metrics) Describe(ch chan<- *prometheus.Desc) {
+	for _, c := range m.collectors() {
+		c.Describe(ch)
+	}
+}
+
+// Collect implements prometheus.Collector
+func (m *Metrics) Collect(ch chan<- prometheus.Metric) {
+	for _, c := range m.collectors() {
+		c.Collect(ch)
+	}
+}
+
+// EngineOption returns an engine option that feeds engine events into m
+func (m *Metrics) EngineOption() engine.EngineOption {
+	return engine.WithListener(m)
+}
+
+// OnEvent implements gorkflow.EventListener
+func (m *Metrics) OnEvent(event gorkflow.Event) {
+	switch event.Type {
+	case gorkflow.EventWorkflowCreated:
+		m.runCreated(event)
+	case gorkflow.EventWorkflowStarted:
+		m.runStarted(event)
+		m.runsStarted.WithLabelValues(event.WorkflowID).Inc()
+	case gorkflow.EventWorkflowCompleted:
+		m.runFinished(event)
+		m.runsCompleted.WithLabelValues(event.WorkflowID).Inc()
+	case gorkflow.EventWorkflowFailed:
+		m.runFinished(event)
+		m.runsFailed.WithLabelValues(event.WorkflowID).Inc()
+	case gorkflow.EventWorkflowCancelled:
+		m.runFinished(event)
+		m.runsCancelled.WithLabelValues(event.WorkflowID).Inc()
+	case gorkflow.EventStepRetrying:
+		m.stepRetries.WithLabelValues(event.StepID).Inc()
+	case gorkflow.EventStepCompleted:
+		m.stepFinished(event, gorkflow.StepStatusCompleted)
+	case gorkflow.EventStepFailed:
+		m.stepFinished(event, gorkflow.StepStatusFailed)
+	case gorkflow.EventStepSkipped:
+		m.stepFinished(event, gorkflow.StepStatusSkipped)
+	}
+}
+
+func (m *Metrics) runCreated(event gorkflow.Event) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if _, ok := m.pending[event.RunID]; ok {
+		return
+	}
+	m.pending[event.RunID] = event.WorkflowID
+	m.queueDepth.WithLabelValues(event.WorkflowID).Inc()
+}
+
+func (m *Metrics) runStarted(event gorkflow.Event) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if workflowID, ok := m.pending[event.RunID]; ok {
+		delete(m.pending, event.RunID)
+		m.queueDepth.WithLabelValues(workflowID).Dec()
+	}
+	if _, ok := m.active[event.RunID]; ok {
+		return
+	}
+	m.active[event.RunID] = event.WorkflowID
+	m.activeRuns.WithLabelValues(event.WorkflowID).Inc()
+}
+
+func (m *Metrics) runFinished(event gorkflow.Event) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if workflowID, ok := m.pending[event.RunID]; ok {
+		delete(m.pending, event.RunID)
+		m.queueDepth.WithLabelValues(workflowID).Dec()
+	}
+	if workflowID, ok := m.active[event.RunID]; ok {
+		delete(m.active, event.RunID)
+		m.activeRuns.WithLabelValues(workflowID).Dec()
+	}
+}
+
+func (m *Metrics) stepFinished(event gorkflow.Event, status gorkflow.StepStatus) {
+	m.stepDuration.WithLabelValues(event.StepID, status.String()).Observe(event.Duration.Seconds())
+	m.stepAttempts.WithLabelValues(event.StepID, status.String()).Observe(float64(event.Attempt + 1))
+}
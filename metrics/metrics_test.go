@@ -0,0 +1,196 @@
+package metrics_test
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/sicko7947/gorkflow"
+	"github.com/sicko7947/gorkflow/engine"
+	"github.com/sicko7947/gorkflow/metrics"
+	"github.com/sicko7947/gorkflow/store"
+)
+
+type input struct {
+	Value int `json:"value"`
+}
+
+func newInstrumentedEngine(t *testing.T) (*metrics.Metrics, *engine.Engine, *prometheus.Registry) {
+	t.Helper()
+	m := metrics.New()
+	reg := prometheus.NewRegistry()
+	require.NoError(t, reg.Register(m))
+
+	eng := engine.NewEngine(m.InstrumentStore(store.NewMemoryStore()),
+		engine.WithLogger(zerolog.Nop()),
+		m.EngineOption(),
+	)
+	return m, eng, reg
+}
+
+func TestMetrics_RunAndStepMetrics(t *testing.T) {
+	_, eng, reg := newInstrumentedEngine(t)
+
+	attempts := 0
+	flaky := gorkflow.NewStep("flaky", "Flaky",
+		func(ctx *gorkflow.StepContext, in input) (input, error) {
+			attempts++
+			if attempts < 2 {
+				return in, errors.New("transient")
+			}
+			return in, nil
+		},
+		gorkflow.WithRetries(2),
+		gorkflow.WithRetryDelay(0),
+	)
+	wf, err := gorkflow.NewWorkflow("metered", "Metered").ThenStep(flaky).Build()
+	require.NoError(t, err)
+
+	_, err = eng.StartWorkflow(context.Background(), wf, input{Value: 1}, gorkflow.WithSynchronousExecution())
+	require.NoError(t, err)
+
+	expected := `
+# HELP gorkflow_runs_completed_total Workflow runs that completed successfully.
+# TYPE gorkflow_runs_completed_total counter
+gorkflow_runs_completed_total{workflow_id="metered"} 1
+# HELP gorkflow_runs_started_total Workflow runs that started executing.
+# TYPE gorkflow_runs_started_total counter
+gorkflow_runs_started_total{workflow_id="metered"} 1
+# HELP gorkflow_step_retries_total Step retry attempts.
+# TYPE gorkflow_step_retries_total counter
+gorkflow_step_retries_total{step_id="flaky"} 1
+# HELP gorkflow_active_runs Workflow runs currently executing.
+# TYPE gorkflow_active_runs gauge
+gorkflow_active_runs{workflow_id="metered"} 0
+# HELP gorkflow_queued_runs Workflow runs created but not yet started.
+# TYPE gorkflow_queued_runs gauge
+gorkflow_queued_runs{workflow_id="metered"} 0
+`
+	require.NoError(t, testutil.GatherAndCompare(reg, strings.NewReader(expected),
+		"gorkflow_runs_completed_total",
+		"gorkflow_runs_started_total",
+		"gorkflow_step_retries_total",
+		"gorkflow_active_runs",
+		"gorkflow_queued_runs",
+	))
+
+	count, err := testutil.GatherAndCount(reg, "gorkflow_step_duration_seconds", "gorkflow_step_attempts")
+	require.NoError(t, err)
+	assert.Equal(t, 2, count)
+
+	storeOps, err := testutil.GatherAndCount(reg, "gorkflow_store_operation_duration_seconds")
+	require.NoError(t, err)
+	assert.Positive(t, storeOps)
+}
+
+func TestMetrics_FailedRun(t *testing.T) {
+	_, eng, reg := newInstrumentedEngine(t)
+
+	failing := gorkflow.NewStep("broken", "Broken",
+		func(ctx *gorkflow.StepContext, in input) (input, error) {
+			return in, errors.New("down")
+		},
+		gorkflow.WithRetries(0),
+	)
+	wf, err := gorkflow.NewWorkflow("failing", "Failing").ThenStep(failing).Build()
+	require.NoError(t, err)
+
+	_, err = eng.StartWorkflow(context.Background(), wf, input{}, gorkflow.WithSynchronousExecution())
+	require.Error(t, err)
+
+	expected := `
+# HELP gorkflow_runs_failed_total Workflow runs that failed.
+# TYPE gorkflow_runs_failed_total counter
+gorkflow_runs_failed_total{workflow_id="failing"} 1
+`
+	require.NoError(t, testutil.GatherAndCompare(reg, strings.NewReader(expected), "gorkflow_runs_failed_total"))
+}
+
+func TestMetrics_QueueDepthAndCancelledWhilePending(t *testing.T) {
+	m := metrics.New()
+
+	m.OnEvent(gorkflow.Event{Type: gorkflow.EventWorkflowCreated, RunID: "r1", WorkflowID: "wf"})
+	m.OnEvent(gorkflow.Event{Type: gorkflow.EventWorkflowCreated, RunID: "r2", WorkflowID: "wf"})
+	m.OnEvent(gorkflow.Event{Type: gorkflow.EventWorkflowStarted, RunID: "r1", WorkflowID: "wf"})
+
+	assert.Equal(t, 1.0, gaugeValue(t, m, "gorkflow_queued_runs"))
+	assert.Equal(t, 1.0, gaugeValue(t, m, "gorkflow_active_runs"))
+
+	m.OnEvent(gorkflow.Event{Type: gorkflow.EventWorkflowCancelled, RunID: "r2", WorkflowID: "wf"})
+	m.OnEvent(gorkflow.Event{Type: gorkflow.EventWorkflowCompleted, RunID: "r1", WorkflowID: "wf"})
+
+	assert.Equal(t, 0.0, gaugeValue(t, m, "gorkflow_queued_runs"))
+	assert.Equal(t, 0.0, gaugeValue(t, m, "gorkflow_active_runs"))
+}
+
+func TestMetrics_StoreErrorsExcludeNotFound(t *testing.T) {
+	m := metrics.New()
+	reg := prometheus.NewRegistry()
+	require.NoError(t, reg.Register(m))
+
+	s := m.InstrumentStore(store.NewMemoryStore())
+	_, err := s.GetRun(context.Background(), "missing")
+	require.ErrorIs(t, err, gorkflow.ErrRunNotFound)
+
+	err = s.UpdateStepExecution(context.Background(), &gorkflow.StepExecution{RunID: "missing", StepID: "x"})
+	require.Error(t, err)
+
+	count, err := testutil.GatherAndCount(reg, "gorkflow_store_operation_errors_total")
+	require.NoError(t, err)
+	assert.Equal(t, 0, count)
+
+	// Duplicate run IDs are real failures
+	run := &gorkflow.WorkflowRun{RunID: "dup", WorkflowID: "wf"}
+	require.NoError(t, s.CreateRun(context.Background(), run))
+	require.Error(t, s.CreateRun(context.Background(), run))
+
+	expected := `
+# HELP gorkflow_store_operation_errors_total Failed WorkflowStore operations, excluding not-found results.
+# TYPE gorkflow_store_operation_errors_total counter
+gorkflow_store_operation_errors_total{operation="CreateRun"} 1
+`
+	require.NoError(t, testutil.GatherAndCompare(reg, strings.NewReader(expected), "gorkflow_store_operation_errors_total"))
+
+	latency, err := testutil.GatherAndCount(reg, "gorkflow_store_operation_duration_seconds")
+	require.NoError(t, err)
+	assert.Equal(t, 3, latency)
+}
+
+func TestMetrics_InstrumentStoreKeepsStepCache(t *testing.T) {
+	m := metrics.New()
+	reg := prometheus.NewRegistry()
+	require.NoError(t, reg.Register(m))
+
+	cache, ok := m.InstrumentStore(store.NewMemoryStore()).(gorkflow.StepCache)
+	require.True(t, ok, "instrumented store should keep implementing StepCache")
+
+	_, err := cache.GetCachedOutput(context.Background(), "step:key")
+	require.ErrorIs(t, err, gorkflow.ErrCacheMiss)
+
+	count, err := testutil.GatherAndCount(reg, "gorkflow_store_operation_errors_total")
+	require.NoError(t, err)
+	assert.Equal(t, 0, count, "cache misses are not failures")
+}
+
+func gaugeValue(t *testing.T, m *metrics.Metrics, name string) float64 {
+	t.Helper()
+	reg := prometheus.NewPedanticRegistry()
+	require.NoError(t, reg.Register(m))
+	families, err := reg.Gather()
+	require.NoError(t, err)
+	for _, family := range families {
+		if family.GetName() == name {
+			require.Len(t, family.GetMetric(), 1)
+			return family.GetMetric()[0].GetGauge().GetValue()
+		}
+	}
+	t.Fatalf("metric %s not found", name)
+	return 0
+}
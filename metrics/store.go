@@ -0,0 +1,139 @@
+package metrics
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/sicko7947/gorkflow"
+)
+
+// instrumentedStore records latency and errors of every call to the wrapped store
+type instrumentedStore struct {
+	store   gorkflow.WorkflowStore
+	metrics *Metrics
+}
+
+// instrumentedCacheStore is an instrumentedStore whose store also implements gorkflow.StepCache
+type instrumentedCacheStore struct {
+	*instrumentedStore
+	cache gorkflow.StepCache
+}
+
+// InstrumentStore wraps a WorkflowStore so each operation is timed and failures are counted.
+// The wrapper keeps implementing gorkflow.StepCache when the store does.
+func (m *Metrics) InstrumentStore(store gorkflow.WorkflowStore) gorkflow.WorkflowStore {
+	s := &instrumentedStore{store: store, metrics: m}
+	if cache, ok := store.(gorkflow.StepCache); ok {
+		return &instrumentedCacheStore{instrumentedStore: s, cache: cache}
+	}
+	return s
+}
+
+// observe records the latency of an operation and counts unexpected errors
+func (s *instrumentedStore) observe(operation string, start time.Time, err error) {
+	s.metrics.storeLatency.WithLabelValues(operation).Observe(time.Since(start).Seconds())
+	if err != nil && !isNotFound(err) {
+		s.metrics.storeErrors.WithLabelValues(operation).Inc()
+	}
+}
+
+// isNotFound reports whether err is one of the store's not-found sentinels,
+// which are regular results rather than failures
+func isNotFound(err error) bool {
+	return errors.Is(err, gorkflow.ErrRunNotFound) ||
+		errors.Is(err, gorkflow.ErrStepExecutionNotFound) ||
+		errors.Is(err, gorkflow.ErrStepOutputNotFound) ||
+		errors.Is(err, gorkflow.ErrStateNotFound) ||
+		errors.Is(err, gorkflow.ErrCacheMiss)
+}
+
+func (s *instrumentedStore) CreateRun(ctx context.Context, run *gorkflow.WorkflowRun) (err error) {
+	defer func(start time.Time) { s.observe("CreateRun", start, err) }(time.Now())
+	return s.store.CreateRun(ctx, run)
+}
+
+func (s *instrumentedStore) GetRun(ctx context.Context, runID string) (_ *gorkflow.WorkflowRun, err error) {
+	defer func(start time.Time) { s.observe("GetRun", start, err) }(time.Now())
+	return s.store.GetRun(ctx, runID)
+}
+
+func (s *instrumentedStore) UpdateRun(ctx context.Context, run *gorkflow.WorkflowRun) (err error) {
+	defer func(start time.Time) { s.observe("UpdateRun", start, err) }(time.Now())
+	return s.store.UpdateRun(ctx, run)
+}
+
+func (s *instrumentedStore) ListRuns(ctx context.Context, filter gorkflow.RunFilter) (_ []*gorkflow.WorkflowRun, err error) {
+	defer func(start time.Time) { s.observe("ListRuns", start, err) }(time.Now())
+	return s.store.ListRuns(ctx, filter)
+}
+
+func (s *instrumentedStore) CreateStepExecution(ctx context.Context, exec *gorkflow.StepExecution) (err error) {
+	defer func(start time.Time) { s.observe("CreateStepExecution", start, err) }(time.Now())
+	return s.store.CreateStepExecution(ctx, exec)
+}
+
+func (s *instrumentedStore) GetStepExecution(ctx context.Context, runID, stepID string) (_ *gorkflow.StepExecution, err error) {
+	defer func(start time.Time) { s.observe("GetStepExecution", start, err) }(time.Now())
+	return s.store.GetStepExecution(ctx, runID, stepID)
+}
+
+func (s *instrumentedStore) UpdateStepExecution(ctx context.Context, exec *gorkflow.StepExecution) (err error) {
+	defer func(start time.Time) { s.observe("UpdateStepExecution", start, err) }(time.Now())
+	return s.store.UpdateStepExecution(ctx, exec)
+}
+
+func (s *instrumentedStore) ListStepExecutions(ctx context.Context, runID string) (_ []*gorkflow.StepExecution, err error) {
+	defer func(start time.Time) { s.observe("ListStepExecutions", start, err) }(time.Now())
+	return s.store.ListStepExecutions(ctx, runID)
+}
+
+func (s *instrumentedStore) SaveStepOutput(ctx context.Context, runID, stepID string, output []byte) (err error) {
+	defer func(start time.Time) { s.observe("SaveStepOutput", start, err) }(time.Now())
+	return s.store.SaveStepOutput(ctx, runID, stepID, output)
+}
+
+func (s *instrumentedStore) LoadStepOutput(ctx context.Context, runID, stepID string) (_ []byte, err error) {
+	defer func(start time.Time) { s.observe("LoadStepOutput", start, err) }(time.Now())
+	return s.store.LoadStepOutput(ctx, runID, stepID)
+}
+
+func (s *instrumentedStore) SaveState(ctx context.Context, runID, key string, value []byte) (err error) {
+	defer func(start time.Time) { s.observe("SaveState", start, err) }(time.Now())
+	return s.store.SaveState(ctx, runID, key, value)
+}
+
+func (s *instrumentedStore) LoadState(ctx context.Context, runID, key string) (_ []byte, err error) {
+	defer func(start time.Time) { s.observe("LoadState", start, err) }(time.Now())
+	return s.store.LoadState(ctx, runID, key)
+}
+
+func (s *instrumentedStore) DeleteState(ctx context.Context, runID, key string) (err error) {
+	defer func(start time.Time) { s.observe("DeleteState", start, err) }(time.Now())
+	return s.store.DeleteState(ctx, runID, key)
+}
+
+func (s *instrumentedStore) GetAllState(ctx context.Context, runID string) (_ map[string][]byte, err error) {
+	defer func(start time.Time) { s.observe("GetAllState", start, err) }(time.Now())
+	return s.store.GetAllState(ctx, runID)
+}
+
+func (s *instrumentedStore) DeleteRun(ctx context.Context, runID string) (err error) {
+	defer func(start time.Time) { s.observe("DeleteRun", start, err) }(time.Now())
+	return s.store.DeleteRun(ctx, runID)
+}
+
+func (s *instrumentedStore) PurgeRuns(ctx context.Context, filter gorkflow.PurgeFilter) (_ int, err error) {
+	defer func(start time.Time) { s.observe("PurgeRuns", start, err) }(time.Now())
+	return s.store.PurgeRuns(ctx, filter)
+}
+
+func (s *instrumentedCacheStore) GetCachedOutput(ctx context.Context, key string) (_ []byte, err error) {
+	defer func(start time.Time) { s.observe("GetCachedOutput", start, err) }(time.Now())
+	return s.cache.GetCachedOutput(ctx, key)
+}
+
+func (s *instrumentedCacheStore) PutCachedOutput(ctx context.Context, key string, output []byte, ttl time.Duration) (err error) {
+	defer func(start time.Time) { s.observe("PutCachedOutput", start, err) }(time.Now())
+	return s.cache.PutCachedOutput(ctx, key, output, ttl)
+}
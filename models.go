@@ -78,6 +78,9 @@ type WorkflowRun struct {
 
 	// Custom context (serialized as JSON bytes)
 	Context json.RawMessage `json:"context,omitempty"`
+
+	// W3C trace context of the caller that started the run
+	TraceContext map[string]string `json:"traceContext,omitempty"`
 }
 
 // StepExecution tracks individual step execution within a workflow run
@@ -103,6 +106,9 @@ type StepExecution struct {
 	Error   *StepError `json:"error,omitempty"`
 	Attempt int        `json:"attempt"` // Current retry attempt
 
+	// CacheHit is set when the output was reused from a previous run instead of executing the step
+	CacheHit bool `json:"cacheHit,omitempty"`
+
 	// Metadata
 	CreatedAt time.Time `json:"createdAt"`
 	UpdatedAt time.Time `json:"updatedAt"`
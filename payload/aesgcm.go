@@ -0,0 +1,94 @@
+package payload
+
+import (
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"errors"
+	"fmt"
+)
+
+// ErrUnknownKey indicates a payload was encrypted with a key the codec does not have
+var ErrUnknownKey = errors.New("unknown encryption key")
+
+// AESGCMCodec encrypts payloads with AES-GCM. Every payload records the ID of
+// the key that encrypted it: new payloads use the active key and existing ones
+// are decrypted with the key they name. To rotate, add a new key, make it
+// active and keep the old key until RewriteRun has re-encrypted the runs that
+// use it.
+type AESGCMCodec struct {
+	active string
+	aeads  map[string]cipher.AEAD
+}
+
+// NewAESGCMCodec creates a codec from keys indexed by key ID. Keys must be 16,
+// 24 or 32 bytes long (AES-128, AES-192 or AES-256) and activeKeyID must name
+// one of them.
+func NewAESGCMCodec(activeKeyID string, keys map[string][]byte) (*AESGCMCodec, error) {
+	if _, ok := keys[activeKeyID]; !ok {
+		return nil, fmt.Errorf("active key %q is not among the keys", activeKeyID)
+	}
+	c := &AESGCMCodec{active: activeKeyID, aeads: make(map[string]cipher.AEAD, len(keys))}
+	for id, key := range keys {
+		if id == "" || len(id) > 255 {
+			return nil, fmt.Errorf("invalid key ID %q", id)
+		}
+		block, err := aes.NewCipher(key)
+		if err != nil {
+			return nil, fmt.Errorf("invalid key %q: %w", id, err)
+		}
+		aead, err := cipher.NewGCM(block)
+		if err != nil {
+			return nil, fmt.Errorf("invalid key %q: %w", id, err)
+		}
+		c.aeads[id] = aead
+	}
+	return c, nil
+}
+
+// Name returns "aes-gcm"
+func (c *AESGCMCodec) Name() string {
+	return "aes-gcm"
+}
+
+// Encode encrypts data with the active key. The result is the key ID length,
+// the key ID, a random nonce and the ciphertext; the key ID is authenticated.
+func (c *AESGCMCodec) Encode(ctx context.Context, data []byte) ([]byte, error) {
+	aead := c.aeads[c.active]
+	out := make([]byte, 0, 1+len(c.active)+aead.NonceSize()+len(data)+aead.Overhead())
+	out = append(out, byte(len(c.active)))
+	out = append(out, c.active...)
+	nonce := out[len(out) : len(out)+aead.NonceSize()]
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, fmt.Errorf("failed to generate nonce: %w", err)
+	}
+	out = out[:len(out)+aead.NonceSize()]
+	return aead.Seal(out, nonce, data, []byte(c.active)), nil
+}
+
+// Decode decrypts data with the key it names
+func (c *AESGCMCodec) Decode(ctx context.Context, data []byte) ([]byte, error) {
+	if len(data) < 1 || len(data) < 1+int(data[0]) {
+		return nil, errors.New("truncated ciphertext")
+	}
+	id := string(data[1 : 1+int(data[0])])
+	aead, ok := c.aeads[id]
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrUnknownKey, id)
+	}
+	rest := data[1+len(id):]
+	if len(rest) < aead.NonceSize() {
+		return nil, errors.New("truncated ciphertext")
+	}
+	plain, err := aead.Open(nil, rest[:aead.NonceSize()], rest[aead.NonceSize():], []byte(id))
+	if err != nil {
+		return nil, fmt.Errorf("failed to decrypt payload with key %s: %w", id, err)
+	}
+	return plain, nil
+}
+
+// ActiveKeyID returns the ID of the key used for new payloads
+func (c *AESGCMCodec) ActiveKeyID() string {
+	return c.active
+}
@@ -0,0 +1,80 @@
+package payload_test
+
+import (
+	"bytes"
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/sicko7947/gorkflow/payload"
+)
+
+var (
+	key1 = bytes.Repeat([]byte{1}, 32)
+	key2 = bytes.Repeat([]byte{2}, 16)
+)
+
+func TestAESGCMCodec_RoundTrip(t *testing.T) {
+	codec, err := payload.NewAESGCMCodec("k1", map[string][]byte{"k1": key1})
+	require.NoError(t, err)
+	ctx := context.Background()
+
+	plain := []byte(`{"email":"jane@example.com"}`)
+	encrypted, err := codec.Encode(ctx, plain)
+	require.NoError(t, err)
+	assert.False(t, bytes.Contains(encrypted, []byte("jane")))
+
+	again, err := codec.Encode(ctx, plain)
+	require.NoError(t, err)
+	assert.NotEqual(t, encrypted, again, "nonces must differ")
+
+	decrypted, err := codec.Decode(ctx, encrypted)
+	require.NoError(t, err)
+	assert.Equal(t, plain, decrypted)
+}
+
+func TestAESGCMCodec_Rotation(t *testing.T) {
+	ctx := context.Background()
+	old, err := payload.NewAESGCMCodec("k1", map[string][]byte{"k1": key1})
+	require.NoError(t, err)
+	encrypted, err := old.Encode(ctx, []byte("secret"))
+	require.NoError(t, err)
+
+	rotated, err := payload.NewAESGCMCodec("k2", map[string][]byte{"k1": key1, "k2": key2})
+	require.NoError(t, err)
+	assert.Equal(t, "k2", rotated.ActiveKeyID())
+
+	decrypted, err := rotated.Decode(ctx, encrypted)
+	require.NoError(t, err)
+	assert.Equal(t, []byte("secret"), decrypted)
+
+	retired, err := payload.NewAESGCMCodec("k2", map[string][]byte{"k2": key2})
+	require.NoError(t, err)
+	_, err = retired.Decode(ctx, encrypted)
+	assert.ErrorIs(t, err, payload.ErrUnknownKey)
+}
+
+func TestAESGCMCodec_DetectsTampering(t *testing.T) {
+	codec, err := payload.NewAESGCMCodec("k1", map[string][]byte{"k1": key1})
+	require.NoError(t, err)
+	ctx := context.Background()
+
+	encrypted, err := codec.Encode(ctx, []byte("secret"))
+	require.NoError(t, err)
+	encrypted[len(encrypted)-1] ^= 0xFF
+	_, err = codec.Decode(ctx, encrypted)
+	assert.Error(t, err)
+
+	_, err = codec.Decode(ctx, []byte{5, 'k'})
+	assert.Error(t, err)
+}
+
+func TestNewAESGCMCodec_InvalidKeys(t *testing.T) {
+	_, err := payload.NewAESGCMCodec("missing", map[string][]byte{"k1": key1})
+	assert.Error(t, err)
+
+	_, err = payload.NewAESGCMCodec("k1", map[string][]byte{"k1": []byte("short")})
+	assert.Error(t, err)
+}
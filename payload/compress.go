@@ -0,0 +1,99 @@
+package payload
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"fmt"
+	"io"
+	"sync"
+
+	"github.com/klauspost/compress/zstd"
+
+	"github.com/sicko7947/gorkflow"
+)
+
+// DefaultCompressionMinSize is the payload size below which Store does not compress
+const DefaultCompressionMinSize = 1 << 10
+
+// GzipCodec compresses payloads with gzip
+type GzipCodec struct {
+	// Level is a compress/gzip level (default gzip.DefaultCompression)
+	Level int
+}
+
+// Name returns "gzip"
+func (GzipCodec) Name() string {
+	return string(gorkflow.CompressionGzip)
+}
+
+func (c GzipCodec) Encode(ctx context.Context, data []byte) ([]byte, error) {
+	level := c.Level
+	if level == 0 {
+		level = gzip.DefaultCompression
+	}
+	var buf bytes.Buffer
+	w, err := gzip.NewWriterLevel(&buf, level)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := w.Write(data); err != nil {
+		return nil, err
+	}
+	if err := w.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+func (GzipCodec) Decode(ctx context.Context, data []byte) ([]byte, error) {
+	r, err := gzip.NewReader(bytes.NewReader(data))
+	if err != nil {
+		return nil, err
+	}
+	defer r.Close()
+	return io.ReadAll(r)
+}
+
+// ZstdCodec compresses payloads with zstd using a pure Go implementation
+type ZstdCodec struct{}
+
+var (
+	zstdEncoder = sync.OnceValues(func() (*zstd.Encoder, error) { return zstd.NewWriter(nil) })
+	zstdDecoder = sync.OnceValues(func() (*zstd.Decoder, error) { return zstd.NewReader(nil) })
+)
+
+// Name returns "zstd"
+func (ZstdCodec) Name() string {
+	return string(gorkflow.CompressionZstd)
+}
+
+func (ZstdCodec) Encode(ctx context.Context, data []byte) ([]byte, error) {
+	encoder, err := zstdEncoder()
+	if err != nil {
+		return nil, err
+	}
+	return encoder.EncodeAll(data, nil), nil
+}
+
+func (ZstdCodec) Decode(ctx context.Context, data []byte) ([]byte, error) {
+	decoder, err := zstdDecoder()
+	if err != nil {
+		return nil, err
+	}
+	return decoder.DecodeAll(data, nil)
+}
+
+// compressionCodec returns the codec implementing compression, or nil for none
+func compressionCodec(compression gorkflow.Compression) (gorkflow.PayloadCodec, error) {
+	switch compression {
+	case gorkflow.CompressionDefault, gorkflow.CompressionNone:
+		return nil, nil
+	case gorkflow.CompressionGzip:
+		return GzipCodec{}, nil
+	case gorkflow.CompressionZstd:
+		return ZstdCodec{}, nil
+	default:
+		return nil, fmt.Errorf("unknown compression %q", compression)
+	}
+}
@@ -0,0 +1,157 @@
+package payload_test
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/sicko7947/gorkflow"
+	"github.com/sicko7947/gorkflow/payload"
+	"github.com/sicko7947/gorkflow/store"
+)
+
+// compressible returns a repetitive JSON document of roughly n bytes
+func compressible(n int) []byte {
+	items := make([]string, 0, n/16)
+	for len(items)*16 < n {
+		items = append(items, "company-name-abc")
+	}
+	data, _ := json.Marshal(map[string]any{"companies": items})
+	return data
+}
+
+func TestCompressionCodecs_RoundTrip(t *testing.T) {
+	ctx := context.Background()
+	data := compressible(8 << 10)
+
+	for _, codec := range []gorkflow.PayloadCodec{payload.GzipCodec{}, payload.ZstdCodec{}} {
+		t.Run(codec.Name(), func(t *testing.T) {
+			compressed, err := codec.Encode(ctx, data)
+			require.NoError(t, err)
+			assert.Less(t, len(compressed), len(data)/5)
+
+			decompressed, err := codec.Decode(ctx, compressed)
+			require.NoError(t, err)
+			assert.Equal(t, data, decompressed)
+		})
+	}
+}
+
+func TestStore_Compression(t *testing.T) {
+	for _, compression := range []gorkflow.Compression{gorkflow.CompressionGzip, gorkflow.CompressionZstd} {
+		t.Run(string(compression), func(t *testing.T) {
+			inner := store.NewMemoryStore()
+			s := payload.NewStore(inner, payload.Options{Compression: compression})
+			ctx := context.Background()
+			data := compressible(8 << 10)
+
+			require.NoError(t, s.CreateRun(ctx, newRun("r1", data)))
+			require.NoError(t, s.SaveStepOutput(ctx, "r1", "a", data))
+			require.NoError(t, s.SaveState(ctx, "r1", "k", data))
+
+			rawOutput, err := inner.LoadStepOutput(ctx, "r1", "a")
+			require.NoError(t, err)
+			assert.Less(t, len(rawOutput), len(data)/5)
+			rawRun, err := inner.GetRun(ctx, "r1")
+			require.NoError(t, err)
+			assert.True(t, json.Valid(rawRun.Input))
+			assert.Less(t, len(rawRun.Input), len(data)/2)
+
+			output, err := s.LoadStepOutput(ctx, "r1", "a")
+			require.NoError(t, err)
+			assert.Equal(t, data, output)
+			state, err := s.LoadState(ctx, "r1", "k")
+			require.NoError(t, err)
+			assert.Equal(t, data, state)
+			run, err := s.GetRun(ctx, "r1")
+			require.NoError(t, err)
+			assert.Equal(t, data, []byte(run.Input))
+		})
+	}
+}
+
+func TestStore_CompressionSkipsSmallAndIncompressiblePayloads(t *testing.T) {
+	inner := store.NewMemoryStore()
+	s := payload.NewStore(inner, payload.Options{Compression: gorkflow.CompressionZstd})
+	ctx := context.Background()
+
+	small := []byte(`{"count":3}`)
+	require.NoError(t, s.SaveStepOutput(ctx, "r1", "small", small))
+	raw, err := inner.LoadStepOutput(ctx, "r1", "small")
+	require.NoError(t, err)
+	assert.Equal(t, small, raw)
+
+	random := make([]byte, 4<<10)
+	_, err = rand.Read(random)
+	require.NoError(t, err)
+	noise, _ := json.Marshal(random)
+	require.NoError(t, s.SaveStepOutput(ctx, "r1", "noise", noise))
+	raw, err = inner.LoadStepOutput(ctx, "r1", "noise")
+	require.NoError(t, err)
+	assert.Equal(t, noise, raw, "base64 noise barely compresses and should stay plain")
+}
+
+func TestStore_CompressionFromContext(t *testing.T) {
+	inner := store.NewMemoryStore()
+	s := payload.NewStore(inner, payload.Options{Compression: gorkflow.CompressionZstd})
+	ctx := context.Background()
+	data := compressible(8 << 10)
+
+	require.NoError(t, s.SaveStepOutput(gorkflow.ContextWithCompression(ctx, gorkflow.CompressionNone), "r1", "plain", data))
+	raw, err := inner.LoadStepOutput(ctx, "r1", "plain")
+	require.NoError(t, err)
+	assert.Equal(t, data, raw)
+
+	require.NoError(t, s.SaveStepOutput(gorkflow.ContextWithCompression(ctx, gorkflow.CompressionGzip), "r1", "gzip", data))
+	raw, err = inner.LoadStepOutput(ctx, "r1", "gzip")
+	require.NoError(t, err)
+	assert.True(t, bytes.Contains(raw[:8], []byte("gzip")))
+
+	err = s.SaveStepOutput(gorkflow.ContextWithCompression(ctx, "lz4"), "r1", "x", data)
+	assert.ErrorContains(t, err, "unknown compression")
+}
+
+func TestStore_CompressedAndPlainPayloadsCoexist(t *testing.T) {
+	inner := store.NewMemoryStore()
+	ctx := context.Background()
+	data := compressible(8 << 10)
+
+	plain := payload.NewStore(inner, payload.Options{})
+	require.NoError(t, plain.SaveStepOutput(ctx, "r1", "before", data))
+
+	compressed := payload.NewStore(inner, payload.Options{Compression: gorkflow.CompressionZstd})
+	require.NoError(t, compressed.SaveStepOutput(ctx, "r1", "after", data))
+
+	// Either configuration reads both payloads
+	for _, s := range []gorkflow.WorkflowStore{plain, compressed} {
+		for _, stepID := range []string{"before", "after"} {
+			output, err := s.LoadStepOutput(ctx, "r1", stepID)
+			require.NoError(t, err)
+			assert.Equal(t, data, output)
+		}
+	}
+}
+
+func TestStore_CompressionBeforeEncryption(t *testing.T) {
+	inner := store.NewMemoryStore()
+	codec := newAESGCMCodec(t, "k1", map[string][]byte{"k1": key1})
+	s := payload.NewStore(inner, payload.Options{Compression: gorkflow.CompressionZstd, Codecs: []gorkflow.PayloadCodec{codec}})
+	ctx := context.Background()
+	data := compressible(8 << 10)
+
+	require.NoError(t, s.SaveStepOutput(ctx, "r1", "a", data))
+	raw, err := inner.LoadStepOutput(ctx, "r1", "a")
+	require.NoError(t, err)
+	assert.Less(t, len(raw), len(data)/5, "payloads must be compressed before they are encrypted")
+	assert.False(t, strings.Contains(string(raw), "company"))
+
+	output, err := s.LoadStepOutput(ctx, "r1", "a")
+	require.NoError(t, err)
+	assert.Equal(t, data, output)
+}
@@ -0,0 +1,54 @@
+package payload
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/sicko7947/gorkflow"
+)
+
+// RewriteRun reads every payload of a run through s and writes it back, so
+// the run is re-encoded with the codecs and keys s is currently configured
+// with. Use it after rotating an encryption key or changing codecs, on runs
+// that are not executing.
+func RewriteRun(ctx context.Context, s gorkflow.WorkflowStore, runID string) error {
+	run, err := s.GetRun(ctx, runID)
+	if err != nil {
+		return err
+	}
+	if err := s.UpdateRun(ctx, run); err != nil {
+		return fmt.Errorf("failed to rewrite run: %w", err)
+	}
+
+	execs, err := s.ListStepExecutions(ctx, runID)
+	if err != nil {
+		return err
+	}
+	for _, exec := range execs {
+		if err := s.UpdateStepExecution(ctx, exec); err != nil {
+			return fmt.Errorf("failed to rewrite step execution %s: %w", exec.StepID, err)
+		}
+		output, err := s.LoadStepOutput(ctx, runID, exec.StepID)
+		if errors.Is(err, gorkflow.ErrStepOutputNotFound) {
+			continue
+		}
+		if err != nil {
+			return err
+		}
+		if err := s.SaveStepOutput(ctx, runID, exec.StepID, output); err != nil {
+			return fmt.Errorf("failed to rewrite output of step %s: %w", exec.StepID, err)
+		}
+	}
+
+	state, err := s.GetAllState(ctx, runID)
+	if err != nil {
+		return err
+	}
+	for key, value := range state {
+		if err := s.SaveState(ctx, runID, key, value); err != nil {
+			return fmt.Errorf("failed to rewrite state %q: %w", key, err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,529 @@
+// Package payload transforms workflow payloads on their way to the workflow
+// store. Store wraps any WorkflowStore; it compresses run inputs and outputs,
+// step inputs and outputs and state values, encodes them with PayloadCodecs
+// such as AESGCMCodec, and moves payloads above a size threshold to a
+// BlobStore, leaving only a reference behind.
+//
+//	blobs, _ := store.NewFileBlobStore("/var/lib/gorkflow/blobs")
+//	s := payload.NewStore(db, payload.Options{Blobs: blobs, Codecs: []gorkflow.PayloadCodec{aead}})
+package payload
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"time"
+
+	"github.com/sicko7947/gorkflow"
+)
+
+// DefaultOffloadThreshold is the payload size above which Store moves
+// a payload to its blob store
+const DefaultOffloadThreshold = 256 << 10
+
+// Options configures a Store
+type Options struct {
+	// Blobs receives payloads larger than OffloadThreshold
+	Blobs gorkflow.BlobStore
+
+	// OffloadThreshold is the size in bytes above which a payload is offloaded
+	// (default DefaultOffloadThreshold)
+	OffloadThreshold int
+
+	// Codecs encode every payload, in order, before it is offloaded or stored.
+	// Payloads written by a codec can only be read while it is configured.
+	Codecs []gorkflow.PayloadCodec
+
+	// Compression compresses payloads before the codecs run (default none).
+	// A context from gorkflow.ContextWithCompression overrides it per call.
+	// Compressed payloads are always readable, whatever the setting.
+	Compression gorkflow.Compression
+
+	// CompressionMinSize is the size in bytes below which payloads are not
+	// compressed (default DefaultCompressionMinSize)
+	CompressionMinSize int
+}
+
+// ErrUnknownCodec indicates a stored payload was encoded by a codec that is not configured
+var ErrUnknownCodec = errors.New("unknown payload codec")
+
+// Store wraps a WorkflowStore and encodes run inputs and outputs, step inputs
+// and outputs and state values, keeping large ones in a BlobStore. Payloads
+// are decoded again on every read, so callers of the Store never see the
+// difference.
+type Store struct {
+	store     gorkflow.WorkflowStore
+	blobs     gorkflow.BlobStore
+	threshold int
+	codecs    []gorkflow.PayloadCodec
+	decoders  map[string]gorkflow.PayloadCodec
+
+	compression        gorkflow.Compression
+	compressionMinSize int
+}
+
+// cacheStore is a Store whose store also implements gorkflow.StepCache.
+// Cached outputs are encoded by the codecs but never offloaded.
+type cacheStore struct {
+	*Store
+	cache gorkflow.StepCache
+}
+
+// NewStore wraps store so payloads are encoded by opts.Codecs and large ones
+// are offloaded to opts.Blobs. The wrapper keeps implementing
+// gorkflow.StepCache when the store does.
+func NewStore(store gorkflow.WorkflowStore, opts Options) gorkflow.WorkflowStore {
+	if opts.OffloadThreshold <= 0 {
+		opts.OffloadThreshold = DefaultOffloadThreshold
+	}
+	if opts.CompressionMinSize <= 0 {
+		opts.CompressionMinSize = DefaultCompressionMinSize
+	}
+	s := &Store{
+		store:     store,
+		blobs:     opts.Blobs,
+		threshold: opts.OffloadThreshold,
+		codecs:    opts.Codecs,
+		decoders: map[string]gorkflow.PayloadCodec{
+			GzipCodec{}.Name(): GzipCodec{},
+			ZstdCodec{}.Name(): ZstdCodec{},
+		},
+		compression:        opts.Compression,
+		compressionMinSize: opts.CompressionMinSize,
+	}
+	for _, codec := range opts.Codecs {
+		s.decoders[codec.Name()] = codec
+	}
+	if cache, ok := store.(gorkflow.StepCache); ok {
+		return &cacheStore{Store: s, cache: cache}
+	}
+	return s
+}
+
+// Stored payloads that are not plain data start with payloadMarker followed by
+// a kind byte. JSON never starts with the marker, so plain payloads are left as is.
+const (
+	payloadMarker    = 0x1E
+	payloadKindBlob  = 'B' // the body is the key of a blob
+	payloadKindCodec = 'C' // the body is the codec name length, the codec name and the encoded payload
+
+	// payloadKindSerialized marks plain data written by a gorkflow.Serializer
+	// other than JSON. The header belongs to the data and is kept.
+	payloadKindSerialized = 'S'
+)
+
+// payloadEnvelope holds a framed payload in a JSON field, such as
+// WorkflowRun.Input, that must stay valid JSON
+type payloadEnvelope struct {
+	Frame []byte `json:"$gorkflow"`
+}
+
+var envelopeKey = []byte(`"$gorkflow"`)
+
+// runBlobPrefix returns the prefix of every blob belonging to a run
+func runBlobPrefix(runID string) string {
+	return "runs/" + runID + "/"
+}
+
+func runBlobKey(runID, name string) string {
+	return runBlobPrefix(runID) + name
+}
+
+func stepBlobKey(runID, stepID, name string) string {
+	return runBlobPrefix(runID) + "steps/" + url.PathEscape(stepID) + "/" + name
+}
+
+func stateBlobKey(runID, key string) string {
+	return runBlobPrefix(runID) + "state/" + url.PathEscape(key)
+}
+
+// encode returns the form of data kept in the wrapped store: data encoded by
+// each codec in turn, then offloaded to key when it exceeds the threshold. It
+// reports whether data was framed.
+func (s *Store) encode(ctx context.Context, key string, data []byte) ([]byte, bool, error) {
+	stored, err := s.applyCodecs(ctx, data)
+	if err != nil {
+		return nil, false, err
+	}
+	framed := isFramed(stored)
+
+	if s.blobs == nil || len(stored) <= s.threshold {
+		return stored, framed, nil
+	}
+	if err := s.blobs.PutBlob(ctx, key, stored); err != nil {
+		return nil, false, fmt.Errorf("failed to offload payload %s: %w", key, err)
+	}
+	frame := make([]byte, 0, len(key)+2)
+	frame = append(frame, payloadMarker, payloadKindBlob)
+	frame = append(frame, key...)
+	return frame, true, nil
+}
+
+// isFramed reports whether a stored payload starts with a frame header
+func isFramed(stored []byte) bool {
+	return len(stored) >= 2 && stored[0] == payloadMarker
+}
+
+// applyCodecs compresses data when that makes it smaller, then encodes it with
+// each codec in turn, framing every result with the codec name. Empty payloads
+// are left empty.
+func (s *Store) applyCodecs(ctx context.Context, data []byte) ([]byte, error) {
+	if len(data) == 0 {
+		return data, nil
+	}
+
+	compression := gorkflow.CompressionFromContext(ctx)
+	if compression == gorkflow.CompressionDefault {
+		compression = s.compression
+	}
+	compressor, err := compressionCodec(compression)
+	if err != nil {
+		return nil, err
+	}
+	if compressor != nil && len(data) >= s.compressionMinSize {
+		compressed, err := encodeFrame(ctx, compressor, data)
+		if err != nil {
+			return nil, err
+		}
+		// Incompressible payloads are stored as they are
+		if len(compressed) < len(data) {
+			data = compressed
+		}
+	}
+
+	for _, codec := range s.codecs {
+		if data, err = encodeFrame(ctx, codec, data); err != nil {
+			return nil, err
+		}
+	}
+	return data, nil
+}
+
+// encodeFrame encodes data with codec and frames the result with the codec name
+func encodeFrame(ctx context.Context, codec gorkflow.PayloadCodec, data []byte) ([]byte, error) {
+	name := codec.Name()
+	if name == "" || len(name) > 255 {
+		return nil, fmt.Errorf("invalid payload codec name %q", name)
+	}
+	encoded, err := codec.Encode(ctx, data)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode payload with %s: %w", name, err)
+	}
+	frame := make([]byte, 0, len(name)+len(encoded)+3)
+	frame = append(frame, payloadMarker, payloadKindCodec, byte(len(name)))
+	frame = append(frame, name...)
+	return append(frame, encoded...), nil
+}
+
+// decode resolves a payload read from the wrapped store, unwrapping frames
+// until plain data remains
+func (s *Store) decode(ctx context.Context, stored []byte) ([]byte, error) {
+	for isFramed(stored) {
+		switch stored[1] {
+		case payloadKindSerialized:
+			return stored, nil
+		case payloadKindBlob:
+			if s.blobs == nil {
+				return nil, fmt.Errorf("payload is offloaded but no blob store is configured")
+			}
+			key := string(stored[2:])
+			data, err := s.blobs.GetBlob(ctx, key)
+			if err != nil {
+				return nil, fmt.Errorf("failed to resolve payload %s: %w", key, err)
+			}
+			stored = data
+		case payloadKindCodec:
+			if len(stored) < 3 || len(stored) < 3+int(stored[2]) {
+				return nil, fmt.Errorf("truncated payload frame")
+			}
+			name := string(stored[3 : 3+int(stored[2])])
+			codec, ok := s.decoders[name]
+			if !ok {
+				return nil, fmt.Errorf("%w: %s", ErrUnknownCodec, name)
+			}
+			data, err := codec.Decode(ctx, stored[3+len(name):])
+			if err != nil {
+				return nil, fmt.Errorf("failed to decode payload with %s: %w", name, err)
+			}
+			stored = data
+		default:
+			return nil, fmt.Errorf("unknown payload kind %q", stored[1])
+		}
+	}
+	return stored, nil
+}
+
+// encodeJSON is encode for JSON fields, wrapping framed payloads in an envelope
+func (s *Store) encodeJSON(ctx context.Context, key string, data json.RawMessage) (json.RawMessage, error) {
+	stored, framed, err := s.encode(ctx, key, data)
+	if err != nil || !framed {
+		return stored, err
+	}
+	return json.Marshal(payloadEnvelope{Frame: stored})
+}
+
+// decodeJSON is decode for JSON fields
+func (s *Store) decodeJSON(ctx context.Context, stored json.RawMessage) (json.RawMessage, error) {
+	trimmed := bytes.TrimSpace(stored)
+	if len(trimmed) == 0 || trimmed[0] != '{' || !bytes.Contains(trimmed, envelopeKey) {
+		return stored, nil
+	}
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(trimmed, &fields); err != nil || len(fields) != 1 || fields["$gorkflow"] == nil {
+		return stored, nil
+	}
+	var envelope payloadEnvelope
+	if err := json.Unmarshal(trimmed, &envelope); err != nil {
+		return nil, fmt.Errorf("failed to decode payload envelope: %w", err)
+	}
+	return s.decode(ctx, envelope.Frame)
+}
+
+// encodeRun returns a copy of run with its payloads encoded
+func (s *Store) encodeRun(ctx context.Context, run *gorkflow.WorkflowRun) (*gorkflow.WorkflowRun, error) {
+	encoded := *run
+	var err error
+	if encoded.Input, err = s.encodeJSON(ctx, runBlobKey(run.RunID, "input"), run.Input); err != nil {
+		return nil, err
+	}
+	if encoded.Output, err = s.encodeJSON(ctx, runBlobKey(run.RunID, "output"), run.Output); err != nil {
+		return nil, err
+	}
+	if encoded.Context, err = s.encodeJSON(ctx, runBlobKey(run.RunID, "context"), run.Context); err != nil {
+		return nil, err
+	}
+	return &encoded, nil
+}
+
+// decodeRun resolves the payloads of a run read from the wrapped store in place
+func (s *Store) decodeRun(ctx context.Context, run *gorkflow.WorkflowRun) error {
+	var err error
+	if run.Input, err = s.decodeJSON(ctx, run.Input); err != nil {
+		return err
+	}
+	if run.Output, err = s.decodeJSON(ctx, run.Output); err != nil {
+		return err
+	}
+	run.Context, err = s.decodeJSON(ctx, run.Context)
+	return err
+}
+
+// encodeStepExecution returns a copy of exec with its payloads encoded
+func (s *Store) encodeStepExecution(ctx context.Context, exec *gorkflow.StepExecution) (*gorkflow.StepExecution, error) {
+	encoded := *exec
+	var err error
+	if encoded.Input, err = s.encodeJSON(ctx, stepBlobKey(exec.RunID, exec.StepID, "input"), exec.Input); err != nil {
+		return nil, err
+	}
+	if encoded.Output, err = s.encodeJSON(ctx, stepBlobKey(exec.RunID, exec.StepID, "output"), exec.Output); err != nil {
+		return nil, err
+	}
+	return &encoded, nil
+}
+
+// decodeStepExecution resolves the payloads of a step execution in place
+func (s *Store) decodeStepExecution(ctx context.Context, exec *gorkflow.StepExecution) error {
+	var err error
+	if exec.Input, err = s.decodeJSON(ctx, exec.Input); err != nil {
+		return err
+	}
+	exec.Output, err = s.decodeJSON(ctx, exec.Output)
+	return err
+}
+
+func (s *Store) CreateRun(ctx context.Context, run *gorkflow.WorkflowRun) error {
+	encoded, err := s.encodeRun(ctx, run)
+	if err != nil {
+		return err
+	}
+	return s.store.CreateRun(ctx, encoded)
+}
+
+func (s *Store) GetRun(ctx context.Context, runID string) (*gorkflow.WorkflowRun, error) {
+	run, err := s.store.GetRun(ctx, runID)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.decodeRun(ctx, run); err != nil {
+		return nil, err
+	}
+	return run, nil
+}
+
+func (s *Store) UpdateRun(ctx context.Context, run *gorkflow.WorkflowRun) error {
+	encoded, err := s.encodeRun(ctx, run)
+	if err != nil {
+		return err
+	}
+	return s.store.UpdateRun(ctx, encoded)
+}
+
+func (s *Store) ListRuns(ctx context.Context, filter gorkflow.RunFilter) ([]*gorkflow.WorkflowRun, error) {
+	runs, err := s.store.ListRuns(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+	for _, run := range runs {
+		if err := s.decodeRun(ctx, run); err != nil {
+			return nil, err
+		}
+	}
+	return runs, nil
+}
+
+func (s *Store) CreateStepExecution(ctx context.Context, exec *gorkflow.StepExecution) error {
+	encoded, err := s.encodeStepExecution(ctx, exec)
+	if err != nil {
+		return err
+	}
+	return s.store.CreateStepExecution(ctx, encoded)
+}
+
+func (s *Store) GetStepExecution(ctx context.Context, runID, stepID string) (*gorkflow.StepExecution, error) {
+	exec, err := s.store.GetStepExecution(ctx, runID, stepID)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.decodeStepExecution(ctx, exec); err != nil {
+		return nil, err
+	}
+	return exec, nil
+}
+
+func (s *Store) UpdateStepExecution(ctx context.Context, exec *gorkflow.StepExecution) error {
+	encoded, err := s.encodeStepExecution(ctx, exec)
+	if err != nil {
+		return err
+	}
+	return s.store.UpdateStepExecution(ctx, encoded)
+}
+
+func (s *Store) ListStepExecutions(ctx context.Context, runID string) ([]*gorkflow.StepExecution, error) {
+	execs, err := s.store.ListStepExecutions(ctx, runID)
+	if err != nil {
+		return nil, err
+	}
+	for _, exec := range execs {
+		if err := s.decodeStepExecution(ctx, exec); err != nil {
+			return nil, err
+		}
+	}
+	return execs, nil
+}
+
+// SaveStepOutput shares its blob with the output of the step execution
+func (s *Store) SaveStepOutput(ctx context.Context, runID, stepID string, output []byte) error {
+	stored, _, err := s.encode(ctx, stepBlobKey(runID, stepID, "output"), output)
+	if err != nil {
+		return err
+	}
+	return s.store.SaveStepOutput(ctx, runID, stepID, stored)
+}
+
+func (s *Store) LoadStepOutput(ctx context.Context, runID, stepID string) ([]byte, error) {
+	stored, err := s.store.LoadStepOutput(ctx, runID, stepID)
+	if err != nil {
+		return nil, err
+	}
+	return s.decode(ctx, stored)
+}
+
+func (s *Store) SaveState(ctx context.Context, runID, key string, value []byte) error {
+	stored, _, err := s.encode(ctx, stateBlobKey(runID, key), value)
+	if err != nil {
+		return err
+	}
+	return s.store.SaveState(ctx, runID, key, stored)
+}
+
+func (s *Store) LoadState(ctx context.Context, runID, key string) ([]byte, error) {
+	stored, err := s.store.LoadState(ctx, runID, key)
+	if err != nil {
+		return nil, err
+	}
+	return s.decode(ctx, stored)
+}
+
+func (s *Store) DeleteState(ctx context.Context, runID, key string) error {
+	if err := s.store.DeleteState(ctx, runID, key); err != nil || s.blobs == nil {
+		return err
+	}
+	return s.blobs.DeleteBlob(ctx, stateBlobKey(runID, key))
+}
+
+func (s *Store) GetAllState(ctx context.Context, runID string) (map[string][]byte, error) {
+	state, err := s.store.GetAllState(ctx, runID)
+	if err != nil {
+		return nil, err
+	}
+	for key, stored := range state {
+		if state[key], err = s.decode(ctx, stored); err != nil {
+			return nil, err
+		}
+	}
+	return state, nil
+}
+
+// DeleteRun deletes the run and then its blobs
+func (s *Store) DeleteRun(ctx context.Context, runID string) error {
+	if err := s.store.DeleteRun(ctx, runID); err != nil || s.blobs == nil {
+		return err
+	}
+	return s.blobs.DeleteBlobs(ctx, runBlobPrefix(runID))
+}
+
+// PurgeRuns purges runs from the wrapped store and then deletes the blobs of
+// the matching runs that are gone. Blobs of a run that starts matching the
+// filter during the purge are left behind.
+func (s *Store) PurgeRuns(ctx context.Context, filter gorkflow.PurgeFilter) (int, error) {
+	if s.blobs == nil {
+		return s.store.PurgeRuns(ctx, filter)
+	}
+
+	var candidates []string
+	for _, status := range filter.RunStatuses() {
+		runs, err := s.store.ListRuns(ctx, gorkflow.RunFilter{WorkflowID: filter.WorkflowID, Status: &status})
+		if err != nil {
+			return 0, err
+		}
+		for _, run := range runs {
+			if filter.Matches(run) {
+				candidates = append(candidates, run.RunID)
+			}
+		}
+	}
+
+	purged, err := s.store.PurgeRuns(ctx, filter)
+	if err != nil {
+		return purged, err
+	}
+
+	for _, runID := range candidates {
+		if _, err := s.store.GetRun(ctx, runID); !errors.Is(err, gorkflow.ErrRunNotFound) {
+			continue
+		}
+		if err := s.blobs.DeleteBlobs(ctx, runBlobPrefix(runID)); err != nil {
+			return purged, err
+		}
+	}
+	return purged, nil
+}
+
+func (s *cacheStore) GetCachedOutput(ctx context.Context, key string) ([]byte, error) {
+	stored, err := s.cache.GetCachedOutput(ctx, key)
+	if err != nil {
+		return nil, err
+	}
+	return s.decode(ctx, stored)
+}
+
+func (s *cacheStore) PutCachedOutput(ctx context.Context, key string, output []byte, ttl time.Duration) error {
+	stored, err := s.applyCodecs(ctx, output)
+	if err != nil {
+		return err
+	}
+	return s.cache.PutCachedOutput(ctx, key, stored, ttl)
+}
@@ -0,0 +1,390 @@
+package payload_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/sicko7947/gorkflow"
+	"github.com/sicko7947/gorkflow/payload"
+	"github.com/sicko7947/gorkflow/store"
+)
+
+const testThreshold = 64
+
+// large returns a JSON string payload above the test threshold
+func large(fill string) []byte {
+	data, _ := json.Marshal(strings.Repeat(fill, testThreshold))
+	return data
+}
+
+func newTestStore(t *testing.T, inner gorkflow.WorkflowStore) (gorkflow.WorkflowStore, *store.MemoryBlobStore) {
+	t.Helper()
+	blobs := store.NewMemoryBlobStore()
+	return payload.NewStore(inner, payload.Options{Blobs: blobs, OffloadThreshold: testThreshold}), blobs
+}
+
+func newRun(runID string, input []byte) *gorkflow.WorkflowRun {
+	now := time.Now()
+	return &gorkflow.WorkflowRun{
+		RunID:      runID,
+		WorkflowID: "wf",
+		Status:     gorkflow.RunStatusRunning,
+		Input:      input,
+		CreatedAt:  now,
+		UpdatedAt:  now,
+	}
+}
+
+func TestStore_SmallPayloadsStayInline(t *testing.T) {
+	inner := store.NewMemoryStore()
+	s, blobs := newTestStore(t, inner)
+	ctx := context.Background()
+
+	require.NoError(t, s.CreateRun(ctx, newRun("r1", []byte(`{"small":true}`))))
+	require.NoError(t, s.SaveStepOutput(ctx, "r1", "a", []byte(`"ok"`)))
+	require.NoError(t, s.SaveState(ctx, "r1", "k", []byte(`1`)))
+
+	raw, err := inner.GetRun(ctx, "r1")
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"small":true}`, string(raw.Input))
+	assert.Empty(t, blobs.Keys())
+}
+
+func TestStore_RunPayloads(t *testing.T) {
+	inner := store.NewMemoryStore()
+	s, blobs := newTestStore(t, inner)
+	ctx := context.Background()
+
+	input := large("i")
+	run := newRun("r1", input)
+	require.NoError(t, s.CreateRun(ctx, run))
+	assert.Equal(t, input, []byte(run.Input), "the caller's run must not be modified")
+
+	run.Output = large("o")
+	run.Status = gorkflow.RunStatusCompleted
+	require.NoError(t, s.UpdateRun(ctx, run))
+
+	raw, err := inner.GetRun(ctx, "r1")
+	require.NoError(t, err)
+	assert.True(t, json.Valid(raw.Input), "the reference must be valid JSON")
+	assert.Less(t, len(raw.Input), testThreshold)
+	assert.Less(t, len(raw.Output), testThreshold)
+	assert.Equal(t, []string{"runs/r1/input", "runs/r1/output"}, blobs.Keys())
+
+	got, err := s.GetRun(ctx, "r1")
+	require.NoError(t, err)
+	assert.Equal(t, input, []byte(got.Input))
+	assert.Equal(t, large("o"), []byte(got.Output))
+
+	runs, err := s.ListRuns(ctx, gorkflow.RunFilter{WorkflowID: "wf"})
+	require.NoError(t, err)
+	require.Len(t, runs, 1)
+	assert.Equal(t, input, []byte(runs[0].Input))
+}
+
+func TestStore_StepPayloads(t *testing.T) {
+	inner := store.NewMemoryStore()
+	s, blobs := newTestStore(t, inner)
+	ctx := context.Background()
+
+	require.NoError(t, s.CreateRun(ctx, newRun("r1", nil)))
+	exec := &gorkflow.StepExecution{
+		RunID:  "r1",
+		StepID: "fetch page",
+		Status: gorkflow.StepStatusRunning,
+		Input:  large("i"),
+	}
+	require.NoError(t, s.CreateStepExecution(ctx, exec))
+	exec.Output = large("o")
+	exec.Status = gorkflow.StepStatusCompleted
+	require.NoError(t, s.UpdateStepExecution(ctx, exec))
+	require.NoError(t, s.SaveStepOutput(ctx, "r1", "fetch page", large("o")))
+
+	assert.Equal(t, []string{"runs/r1/steps/fetch%20page/input", "runs/r1/steps/fetch%20page/output"}, blobs.Keys())
+
+	raw, err := inner.LoadStepOutput(ctx, "r1", "fetch page")
+	require.NoError(t, err)
+	assert.Less(t, len(raw), testThreshold)
+
+	output, err := s.LoadStepOutput(ctx, "r1", "fetch page")
+	require.NoError(t, err)
+	assert.Equal(t, large("o"), output)
+
+	got, err := s.GetStepExecution(ctx, "r1", "fetch page")
+	require.NoError(t, err)
+	assert.Equal(t, large("i"), []byte(got.Input))
+	assert.Equal(t, large("o"), []byte(got.Output))
+
+	execs, err := s.ListStepExecutions(ctx, "r1")
+	require.NoError(t, err)
+	require.Len(t, execs, 1)
+	assert.Equal(t, large("o"), []byte(execs[0].Output))
+
+	// Accessors built on the wrapped store see the original output
+	accessor := gorkflow.NewStepAccessor("r1", s)
+	var text string
+	require.NoError(t, accessor.GetOutput("fetch page", &text))
+	assert.Equal(t, strings.Repeat("o", testThreshold), text)
+}
+
+func TestStore_State(t *testing.T) {
+	s, blobs := newTestStore(t, store.NewMemoryStore())
+	ctx := context.Background()
+
+	require.NoError(t, s.CreateRun(ctx, newRun("r1", nil)))
+	require.NoError(t, s.SaveState(ctx, "r1", "big", large("s")))
+	require.NoError(t, s.SaveState(ctx, "r1", "small", []byte(`1`)))
+
+	value, err := s.LoadState(ctx, "r1", "big")
+	require.NoError(t, err)
+	assert.Equal(t, large("s"), value)
+
+	all, err := s.GetAllState(ctx, "r1")
+	require.NoError(t, err)
+	assert.Equal(t, map[string][]byte{"big": large("s"), "small": []byte(`1`)}, all)
+
+	require.NoError(t, s.DeleteState(ctx, "r1", "big"))
+	assert.Empty(t, blobs.Keys())
+}
+
+func TestStore_DeleteRunRemovesBlobs(t *testing.T) {
+	s, blobs := newTestStore(t, store.NewMemoryStore())
+	ctx := context.Background()
+
+	require.NoError(t, s.CreateRun(ctx, newRun("r1", large("a"))))
+	require.NoError(t, s.CreateRun(ctx, newRun("r2", large("b"))))
+	require.NoError(t, s.SaveStepOutput(ctx, "r1", "a", large("o")))
+
+	require.NoError(t, s.DeleteRun(ctx, "r1"))
+	assert.Equal(t, []string{"runs/r2/input"}, blobs.Keys())
+}
+
+func TestStore_PurgeRunsRemovesBlobs(t *testing.T) {
+	s, blobs := newTestStore(t, store.NewMemoryStore())
+	ctx := context.Background()
+
+	old := newRun("old", large("a"))
+	old.Status = gorkflow.RunStatusCompleted
+	old.UpdatedAt = time.Now().Add(-time.Hour)
+	require.NoError(t, s.CreateRun(ctx, old))
+	require.NoError(t, s.CreateRun(ctx, newRun("active", large("b"))))
+
+	purged, err := s.PurgeRuns(ctx, gorkflow.PurgeFilter{OlderThan: time.Now()})
+	require.NoError(t, err)
+	assert.Equal(t, 1, purged)
+	assert.Equal(t, []string{"runs/active/input"}, blobs.Keys())
+}
+
+func TestStore_MissingBlob(t *testing.T) {
+	s, blobs := newTestStore(t, store.NewMemoryStore())
+	ctx := context.Background()
+
+	require.NoError(t, s.SaveStepOutput(ctx, "r1", "a", large("o")))
+	require.NoError(t, blobs.DeleteBlobs(ctx, "runs/"))
+
+	_, err := s.LoadStepOutput(ctx, "r1", "a")
+	assert.ErrorIs(t, err, gorkflow.ErrBlobNotFound)
+}
+
+func TestStore_KeepsStepCache(t *testing.T) {
+	s, _ := newTestStore(t, store.NewMemoryStore())
+	ctx := context.Background()
+
+	cache, ok := s.(gorkflow.StepCache)
+	require.True(t, ok, "wrapper of a cache-capable store must implement StepCache")
+	require.NoError(t, cache.PutCachedOutput(ctx, "k", large("c"), 0))
+	output, err := cache.GetCachedOutput(ctx, "k")
+	require.NoError(t, err)
+	assert.Equal(t, large("c"), output)
+}
+
+func TestStore_LibSQLRoundTrip(t *testing.T) {
+	inner, err := store.NewLibSQLStore("file:" + filepath.Join(t.TempDir(), "payload.db"))
+	require.NoError(t, err)
+	t.Cleanup(func() { inner.Close() })
+
+	blobs, err := store.NewFileBlobStore(filepath.Join(t.TempDir(), "blobs"))
+	require.NoError(t, err)
+	s := payload.NewStore(inner, payload.Options{Blobs: blobs, OffloadThreshold: testThreshold})
+	ctx := context.Background()
+
+	require.NoError(t, s.CreateRun(ctx, newRun("r1", large("i"))))
+	require.NoError(t, s.CreateStepExecution(ctx, &gorkflow.StepExecution{
+		RunID:  "r1",
+		StepID: "a",
+		Status: gorkflow.StepStatusCompleted,
+		Output: large("o"),
+	}))
+	require.NoError(t, s.SaveStepOutput(ctx, "r1", "a", large("o")))
+
+	run, err := s.GetRun(ctx, "r1")
+	require.NoError(t, err)
+	assert.Equal(t, large("i"), []byte(run.Input))
+
+	exec, err := s.GetStepExecution(ctx, "r1", "a")
+	require.NoError(t, err)
+	assert.Equal(t, large("o"), []byte(exec.Output))
+
+	output, err := s.LoadStepOutput(ctx, "r1", "a")
+	require.NoError(t, err)
+	assert.Equal(t, large("o"), output)
+
+	require.NoError(t, s.DeleteRun(ctx, "r1"))
+	_, err = blobs.GetBlob(ctx, "runs/r1/input")
+	assert.ErrorIs(t, err, gorkflow.ErrBlobNotFound)
+}
+
+func TestStore_SerializedPayloads(t *testing.T) {
+	inner, err := store.NewLibSQLStore("file:" + filepath.Join(t.TempDir(), "payload.db"))
+	require.NoError(t, err)
+	t.Cleanup(func() { inner.Close() })
+	s := payload.NewStore(inner, payload.Options{})
+	ctx := context.Background()
+
+	input, err := gorkflow.MarshalPayload(gorkflow.MsgpackSerializer, map[string]any{"id": int64(1<<53 + 1)})
+	require.NoError(t, err)
+
+	// Binary payloads in JSON fields are kept in an envelope
+	require.NoError(t, s.CreateRun(ctx, newRun("r1", input)))
+	raw, err := inner.GetRun(ctx, "r1")
+	require.NoError(t, err)
+	assert.True(t, json.Valid(raw.Input))
+
+	run, err := s.GetRun(ctx, "r1")
+	require.NoError(t, err)
+	assert.Equal(t, input, []byte(run.Input))
+
+	require.NoError(t, s.SaveState(ctx, "r1", "k", input))
+	state, err := s.LoadState(ctx, "r1", "k")
+	require.NoError(t, err)
+	assert.Equal(t, input, state)
+
+	var decoded map[string]int64
+	require.NoError(t, gorkflow.UnmarshalPayload(state, &decoded))
+	assert.Equal(t, int64(1<<53+1), decoded["id"])
+}
+
+func newAESGCMCodec(t *testing.T, active string, keys map[string][]byte) *payload.AESGCMCodec {
+	t.Helper()
+	codec, err := payload.NewAESGCMCodec(active, keys)
+	require.NoError(t, err)
+	return codec
+}
+
+func TestStore_CodecEncryptsPayloads(t *testing.T) {
+	inner := store.NewMemoryStore()
+	codec := newAESGCMCodec(t, "k1", map[string][]byte{"k1": key1})
+	s := payload.NewStore(inner, payload.Options{Codecs: []gorkflow.PayloadCodec{codec}})
+	ctx := context.Background()
+	secret := []byte(`{"ssn":"123-45-6789"}`)
+
+	require.NoError(t, s.CreateRun(ctx, newRun("r1", secret)))
+	require.NoError(t, s.CreateStepExecution(ctx, &gorkflow.StepExecution{RunID: "r1", StepID: "a", Output: secret}))
+	require.NoError(t, s.SaveStepOutput(ctx, "r1", "a", secret))
+	require.NoError(t, s.SaveState(ctx, "r1", "k", secret))
+
+	rawRun, err := inner.GetRun(ctx, "r1")
+	require.NoError(t, err)
+	assert.True(t, json.Valid(rawRun.Input))
+	rawExec, err := inner.GetStepExecution(ctx, "r1", "a")
+	require.NoError(t, err)
+	rawOutput, err := inner.LoadStepOutput(ctx, "r1", "a")
+	require.NoError(t, err)
+	rawState, err := inner.LoadState(ctx, "r1", "k")
+	require.NoError(t, err)
+	for _, raw := range [][]byte{rawRun.Input, rawExec.Output, rawOutput, rawState} {
+		assert.False(t, bytes.Contains(raw, []byte("123-45")), "payload stored in plaintext: %s", raw)
+	}
+
+	run, err := s.GetRun(ctx, "r1")
+	require.NoError(t, err)
+	assert.Equal(t, secret, []byte(run.Input))
+	output, err := s.LoadStepOutput(ctx, "r1", "a")
+	require.NoError(t, err)
+	assert.Equal(t, secret, output)
+	state, err := s.GetAllState(ctx, "r1")
+	require.NoError(t, err)
+	assert.Equal(t, secret, state["k"])
+
+	// Without the codec the payloads cannot be read
+	plain := payload.NewStore(inner, payload.Options{})
+	_, err = plain.LoadStepOutput(ctx, "r1", "a")
+	assert.ErrorIs(t, err, payload.ErrUnknownCodec)
+}
+
+func TestStore_CodecAndOffloading(t *testing.T) {
+	inner := store.NewMemoryStore()
+	blobs := store.NewMemoryBlobStore()
+	codec := newAESGCMCodec(t, "k1", map[string][]byte{"k1": key1})
+	s := payload.NewStore(inner, payload.Options{Blobs: blobs, OffloadThreshold: testThreshold, Codecs: []gorkflow.PayloadCodec{codec}})
+	ctx := context.Background()
+
+	require.NoError(t, s.SaveStepOutput(ctx, "r1", "a", large("o")))
+	blob, err := blobs.GetBlob(ctx, "runs/r1/steps/a/output")
+	require.NoError(t, err)
+	assert.False(t, bytes.Contains(blob, []byte("ooo")), "offloaded payload stored in plaintext")
+
+	output, err := s.LoadStepOutput(ctx, "r1", "a")
+	require.NoError(t, err)
+	assert.Equal(t, large("o"), output)
+}
+
+func TestStore_CodecEncryptsCachedOutputs(t *testing.T) {
+	inner := store.NewMemoryStore()
+	codec := newAESGCMCodec(t, "k1", map[string][]byte{"k1": key1})
+	s := payload.NewStore(inner, payload.Options{Codecs: []gorkflow.PayloadCodec{codec}})
+	ctx := context.Background()
+
+	require.NoError(t, s.(gorkflow.StepCache).PutCachedOutput(ctx, "k", []byte(`"secret"`), 0))
+	raw, err := inner.(gorkflow.StepCache).GetCachedOutput(ctx, "k")
+	require.NoError(t, err)
+	assert.False(t, bytes.Contains(raw, []byte("secret")))
+
+	output, err := s.(gorkflow.StepCache).GetCachedOutput(ctx, "k")
+	require.NoError(t, err)
+	assert.Equal(t, []byte(`"secret"`), output)
+}
+
+func TestRewriteRun_RotatesKeys(t *testing.T) {
+	inner := store.NewMemoryStore()
+	ctx := context.Background()
+	secret := []byte(`"secret"`)
+
+	old := payload.NewStore(inner, payload.Options{Codecs: []gorkflow.PayloadCodec{
+		newAESGCMCodec(t, "k1", map[string][]byte{"k1": key1}),
+	}})
+	require.NoError(t, old.CreateRun(ctx, newRun("r1", secret)))
+	require.NoError(t, old.CreateStepExecution(ctx, &gorkflow.StepExecution{RunID: "r1", StepID: "a", Input: secret, Output: secret}))
+	require.NoError(t, old.CreateStepExecution(ctx, &gorkflow.StepExecution{RunID: "r1", StepID: "b", Input: secret}))
+	require.NoError(t, old.SaveStepOutput(ctx, "r1", "a", secret))
+	require.NoError(t, old.SaveState(ctx, "r1", "k", secret))
+
+	rotated := payload.NewStore(inner, payload.Options{Codecs: []gorkflow.PayloadCodec{
+		newAESGCMCodec(t, "k2", map[string][]byte{"k1": key1, "k2": key2}),
+	}})
+	require.NoError(t, payload.RewriteRun(ctx, rotated, "r1"))
+
+	retired := payload.NewStore(inner, payload.Options{Codecs: []gorkflow.PayloadCodec{
+		newAESGCMCodec(t, "k2", map[string][]byte{"k2": key2}),
+	}})
+	run, err := retired.GetRun(ctx, "r1")
+	require.NoError(t, err)
+	assert.Equal(t, secret, []byte(run.Input))
+	execs, err := retired.ListStepExecutions(ctx, "r1")
+	require.NoError(t, err)
+	assert.Len(t, execs, 2)
+	output, err := retired.LoadStepOutput(ctx, "r1", "a")
+	require.NoError(t, err)
+	assert.Equal(t, secret, output)
+	state, err := retired.LoadState(ctx, "r1", "k")
+	require.NoError(t, err)
+	assert.Equal(t, secret, state)
+}
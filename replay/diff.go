@@ -0,0 +1,101 @@
+package replay
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+
+	"github.com/sicko7947/gorkflow"
+)
+
+// Difference is a value that differs between a recorded and a replayed output.
+// A nil Recorded or Replayed value is null or absent on that side.
+type Difference struct {
+	// Path locates the value, e.g. $.companies[2].name
+	Path     string
+	Recorded any
+	Replayed any
+}
+
+func (d Difference) String() string {
+	return fmt.Sprintf("%s: %s -> %s", d.Path, describe(d.Recorded), describe(d.Replayed))
+}
+
+// Compare returns the differences between two outputs. Object key order and
+// formatting are ignored. Outputs that cannot be decoded (by the serializer
+// recorded in them) are compared as raw bytes and reported as a single
+// difference at $.
+func Compare(recorded, replayed []byte) []Difference {
+	if bytes.Equal(recorded, replayed) {
+		return nil
+	}
+
+	var a, b any
+	if gorkflow.UnmarshalPayload(recorded, &a) != nil || gorkflow.UnmarshalPayload(replayed, &b) != nil {
+		return []Difference{{Path: "$", Recorded: string(recorded), Replayed: string(replayed)}}
+	}
+
+	var diffs []Difference
+	compareValues("$", a, b, &diffs)
+	return diffs
+}
+
+// compareValues walks two decoded JSON values and appends their differences
+func compareValues(path string, a, b any, diffs *[]Difference) {
+	switch av := a.(type) {
+	case map[string]any:
+		bv, ok := b.(map[string]any)
+		if !ok {
+			break
+		}
+		keys := make([]string, 0, len(av)+len(bv))
+		for key := range av {
+			keys = append(keys, key)
+		}
+		for key := range bv {
+			if _, ok := av[key]; !ok {
+				keys = append(keys, key)
+			}
+		}
+		sort.Strings(keys)
+		for _, key := range keys {
+			compareValues(path+"."+key, av[key], bv[key], diffs)
+		}
+		return
+
+	case []any:
+		bv, ok := b.([]any)
+		if !ok {
+			break
+		}
+		for i := 0; i < len(av) || i < len(bv); i++ {
+			var ai, bi any
+			if i < len(av) {
+				ai = av[i]
+			}
+			if i < len(bv) {
+				bi = bv[i]
+			}
+			compareValues(fmt.Sprintf("%s[%d]", path, i), ai, bi, diffs)
+		}
+		return
+	}
+
+	if !reflect.DeepEqual(a, b) {
+		*diffs = append(*diffs, Difference{Path: path, Recorded: a, Replayed: b})
+	}
+}
+
+// describe formats a value of a difference
+func describe(v any) string {
+	if v == nil {
+		return "null"
+	}
+	data, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Sprint(v)
+	}
+	return string(data)
+}
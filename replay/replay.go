@@ -0,0 +1,296 @@
+// Package replay re-executes a recorded workflow run against the current step
+// handlers, for debugging runs that produced a wrong result.
+//
+//	result, err := replay.Run(ctx, prodStore, wf, runID, replay.Steps("score"))
+//	for _, step := range result.Changed() {
+//		fmt.Println(step.StepID, step.Diff)
+//	}
+//
+// Each step receives the exact input recorded for it. Selected steps run with the
+// current handlers; the others are served from their recorded outputs. The run is
+// copied into an in-memory store first, so the source store is only read.
+package replay
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/rs/zerolog"
+
+	"github.com/sicko7947/gorkflow"
+	"github.com/sicko7947/gorkflow/store"
+)
+
+// ErrStepNotRecorded indicates a selected step has no recorded execution in the run
+var ErrStepNotRecorded = errors.New("step not recorded in run")
+
+// Option configures a replay
+type Option func(*config)
+
+type config struct {
+	steps        map[string]bool // nil replays every recorded step
+	interceptors []gorkflow.StepInterceptor
+	logger       zerolog.Logger
+}
+
+// Steps selects the steps re-executed with the current handlers. By default
+// every recorded step is re-executed.
+func Steps(stepIDs ...string) Option {
+	return func(c *config) {
+		if c.steps == nil {
+			c.steps = make(map[string]bool)
+		}
+		for _, id := range stepIDs {
+			c.steps[id] = true
+		}
+	}
+}
+
+// WithInterceptors wraps replayed steps with additional interceptors, outside the
+// workflow and step interceptors, like engine.WithStepInterceptor
+func WithInterceptors(interceptors ...gorkflow.StepInterceptor) Option {
+	return func(c *config) {
+		c.interceptors = append(c.interceptors, interceptors...)
+	}
+}
+
+// WithLogger sets the logger passed to replayed steps. Defaults to a no-op logger.
+func WithLogger(logger zerolog.Logger) Option {
+	return func(c *config) {
+		c.logger = logger
+	}
+}
+
+// Result is the outcome of a replay
+type Result struct {
+	RunID      string
+	WorkflowID string
+
+	// Steps holds one entry per recorded step execution, in execution order
+	Steps []StepResult
+}
+
+// Changed returns the replayed steps whose status or output differ from the recording
+func (r *Result) Changed() []StepResult {
+	var changed []StepResult
+	for _, step := range r.Steps {
+		if step.Changed() {
+			changed = append(changed, step)
+		}
+	}
+	return changed
+}
+
+// StepResult compares a step's recorded execution with its replay
+type StepResult struct {
+	StepID string
+
+	// Replayed is false for steps served from the recorded output
+	Replayed bool
+
+	RecordedStatus gorkflow.StepStatus
+	Status         gorkflow.StepStatus
+
+	Input          []byte
+	RecordedOutput []byte
+	Output         []byte
+
+	// Error is the error returned by the replayed step
+	Error    error
+	Duration time.Duration
+
+	// Diff lists the differences between the recorded and replayed output
+	Diff []Difference
+}
+
+// Changed reports whether the replay's status or output differ from the recording
+func (s StepResult) Changed() bool {
+	return s.Replayed && (s.Status != s.RecordedStatus || len(s.Diff) > 0)
+}
+
+// Run replays a recorded run of wf from source. wf must be the current
+// definition of the run's workflow; only the selected steps need to exist in it.
+//
+// Replayed steps run once, without retries, within the step timeout. Outputs
+// they produce replace the recorded ones for steps that read them through
+// ctx.Data later in the replay. Workflow state starts as recorded at the end
+// of the run, since intermediate state is not stored.
+func Run(ctx context.Context, source gorkflow.WorkflowStore, wf *gorkflow.Workflow, runID string, opts ...Option) (*Result, error) {
+	cfg := &config{logger: zerolog.Nop()}
+	for _, opt := range opts {
+		opt(cfg)
+	}
+
+	run, err := source.GetRun(ctx, runID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get run: %w", err)
+	}
+	if run.WorkflowID != wf.ID() {
+		return nil, fmt.Errorf("run %s belongs to workflow %s, not %s", runID, run.WorkflowID, wf.ID())
+	}
+
+	execs, err := source.ListStepExecutions(ctx, runID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list step executions: %w", err)
+	}
+	sort.SliceStable(execs, func(i, j int) bool { return execs[i].ExecutionIndex < execs[j].ExecutionIndex })
+
+	recorded := make(map[string]bool, len(execs))
+	for _, exec := range execs {
+		recorded[exec.StepID] = true
+	}
+	for stepID := range cfg.steps {
+		if !recorded[stepID] {
+			return nil, fmt.Errorf("%w: %s", ErrStepNotRecorded, stepID)
+		}
+	}
+
+	sandbox, outputs, err := copyRun(ctx, source, run, execs)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &Result{RunID: run.RunID, WorkflowID: run.WorkflowID}
+	state := gorkflow.NewStateAccessor(run.RunID, sandbox)
+	gorkflow.SetStateAccessorSerializer(state, wf.Serializer())
+
+	for _, exec := range execs {
+		step := StepResult{
+			StepID:         exec.StepID,
+			RecordedStatus: exec.Status,
+			Status:         exec.Status,
+			Input:          exec.Input,
+			RecordedOutput: outputs[exec.StepID],
+			Output:         outputs[exec.StepID],
+		}
+
+		if cfg.steps == nil || cfg.steps[exec.StepID] {
+			if err := ctx.Err(); err != nil {
+				return nil, err
+			}
+			executor, err := wf.GetStep(exec.StepID)
+			if err != nil {
+				return nil, fmt.Errorf("step %s: %w", exec.StepID, err)
+			}
+			replayStep(ctx, cfg, wf, run, executor, sandbox, state, &step)
+		}
+
+		result.Steps = append(result.Steps, step)
+	}
+	return result, nil
+}
+
+// copyRun copies a run's step executions, outputs and state into an in-memory
+// store and returns it with the recorded outputs
+func copyRun(ctx context.Context, source gorkflow.WorkflowStore, run *gorkflow.WorkflowRun, execs []*gorkflow.StepExecution) (gorkflow.WorkflowStore, map[string][]byte, error) {
+	sandbox := store.NewMemoryStore()
+	if err := sandbox.CreateRun(ctx, run); err != nil {
+		return nil, nil, fmt.Errorf("failed to copy run: %w", err)
+	}
+
+	outputs := make(map[string][]byte, len(execs))
+	for _, exec := range execs {
+		if err := sandbox.CreateStepExecution(ctx, exec); err != nil {
+			return nil, nil, fmt.Errorf("failed to copy step execution %s: %w", exec.StepID, err)
+		}
+
+		output, err := source.LoadStepOutput(ctx, run.RunID, exec.StepID)
+		if errors.Is(err, gorkflow.ErrStepOutputNotFound) {
+			continue
+		}
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to load output of step %s: %w", exec.StepID, err)
+		}
+		outputs[exec.StepID] = output
+		if err := sandbox.SaveStepOutput(ctx, run.RunID, exec.StepID, output); err != nil {
+			return nil, nil, fmt.Errorf("failed to copy output of step %s: %w", exec.StepID, err)
+		}
+	}
+
+	state, err := source.GetAllState(ctx, run.RunID)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to load state: %w", err)
+	}
+	for key, value := range state {
+		if err := sandbox.SaveState(ctx, run.RunID, key, value); err != nil {
+			return nil, nil, fmt.Errorf("failed to copy state %s: %w", key, err)
+		}
+	}
+	return sandbox, outputs, nil
+}
+
+// replayStep executes a step once with its recorded input and records the outcome
+func replayStep(
+	ctx context.Context,
+	cfg *config,
+	wf *gorkflow.Workflow,
+	run *gorkflow.WorkflowRun,
+	executor gorkflow.StepExecutor,
+	sandbox gorkflow.WorkflowStore,
+	state gorkflow.StateAccessor,
+	step *StepResult,
+) {
+	step.Replayed = true
+
+	var chain []gorkflow.StepInterceptor
+	chain = append(chain, cfg.interceptors...)
+	chain = append(chain, wf.Interceptors()...)
+	chain = append(chain, gorkflow.StepInterceptors(executor)...)
+	invoke := gorkflow.ChainInterceptors(executor.Execute, chain...)
+
+	timeout := time.Duration(executor.GetConfig().TimeoutSeconds) * time.Second
+	execCtx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	data := gorkflow.NewStepAccessor(run.RunID, sandbox)
+	gorkflow.SetStepAccessorCtx(data, execCtx)
+	gorkflow.SetStateAccessorCtx(state, execCtx)
+
+	stepCtx := &gorkflow.StepContext{
+		Context:       execCtx,
+		RunID:         run.RunID,
+		StepID:        executor.GetID(),
+		Logger:        cfg.logger.With().Str("run_id", run.RunID).Str("step_id", executor.GetID()).Logger(),
+		Data:          data,
+		State:         state,
+		CustomContext: wf.GetContext(),
+		Serializer:    wf.Serializer(),
+	}
+
+	start := time.Now()
+	output, err := invokeRecovered(invoke, stepCtx, step.Input)
+	step.Duration = time.Since(start)
+
+	switch {
+	case errors.Is(err, gorkflow.ErrStepSkipped):
+		step.Status = gorkflow.StepStatusSkipped
+	case err != nil:
+		step.Status = gorkflow.StepStatusFailed
+		step.Error = err
+		step.Output = nil
+		return
+	case stepCtx.SkipReason() != "":
+		step.Status = gorkflow.StepStatusSkipped
+	default:
+		step.Status = gorkflow.StepStatusCompleted
+	}
+
+	step.Output = output
+	step.Diff = Compare(step.RecordedOutput, output)
+
+	// Later steps reading this output through ctx.Data see the replayed value
+	_ = sandbox.SaveStepOutput(ctx, run.RunID, executor.GetID(), output)
+}
+
+// invokeRecovered calls a step, converting a panic into an error
+func invokeRecovered(invoke gorkflow.StepInvoker, ctx *gorkflow.StepContext, input []byte) (output []byte, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("step panicked: %v", r)
+		}
+	}()
+	return invoke(ctx, input)
+}
@@ -0,0 +1,172 @@
+package replay_test
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/sicko7947/gorkflow"
+	"github.com/sicko7947/gorkflow/engine"
+	"github.com/sicko7947/gorkflow/replay"
+	"github.com/sicko7947/gorkflow/store"
+)
+
+type order struct {
+	Items []string `json:"items"`
+}
+
+type priced struct {
+	Items []string `json:"items"`
+	Total int      `json:"total"`
+}
+
+type receipt struct {
+	Text string `json:"text"`
+}
+
+// orderWorkflow builds the workflow with the given price per item, standing in
+// for a change to the pricing handler between recording and replay
+func orderWorkflow(t *testing.T, unitPrice int) *gorkflow.Workflow {
+	t.Helper()
+	price := gorkflow.NewStep("price", "Price", func(ctx *gorkflow.StepContext, in order) (priced, error) {
+		return priced{Items: in.Items, Total: unitPrice * len(in.Items)}, nil
+	})
+	printStep := gorkflow.NewStep("print", "Print", func(ctx *gorkflow.StepContext, in priced) (receipt, error) {
+		if err := ctx.State.Set("printed", true); err != nil {
+			return receipt{}, err
+		}
+		return receipt{Text: strings.Join(in.Items, ",")}, nil
+	})
+	wf, err := gorkflow.NewWorkflow("orders", "Orders").
+		ThenStep(price).
+		ThenStep(printStep).
+		Build()
+	require.NoError(t, err)
+	return wf
+}
+
+// recordRun executes the workflow once and returns its store and run ID
+func recordRun(t *testing.T) (gorkflow.WorkflowStore, string) {
+	t.Helper()
+	wfStore := store.NewMemoryStore()
+	eng := engine.NewEngine(wfStore, engine.WithLogger(zerolog.Nop()))
+	runID, err := eng.StartWorkflow(context.Background(), orderWorkflow(t, 10), order{Items: []string{"a", "b"}},
+		gorkflow.WithSynchronousExecution())
+	require.NoError(t, err)
+	return wfStore, runID
+}
+
+func TestRun_DetectsChangedOutput(t *testing.T) {
+	source, runID := recordRun(t)
+
+	result, err := replay.Run(context.Background(), source, orderWorkflow(t, 12), runID)
+	require.NoError(t, err)
+	require.Len(t, result.Steps, 2)
+
+	changed := result.Changed()
+	require.Len(t, changed, 1)
+	assert.Equal(t, "price", changed[0].StepID)
+	assert.Equal(t, []replay.Difference{{Path: "$.total", Recorded: float64(20), Replayed: float64(24)}}, changed[0].Diff)
+
+	// The print step replays with its recorded input, so it is unchanged
+	assert.True(t, result.Steps[1].Replayed)
+	assert.False(t, result.Steps[1].Changed())
+}
+
+func TestRun_SelectedSteps(t *testing.T) {
+	source, runID := recordRun(t)
+
+	calls := 0
+	wf := orderWorkflow(t, 12)
+	result, err := replay.Run(context.Background(), source, wf, runID,
+		replay.Steps("print"),
+		replay.WithInterceptors(func(ctx *gorkflow.StepContext, input []byte, next gorkflow.StepInvoker) ([]byte, error) {
+			calls++
+			return next(ctx, input)
+		}),
+	)
+	require.NoError(t, err)
+
+	assert.Equal(t, 1, calls)
+	assert.False(t, result.Steps[0].Replayed, "price should be served from the recording")
+	assert.Equal(t, result.Steps[0].RecordedOutput, result.Steps[0].Output)
+	assert.True(t, result.Steps[1].Replayed)
+	assert.Empty(t, result.Changed())
+}
+
+func TestRun_DoesNotWriteSource(t *testing.T) {
+	source, runID := recordRun(t)
+	ctx := context.Background()
+
+	require.NoError(t, source.DeleteState(ctx, runID, "printed"))
+	before, err := source.LoadStepOutput(ctx, runID, "price")
+	require.NoError(t, err)
+
+	_, err = replay.Run(ctx, source, orderWorkflow(t, 99), runID)
+	require.NoError(t, err)
+
+	after, err := source.LoadStepOutput(ctx, runID, "price")
+	require.NoError(t, err)
+	assert.Equal(t, before, after)
+
+	_, err = source.LoadState(ctx, runID, "printed")
+	assert.ErrorIs(t, err, gorkflow.ErrStateNotFound, "state written during replay must not reach the source")
+}
+
+func TestRun_FailingStep(t *testing.T) {
+	source, runID := recordRun(t)
+
+	price := gorkflow.NewStep("price", "Price", func(ctx *gorkflow.StepContext, in order) (priced, error) {
+		panic("pricing bug")
+	})
+	wf, err := gorkflow.NewWorkflow("orders", "Orders").ThenStep(price).Build()
+	require.NoError(t, err)
+
+	result, err := replay.Run(context.Background(), source, wf, runID, replay.Steps("price"))
+	require.NoError(t, err)
+
+	step := result.Steps[0]
+	assert.Equal(t, gorkflow.StepStatusFailed, step.Status)
+	assert.ErrorContains(t, step.Error, "pricing bug")
+	assert.True(t, step.Changed())
+}
+
+func TestRun_Errors(t *testing.T) {
+	source, runID := recordRun(t)
+	ctx := context.Background()
+
+	_, err := replay.Run(ctx, source, orderWorkflow(t, 10), runID, replay.Steps("missing"))
+	assert.ErrorIs(t, err, replay.ErrStepNotRecorded)
+
+	_, err = replay.Run(ctx, source, orderWorkflow(t, 10), "unknown")
+	assert.ErrorIs(t, err, gorkflow.ErrRunNotFound)
+
+	other, err := gorkflow.NewWorkflow("other", "Other").
+		ThenStep(gorkflow.NewStep("price", "Price", func(ctx *gorkflow.StepContext, in order) (order, error) { return in, nil })).
+		Build()
+	require.NoError(t, err)
+	_, err = replay.Run(ctx, source, other, runID)
+	assert.Error(t, err)
+	assert.False(t, errors.Is(err, gorkflow.ErrRunNotFound))
+}
+
+func TestCompare(t *testing.T) {
+	assert.Empty(t, replay.Compare([]byte(`{"a":1,"b":[1,2]}`), []byte(`{ "b": [1, 2], "a": 1 }`)))
+
+	diffs := replay.Compare([]byte(`{"a":1,"b":[1,2],"c":"x"}`), []byte(`{"a":2,"b":[1],"d":true}`))
+	paths := make([]string, len(diffs))
+	for i, d := range diffs {
+		paths[i] = d.Path
+	}
+	assert.Equal(t, []string{"$.a", "$.b[1]", "$.c", "$.d"}, paths)
+	assert.Equal(t, `$.b[1]: 2 -> null`, diffs[1].String())
+
+	diffs = replay.Compare([]byte("not json"), []byte(`{}`))
+	require.Len(t, diffs, 1)
+	assert.Equal(t, "$", diffs[0].Path)
+}
@@ -0,0 +1,44 @@
+package gorkflow
+
+import (
+	"context"
+	"fmt"
+)
+
+// RunWaiter blocks until a run reaches a terminal status; implemented by the engine
+type RunWaiter interface {
+	WaitForRun(ctx context.Context, runID string) (*WorkflowRun, error)
+}
+
+// GetResult waits for a run to finish and returns its output decoded as T.
+// Failed and cancelled runs return the run's *WorkflowError.
+func GetResult[T any](ctx context.Context, waiter RunWaiter, runID string) (T, error) {
+	var result T
+
+	run, err := waiter.WaitForRun(ctx, runID)
+	if err != nil {
+		return result, err
+	}
+
+	switch run.Status {
+	case RunStatusCompleted:
+	case RunStatusCancelled:
+		if run.Error != nil {
+			return result, run.Error
+		}
+		return result, NewWorkflowError(ErrCodeCancelled, "workflow cancelled")
+	default:
+		if run.Error != nil {
+			return result, run.Error
+		}
+		return result, NewWorkflowError(ErrCodeExecutionFailed, fmt.Sprintf("workflow %s", run.Status))
+	}
+
+	if len(run.Output) == 0 {
+		return result, nil
+	}
+	if err := UnmarshalPayload(run.Output, &result); err != nil {
+		return result, fmt.Errorf("failed to decode output of run %s: %w", runID, err)
+	}
+	return result, nil
+}
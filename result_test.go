@@ -0,0 +1,67 @@
+package gorkflow
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// waiterFunc adapts a function to RunWaiter
+type waiterFunc func(ctx context.Context, runID string) (*WorkflowRun, error)
+
+func (f waiterFunc) WaitForRun(ctx context.Context, runID string) (*WorkflowRun, error) {
+	return f(ctx, runID)
+}
+
+func finishedRun(run *WorkflowRun) RunWaiter {
+	return waiterFunc(func(ctx context.Context, runID string) (*WorkflowRun, error) {
+		return run, nil
+	})
+}
+
+type resultOutput struct {
+	Count int `json:"count"`
+}
+
+func TestGetResult_Completed(t *testing.T) {
+	waiter := finishedRun(&WorkflowRun{Status: RunStatusCompleted, Output: json.RawMessage(`{"count":3}`)})
+
+	result, err := GetResult[resultOutput](context.Background(), waiter, "run-1")
+	require.NoError(t, err)
+	assert.Equal(t, 3, result.Count)
+
+	_, err = GetResult[[]string](context.Background(), waiter, "run-1")
+	assert.ErrorContains(t, err, "failed to decode output")
+}
+
+func TestGetResult_Failed(t *testing.T) {
+	waiter := finishedRun(&WorkflowRun{
+		Status: RunStatusFailed,
+		Error:  NewWorkflowErrorWithStep(ErrCodeTimeout, "step timed out", "fetch"),
+	})
+
+	_, err := GetResult[resultOutput](context.Background(), waiter, "run-1")
+	var wfErr *WorkflowError
+	require.ErrorAs(t, err, &wfErr)
+	assert.Equal(t, ErrCodeTimeout, wfErr.Code)
+	assert.Equal(t, "fetch", wfErr.Step)
+}
+
+func TestGetResult_Cancelled(t *testing.T) {
+	_, err := GetResult[resultOutput](context.Background(), finishedRun(&WorkflowRun{Status: RunStatusCancelled}), "run-1")
+	var wfErr *WorkflowError
+	require.ErrorAs(t, err, &wfErr)
+	assert.Equal(t, ErrCodeCancelled, wfErr.Code)
+}
+
+func TestGetResult_WaitError(t *testing.T) {
+	waiter := waiterFunc(func(ctx context.Context, runID string) (*WorkflowRun, error) {
+		return nil, ErrRunNotFound
+	})
+	_, err := GetResult[resultOutput](context.Background(), waiter, "missing")
+	assert.True(t, errors.Is(err, ErrRunNotFound))
+}
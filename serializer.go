@@ -0,0 +1,174 @@
+package gorkflow
+
+import (
+	"bytes"
+	"encoding/gob"
+	"encoding/json"
+	"fmt"
+	"sync"
+
+	"github.com/vmihailenco/msgpack/v5"
+)
+
+// Serializer converts run inputs, step inputs and outputs and state values to
+// and from bytes. The serializer of a workflow is set with
+// WorkflowBuilder.WithSerializer and defaults to JSONSerializer.
+type Serializer interface {
+	// Name identifies the format in serialized payloads (at most 255 bytes)
+	Name() string
+
+	Marshal(v any) ([]byte, error)
+	Unmarshal(data []byte, v any) error
+}
+
+var (
+	// JSONSerializer uses encoding/json. Its payloads are stored as plain JSON.
+	JSONSerializer Serializer = jsonSerializer{}
+
+	// MsgpackSerializer uses MessagePack, honouring json struct tags. Integers
+	// keep their full precision and []byte values are stored as raw bytes.
+	MsgpackSerializer Serializer = msgpackSerializer{}
+
+	// GobSerializer uses encoding/gob. Values stored in interface fields must
+	// be registered with gob.Register.
+	GobSerializer Serializer = gobSerializer{}
+)
+
+var (
+	serializersMu sync.RWMutex
+	serializers   = map[string]Serializer{
+		JSONSerializer.Name():    JSONSerializer,
+		MsgpackSerializer.Name(): MsgpackSerializer,
+		GobSerializer.Name():     GobSerializer,
+	}
+)
+
+// RegisterSerializer makes a custom serializer available to UnmarshalPayload.
+// Built-in serializers are always registered.
+func RegisterSerializer(s Serializer) {
+	serializersMu.Lock()
+	defer serializersMu.Unlock()
+	serializers[s.Name()] = s
+}
+
+// Payloads of serializers other than JSON start with a header naming the
+// serializer: serializedMarker, serializedKind, the name length and the name.
+// JSON never starts with the marker, which payload-aware stores also use for
+// their own frames.
+const (
+	serializedMarker = 0x1E
+	serializedKind   = 'S'
+)
+
+// MarshalPayload serializes v with s and records the format. JSON payloads are
+// returned as plain JSON; other formats are prefixed with a header naming the
+// serializer. A nil s uses JSONSerializer.
+func MarshalPayload(s Serializer, v any) ([]byte, error) {
+	if s == nil || s.Name() == JSONSerializer.Name() {
+		return json.Marshal(v)
+	}
+	name := s.Name()
+	if name == "" || len(name) > 255 {
+		return nil, fmt.Errorf("invalid serializer name %q", name)
+	}
+	body, err := s.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	data := make([]byte, 0, len(name)+len(body)+3)
+	data = append(data, serializedMarker, serializedKind, byte(len(name)))
+	data = append(data, name...)
+	return append(data, body...), nil
+}
+
+// UnmarshalPayload decodes a payload written by MarshalPayload into v, using
+// the serializer recorded in the payload
+func UnmarshalPayload(data []byte, v any) error {
+	s, body, err := payloadSerializer(data)
+	if err != nil {
+		return err
+	}
+	return s.Unmarshal(body, v)
+}
+
+// PayloadFormat returns the name of the serializer that wrote a payload
+func PayloadFormat(data []byte) (string, error) {
+	s, _, err := payloadSerializer(data)
+	if err != nil {
+		return "", err
+	}
+	return s.Name(), nil
+}
+
+// PayloadJSON returns a payload as JSON for display. JSON payloads are
+// returned unchanged; payloads of other formats are decoded and re-encoded.
+func PayloadJSON(data []byte) (json.RawMessage, error) {
+	if len(data) == 0 || json.Valid(data) {
+		return data, nil
+	}
+	var v any
+	if err := UnmarshalPayload(data, &v); err != nil {
+		return nil, err
+	}
+	return json.Marshal(v)
+}
+
+// payloadSerializer returns the serializer recorded in data and the serialized body
+func payloadSerializer(data []byte) (Serializer, []byte, error) {
+	if len(data) < 2 || data[0] != serializedMarker || data[1] != serializedKind {
+		return JSONSerializer, data, nil
+	}
+	if len(data) < 3 || len(data) < 3+int(data[2]) {
+		return nil, nil, fmt.Errorf("truncated serialized payload")
+	}
+	name := string(data[3 : 3+int(data[2])])
+	serializersMu.RLock()
+	s, ok := serializers[name]
+	serializersMu.RUnlock()
+	if !ok {
+		return nil, nil, fmt.Errorf("unknown serializer %q", name)
+	}
+	return s, data[3+len(name):], nil
+}
+
+type jsonSerializer struct{}
+
+func (jsonSerializer) Name() string                       { return "json" }
+func (jsonSerializer) Marshal(v any) ([]byte, error)      { return json.Marshal(v) }
+func (jsonSerializer) Unmarshal(data []byte, v any) error { return json.Unmarshal(data, v) }
+
+type msgpackSerializer struct{}
+
+func (msgpackSerializer) Name() string { return "msgpack" }
+
+func (msgpackSerializer) Marshal(v any) ([]byte, error) {
+	var buf bytes.Buffer
+	enc := msgpack.NewEncoder(&buf)
+	enc.SetCustomStructTag("json")
+	if err := enc.Encode(v); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+func (msgpackSerializer) Unmarshal(data []byte, v any) error {
+	dec := msgpack.NewDecoder(bytes.NewReader(data))
+	dec.SetCustomStructTag("json")
+	return dec.Decode(v)
+}
+
+type gobSerializer struct{}
+
+func (gobSerializer) Name() string { return "gob" }
+
+func (gobSerializer) Marshal(v any) ([]byte, error) {
+	var buf bytes.Buffer
+	if err := gob.NewEncoder(&buf).Encode(v); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+func (gobSerializer) Unmarshal(data []byte, v any) error {
+	return gob.NewDecoder(bytes.NewReader(data)).Decode(v)
+}
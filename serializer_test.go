@@ -0,0 +1,129 @@
+package gorkflow
+
+import (
+	"math"
+	"reflect"
+	"testing"
+)
+
+type serializerPayload struct {
+	ID    int64             `json:"id"`
+	Name  string            `json:"name"`
+	Blob  []byte            `json:"blob"`
+	Attrs map[string]string `json:"attrs"`
+}
+
+func TestMarshalPayload_RoundTrip(t *testing.T) {
+	in := serializerPayload{
+		ID:    math.MaxInt64,
+		Name:  "order",
+		Blob:  []byte{0, 1, 2, 0x1E, 0xFF},
+		Attrs: map[string]string{"region": "eu"},
+	}
+
+	for _, s := range []Serializer{JSONSerializer, MsgpackSerializer, GobSerializer} {
+		t.Run(s.Name(), func(t *testing.T) {
+			data, err := MarshalPayload(s, in)
+			if err != nil {
+				t.Fatalf("MarshalPayload() error = %v", err)
+			}
+			format, err := PayloadFormat(data)
+			if err != nil {
+				t.Fatalf("PayloadFormat() error = %v", err)
+			}
+			if format != s.Name() {
+				t.Errorf("PayloadFormat() = %q, want %q", format, s.Name())
+			}
+
+			var out serializerPayload
+			if err := UnmarshalPayload(data, &out); err != nil {
+				t.Fatalf("UnmarshalPayload() error = %v", err)
+			}
+			if !reflect.DeepEqual(out, in) {
+				t.Errorf("UnmarshalPayload() = %+v, want %+v", out, in)
+			}
+		})
+	}
+}
+
+func TestMarshalPayload_JSONIsPlain(t *testing.T) {
+	for _, s := range []Serializer{nil, JSONSerializer} {
+		data, err := MarshalPayload(s, map[string]int{"a": 1})
+		if err != nil {
+			t.Fatalf("MarshalPayload() error = %v", err)
+		}
+		if string(data) != `{"a":1}` {
+			t.Errorf("MarshalPayload() = %s, want plain JSON", data)
+		}
+	}
+}
+
+func TestMarshalPayload_MsgpackKeepsIntegerPrecision(t *testing.T) {
+	// 2^53 + 1 is not representable as a float64, so decoding it into an
+	// interface value loses precision with JSON
+	const big = int64(1<<53 + 1)
+
+	data, err := MarshalPayload(MsgpackSerializer, map[string]any{"n": big})
+	if err != nil {
+		t.Fatalf("MarshalPayload() error = %v", err)
+	}
+	var out map[string]any
+	if err := UnmarshalPayload(data, &out); err != nil {
+		t.Fatalf("UnmarshalPayload() error = %v", err)
+	}
+	if got := reflect.ValueOf(out["n"]).Int(); got != big {
+		t.Errorf("n = %d, want %d", got, big)
+	}
+}
+
+type upperSerializer struct{}
+
+func (upperSerializer) Name() string                       { return "test-upper" }
+func (upperSerializer) Marshal(v any) ([]byte, error)      { return []byte(v.(string)), nil }
+func (upperSerializer) Unmarshal(data []byte, v any) error { *v.(*string) = string(data); return nil }
+
+func TestUnmarshalPayload_Registry(t *testing.T) {
+	data, err := MarshalPayload(upperSerializer{}, "hello")
+	if err != nil {
+		t.Fatalf("MarshalPayload() error = %v", err)
+	}
+
+	var out string
+	if err := UnmarshalPayload(data, &out); err == nil {
+		t.Fatal("UnmarshalPayload() with an unregistered serializer should fail")
+	}
+
+	RegisterSerializer(upperSerializer{})
+	if err := UnmarshalPayload(data, &out); err != nil {
+		t.Fatalf("UnmarshalPayload() error = %v", err)
+	}
+	if out != "hello" {
+		t.Errorf("UnmarshalPayload() = %q, want hello", out)
+	}
+}
+
+func TestUnmarshalPayload_Truncated(t *testing.T) {
+	var out string
+	if err := UnmarshalPayload([]byte{serializedMarker, serializedKind, 10, 'g'}, &out); err == nil {
+		t.Error("UnmarshalPayload() of a truncated header should fail")
+	}
+}
+
+func TestPayloadJSON(t *testing.T) {
+	data, err := MarshalPayload(MsgpackSerializer, map[string]any{"name": "order", "count": 2})
+	if err != nil {
+		t.Fatalf("MarshalPayload() error = %v", err)
+	}
+	out, err := PayloadJSON(data)
+	if err != nil {
+		t.Fatalf("PayloadJSON() error = %v", err)
+	}
+	if string(out) != `{"count":2,"name":"order"}` {
+		t.Errorf("PayloadJSON() = %s, want the payload as JSON", out)
+	}
+
+	plain := []byte(`{"a": 1}`)
+	if out, _ := PayloadJSON(plain); string(out) != string(plain) {
+		t.Errorf("PayloadJSON() = %s, want JSON unchanged", out)
+	}
+}
@@ -0,0 +1,25 @@
+package gorkflow
+
+// signalStatePrefix namespaces signal payloads in the state of a run
+const signalStatePrefix = "signal:"
+
+// SignalStateKey returns the state key holding the payload of a signal sent to
+// a run with Engine.Signal
+func SignalStateKey(name string) string {
+	return signalStatePrefix + name
+}
+
+// ReceiveSignal reads the payload of a signal sent to the run. ok is false
+// while the signal has not been sent; steps that cannot proceed without it
+// return an error and let their retry policy wait for it.
+func ReceiveSignal[T any](ctx *StepContext, name string) (value T, ok bool, err error) {
+	key := SignalStateKey(name)
+	if !ctx.State.Has(key) {
+		return value, false, nil
+	}
+	value, err = GetTyped[T](ctx.State, key)
+	if err != nil {
+		return value, false, err
+	}
+	return value, true, nil
+}
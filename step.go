@@ -1,7 +1,6 @@
 package gorkflow
 
 import (
-	"encoding/json"
 	"fmt"
 	"reflect"
 
@@ -27,6 +26,21 @@ type Step[TIn, TOut any] struct {
 	// Validation configuration (internal)
 	validationConfig *validationConfig
 
+	// Step-level interceptors (internal)
+	interceptors []StepInterceptor
+
+	// Names of engine limiters acquired before each attempt (internal)
+	limiters []string
+
+	// Name of the engine circuit breaker guarding the step (internal)
+	circuitBreaker string
+
+	// Cross-run output cache policy (internal)
+	cachePolicy *CachePolicy
+
+	// Compression of persisted payloads (internal)
+	compression Compression
+
 	// Type information (for runtime reflection/validation)
 	inputType  reflect.Type
 	outputType reflect.Type
@@ -122,7 +136,7 @@ func (s *Step[TIn, TOut]) Execute(ctx *StepContext, inputBytes []byte) ([]byte,
 	}
 
 	// Validate and marshal output
-	outputBytes, err := validateOutputData(output, s.validationConfig)
+	outputBytes, err := validateOutputData(output, s.validationConfig, ctx.Serializer)
 	if err != nil {
 		return nil, err
 	}
@@ -142,7 +156,7 @@ func (s *Step[TIn, TOut]) ValidateInput(data []byte) error {
 // ValidateOutput validates that data can be unmarshaled to TOut and passes validation
 func (s *Step[TIn, TOut]) ValidateOutput(data []byte) error {
 	var output TOut
-	if err := json.Unmarshal(data, &output); err != nil {
+	if err := UnmarshalPayload(data, &output); err != nil {
 		return fmt.Errorf("invalid output for step %s: %w", s.ID, err)
 	}
 
@@ -192,6 +206,51 @@ func (s *Step[TIn, TOut]) DisableValidation() {
 	s.validationConfig = nil
 }
 
+func (s *Step[TIn, TOut]) AddInterceptors(interceptors ...StepInterceptor) {
+	s.interceptors = append(s.interceptors, interceptors...)
+}
+
+// GetInterceptors returns the step-level interceptors
+func (s *Step[TIn, TOut]) GetInterceptors() []StepInterceptor {
+	return s.interceptors
+}
+
+func (s *Step[TIn, TOut]) AddLimiters(names ...string) {
+	s.limiters = append(s.limiters, names...)
+}
+
+// GetLimiters returns the names of the limiters attached to the step
+func (s *Step[TIn, TOut]) GetLimiters() []string {
+	return s.limiters
+}
+
+func (s *Step[TIn, TOut]) SetCircuitBreaker(name string) {
+	s.circuitBreaker = name
+}
+
+// GetCircuitBreaker returns the name of the circuit breaker guarding the step
+func (s *Step[TIn, TOut]) GetCircuitBreaker() string {
+	return s.circuitBreaker
+}
+
+func (s *Step[TIn, TOut]) SetCachePolicy(policy *CachePolicy) {
+	s.cachePolicy = policy
+}
+
+// GetCachePolicy returns the step's output cache policy, or nil
+func (s *Step[TIn, TOut]) GetCachePolicy() *CachePolicy {
+	return s.cachePolicy
+}
+
+func (s *Step[TIn, TOut]) SetCompression(compression Compression) {
+	s.compression = compression
+}
+
+// GetCompression returns the step's payload compression
+func (s *Step[TIn, TOut]) GetCompression() Compression {
+	return s.compression
+}
+
 // Condition is a function that determines if a step should execute
 type Condition func(ctx *StepContext) (bool, error)
 
@@ -202,11 +261,51 @@ func evalCondition(ctx *StepContext, cond Condition) (bool, error) {
 		return false, fmt.Errorf("condition evaluation failed: %w", err)
 	}
 	if !shouldRun {
-		LogStepSkipped(ctx.Logger, ctx.RunID, ctx.StepID, "condition_not_met")
+		ctx.skipReason = "condition_not_met"
+		LogStepSkipped(ctx.Logger, ctx.RunID, ctx.StepID, ctx.skipReason)
 	}
 	return shouldRun, nil
 }
 
+// evalStepCondition evaluates the condition of step, reusing the result of a
+// StepConditionMet call made for the same step ahead of this execution
+func evalStepCondition(ctx *StepContext, step StepExecutor, cond Condition) (bool, error) {
+	if ctx.checkedStep == step {
+		ctx.checkedStep = nil
+		return ctx.conditionMet, nil
+	}
+	return evalCondition(ctx, cond)
+}
+
+// StepConditionMet evaluates the condition of a conditional step before it
+// executes; other steps always run. The step reuses the result when it next
+// executes with ctx, so the condition is evaluated once.
+func StepConditionMet(ctx *StepContext, step StepExecutor) (met bool, err error) {
+	s, ok := step.(interface{ stepCondition() Condition })
+	if !ok {
+		return true, nil
+	}
+	// A panicking condition is left for the step's execution to recover and report
+	defer func() {
+		if r := recover(); r != nil {
+			met, err = false, fmt.Errorf("condition panicked: %v", r)
+		}
+	}()
+	met, err = evalCondition(ctx, s.stepCondition())
+	if err != nil {
+		return false, err
+	}
+	ctx.checkedStep = step
+	ctx.conditionMet = met
+	return met, nil
+}
+
+// IsConditionalStep reports whether a step only runs when its condition holds
+func IsConditionalStep(step StepExecutor) bool {
+	_, ok := step.(interface{ conditional() })
+	return ok
+}
+
 // ConditionalStep wraps a step with a condition
 type ConditionalStep[TIn, TOut any] struct {
 	Step      *Step[TIn, TOut]
@@ -228,6 +327,12 @@ func (cs *ConditionalStep[TIn, TOut]) GetDescription() string {
 	return cs.Step.GetDescription()
 }
 
+func (cs *ConditionalStep[TIn, TOut]) conditional() {}
+
+func (cs *ConditionalStep[TIn, TOut]) stepCondition() Condition {
+	return cs.Condition
+}
+
 func (cs *ConditionalStep[TIn, TOut]) GetConfig() ExecutionConfig {
 	return cs.Step.GetConfig()
 }
@@ -245,23 +350,43 @@ func (cs *ConditionalStep[TIn, TOut]) OutputType() reflect.Type {
 }
 
 func (cs *ConditionalStep[TIn, TOut]) Execute(ctx *StepContext, inputBytes []byte) ([]byte, error) {
-	shouldRun, err := evalCondition(ctx, cs.Condition)
+	shouldRun, err := evalStepCondition(ctx, cs, cs.Condition)
 	if err != nil {
 		return nil, err
 	}
 
 	if !shouldRun {
 		if cs.Default != nil {
-			return json.Marshal(cs.Default)
+			return MarshalPayload(ctx.Serializer, cs.Default)
 		}
 		var zero TOut
-		return json.Marshal(zero)
+		return MarshalPayload(ctx.Serializer, zero)
 	}
 
 	// Execute the wrapped step
 	return cs.Step.Execute(ctx, inputBytes)
 }
 
+func (cs *ConditionalStep[TIn, TOut]) GetInterceptors() []StepInterceptor {
+	return cs.Step.GetInterceptors()
+}
+
+func (cs *ConditionalStep[TIn, TOut]) GetLimiters() []string {
+	return cs.Step.GetLimiters()
+}
+
+func (cs *ConditionalStep[TIn, TOut]) GetCircuitBreaker() string {
+	return cs.Step.GetCircuitBreaker()
+}
+
+func (cs *ConditionalStep[TIn, TOut]) GetCompression() Compression {
+	return cs.Step.GetCompression()
+}
+
+func (cs *ConditionalStep[TIn, TOut]) GetCachePolicy() *CachePolicy {
+	return cs.Step.GetCachePolicy()
+}
+
 func (cs *ConditionalStep[TIn, TOut]) ValidateInput(data []byte) error {
 	return cs.Step.ValidateInput(data)
 }
@@ -303,6 +428,12 @@ func (w *conditionalStepWrapper) GetDescription() string {
 	return w.step.GetDescription()
 }
 
+func (w *conditionalStepWrapper) conditional() {}
+
+func (w *conditionalStepWrapper) stepCondition() Condition {
+	return w.condition
+}
+
 func (w *conditionalStepWrapper) GetConfig() ExecutionConfig {
 	return w.step.GetConfig()
 }
@@ -320,14 +451,14 @@ func (w *conditionalStepWrapper) OutputType() reflect.Type {
 }
 
 func (w *conditionalStepWrapper) Execute(ctx *StepContext, inputBytes []byte) ([]byte, error) {
-	shouldRun, err := evalCondition(ctx, w.condition)
+	shouldRun, err := evalStepCondition(ctx, w, w.condition)
 	if err != nil {
 		return nil, err
 	}
 
 	if !shouldRun {
 		if w.defaultValue != nil {
-			return json.Marshal(w.defaultValue)
+			return MarshalPayload(ctx.Serializer, w.defaultValue)
 		}
 
 		// If input type matches output type, pass through the input
@@ -338,7 +469,7 @@ func (w *conditionalStepWrapper) Execute(ctx *StepContext, inputBytes []byte) ([
 
 		// Return zero value for the output type
 		zeroVal := reflect.Zero(w.step.OutputType()).Interface()
-		bytes, _ := json.Marshal(zeroVal)
+		bytes, _ := MarshalPayload(ctx.Serializer, zeroVal)
 		return bytes, ErrStepSkipped
 	}
 
@@ -346,6 +477,26 @@ func (w *conditionalStepWrapper) Execute(ctx *StepContext, inputBytes []byte) ([
 	return w.step.Execute(ctx, inputBytes)
 }
 
+func (w *conditionalStepWrapper) GetInterceptors() []StepInterceptor {
+	return StepInterceptors(w.step)
+}
+
+func (w *conditionalStepWrapper) GetLimiters() []string {
+	return StepLimiters(w.step)
+}
+
+func (w *conditionalStepWrapper) GetCircuitBreaker() string {
+	return StepCircuitBreaker(w.step)
+}
+
+func (w *conditionalStepWrapper) GetCompression() Compression {
+	return StepCompression(w.step)
+}
+
+func (w *conditionalStepWrapper) GetCachePolicy() *CachePolicy {
+	return StepCachePolicy(w.step)
+}
+
 func (w *conditionalStepWrapper) ValidateInput(data []byte) error {
 	return w.step.ValidateInput(data)
 }
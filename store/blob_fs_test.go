@@ -0,0 +1,132 @@
+package store
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/sicko7947/gorkflow"
+)
+
+func newTestFileBlobStore(t *testing.T) *FileBlobStore {
+	t.Helper()
+	s, err := NewFileBlobStore(filepath.Join(t.TempDir(), "blobs"))
+	if err != nil {
+		t.Fatalf("NewFileBlobStore() error = %v", err)
+	}
+	return s
+}
+
+func TestFileBlobStore_PutGet(t *testing.T) {
+	s := newTestFileBlobStore(t)
+	ctx := context.Background()
+
+	if err := s.PutBlob(ctx, "runs/r1/input", []byte("first")); err != nil {
+		t.Fatalf("PutBlob() error = %v", err)
+	}
+	if err := s.PutBlob(ctx, "runs/r1/input", []byte("second")); err != nil {
+		t.Fatalf("PutBlob() overwrite error = %v", err)
+	}
+
+	data, err := s.GetBlob(ctx, "runs/r1/input")
+	if err != nil {
+		t.Fatalf("GetBlob() error = %v", err)
+	}
+	if string(data) != "second" {
+		t.Errorf("GetBlob() = %q, want %q", data, "second")
+	}
+
+	entries, err := os.ReadDir(filepath.Join(s.dir, "runs", "r1"))
+	if err != nil {
+		t.Fatalf("ReadDir() error = %v", err)
+	}
+	if len(entries) != 1 {
+		t.Errorf("blob directory has %d entries, want 1 (no temporary files left)", len(entries))
+	}
+}
+
+func TestFileBlobStore_GetNotFound(t *testing.T) {
+	s := newTestFileBlobStore(t)
+
+	_, err := s.GetBlob(context.Background(), "runs/missing/input")
+	if !errors.Is(err, gorkflow.ErrBlobNotFound) {
+		t.Errorf("GetBlob() error = %v, want ErrBlobNotFound", err)
+	}
+}
+
+func TestFileBlobStore_Delete(t *testing.T) {
+	s := newTestFileBlobStore(t)
+	ctx := context.Background()
+
+	if err := s.PutBlob(ctx, "a", []byte("x")); err != nil {
+		t.Fatalf("PutBlob() error = %v", err)
+	}
+	if err := s.DeleteBlob(ctx, "a"); err != nil {
+		t.Fatalf("DeleteBlob() error = %v", err)
+	}
+	if err := s.DeleteBlob(ctx, "a"); err != nil {
+		t.Errorf("DeleteBlob() of a missing blob error = %v, want nil", err)
+	}
+	if _, err := s.GetBlob(ctx, "a"); !errors.Is(err, gorkflow.ErrBlobNotFound) {
+		t.Errorf("GetBlob() after delete error = %v, want ErrBlobNotFound", err)
+	}
+}
+
+func TestFileBlobStore_DeleteBlobs(t *testing.T) {
+	s := newTestFileBlobStore(t)
+	ctx := context.Background()
+
+	keys := []string{
+		"runs/r1/input",
+		"runs/r1/steps/a/output",
+		"runs/r10/input",
+		"runs/r2/input",
+		"runs/r2/state/big",
+		"runs/r2/state/bigger",
+		"runs/r2/state/other",
+	}
+	for _, key := range keys {
+		if err := s.PutBlob(ctx, key, []byte(key)); err != nil {
+			t.Fatalf("PutBlob(%s) error = %v", key, err)
+		}
+	}
+
+	if err := s.DeleteBlobs(ctx, "runs/r1/"); err != nil {
+		t.Fatalf("DeleteBlobs(dir) error = %v", err)
+	}
+	if err := s.DeleteBlobs(ctx, "runs/r2/state/big"); err != nil {
+		t.Fatalf("DeleteBlobs(name prefix) error = %v", err)
+	}
+	if err := s.DeleteBlobs(ctx, "runs/missing/"); err != nil {
+		t.Errorf("DeleteBlobs() of a missing prefix error = %v, want nil", err)
+	}
+
+	remaining := map[string]bool{
+		"runs/r1/input":          false,
+		"runs/r1/steps/a/output": false,
+		"runs/r10/input":         true,
+		"runs/r2/input":          true,
+		"runs/r2/state/big":      false,
+		"runs/r2/state/bigger":   false,
+		"runs/r2/state/other":    true,
+	}
+	for key, want := range remaining {
+		_, err := s.GetBlob(ctx, key)
+		if got := err == nil; got != want {
+			t.Errorf("blob %s exists = %v, want %v", key, got, want)
+		}
+	}
+}
+
+func TestFileBlobStore_InvalidKeys(t *testing.T) {
+	s := newTestFileBlobStore(t)
+	ctx := context.Background()
+
+	for _, key := range []string{"", "/etc/passwd", "../outside", "runs/../../outside", "runs//x"} {
+		if err := s.PutBlob(ctx, key, []byte("x")); err == nil {
+			t.Errorf("PutBlob(%q) succeeded, want error", key)
+		}
+	}
+}
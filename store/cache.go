@@ -0,0 +1,25 @@
+package store
+
+import (
+	"sync/atomic"
+	"time"
+)
+
+// cacheSweepInterval is the minimum time between deletions of expired step
+// cache entries by a store
+const cacheSweepInterval = time.Minute
+
+// cacheSweeper decides when a store deletes expired step cache entries, so
+// writes to the cache purge them without doing so on every write
+type cacheSweeper struct {
+	last atomic.Int64 // unix nanoseconds of the last sweep
+}
+
+// due reports whether a sweep should run at now and claims it if so
+func (c *cacheSweeper) due(now time.Time) bool {
+	last := c.last.Load()
+	if now.UnixNano()-last < int64(cacheSweepInterval) {
+		return false
+	}
+	return c.last.CompareAndSwap(last, now.UnixNano())
+}
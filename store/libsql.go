@@ -34,7 +34,8 @@ func DefaultLibSQLStoreOptions() LibSQLStoreOptions {
 
 // LibSQLStore implements WorkflowStore for LibSQL/SQLite
 type LibSQLStore struct {
-	db *sql.DB
+	db           *sql.DB
+	cacheSweeper cacheSweeper
 }
 
 // NewLibSQLStore creates a new LibSQL store with default options
@@ -210,12 +211,25 @@ func (s *LibSQLStore) ListRuns(ctx context.Context, filter workflow.RunFilter) (
 		queryBuilder.WriteString(" AND resource_id = ?")
 		args = append(args, filter.ResourceID)
 	}
+	if after := filter.After; after != nil {
+		// created_at holds the driver's text form of the time, which depends on
+		// its zone; compare against the cursor run's stored value so paging
+		// follows ORDER BY, falling back to the bound time if that run is gone
+		const cursor = "COALESCE((SELECT created_at FROM workflow_runs WHERE run_id = ?), ?)"
+		queryBuilder.WriteString(" AND (created_at < " + cursor + " OR (created_at = " + cursor + " AND run_id > ?))")
+		args = append(args, after.RunID, after.CreatedAt, after.RunID, after.CreatedAt, after.RunID)
+	}
 
-	queryBuilder.WriteString(" ORDER BY created_at DESC")
+	queryBuilder.WriteString(" ORDER BY created_at DESC, run_id")
 
-	if filter.Limit > 0 {
-		queryBuilder.WriteString(" LIMIT ?")
-		args = append(args, filter.Limit)
+	if filter.Limit > 0 || filter.Offset > 0 {
+		// SQLite only accepts OFFSET after LIMIT; -1 means no limit
+		limit := -1
+		if filter.Limit > 0 {
+			limit = filter.Limit
+		}
+		queryBuilder.WriteString(" LIMIT ? OFFSET ?")
+		args = append(args, limit, filter.Offset)
 	}
 
 	rows, err := s.db.QueryContext(ctx, queryBuilder.String(), args...)
@@ -468,3 +482,170 @@ func (s *LibSQLStore) GetAllState(ctx context.Context, runID string) (map[string
 	return state, nil
 }
 
+// --- Retention ---
+
+// libsqlRunTables are the tables holding per-run data, children first
+var libsqlRunTables = []string{TableStepExecutions, TableStepOutputs, TableWorkflowState}
+
+func (s *LibSQLStore) DeleteRun(ctx context.Context, runID string) error {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin delete transaction: %w", err)
+	}
+	defer tx.Rollback() //nolint:errcheck
+
+	for _, table := range libsqlRunTables {
+		if _, err := tx.ExecContext(ctx, "DELETE FROM "+table+" WHERE run_id = ?", runID); err != nil {
+			return fmt.Errorf("failed to delete %s: %w", table, err)
+		}
+	}
+	res, err := tx.ExecContext(ctx, `DELETE FROM workflow_runs WHERE run_id = ?`, runID)
+	if err != nil {
+		return fmt.Errorf("failed to delete run: %w", err)
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return workflow.ErrRunNotFound
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit delete: %w", err)
+	}
+	return nil
+}
+
+func (s *LibSQLStore) PurgeRuns(ctx context.Context, filter workflow.PurgeFilter) (int, error) {
+	var where strings.Builder
+	var args []any
+
+	// Column timestamps are stored in a driver-specific text format, so compare
+	// the RFC 3339 time kept in the run document instead
+	where.WriteString("julianday(json_extract(data, '$.updatedAt')) < julianday(?)")
+	args = append(args, filter.OlderThan.UTC().Format(time.RFC3339Nano))
+
+	statuses := filter.RunStatuses()
+	where.WriteString(" AND status IN (?" + strings.Repeat(", ?", len(statuses)-1) + ")")
+	for _, status := range statuses {
+		args = append(args, string(status))
+	}
+	if filter.WorkflowID != "" {
+		where.WriteString(" AND workflow_id = ?")
+		args = append(args, filter.WorkflowID)
+	}
+	if len(filter.ExcludeWorkflowIDs) > 0 {
+		where.WriteString(" AND workflow_id NOT IN (?" + strings.Repeat(", ?", len(filter.ExcludeWorkflowIDs)-1) + ")")
+		for _, id := range filter.ExcludeWorkflowIDs {
+			args = append(args, id)
+		}
+	}
+
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, fmt.Errorf("failed to begin purge transaction: %w", err)
+	}
+	defer tx.Rollback() //nolint:errcheck
+
+	for _, table := range libsqlRunTables {
+		query := "DELETE FROM " + table + " WHERE run_id IN (SELECT run_id FROM workflow_runs WHERE " + where.String() + ")"
+		if _, err := tx.ExecContext(ctx, query, args...); err != nil {
+			return 0, fmt.Errorf("failed to purge %s: %w", table, err)
+		}
+	}
+	res, err := tx.ExecContext(ctx, "DELETE FROM workflow_runs WHERE "+where.String(), args...)
+	if err != nil {
+		return 0, fmt.Errorf("failed to purge runs: %w", err)
+	}
+	purged, err := res.RowsAffected()
+	if err != nil {
+		return 0, fmt.Errorf("failed to purge runs: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return 0, fmt.Errorf("failed to commit purge: %w", err)
+	}
+	return int(purged), nil
+}
+
+// --- Resource Leases ---
+
+func (s *LibSQLStore) AcquireLease(ctx context.Context, resource, holder string, limit int, ttl time.Duration) (bool, error) {
+	now := time.Now()
+	expiresAt := now.Add(ttl).UnixNano()
+
+	if _, err := s.db.ExecContext(ctx,
+		`DELETE FROM resource_leases WHERE resource = ? AND expires_at <= ?`, resource, now.UnixNano(),
+	); err != nil {
+		return false, fmt.Errorf("failed to expire leases: %w", err)
+	}
+
+	// Extend the lease if the holder already has one
+	res, err := s.db.ExecContext(ctx,
+		`UPDATE resource_leases SET expires_at = ? WHERE resource = ? AND holder = ?`, expiresAt, resource, holder,
+	)
+	if err != nil {
+		return false, fmt.Errorf("failed to renew lease: %w", err)
+	}
+	if n, _ := res.RowsAffected(); n > 0 {
+		return true, nil
+	}
+
+	// Take a free slot; a single statement keeps the count and insert atomic
+	query := `
+		INSERT INTO resource_leases (resource, holder, expires_at)
+		SELECT ?, ?, ?
+		WHERE (SELECT COUNT(*) FROM resource_leases WHERE resource = ? AND expires_at > ?) < ?
+	`
+	res, err = s.db.ExecContext(ctx, query, resource, holder, expiresAt, resource, now.UnixNano(), limit)
+	if err != nil {
+		return false, fmt.Errorf("failed to acquire lease: %w", err)
+	}
+	n, err := res.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("failed to acquire lease: %w", err)
+	}
+	return n > 0, nil
+}
+
+func (s *LibSQLStore) ReleaseLease(ctx context.Context, resource, holder string) error {
+	query := `DELETE FROM resource_leases WHERE resource = ? AND holder = ?`
+	if _, err := s.db.ExecContext(ctx, query, resource, holder); err != nil {
+		return fmt.Errorf("failed to release lease: %w", err)
+	}
+	return nil
+}
+
+// --- Step Cache ---
+
+func (s *LibSQLStore) GetCachedOutput(ctx context.Context, key string) ([]byte, error) {
+	query := `SELECT output FROM step_cache WHERE cache_key = ? AND (expires_at IS NULL OR expires_at > ?)`
+	var output []byte
+	err := s.db.QueryRowContext(ctx, query, key, time.Now().UnixNano()).Scan(&output)
+	if err == sql.ErrNoRows {
+		return nil, workflow.ErrCacheMiss
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get cached output: %w", err)
+	}
+	return output, nil
+}
+
+func (s *LibSQLStore) PutCachedOutput(ctx context.Context, key string, output []byte, ttl time.Duration) error {
+	now := time.Now()
+	if s.cacheSweeper.due(now) {
+		if _, err := s.db.ExecContext(ctx, `DELETE FROM step_cache WHERE expires_at <= ?`, now.UnixNano()); err != nil {
+			return fmt.Errorf("failed to purge expired cached outputs: %w", err)
+		}
+	}
+
+	var expiresAt any
+	if ttl > 0 {
+		expiresAt = now.Add(ttl).UnixNano()
+	}
+	query := `
+		INSERT INTO step_cache (cache_key, output, expires_at) VALUES (?, ?, ?)
+		ON CONFLICT(cache_key) DO UPDATE SET output = excluded.output, expires_at = excluded.expires_at, created_at = CURRENT_TIMESTAMP
+	`
+	if _, err := s.db.ExecContext(ctx, query, key, output, expiresAt); err != nil {
+		return fmt.Errorf("failed to put cached output: %w", err)
+	}
+	return nil
+}
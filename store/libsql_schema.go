@@ -8,6 +8,8 @@ const (
 	TableStepExecutions = "step_executions"
 	TableStepOutputs    = "step_outputs"
 	TableWorkflowState  = "workflow_state"
+	TableResourceLeases = "resource_leases"
+	TableStepCache      = "step_cache"
 )
 
 // Schema definitions
@@ -63,6 +65,26 @@ CREATE TABLE IF NOT EXISTS workflow_state (
 	updated_at DATETIME DEFAULT CURRENT_TIMESTAMP,
 	PRIMARY KEY (run_id, key)
 );
+`
+
+	schemaResourceLeases = `
+CREATE TABLE IF NOT EXISTS resource_leases (
+	resource TEXT NOT NULL,
+	holder TEXT NOT NULL,
+	expires_at INTEGER NOT NULL,
+	PRIMARY KEY (resource, holder)
+);
+CREATE INDEX IF NOT EXISTS idx_resource_leases_expiry ON resource_leases(resource, expires_at);
+`
+
+	schemaStepCache = `
+CREATE TABLE IF NOT EXISTS step_cache (
+	cache_key TEXT PRIMARY KEY,
+	output BLOB,
+	created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+	expires_at INTEGER
+);
+CREATE INDEX IF NOT EXISTS idx_step_cache_expiry ON step_cache(expires_at);
 `
 )
 
@@ -73,5 +95,7 @@ func GetLibSQLSchema() string {
 		schemaStepExecutions,
 		schemaStepOutputs,
 		schemaWorkflowState,
+		schemaResourceLeases,
+		schemaStepCache,
 	}, "\n")
 }
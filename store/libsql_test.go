@@ -111,6 +111,45 @@ func TestLibSQL_ListRuns_WithFilter(t *testing.T) {
 	all, err := s.ListRuns(ctx, workflow.RunFilter{})
 	require.NoError(t, err)
 	assert.Len(t, all, 3)
+
+	// Pages follow the order of the full listing
+	page, err := s.ListRuns(ctx, workflow.RunFilter{Limit: 2, Offset: 1})
+	require.NoError(t, err)
+	require.Len(t, page, 2)
+	assert.Equal(t, all[1].RunID, page[0].RunID)
+	assert.Equal(t, all[2].RunID, page[1].RunID)
+
+	rest, err := s.ListRuns(ctx, workflow.RunFilter{Offset: 2})
+	require.NoError(t, err)
+	require.Len(t, rest, 1)
+	assert.Equal(t, all[2].RunID, rest[0].RunID)
+}
+
+func TestLibSQL_ListRuns_After(t *testing.T) {
+	s := newTestLibSQLStore(t)
+	ctx := context.Background()
+
+	base := time.Now()
+	for _, r := range []struct {
+		id  string
+		age time.Duration
+	}{{"d", 2 * time.Second}, {"c", time.Second}, {"b", time.Second}, {"a", 0}} {
+		created := base.Add(-r.age)
+		require.NoError(t, s.CreateRun(ctx, &workflow.WorkflowRun{
+			RunID: r.id, WorkflowID: "wf", Status: workflow.RunStatusCompleted, CreatedAt: created, UpdatedAt: created,
+		}))
+	}
+
+	first, err := s.ListRuns(ctx, workflow.RunFilter{Limit: 2})
+	require.NoError(t, err)
+	require.Len(t, first, 2)
+	assert.Equal(t, []string{"a", "b"}, []string{first[0].RunID, first[1].RunID})
+
+	last := first[1]
+	rest, err := s.ListRuns(ctx, workflow.RunFilter{After: &workflow.RunCursor{CreatedAt: last.CreatedAt, RunID: last.RunID}})
+	require.NoError(t, err)
+	require.Len(t, rest, 2)
+	assert.Equal(t, []string{"c", "d"}, []string{rest[0].RunID, rest[1].RunID})
 }
 
 func TestLibSQL_StepExecution_FullLifecycle(t *testing.T) {
@@ -343,3 +382,133 @@ func TestLibSQLStore(t *testing.T) {
 		assert.Error(t, err)
 	})
 }
+
+func TestLibSQL_Leases(t *testing.T) {
+	s := newTestLibSQLStore(t)
+	ctx := context.Background()
+
+	for _, holder := range []string{"a", "b"} {
+		acquired, err := s.AcquireLease(ctx, "vendor", holder, 2, time.Minute)
+		require.NoError(t, err)
+		assert.True(t, acquired, holder)
+	}
+
+	acquired, err := s.AcquireLease(ctx, "vendor", "c", 2, time.Minute)
+	require.NoError(t, err)
+	assert.False(t, acquired, "pool should be full")
+
+	// Renewal by an existing holder succeeds on a full pool
+	acquired, err = s.AcquireLease(ctx, "vendor", "a", 2, time.Minute)
+	require.NoError(t, err)
+	assert.True(t, acquired)
+
+	// Other resources are independent
+	acquired, err = s.AcquireLease(ctx, "other", "c", 1, time.Minute)
+	require.NoError(t, err)
+	assert.True(t, acquired)
+
+	require.NoError(t, s.ReleaseLease(ctx, "vendor", "a"))
+	acquired, err = s.AcquireLease(ctx, "vendor", "c", 2, time.Minute)
+	require.NoError(t, err)
+	assert.True(t, acquired)
+}
+
+func TestLibSQL_Leases_Expire(t *testing.T) {
+	s := newTestLibSQLStore(t)
+	ctx := context.Background()
+
+	acquired, err := s.AcquireLease(ctx, "vendor", "a", 1, 10*time.Millisecond)
+	require.NoError(t, err)
+	require.True(t, acquired)
+
+	time.Sleep(20 * time.Millisecond)
+	acquired, err = s.AcquireLease(ctx, "vendor", "b", 1, time.Minute)
+	require.NoError(t, err)
+	assert.True(t, acquired)
+}
+
+func TestLibSQL_DeleteRun(t *testing.T) {
+	s := newTestLibSQLStore(t)
+	ctx := context.Background()
+
+	now := time.Now()
+	require.NoError(t, s.CreateRun(ctx, &workflow.WorkflowRun{
+		RunID: "run-1", WorkflowID: "wf", Status: workflow.RunStatusCompleted, CreatedAt: now, UpdatedAt: now,
+	}))
+	require.NoError(t, s.CreateStepExecution(ctx, &workflow.StepExecution{RunID: "run-1", StepID: "step-1", Status: workflow.StepStatusCompleted}))
+	require.NoError(t, s.SaveStepOutput(ctx, "run-1", "step-1", []byte("output")))
+	require.NoError(t, s.SaveState(ctx, "run-1", "key", []byte("value")))
+
+	require.NoError(t, s.DeleteRun(ctx, "run-1"))
+
+	_, err := s.GetRun(ctx, "run-1")
+	assert.ErrorIs(t, err, workflow.ErrRunNotFound)
+	_, err = s.GetStepExecution(ctx, "run-1", "step-1")
+	assert.ErrorIs(t, err, workflow.ErrStepExecutionNotFound)
+	_, err = s.LoadStepOutput(ctx, "run-1", "step-1")
+	assert.ErrorIs(t, err, workflow.ErrStepOutputNotFound)
+	_, err = s.LoadState(ctx, "run-1", "key")
+	assert.ErrorIs(t, err, workflow.ErrStateNotFound)
+
+	assert.ErrorIs(t, s.DeleteRun(ctx, "run-1"), workflow.ErrRunNotFound)
+}
+
+func TestLibSQL_PurgeRuns(t *testing.T) {
+	s := newTestLibSQLStore(t)
+	ctx := context.Background()
+
+	old := time.Now().Add(-2 * time.Hour)
+	for _, run := range []*workflow.WorkflowRun{
+		{RunID: "old-completed", WorkflowID: "wf", Status: workflow.RunStatusCompleted, CreatedAt: old, UpdatedAt: old},
+		{RunID: "old-running", WorkflowID: "wf", Status: workflow.RunStatusRunning, CreatedAt: old, UpdatedAt: old},
+		{RunID: "recent-failed", WorkflowID: "wf", Status: workflow.RunStatusFailed, CreatedAt: old, UpdatedAt: time.Now()},
+		{RunID: "old-other", WorkflowID: "other", Status: workflow.RunStatusCancelled, CreatedAt: old, UpdatedAt: old},
+	} {
+		require.NoError(t, s.CreateRun(ctx, run))
+	}
+	require.NoError(t, s.SaveStepOutput(ctx, "old-completed", "step-1", []byte("output")))
+	require.NoError(t, s.SaveState(ctx, "old-completed", "key", []byte("value")))
+
+	cutoff := time.Now().Add(-time.Hour)
+	purged, err := s.PurgeRuns(ctx, workflow.PurgeFilter{OlderThan: cutoff, ExcludeWorkflowIDs: []string{"other"}})
+	require.NoError(t, err)
+	assert.Equal(t, 1, purged)
+
+	_, err = s.LoadStepOutput(ctx, "old-completed", "step-1")
+	assert.ErrorIs(t, err, workflow.ErrStepOutputNotFound)
+	_, err = s.LoadState(ctx, "old-completed", "key")
+	assert.ErrorIs(t, err, workflow.ErrStateNotFound)
+
+	purged, err = s.PurgeRuns(ctx, workflow.PurgeFilter{OlderThan: cutoff, WorkflowID: "other"})
+	require.NoError(t, err)
+	assert.Equal(t, 1, purged)
+
+	// Explicit statuses include non-terminal runs
+	purged, err = s.PurgeRuns(ctx, workflow.PurgeFilter{OlderThan: cutoff, Statuses: []workflow.RunStatus{workflow.RunStatusRunning}})
+	require.NoError(t, err)
+	assert.Equal(t, 1, purged)
+
+	remaining, err := s.ListRuns(ctx, workflow.RunFilter{})
+	require.NoError(t, err)
+	require.Len(t, remaining, 1)
+	assert.Equal(t, "recent-failed", remaining[0].RunID)
+}
+
+func TestLibSQL_StepCache(t *testing.T) {
+	s := newTestLibSQLStore(t)
+	ctx := context.Background()
+
+	_, err := s.GetCachedOutput(ctx, "step:key")
+	assert.ErrorIs(t, err, workflow.ErrCacheMiss)
+
+	require.NoError(t, s.PutCachedOutput(ctx, "step:key", []byte(`{"v":1}`), 0))
+	output, err := s.GetCachedOutput(ctx, "step:key")
+	require.NoError(t, err)
+	assert.Equal(t, []byte(`{"v":1}`), output)
+
+	// Overwrite with a TTL that has already passed
+	require.NoError(t, s.PutCachedOutput(ctx, "step:key", []byte(`{"v":2}`), time.Nanosecond))
+	time.Sleep(time.Millisecond)
+	_, err = s.GetCachedOutput(ctx, "step:key")
+	assert.ErrorIs(t, err, workflow.ErrCacheMiss)
+}
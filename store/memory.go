@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"sort"
 	"sync"
+	"time"
 
 	"github.com/sicko7947/gorkflow"
 )
@@ -15,16 +16,43 @@ type MemoryStore struct {
 	stepExecutions map[string]map[string]*gorkflow.StepExecution // runID -> stepID -> execution
 	stepOutputs    map[string]map[string][]byte                  // runID -> stepID -> output
 	state          map[string]map[string][]byte                  // runID -> key -> value
+	leases         map[string]map[string]time.Time               // resource -> holder -> expiry
+	cache          map[string]cacheEntry                         // cache key -> cached step output
+	cacheSweeper   cacheSweeper
+	clock          gorkflow.Clock
 	mu             sync.RWMutex
 }
 
+// cacheEntry is a cached step output; a zero expiresAt never expires
+type cacheEntry struct {
+	output    []byte
+	expiresAt time.Time
+}
+
+// MemoryStoreOptions configures the in-memory store
+type MemoryStoreOptions struct {
+	// Clock decides when leases and cached outputs expire (default gorkflow.SystemClock)
+	Clock gorkflow.Clock
+}
+
 // NewMemoryStore creates a new in-memory workflow store
 func NewMemoryStore() gorkflow.WorkflowStore {
+	return NewMemoryStoreWithOptions(MemoryStoreOptions{})
+}
+
+// NewMemoryStoreWithOptions creates a new in-memory workflow store with custom options
+func NewMemoryStoreWithOptions(opts MemoryStoreOptions) gorkflow.WorkflowStore {
+	if opts.Clock == nil {
+		opts.Clock = gorkflow.SystemClock
+	}
 	return &MemoryStore{
 		runs:           make(map[string]*gorkflow.WorkflowRun),
 		stepExecutions: make(map[string]map[string]*gorkflow.StepExecution),
 		stepOutputs:    make(map[string]map[string][]byte),
 		state:          make(map[string]map[string][]byte),
+		leases:         make(map[string]map[string]time.Time),
+		cache:          make(map[string]cacheEntry),
+		clock:          opts.Clock,
 	}
 }
 
@@ -43,6 +71,14 @@ func deepCopyRun(run *gorkflow.WorkflowRun) *gorkflow.WorkflowRun {
 		}
 	}
 
+	// Deep copy TraceContext map
+	if run.TraceContext != nil {
+		runCopy.TraceContext = make(map[string]string, len(run.TraceContext))
+		for k, v := range run.TraceContext {
+			runCopy.TraceContext[k] = v
+		}
+	}
+
 	// Deep copy Input/Output/Context (json.RawMessage is []byte)
 	if run.Input != nil {
 		runCopy.Input = make([]byte, len(run.Input))
@@ -160,16 +196,29 @@ func (s *MemoryStore) ListRuns(ctx context.Context, filter gorkflow.RunFilter) (
 		if filter.ResourceID != "" && run.ResourceID != filter.ResourceID {
 			continue
 		}
+		if after := filter.After; after != nil && !(run.CreatedAt.Before(after.CreatedAt) ||
+			run.CreatedAt.Equal(after.CreatedAt) && run.RunID > after.RunID) {
+			continue
+		}
 
 		runs = append(runs, deepCopyRun(run))
 	}
 
 	// Sort by created_at DESC to match LibSQL behavior
 	sort.Slice(runs, func(i, j int) bool {
-		return runs[i].CreatedAt.After(runs[j].CreatedAt)
+		if !runs[i].CreatedAt.Equal(runs[j].CreatedAt) {
+			return runs[i].CreatedAt.After(runs[j].CreatedAt)
+		}
+		return runs[i].RunID < runs[j].RunID
 	})
 
-	// Apply limit after sorting
+	// Apply offset and limit after sorting
+	if filter.Offset > 0 {
+		if filter.Offset >= len(runs) {
+			return []*gorkflow.WorkflowRun{}, nil
+		}
+		runs = runs[filter.Offset:]
+	}
 	if filter.Limit > 0 && len(runs) > filter.Limit {
 		runs = runs[:filter.Limit]
 	}
@@ -351,3 +400,113 @@ func (s *MemoryStore) GetAllState(ctx context.Context, runID string) (map[string
 	return stateCopy, nil
 }
 
+// Retention operations
+
+func (s *MemoryStore) DeleteRun(ctx context.Context, runID string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, exists := s.runs[runID]; !exists {
+		return gorkflow.ErrRunNotFound
+	}
+	s.deleteRunLocked(runID)
+	return nil
+}
+
+func (s *MemoryStore) PurgeRuns(ctx context.Context, filter gorkflow.PurgeFilter) (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	purged := 0
+	for runID, run := range s.runs {
+		if filter.Matches(run) {
+			s.deleteRunLocked(runID)
+			purged++
+		}
+	}
+	return purged, nil
+}
+
+// deleteRunLocked removes a run and everything stored for it; s.mu must be held
+func (s *MemoryStore) deleteRunLocked(runID string) {
+	delete(s.runs, runID)
+	delete(s.stepExecutions, runID)
+	delete(s.stepOutputs, runID)
+	delete(s.state, runID)
+}
+
+// Lease operations
+
+func (s *MemoryStore) AcquireLease(ctx context.Context, resource, holder string, limit int, ttl time.Duration) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := s.clock.Now()
+	holders, exists := s.leases[resource]
+	if !exists {
+		holders = make(map[string]time.Time)
+		s.leases[resource] = holders
+	}
+
+	// Drop expired leases
+	for h, expiresAt := range holders {
+		if !expiresAt.After(now) {
+			delete(holders, h)
+		}
+	}
+
+	if _, held := holders[holder]; !held && len(holders) >= limit {
+		return false, nil
+	}
+
+	holders[holder] = now.Add(ttl)
+	return true, nil
+}
+
+func (s *MemoryStore) ReleaseLease(ctx context.Context, resource, holder string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if holders, exists := s.leases[resource]; exists {
+		delete(holders, holder)
+	}
+	return nil
+}
+
+// Step cache operations
+
+func (s *MemoryStore) GetCachedOutput(ctx context.Context, key string) ([]byte, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	entry, exists := s.cache[key]
+	if !exists || (!entry.expiresAt.IsZero() && !entry.expiresAt.After(s.clock.Now())) {
+		return nil, gorkflow.ErrCacheMiss
+	}
+
+	output := make([]byte, len(entry.output))
+	copy(output, entry.output)
+	return output, nil
+}
+
+func (s *MemoryStore) PutCachedOutput(ctx context.Context, key string, output []byte, ttl time.Duration) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := s.clock.Now()
+	if s.cacheSweeper.due(now) {
+		for k, e := range s.cache {
+			if !e.expiresAt.IsZero() && !e.expiresAt.After(now) {
+				delete(s.cache, k)
+			}
+		}
+	}
+
+	entry := cacheEntry{output: make([]byte, len(output))}
+	copy(entry.output, output)
+	if ttl > 0 {
+		entry.expiresAt = now.Add(ttl)
+	}
+	s.cache[key] = entry
+	return nil
+}
@@ -205,6 +205,21 @@ func TestMemoryStore_ListRuns(t *testing.T) {
 			},
 			want: 2,
 		},
+		{
+			name: "filter with offset",
+			filter: gorkflow.RunFilter{
+				Limit:  2,
+				Offset: 2,
+			},
+			want: 1,
+		},
+		{
+			name: "offset past the end",
+			filter: gorkflow.RunFilter{
+				Offset: 5,
+			},
+			want: 0,
+		},
 	}
 
 	for _, tt := range tests {
@@ -221,6 +236,31 @@ func TestMemoryStore_ListRuns(t *testing.T) {
 	}
 }
 
+func TestMemoryStore_ListRuns_After(t *testing.T) {
+	store := NewMemoryStore()
+	ctx := context.Background()
+
+	base := time.Now()
+	for _, r := range []struct {
+		id  string
+		age time.Duration
+	}{{"d", 2 * time.Second}, {"c", time.Second}, {"b", time.Second}, {"a", 0}} {
+		created := base.Add(-r.age)
+		if err := store.CreateRun(ctx, &gorkflow.WorkflowRun{RunID: r.id, WorkflowID: "wf", CreatedAt: created, UpdatedAt: created}); err != nil {
+			t.Fatalf("CreateRun() failed: %v", err)
+		}
+	}
+
+	cursor := &gorkflow.RunCursor{CreatedAt: base.Add(-time.Second), RunID: "b"}
+	runs, err := store.ListRuns(ctx, gorkflow.RunFilter{After: cursor})
+	if err != nil {
+		t.Fatalf("ListRuns() failed: %v", err)
+	}
+	if len(runs) != 2 || runs[0].RunID != "c" || runs[1].RunID != "d" {
+		t.Errorf("ListRuns() after b returned %d runs, want c and d", len(runs))
+	}
+}
+
 func TestMemoryStore_CreateStepExecution(t *testing.T) {
 	store := NewMemoryStore()
 	ctx := context.Background()
@@ -590,3 +630,208 @@ func TestMemoryStore_ThreadSafety(t *testing.T) {
 		<-done
 	}
 }
+
+func TestMemoryStore_Leases(t *testing.T) {
+	store, ok := NewMemoryStore().(gorkflow.LeaseStore)
+	if !ok {
+		t.Fatal("MemoryStore does not implement LeaseStore")
+	}
+	ctx := context.Background()
+
+	for _, holder := range []string{"a", "b"} {
+		acquired, err := store.AcquireLease(ctx, "vendor", holder, 2, time.Minute)
+		if err != nil || !acquired {
+			t.Fatalf("AcquireLease(%s) = %v, %v; want true", holder, acquired, err)
+		}
+	}
+
+	acquired, err := store.AcquireLease(ctx, "vendor", "c", 2, time.Minute)
+	if err != nil || acquired {
+		t.Fatalf("AcquireLease(c) on full pool = %v, %v; want false", acquired, err)
+	}
+
+	// Existing holders can renew even when the pool is full
+	acquired, err = store.AcquireLease(ctx, "vendor", "a", 2, time.Minute)
+	if err != nil || !acquired {
+		t.Fatalf("renewing lease = %v, %v; want true", acquired, err)
+	}
+
+	if err := store.ReleaseLease(ctx, "vendor", "a"); err != nil {
+		t.Fatalf("ReleaseLease() error = %v", err)
+	}
+	acquired, err = store.AcquireLease(ctx, "vendor", "c", 2, time.Minute)
+	if err != nil || !acquired {
+		t.Fatalf("AcquireLease(c) after release = %v, %v; want true", acquired, err)
+	}
+}
+
+func TestMemoryStore_Leases_Expire(t *testing.T) {
+	store := NewMemoryStore().(gorkflow.LeaseStore)
+	ctx := context.Background()
+
+	if acquired, _ := store.AcquireLease(ctx, "vendor", "a", 1, 10*time.Millisecond); !acquired {
+		t.Fatal("expected first lease to be acquired")
+	}
+	if acquired, _ := store.AcquireLease(ctx, "vendor", "b", 1, time.Minute); acquired {
+		t.Fatal("expected pool to be full")
+	}
+
+	time.Sleep(20 * time.Millisecond)
+	if acquired, _ := store.AcquireLease(ctx, "vendor", "b", 1, time.Minute); !acquired {
+		t.Fatal("expected expired lease to free its slot")
+	}
+}
+
+func TestMemoryStore_DeleteRun(t *testing.T) {
+	store := NewMemoryStore()
+	ctx := context.Background()
+
+	run := &gorkflow.WorkflowRun{
+		RunID:      "test-run-1",
+		WorkflowID: "test-workflow",
+		Status:     gorkflow.RunStatusCompleted,
+		CreatedAt:  time.Now(),
+		UpdatedAt:  time.Now(),
+	}
+	if err := store.CreateRun(ctx, run); err != nil {
+		t.Fatalf("CreateRun() failed: %v", err)
+	}
+	store.CreateStepExecution(ctx, &gorkflow.StepExecution{RunID: run.RunID, StepID: "step-1"})
+	store.SaveStepOutput(ctx, run.RunID, "step-1", []byte("output"))
+	store.SaveState(ctx, run.RunID, "key", []byte("value"))
+
+	if err := store.DeleteRun(ctx, run.RunID); err != nil {
+		t.Fatalf("DeleteRun() failed: %v", err)
+	}
+
+	if _, err := store.GetRun(ctx, run.RunID); err != gorkflow.ErrRunNotFound {
+		t.Errorf("GetRun() error = %v, want ErrRunNotFound", err)
+	}
+	if _, err := store.GetStepExecution(ctx, run.RunID, "step-1"); err == nil {
+		t.Error("step execution should be deleted with the run")
+	}
+	if _, err := store.LoadStepOutput(ctx, run.RunID, "step-1"); err == nil {
+		t.Error("step output should be deleted with the run")
+	}
+	if _, err := store.LoadState(ctx, run.RunID, "key"); err == nil {
+		t.Error("state should be deleted with the run")
+	}
+
+	if err := store.DeleteRun(ctx, run.RunID); err != gorkflow.ErrRunNotFound {
+		t.Errorf("DeleteRun() on missing run error = %v, want ErrRunNotFound", err)
+	}
+}
+
+func TestMemoryStore_PurgeRuns(t *testing.T) {
+	store := NewMemoryStore()
+	ctx := context.Background()
+
+	old := time.Now().Add(-2 * time.Hour)
+	runs := []*gorkflow.WorkflowRun{
+		{RunID: "old-completed", WorkflowID: "wf", Status: gorkflow.RunStatusCompleted, UpdatedAt: old},
+		{RunID: "old-running", WorkflowID: "wf", Status: gorkflow.RunStatusRunning, UpdatedAt: old},
+		{RunID: "recent-failed", WorkflowID: "wf", Status: gorkflow.RunStatusFailed, UpdatedAt: time.Now()},
+		{RunID: "old-other", WorkflowID: "other", Status: gorkflow.RunStatusCancelled, UpdatedAt: old},
+	}
+	for _, run := range runs {
+		if err := store.CreateRun(ctx, run); err != nil {
+			t.Fatalf("CreateRun() failed: %v", err)
+		}
+	}
+
+	cutoff := time.Now().Add(-time.Hour)
+	purged, err := store.PurgeRuns(ctx, gorkflow.PurgeFilter{OlderThan: cutoff, ExcludeWorkflowIDs: []string{"other"}})
+	if err != nil {
+		t.Fatalf("PurgeRuns() failed: %v", err)
+	}
+	if purged != 1 {
+		t.Errorf("PurgeRuns() = %d, want 1", purged)
+	}
+	if _, err := store.GetRun(ctx, "old-completed"); err != gorkflow.ErrRunNotFound {
+		t.Error("old terminal run should be purged")
+	}
+
+	purged, err = store.PurgeRuns(ctx, gorkflow.PurgeFilter{OlderThan: cutoff, WorkflowID: "other"})
+	if err != nil {
+		t.Fatalf("PurgeRuns() failed: %v", err)
+	}
+	if purged != 1 {
+		t.Errorf("PurgeRuns(WorkflowID) = %d, want 1", purged)
+	}
+
+	remaining, _ := store.ListRuns(ctx, gorkflow.RunFilter{})
+	if len(remaining) != 2 {
+		t.Errorf("remaining runs = %d, want 2 (running and recent)", len(remaining))
+	}
+}
+
+func TestMemoryStore_StepCache(t *testing.T) {
+	store, ok := NewMemoryStore().(gorkflow.StepCache)
+	if !ok {
+		t.Fatal("MemoryStore should implement StepCache")
+	}
+	ctx := context.Background()
+
+	if _, err := store.GetCachedOutput(ctx, "step:key"); err != gorkflow.ErrCacheMiss {
+		t.Fatalf("GetCachedOutput() error = %v, want ErrCacheMiss", err)
+	}
+
+	if err := store.PutCachedOutput(ctx, "step:key", []byte(`{"v":1}`), 0); err != nil {
+		t.Fatalf("PutCachedOutput() failed: %v", err)
+	}
+	output, err := store.GetCachedOutput(ctx, "step:key")
+	if err != nil {
+		t.Fatalf("GetCachedOutput() failed: %v", err)
+	}
+	if string(output) != `{"v":1}` {
+		t.Errorf("GetCachedOutput() = %s, want {\"v\":1}", output)
+	}
+
+	if err := store.PutCachedOutput(ctx, "step:short", []byte("x"), 10*time.Millisecond); err != nil {
+		t.Fatalf("PutCachedOutput() failed: %v", err)
+	}
+	time.Sleep(20 * time.Millisecond)
+	if _, err := store.GetCachedOutput(ctx, "step:short"); err != gorkflow.ErrCacheMiss {
+		t.Errorf("expired entry: error = %v, want ErrCacheMiss", err)
+	}
+}
+
+// steppedClock is a Clock whose Now only moves when the test advances it
+type steppedClock struct {
+	gorkflow.Clock
+	now time.Time
+}
+
+func (c *steppedClock) Now() time.Time { return c.now }
+
+func (c *steppedClock) Since(t time.Time) time.Duration { return c.now.Sub(t) }
+
+func TestMemoryStore_StepCache_PurgesExpired(t *testing.T) {
+	clock := &steppedClock{Clock: gorkflow.SystemClock, now: time.Unix(1_700_000_000, 0)}
+	ms := NewMemoryStoreWithOptions(MemoryStoreOptions{Clock: clock}).(*MemoryStore)
+	ctx := context.Background()
+
+	if err := ms.PutCachedOutput(ctx, "step:short", []byte("x"), time.Second); err != nil {
+		t.Fatalf("PutCachedOutput() failed: %v", err)
+	}
+	if err := ms.PutCachedOutput(ctx, "step:forever", []byte("y"), 0); err != nil {
+		t.Fatalf("PutCachedOutput() failed: %v", err)
+	}
+
+	clock.now = clock.now.Add(cacheSweepInterval + time.Second)
+	if err := ms.PutCachedOutput(ctx, "step:new", []byte("z"), time.Hour); err != nil {
+		t.Fatalf("PutCachedOutput() failed: %v", err)
+	}
+
+	ms.mu.RLock()
+	defer ms.mu.RUnlock()
+	if _, ok := ms.cache["step:short"]; ok {
+		t.Error("expired entry should be purged when a new output is written")
+	}
+	if _, ok := ms.cache["step:forever"]; !ok {
+		t.Error("entries without a TTL should not be purged")
+	}
+	if _, ok := ms.cache["step:new"]; !ok {
+		t.Error("new entry should be stored")
+	}
+}
@@ -13,8 +13,11 @@ import (
 	workflow "github.com/sicko7947/gorkflow"
 )
 
-// Compile-time interface compliance check.
-var _ workflow.WorkflowStore = (*PostgresStore)(nil)
+// Compile-time interface compliance checks.
+var (
+	_ workflow.WorkflowStore = (*PostgresStore)(nil)
+	_ workflow.LeaseStore    = (*PostgresStore)(nil)
+)
 
 // PostgresStoreOptions configures the PostgreSQL store.
 type PostgresStoreOptions struct {
@@ -36,7 +39,8 @@ func DefaultPostgresStoreOptions() PostgresStoreOptions {
 
 // PostgresStore implements WorkflowStore for PostgreSQL.
 type PostgresStore struct {
-	pool *pgxpool.Pool
+	pool         *pgxpool.Pool
+	cacheSweeper cacheSweeper
 }
 
 // NewPostgresStore creates a new PostgreSQL store with default options.
@@ -196,13 +200,21 @@ func (s *PostgresStore) ListRuns(ctx context.Context, filter workflow.RunFilter)
 		args = append(args, filter.ResourceID)
 		fmt.Fprintf(&sb, " AND resource_id = $%d", len(args))
 	}
+	if after := filter.After; after != nil {
+		args = append(args, after.CreatedAt, after.RunID)
+		fmt.Fprintf(&sb, " AND (created_at < $%[1]d OR (created_at = $%[1]d AND run_id > $%[2]d))", len(args)-1, len(args))
+	}
 
-	sb.WriteString(" ORDER BY created_at DESC")
+	sb.WriteString(" ORDER BY created_at DESC, run_id")
 
 	if filter.Limit > 0 {
 		args = append(args, filter.Limit)
 		fmt.Fprintf(&sb, " LIMIT $%d", len(args))
 	}
+	if filter.Offset > 0 {
+		args = append(args, filter.Offset)
+		fmt.Fprintf(&sb, " OFFSET $%d", len(args))
+	}
 
 	rows, err := s.pool.Query(ctx, sb.String(), args...)
 	if err != nil {
@@ -432,3 +444,148 @@ func (s *PostgresStore) GetAllState(ctx context.Context, runID string) (map[stri
 	}
 	return state, nil
 }
+
+// --- Retention ---
+
+// DeleteRun removes a run; step executions, outputs and state cascade
+func (s *PostgresStore) DeleteRun(ctx context.Context, runID string) error {
+	tag, err := s.pool.Exec(ctx, `DELETE FROM workflow_runs WHERE run_id = $1`, runID)
+	if err != nil {
+		return fmt.Errorf("failed to delete run: %w", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return workflow.ErrRunNotFound
+	}
+	return nil
+}
+
+func (s *PostgresStore) PurgeRuns(ctx context.Context, filter workflow.PurgeFilter) (int, error) {
+	var sb strings.Builder
+	args := make([]any, 0, 4)
+
+	statuses := make([]string, 0, len(filter.RunStatuses()))
+	for _, status := range filter.RunStatuses() {
+		statuses = append(statuses, string(status))
+	}
+	args = append(args, filter.OlderThan.UTC(), statuses)
+	sb.WriteString("DELETE FROM workflow_runs WHERE updated_at < $1 AND status = ANY($2)")
+
+	if filter.WorkflowID != "" {
+		args = append(args, filter.WorkflowID)
+		fmt.Fprintf(&sb, " AND workflow_id = $%d", len(args))
+	}
+	if len(filter.ExcludeWorkflowIDs) > 0 {
+		args = append(args, filter.ExcludeWorkflowIDs)
+		fmt.Fprintf(&sb, " AND NOT (workflow_id = ANY($%d))", len(args))
+	}
+
+	tag, err := s.pool.Exec(ctx, sb.String(), args...)
+	if err != nil {
+		return 0, fmt.Errorf("failed to purge runs: %w", err)
+	}
+	return int(tag.RowsAffected()), nil
+}
+
+// --- Resource Leases ---
+
+func (s *PostgresStore) AcquireLease(ctx context.Context, resource, holder string, limit int, ttl time.Duration) (bool, error) {
+	tx, err := s.pool.Begin(ctx)
+	if err != nil {
+		return false, fmt.Errorf("failed to begin lease transaction: %w", err)
+	}
+	defer tx.Rollback(ctx) //nolint:errcheck
+
+	// Serialize acquisitions per resource so the count below cannot race
+	if _, err := tx.Exec(ctx, `SELECT pg_advisory_xact_lock(hashtext($1))`, resource); err != nil {
+		return false, fmt.Errorf("failed to lock resource: %w", err)
+	}
+
+	now := time.Now().UTC()
+	expiresAt := now.Add(ttl)
+
+	if _, err := tx.Exec(ctx,
+		`DELETE FROM resource_leases WHERE resource = $1 AND expires_at <= $2`, resource, now,
+	); err != nil {
+		return false, fmt.Errorf("failed to expire leases: %w", err)
+	}
+
+	var held int
+	if err := tx.QueryRow(ctx,
+		`SELECT COUNT(*) FROM resource_leases WHERE resource = $1 AND holder <> $2`, resource, holder,
+	).Scan(&held); err != nil {
+		return false, fmt.Errorf("failed to count leases: %w", err)
+	}
+
+	var exists bool
+	if err := tx.QueryRow(ctx,
+		`SELECT EXISTS (SELECT 1 FROM resource_leases WHERE resource = $1 AND holder = $2)`, resource, holder,
+	).Scan(&exists); err != nil {
+		return false, fmt.Errorf("failed to check lease: %w", err)
+	}
+	if !exists && held >= limit {
+		return false, nil
+	}
+
+	if _, err := tx.Exec(ctx, `
+		INSERT INTO resource_leases (resource, holder, expires_at)
+		VALUES ($1, $2, $3)
+		ON CONFLICT (resource, holder) DO UPDATE SET expires_at = EXCLUDED.expires_at`,
+		resource, holder, expiresAt,
+	); err != nil {
+		return false, fmt.Errorf("failed to acquire lease: %w", err)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return false, fmt.Errorf("failed to commit lease: %w", err)
+	}
+	return true, nil
+}
+
+func (s *PostgresStore) ReleaseLease(ctx context.Context, resource, holder string) error {
+	_, err := s.pool.Exec(ctx,
+		`DELETE FROM resource_leases WHERE resource = $1 AND holder = $2`, resource, holder,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to release lease: %w", err)
+	}
+	return nil
+}
+
+// --- Step Cache ---
+
+func (s *PostgresStore) GetCachedOutput(ctx context.Context, key string) ([]byte, error) {
+	var output []byte
+	err := s.pool.QueryRow(ctx,
+		`SELECT output FROM step_cache WHERE cache_key = $1 AND (expires_at IS NULL OR expires_at > NOW())`, key,
+	).Scan(&output)
+	if err == pgx.ErrNoRows {
+		return nil, workflow.ErrCacheMiss
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get cached output: %w", err)
+	}
+	return output, nil
+}
+
+func (s *PostgresStore) PutCachedOutput(ctx context.Context, key string, output []byte, ttl time.Duration) error {
+	if s.cacheSweeper.due(time.Now()) {
+		if _, err := s.pool.Exec(ctx, `DELETE FROM step_cache WHERE expires_at <= NOW()`); err != nil {
+			return fmt.Errorf("failed to purge expired cached outputs: %w", err)
+		}
+	}
+
+	var expiresAt *time.Time
+	if ttl > 0 {
+		t := time.Now().UTC().Add(ttl)
+		expiresAt = &t
+	}
+	_, err := s.pool.Exec(ctx, `
+		INSERT INTO step_cache (cache_key, output, expires_at) VALUES ($1, $2, $3)
+		ON CONFLICT (cache_key) DO UPDATE SET output = EXCLUDED.output, expires_at = EXCLUDED.expires_at, created_at = NOW()`,
+		key, output, expiresAt,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to put cached output: %w", err)
+	}
+	return nil
+}
@@ -55,6 +55,26 @@ CREATE TABLE IF NOT EXISTS workflow_state (
 	updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
 	PRIMARY KEY (run_id, key)
 )
+`
+
+	postgresSchemaResourceLeases = `
+CREATE TABLE IF NOT EXISTS resource_leases (
+	resource   TEXT        NOT NULL,
+	holder     TEXT        NOT NULL,
+	expires_at TIMESTAMPTZ NOT NULL,
+	PRIMARY KEY (resource, holder)
+);
+CREATE INDEX IF NOT EXISTS idx_resource_leases_expiry ON resource_leases(resource, expires_at)
+`
+
+	postgresSchemaStepCache = `
+CREATE TABLE IF NOT EXISTS step_cache (
+	cache_key  TEXT        PRIMARY KEY,
+	output     BYTEA,
+	created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+	expires_at TIMESTAMPTZ
+);
+CREATE INDEX IF NOT EXISTS idx_step_cache_expiry ON step_cache(expires_at)
 `
 )
 
@@ -66,5 +86,7 @@ func GetPostgresSchema() string {
 		postgresSchemaStepExecutions,
 		postgresSchemaStepOutputs,
 		postgresSchemaWorkflowState,
+		postgresSchemaResourceLeases,
+		postgresSchemaStepCache,
 	}, ";\n")
 }
@@ -47,7 +47,7 @@ func truncatePostgresTables(t *testing.T, dsn string) {
 	// workflow_state, step_outputs, step_executions all FK-reference workflow_runs,
 	// so truncating in dependency order (or using RESTART IDENTITY CASCADE) is safe.
 	_, err = conn.Exec(ctx, `
-		TRUNCATE TABLE workflow_state, step_outputs, step_executions, workflow_runs
+		TRUNCATE TABLE workflow_state, step_outputs, step_executions, workflow_runs, resource_leases, step_cache
 		RESTART IDENTITY
 	`)
 	require.NoError(t, err)
@@ -174,6 +174,37 @@ func TestPostgres_ListRuns_WithLimit(t *testing.T) {
 	runs, err := s.ListRuns(ctx, gorkflow.RunFilter{Limit: 3})
 	require.NoError(t, err)
 	assert.Len(t, runs, 3)
+
+	rest, err := s.ListRuns(ctx, gorkflow.RunFilter{Limit: 3, Offset: 3})
+	require.NoError(t, err)
+	assert.Len(t, rest, 2)
+}
+
+func TestPostgres_ListRuns_After(t *testing.T) {
+	s := newTestPostgresStore(t)
+	ctx := context.Background()
+
+	base := time.Now().UTC()
+	for _, r := range []struct {
+		id  string
+		age time.Duration
+	}{{"pg-after-d", 2 * time.Second}, {"pg-after-c", time.Second}, {"pg-after-b", time.Second}, {"pg-after-a", 0}} {
+		created := base.Add(-r.age)
+		require.NoError(t, s.CreateRun(ctx, &gorkflow.WorkflowRun{
+			RunID: r.id, WorkflowID: "wf-after", Status: gorkflow.RunStatusCompleted, CreatedAt: created, UpdatedAt: created,
+		}))
+	}
+
+	first, err := s.ListRuns(ctx, gorkflow.RunFilter{WorkflowID: "wf-after", Limit: 2})
+	require.NoError(t, err)
+	require.Len(t, first, 2)
+
+	last := first[1]
+	rest, err := s.ListRuns(ctx, gorkflow.RunFilter{WorkflowID: "wf-after", After: &gorkflow.RunCursor{CreatedAt: last.CreatedAt, RunID: last.RunID}})
+	require.NoError(t, err)
+	require.Len(t, rest, 2)
+	assert.Equal(t, "pg-after-c", rest[0].RunID)
+	assert.Equal(t, "pg-after-d", rest[1].RunID)
 }
 
 func TestPostgres_ListRuns_Empty(t *testing.T) {
@@ -372,3 +403,99 @@ func TestPostgres_Schema_Idempotent(t *testing.T) {
 
 // statusPtr is a helper to take the address of a RunStatus value.
 func statusPtr(s gorkflow.RunStatus) *gorkflow.RunStatus { return &s }
+
+func TestPostgres_Leases(t *testing.T) {
+	s := newTestPostgresStore(t)
+	ctx := context.Background()
+
+	for _, holder := range []string{"a", "b"} {
+		acquired, err := s.AcquireLease(ctx, "vendor", holder, 2, time.Minute)
+		require.NoError(t, err)
+		assert.True(t, acquired, holder)
+	}
+
+	acquired, err := s.AcquireLease(ctx, "vendor", "c", 2, time.Minute)
+	require.NoError(t, err)
+	assert.False(t, acquired, "pool should be full")
+
+	acquired, err = s.AcquireLease(ctx, "vendor", "a", 2, time.Minute)
+	require.NoError(t, err)
+	assert.True(t, acquired, "holder should renew on a full pool")
+
+	require.NoError(t, s.ReleaseLease(ctx, "vendor", "a"))
+	acquired, err = s.AcquireLease(ctx, "vendor", "c", 2, time.Minute)
+	require.NoError(t, err)
+	assert.True(t, acquired)
+}
+
+func TestPostgres_DeleteRun_Cascades(t *testing.T) {
+	s := newTestPostgresStore(t)
+	ctx := context.Background()
+
+	now := time.Now().UTC()
+	require.NoError(t, s.CreateRun(ctx, &gorkflow.WorkflowRun{
+		RunID: "run-1", WorkflowID: "wf", Status: gorkflow.RunStatusCompleted, CreatedAt: now, UpdatedAt: now,
+	}))
+	require.NoError(t, s.CreateStepExecution(ctx, &gorkflow.StepExecution{RunID: "run-1", StepID: "step-1", Status: gorkflow.StepStatusCompleted, CreatedAt: time.Now()}))
+	require.NoError(t, s.SaveStepOutput(ctx, "run-1", "step-1", []byte("output")))
+	require.NoError(t, s.SaveState(ctx, "run-1", "key", []byte("value")))
+
+	require.NoError(t, s.DeleteRun(ctx, "run-1"))
+
+	_, err := s.GetStepExecution(ctx, "run-1", "step-1")
+	assert.ErrorIs(t, err, gorkflow.ErrStepExecutionNotFound)
+	_, err = s.LoadStepOutput(ctx, "run-1", "step-1")
+	assert.ErrorIs(t, err, gorkflow.ErrStepOutputNotFound)
+	_, err = s.LoadState(ctx, "run-1", "key")
+	assert.ErrorIs(t, err, gorkflow.ErrStateNotFound)
+
+	assert.ErrorIs(t, s.DeleteRun(ctx, "run-1"), gorkflow.ErrRunNotFound)
+}
+
+func TestPostgres_PurgeRuns(t *testing.T) {
+	s := newTestPostgresStore(t)
+	ctx := context.Background()
+
+	old := time.Now().Add(-2 * time.Hour)
+	for _, run := range []*gorkflow.WorkflowRun{
+		{RunID: "old-completed", WorkflowID: "wf", Status: gorkflow.RunStatusCompleted, CreatedAt: old, UpdatedAt: old},
+		{RunID: "old-running", WorkflowID: "wf", Status: gorkflow.RunStatusRunning, CreatedAt: old, UpdatedAt: old},
+		{RunID: "recent-failed", WorkflowID: "wf", Status: gorkflow.RunStatusFailed, CreatedAt: old, UpdatedAt: time.Now()},
+		{RunID: "old-other", WorkflowID: "other", Status: gorkflow.RunStatusCancelled, CreatedAt: old, UpdatedAt: old},
+	} {
+		require.NoError(t, s.CreateRun(ctx, run))
+	}
+
+	cutoff := time.Now().Add(-time.Hour)
+	purged, err := s.PurgeRuns(ctx, gorkflow.PurgeFilter{OlderThan: cutoff, ExcludeWorkflowIDs: []string{"other"}})
+	require.NoError(t, err)
+	assert.Equal(t, 1, purged)
+
+	purged, err = s.PurgeRuns(ctx, gorkflow.PurgeFilter{OlderThan: cutoff, WorkflowID: "other"})
+	require.NoError(t, err)
+	assert.Equal(t, 1, purged)
+
+	remaining, err := s.ListRuns(ctx, gorkflow.RunFilter{})
+	require.NoError(t, err)
+	assert.Len(t, remaining, 2)
+}
+
+func TestPostgres_StepCache(t *testing.T) {
+	s := newTestPostgresStore(t)
+	ctx := context.Background()
+
+	var _ gorkflow.StepCache = s
+
+	_, err := s.GetCachedOutput(ctx, "step:key")
+	assert.ErrorIs(t, err, gorkflow.ErrCacheMiss)
+
+	require.NoError(t, s.PutCachedOutput(ctx, "step:key", []byte(`{"v":1}`), 0))
+	output, err := s.GetCachedOutput(ctx, "step:key")
+	require.NoError(t, err)
+	assert.Equal(t, []byte(`{"v":1}`), output)
+
+	require.NoError(t, s.PutCachedOutput(ctx, "step:key", []byte(`{"v":2}`), time.Nanosecond))
+	time.Sleep(10 * time.Millisecond)
+	_, err = s.GetCachedOutput(ctx, "step:key")
+	assert.ErrorIs(t, err, gorkflow.ErrCacheMiss)
+}
@@ -1,6 +1,9 @@
 package gorkflow
 
-import "context"
+import (
+	"context"
+	"time"
+)
 
 // WorkflowStore defines the persistence interface for workflows
 type WorkflowStore interface {
@@ -26,6 +29,9 @@ type WorkflowStore interface {
 	DeleteState(ctx context.Context, runID, key string) error
 	GetAllState(ctx context.Context, runID string) (map[string][]byte, error)
 
+	// Retention
+	DeleteRun(ctx context.Context, runID string) error
+	PurgeRuns(ctx context.Context, filter PurgeFilter) (int, error)
 }
 
 // RunFilter defines filtering criteria for workflow runs
@@ -34,4 +40,64 @@ type RunFilter struct {
 	Status     *RunStatus
 	ResourceID string
 	Limit      int
+
+	// Offset skips this many runs of the result, for pagination
+	Offset int
+
+	// After lists only the runs that follow this position in the result order.
+	// Unlike Offset, paging with the last run of each page is not disturbed by
+	// runs created or deleted in the meantime.
+	After *RunCursor
+}
+
+// RunCursor is a position in a run listing, which is ordered by creation time,
+// newest first, then by run ID
+type RunCursor struct {
+	CreatedAt time.Time
+	RunID     string
+}
+
+// PurgeFilter selects the runs removed by PurgeRuns. Deleting a run also removes
+// its step executions, step outputs and state.
+type PurgeFilter struct {
+	// OlderThan matches runs last updated before this time
+	OlderThan time.Time
+
+	// Statuses to purge; empty means the terminal statuses
+	Statuses []RunStatus
+
+	// WorkflowID restricts the purge to one workflow
+	WorkflowID string
+
+	// ExcludeWorkflowIDs skips runs of these workflows
+	ExcludeWorkflowIDs []string
+}
+
+// RunStatuses returns the statuses matched by the filter
+func (f PurgeFilter) RunStatuses() []RunStatus {
+	if len(f.Statuses) > 0 {
+		return f.Statuses
+	}
+	return []RunStatus{RunStatusCompleted, RunStatusFailed, RunStatusCancelled}
+}
+
+// Matches reports whether run is selected by the filter
+func (f PurgeFilter) Matches(run *WorkflowRun) bool {
+	if !run.UpdatedAt.Before(f.OlderThan) {
+		return false
+	}
+	if f.WorkflowID != "" && run.WorkflowID != f.WorkflowID {
+		return false
+	}
+	for _, id := range f.ExcludeWorkflowIDs {
+		if run.WorkflowID == id {
+			return false
+		}
+	}
+	for _, status := range f.RunStatuses() {
+		if run.Status == status {
+			return true
+		}
+	}
+	return false
 }
@@ -0,0 +1,36 @@
+package gorkflow
+
+import "time"
+
+// RunUpdate is a live status notification delivered to run subscribers. Every
+// update carries the run status and progress after the change; step updates
+// also carry the status of the step that changed.
+type RunUpdate struct {
+	RunID      string    `json:"runId"`
+	WorkflowID string    `json:"workflowId"`
+	Timestamp  time.Time `json:"timestamp"`
+
+	// Run state after the update
+	Status   RunStatus `json:"status"`
+	Progress float64   `json:"progress"` // 0.0 to 1.0
+
+	// Step state, empty for run-level updates
+	StepID     string     `json:"stepId,omitempty"`
+	StepName   string     `json:"stepName,omitempty"`
+	StepStatus StepStatus `json:"stepStatus,omitempty"`
+	Attempt    int        `json:"attempt,omitempty"`
+
+	// Error message of a failed run or step
+	Error string `json:"error,omitempty"`
+
+	// Replay marks updates describing state that existed before subscribing
+	Replay bool `json:"replay,omitempty"`
+
+	// Dropped counts earlier updates discarded because the subscriber fell behind
+	Dropped int `json:"dropped,omitempty"`
+}
+
+// IsStepUpdate returns true if the update describes a single step
+func (u RunUpdate) IsStepUpdate() bool {
+	return u.StepID != ""
+}
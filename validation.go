@@ -1,7 +1,6 @@
 package gorkflow
 
 import (
-	"encoding/json"
 	"fmt"
 	"reflect"
 
@@ -54,7 +53,7 @@ func validateInputData[T any](data []byte, config *validationConfig) (T, error)
 	var input T
 
 	// Unmarshal
-	if err := json.Unmarshal(data, &input); err != nil {
+	if err := UnmarshalPayload(data, &input); err != nil {
 		return input, fmt.Errorf("failed to unmarshal input: %w", err)
 	}
 
@@ -69,7 +68,7 @@ func validateInputData[T any](data []byte, config *validationConfig) (T, error)
 }
 
 // validateOutputData validates and marshals output data
-func validateOutputData[T any](output T, config *validationConfig) ([]byte, error) {
+func validateOutputData[T any](output T, config *validationConfig, serializer Serializer) ([]byte, error) {
 	// Validate if enabled
 	if config != nil && config.validateOutput {
 		if err := config.validateStruct(output); err != nil {
@@ -78,7 +77,7 @@ func validateOutputData[T any](output T, config *validationConfig) ([]byte, erro
 	}
 
 	// Marshal
-	outputBytes, err := json.Marshal(output)
+	outputBytes, err := MarshalPayload(serializer, output)
 	if err != nil {
 		return nil, fmt.Errorf("failed to marshal output: %w", err)
 	}
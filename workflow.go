@@ -27,6 +27,12 @@ type Workflow struct {
 
 	// Custom context
 	customContext any
+
+	// Workflow-level step interceptors
+	interceptors []StepInterceptor
+
+	// Serializer of run inputs, step outputs and state values
+	serializer Serializer
 }
 
 // ID returns the workflow ID
@@ -73,11 +79,50 @@ func (w *Workflow) GetConfig() ExecutionConfig {
 	return w.config
 }
 
+// Tags returns the workflow tags
+func (w *Workflow) Tags() map[string]string {
+	return w.tags
+}
+
 // GetContext returns the custom context
 func (w *Workflow) GetContext() any {
 	return w.customContext
 }
 
+// DOT renders the workflow graph as a Graphviz digraph labelled with step
+// names and descriptions
+func (w *Workflow) DOT(opts ...GraphExportOption) string {
+	return w.graph.DOT(w.graphExportOptions(opts)...)
+}
+
+// Mermaid renders the workflow graph as a Mermaid flowchart labelled with
+// step names and descriptions
+func (w *Workflow) Mermaid(opts ...GraphExportOption) string {
+	return w.graph.Mermaid(w.graphExportOptions(opts)...)
+}
+
+func (w *Workflow) graphExportOptions(opts []GraphExportOption) []GraphExportOption {
+	return append([]GraphExportOption{WithGraphTitle(w.name), WithGraphSteps(w.steps)}, opts...)
+}
+
+// Serializer returns the serializer of the workflow's payloads
+func (w *Workflow) Serializer() Serializer {
+	if w.serializer == nil {
+		return JSONSerializer
+	}
+	return w.serializer
+}
+
+// SetSerializer sets the serializer of the workflow's payloads
+func (w *Workflow) SetSerializer(s Serializer) {
+	w.serializer = s
+}
+
+// Interceptors returns the workflow-level step interceptors
+func (w *Workflow) Interceptors() []StepInterceptor {
+	return w.interceptors
+}
+
 // WorkflowOption configures a workflow
 type WorkflowOption func(*Workflow)
 
@@ -141,6 +186,11 @@ func (w *Workflow) SetContext(ctx any) {
 	w.customContext = ctx
 }
 
+// AddInterceptors appends workflow-level step interceptors
+func (w *Workflow) AddInterceptors(interceptors ...StepInterceptor) {
+	w.interceptors = append(w.interceptors, interceptors...)
+}
+
 // Validate performs comprehensive validation on the workflow
 func (w *Workflow) Validate() error {
 	// Validate graph structure
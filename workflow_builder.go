@@ -2,6 +2,8 @@ package gorkflow
 
 import (
 	"fmt"
+	"slices"
+	"strings"
 )
 
 // WorkflowBuilder provides a fluent API for building workflows
@@ -9,6 +11,16 @@ type WorkflowBuilder struct {
 	workflow     *Workflow
 	lastStepIDs  []string
 	currentChain []string
+
+	// declared holds the steps added with Step, wired by their dependencies in Build
+	declared []declaredStep
+	err      error
+}
+
+// declaredStep is a step added with Step and the steps it depends on
+type declaredStep struct {
+	stepID    string
+	dependsOn []string
 }
 
 // NewWorkflow creates a new workflow builder
@@ -50,6 +62,21 @@ func (b *WorkflowBuilder) WithContext(ctx any) *WorkflowBuilder {
 	return b
 }
 
+// WithSerializer sets the serializer of run inputs, step outputs and state
+// values (default JSONSerializer). Each payload records its format, so runs
+// written with another serializer stay readable.
+func (b *WorkflowBuilder) WithSerializer(s Serializer) *WorkflowBuilder {
+	b.workflow.SetSerializer(s)
+	return b
+}
+
+// Use adds workflow-level interceptors that wrap every step of the workflow.
+// They run inside engine-level interceptors and outside step-level ones.
+func (b *WorkflowBuilder) Use(interceptors ...StepInterceptor) *WorkflowBuilder {
+	b.workflow.AddInterceptors(interceptors...)
+	return b
+}
+
 // ThenStep chains the given step after the last added step
 func (b *WorkflowBuilder) ThenStep(step StepExecutor) *WorkflowBuilder {
 	stepID := step.GetID()
@@ -129,6 +156,112 @@ func (b *WorkflowBuilder) ThenStepIf(step StepExecutor, condition Condition, def
 	return b.ThenStep(wrappedStep)
 }
 
+// Step adds a step that runs after the steps it declares with DependsOn, or at
+// the start of the workflow if it declares none. Dependencies may reference
+// steps added later; they are resolved by Build. Workflows built with Step
+// can have several root steps and arbitrary joins, and cannot be combined
+// with ThenStep, Parallel or Sequence.
+//
+// Example:
+//
+//	builder.
+//	    Step(fetchUser).
+//	    Step(fetchOrders).
+//	    Step(report).DependsOn("fetch-user", "fetch-orders").
+//	    Step(notify).DependsOn("fetch-user")
+//
+// A step with several dependencies receives the output of the first one and
+// reads the others with GetOutput.
+func (b *WorkflowBuilder) Step(step StepExecutor) *WorkflowBuilder {
+	stepID := step.GetID()
+	if _, err := b.workflow.GetStep(stepID); err == nil {
+		b.setErr(fmt.Errorf("step %s added twice", stepID))
+		return b
+	}
+	b.workflow.AddStep(step)
+	b.declared = append(b.declared, declaredStep{stepID: stepID})
+	return b
+}
+
+// StepIf adds a step like Step that executes only if condition evaluates to
+// true at runtime; otherwise defaultValue is used as its output
+func (b *WorkflowBuilder) StepIf(step StepExecutor, condition Condition, defaultValue any) *WorkflowBuilder {
+	return b.Step(WrapStepWithCondition(step, condition, defaultValue))
+}
+
+// DependsOn declares the steps that the step last added with Step runs after
+func (b *WorkflowBuilder) DependsOn(stepIDs ...string) *WorkflowBuilder {
+	if len(b.declared) == 0 {
+		b.setErr(fmt.Errorf("DependsOn(%s) must follow Step", strings.Join(stepIDs, ", ")))
+		return b
+	}
+	last := &b.declared[len(b.declared)-1]
+	for _, stepID := range stepIDs {
+		if !slices.Contains(last.dependsOn, stepID) {
+			last.dependsOn = append(last.dependsOn, stepID)
+		}
+	}
+	return b
+}
+
+// setErr records the first error of the builder, returned by Build
+func (b *WorkflowBuilder) setErr(err error) {
+	if b.err == nil {
+		b.err = err
+	}
+}
+
+// wireDependencies adds the edges declared with DependsOn and makes the steps
+// without dependencies the entry points
+func (b *WorkflowBuilder) wireDependencies() error {
+	if len(b.currentChain) > 0 {
+		return fmt.Errorf("steps added with Step cannot be combined with ThenStep, Parallel or Sequence")
+	}
+
+	graph := b.workflow.graph
+	graph.EntryPoint = ""
+	for _, decl := range b.declared {
+		for _, dep := range decl.dependsOn {
+			if dep == decl.stepID {
+				return fmt.Errorf("step %s depends on itself", decl.stepID)
+			}
+			if _, exists := graph.Nodes[dep]; !exists {
+				return fmt.Errorf("step %s depends on %s: %w", decl.stepID, dep, ErrUnknownDependency)
+			}
+			if err := graph.AddEdge(dep, decl.stepID); err != nil {
+				return err
+			}
+		}
+	}
+	for _, decl := range b.declared {
+		if len(decl.dependsOn) == 0 {
+			if err := graph.AddEntryPoint(decl.stepID); err != nil {
+				return err
+			}
+		}
+	}
+	if graph.EntryPoint == "" {
+		return fmt.Errorf("every step has dependencies, so the dependencies contain a cycle")
+	}
+
+	// Every root receives the workflow input, so they must agree on its type
+	entry, err := b.workflow.GetStep(graph.EntryPoint)
+	if err != nil {
+		return err
+	}
+	for _, stepID := range graph.EntryPoints()[1:] {
+		root, err := b.workflow.GetStep(stepID)
+		if err != nil {
+			return err
+		}
+		if root.InputType() != entry.InputType() {
+			return fmt.Errorf("root steps %s and %s take different input types (%v, %v); every root receives the workflow input",
+				entry.GetID(), root.GetID(), entry.InputType(), root.InputType())
+		}
+	}
+	return nil
+}
+
 // SetEntryPoint sets the workflow entry point explicitly
 func (b *WorkflowBuilder) SetEntryPoint(stepID string) *WorkflowBuilder {
 	if err := b.workflow.graph.SetEntryPoint(stepID); err != nil {
@@ -139,6 +272,17 @@ func (b *WorkflowBuilder) SetEntryPoint(stepID string) *WorkflowBuilder {
 
 // Build finalizes and validates the workflow
 func (b *WorkflowBuilder) Build() (*Workflow, error) {
+	if b.err != nil {
+		return nil, b.err
+	}
+	if len(b.declared) > 0 {
+		if err := b.wireDependencies(); err != nil {
+			return nil, fmt.Errorf("invalid workflow graph: %w", err)
+		}
+		// Wire once, so calling Build again does not add the edges twice
+		b.declared = nil
+	}
+
 	// Validate graph
 	if err := b.workflow.graph.Validate(); err != nil {
 		return nil, fmt.Errorf("invalid workflow graph: %w", err)
@@ -356,3 +356,114 @@ func TestWorkflowBuilder_MultipleConditionalSteps(t *testing.T) {
 	require.NoError(t, err)
 	assert.Contains(t, nextSteps3, "step4")
 }
+
+func TestWorkflowBuilder_DependsOn(t *testing.T) {
+	a := gorkflow.NewStep("a", "A", testHandler)
+	b := gorkflow.NewStep("b", "B", testHandler)
+	c := gorkflow.NewStep("c", "C", testHandler)
+	d := gorkflow.NewStep("d", "D", testHandler)
+	e := gorkflow.NewStep("e", "E", testHandler)
+
+	// Dependencies may reference steps added later
+	wf, err := gorkflow.NewWorkflow("dag", "DAG").
+		Step(e).DependsOn("c", "d").
+		Step(a).
+		Step(b).
+		Step(c).DependsOn("a", "b", "a").
+		Step(d).DependsOn("a").
+		Build()
+	require.NoError(t, err)
+
+	graph := wf.Graph()
+	assert.Equal(t, []string{"a", "b"}, graph.EntryPoints())
+	assert.Equal(t, []string{"a", "b"}, graph.Nodes["c"].Previous)
+	assert.Equal(t, []string{"c", "d"}, graph.Nodes["e"].Previous)
+	assert.ElementsMatch(t, []string{"c", "d"}, graph.Nodes["a"].Next)
+
+	levels, err := graph.ComputeLevels()
+	require.NoError(t, err)
+	require.Len(t, levels, 3)
+	assert.ElementsMatch(t, []string{"a", "b"}, levels[0])
+	assert.ElementsMatch(t, []string{"c", "d"}, levels[1])
+	assert.Equal(t, []string{"e"}, levels[2])
+}
+
+func TestWorkflowBuilder_StepIf(t *testing.T) {
+	never := func(ctx *gorkflow.StepContext) (bool, error) { return false, nil }
+
+	wf, err := gorkflow.NewWorkflow("dag", "DAG").
+		Step(gorkflow.NewStep("a", "A", testHandler)).
+		StepIf(gorkflow.NewStep("b", "B", testHandler), never, nil).DependsOn("a").
+		Build()
+	require.NoError(t, err)
+
+	step, err := wf.GetStep("b")
+	require.NoError(t, err)
+	assert.True(t, gorkflow.IsConditionalStep(step))
+	assert.Equal(t, []string{"a"}, wf.Graph().Nodes["b"].Previous)
+}
+
+func TestWorkflowBuilder_DependsOn_Invalid(t *testing.T) {
+	step := func(id string) gorkflow.StepExecutor {
+		return gorkflow.NewStep(id, id, testHandler)
+	}
+
+	tests := []struct {
+		name    string
+		builder *gorkflow.WorkflowBuilder
+		err     string
+	}{
+		{
+			name:    "unknown dependency",
+			builder: gorkflow.NewWorkflow("dag", "DAG").Step(step("a")).Step(step("b")).DependsOn("a", "z"),
+			err:     "step b depends on z",
+		},
+		{
+			name:    "self dependency",
+			builder: gorkflow.NewWorkflow("dag", "DAG").Step(step("a")).DependsOn("a"),
+			err:     "step a depends on itself",
+		},
+		{
+			name:    "cycle",
+			builder: gorkflow.NewWorkflow("dag", "DAG").Step(step("a")).Step(step("b")).DependsOn("c").Step(step("c")).DependsOn("b"),
+			err:     "cycles",
+		},
+		{
+			name:    "no root",
+			builder: gorkflow.NewWorkflow("dag", "DAG").Step(step("a")).DependsOn("b").Step(step("b")).DependsOn("a"),
+			err:     "every step has dependencies",
+		},
+		{
+			name: "roots with different input types",
+			builder: gorkflow.NewWorkflow("dag", "DAG").
+				Step(step("a")).
+				Step(gorkflow.NewStep("b", "B", func(ctx *gorkflow.StepContext, in int) (int, error) { return in, nil })),
+			err: "root steps a and b take different input types",
+		},
+		{
+			name:    "duplicate step",
+			builder: gorkflow.NewWorkflow("dag", "DAG").Step(step("a")).Step(step("a")),
+			err:     "step a added twice",
+		},
+		{
+			name:    "DependsOn without Step",
+			builder: gorkflow.NewWorkflow("dag", "DAG").DependsOn("a"),
+			err:     "must follow Step",
+		},
+		{
+			name:    "mixed with ThenStep",
+			builder: gorkflow.NewWorkflow("dag", "DAG").ThenStep(step("a")).Step(step("b")).DependsOn("a"),
+			err:     "cannot be combined",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			wf, err := tt.builder.Build()
+			assert.Nil(t, wf)
+			assert.ErrorContains(t, err, tt.err)
+		})
+	}
+
+	_, err := gorkflow.NewWorkflow("dag", "DAG").Step(step("a")).DependsOn("z").Build()
+	assert.ErrorIs(t, err, gorkflow.ErrUnknownDependency)
+}